@@ -0,0 +1,134 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// abLoop follows the position of the looped track.
+type abLoop struct {
+	positionClock
+	a, b    time.Duration
+	trackID TrackID
+}
+
+// untilB returns how long until the position reaches b, or false if it doesn't move.
+func (l *abLoop) untilB(now time.Time) (time.Duration, bool) {
+	if !l.playing || l.rate <= 0 {
+		return 0, false
+	}
+	remaining := l.b - l.current(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(float64(remaining) / l.rate), true
+}
+
+// ABLoop loops the section of the current track between the positions a and b, seeking back
+// to a each time the playback reaches b. It starts by seeking to a, then blocks until ctx is
+// done, returning its error, or the track changes, returning nil. Seeking past b jumps back to
+// a too, while seeking before a plays on until b.
+//
+// The position is extrapolated from the Seeked signals and the playback status and rate, so
+// the loop doesn't poll the player. It returns ErrNotSupported if the player can't seek,
+// ErrNilVariant if the track has no id or length, and an error if the section is empty once
+// clamped to the track length.
+func (i *Player) ABLoop(ctx context.Context, a, b time.Duration) error {
+	if a < 0 || b <= a {
+		return errors.New("invalid loop section, a must not be negative and must be before b")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	player := i.WithContext(ctx)
+
+	// subscribe before reading the state, so a change in the meantime isn't missed
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	target, err := player.getSeekTarget()
+	if err != nil {
+		return err
+	}
+	state, err := player.GetState()
+	if err != nil {
+		return err
+	}
+	loop := &abLoop{
+		positionClock: positionClock{playing: state.PlaybackStatus == PlaybackPlaying, rate: state.Rate},
+		a:             target.clamp(a),
+		b:             target.clamp(b),
+		trackID:       target.trackID,
+	}
+	// past the end of the track, a and b are both clamped to its length
+	if loop.b <= loop.a {
+		return errors.New("invalid loop section, a must be before the end of the track")
+	}
+	if loop.rate == 0 {
+		loop.rate = 1
+	}
+	seekToA := func() error {
+		loop.update(loop.a, time.Now())
+		return player.SetPositionWithTrackID(loop.trackID, loop.a)
+	}
+	if err := seekToA(); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		now := time.Now()
+		if loop.current(now) >= loop.b {
+			if err := seekToA(); err != nil {
+				return err
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var timeout <-chan time.Time
+		if wait, ok := loop.untilB(now); ok {
+			timer.Reset(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+		case event, ok := <-events:
+			if !ok {
+				return closedError(ctx)
+			}
+			if changed := loop.handle(event, time.Now()); changed {
+				return nil
+			}
+		}
+	}
+}
+
+// handle updates the loop with the event, returning true if the track changed.
+func (l *abLoop) handle(event Event, now time.Time) bool {
+	switch event := event.(type) {
+	case SeekedEvent:
+		l.update(event.Position, now)
+	case PropertiesChangedEvent:
+		if event.Interface != PlayerInterface {
+			return false
+		}
+		if value, ok := event.Changed["Metadata"].Value().(map[string]dbus.Variant); ok {
+			if Metadata(value).TrackID() != l.trackID {
+				return true
+			}
+		}
+		l.applyChanges(event.Changed, now)
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestABLoop(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(10000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	seeksToA := func() int {
+		seeks := 0
+		for _, call := range fake.Calls() {
+			if call.Method == "SetPosition" && call.Args[1] == int64(1000000) {
+				seeks++
+			}
+		}
+		return seeks
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- player.ABLoop(ctx, time.Second, 1100*time.Millisecond)
+	}()
+
+	// the 100ms section loops a few times
+	mpristest.Eventually(t, func() bool { return seeksToA() >= 3 }, "Expected the section to loop")
+
+	// pausing stops the loop, seeking past b jumps back to a
+	if err := fake.SetPlaybackStatus("Paused"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	before := seeksToA()
+	time.Sleep(250 * time.Millisecond)
+	if seeks := seeksToA(); seeks != before {
+		t.Errorf("Expected no seek while paused, got %d more", seeks-before)
+	}
+	if err := fake.EmitSeeked(5000000); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool { return seeksToA() > before }, "Expected seeking past b to jump back to a")
+
+	// a new track ends the loop
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/2")),
+		"mpris:length":  dbus.MakeVariant(int64(10000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the loop to end without error, got %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("The loop didn't end with the track")
+	}
+
+	if err := player.ABLoop(ctx, 2*time.Second, time.Second); err == nil {
+		t.Error("Expected an error for a section ending before it starts")
+	}
+
+	seeks := len(fake.Calls())
+	if err := player.ABLoop(ctx, 20*time.Second, 30*time.Second); err == nil {
+		t.Error("Expected an error for a section past the end of the track")
+	}
+	for _, call := range fake.Calls()[seeks:] {
+		if call.Method == "SetPosition" {
+			t.Errorf("Expected no seek for a section past the end of the track, got %v", call.Args)
+		}
+	}
+}
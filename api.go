@@ -0,0 +1,87 @@
+package mpris
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// PlayerAPI is the interface implemented by Player, so code controlling players can be tested
+// with a fake, like the one of the mprisfake package. It leaves out WithContext and the raw
+// signal subscriptions, which are tied to the D-Bus connection.
+type PlayerAPI interface {
+	GetName() string
+	Owner() (string, error)
+	PID() (uint32, error)
+	Context() context.Context
+	Exists() (bool, error)
+	Ping() error
+	Close() error
+
+	Raise() error
+	Quit() error
+	GetIdentity() (string, error)
+	GetDesktopEntry() (string, error)
+	HasTrackList() (bool, error)
+	GetSupportedUriSchemes() ([]string, error)
+	GetSupportedMimeTypes() ([]string, error)
+
+	Next() error
+	Previous() error
+	Pause() error
+	PlayPause() error
+	Stop() error
+	Play() error
+	OpenUri(uri string) error
+
+	Seek(offset float64) error
+	SeekBy(offset time.Duration) error
+	SeekTo(position time.Duration) error
+	SeekPercent(percent float64) error
+	SetTrackPosition(trackId *dbus.ObjectPath, position float64) error
+	SetPosition(position float64) error
+	SetPositionWithTrackID(trackID TrackID, position time.Duration) error
+	SetPositionPercent(percent float64) error
+	CanSeek() (bool, error)
+	GetPosition() (float64, error)
+	GetPositionDuration() (time.Duration, error)
+	GetLength() (float64, error)
+	GetLengthDuration() (time.Duration, error)
+
+	GetPlaybackStatus() (PlaybackStatus, error)
+	GetLoopStatus() (LoopStatus, error)
+	SetLoopStatus(loopStatus LoopStatus) error
+	CycleLoopStatus() (LoopStatus, error)
+	GetShuffle() (bool, error)
+	SetShuffle(value bool) error
+	ToggleShuffle() (bool, error)
+	GetRate() (float64, error)
+	GetVolume() (float64, error)
+	SetVolume(volume float64) error
+	IncreaseVolume(step float64) (float64, error)
+	DecreaseVolume(step float64) (float64, error)
+	ToggleMute() (bool, error)
+
+	GetMetadata() (Metadata, error)
+	GetTitle() (string, error)
+	GetArtists() ([]string, error)
+	GetAlbum() (string, error)
+	GetArtURL() (string, error)
+	GetState() (PlayerState, error)
+
+	GetProperty(targetInterface, propertyName string) (dbus.Variant, error)
+	GetPlayerProperty(propertyName string) (dbus.Variant, error)
+	SetProperty(targetInterface, propertyName string, value interface{}) error
+	SetPlayerProperty(propertyName string, value interface{}) error
+
+	Subscribe(ctx context.Context, opts ...SubscribeOption) (<-chan Event, error)
+	Events(ctx context.Context, opts ...SubscribeOption) iter.Seq[Event]
+	OnTrackChange(ctx context.Context) (<-chan TrackChangeEvent, error)
+	TrackChanges(ctx context.Context) iter.Seq[TrackChangeEvent]
+	WaitForStatus(ctx context.Context, status PlaybackStatus) error
+	WaitForTrackChange(ctx context.Context) (Metadata, error)
+}
+
+var _ PlayerAPI = (*Player)(nil)
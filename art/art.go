@@ -0,0 +1,171 @@
+// Package art fetches the album art of the tracks played by MPRIS players, as announced in the
+// mpris:artUrl metadata.
+package art
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// DefaultMaxSize is the maximum art size used when Fetcher.MaxSize is not set.
+const DefaultMaxSize = 10 << 20
+
+var (
+	// ErrUnsupportedScheme is returned for art urls that aren't file, http, https or data urls.
+	ErrUnsupportedScheme = errors.New("unsupported art url scheme")
+	// ErrTooLarge is returned when the art is bigger than the fetcher max size.
+	ErrTooLarge = errors.New("art is too large")
+)
+
+// Fetcher fetches art from file://, http(s):// and data: urls. The zero value is ready to use.
+type Fetcher struct {
+	// Client is used to download http(s) urls. Defaults to http.DefaultClient.
+	Client *http.Client
+	// CacheDir is where downloaded art is stored, keyed by track id. Caching is disabled when
+	// it's empty.
+	CacheDir string
+	// MaxSize is the maximum size of the art in bytes. Defaults to DefaultMaxSize.
+	MaxSize int64
+}
+
+// Fetch fetches the art of the track with the given id. The track id is only used as the
+// cache key, so it can be empty.
+func (f *Fetcher) Fetch(ctx context.Context, artURL, trackID string) ([]byte, error) {
+	u, err := url.Parse(artURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		return f.readFile(u.Path)
+	case "data":
+		return f.decodeData(u.Opaque)
+	case "http", "https":
+		return f.download(ctx, artURL, trackID)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, u.Scheme)
+}
+
+// FetchMetadata fetches the art of the track described by the metadata.
+func (f *Fetcher) FetchMetadata(ctx context.Context, metadata mpris.Metadata) ([]byte, error) {
+	return f.Fetch(ctx, metadata.ArtURL(), string(metadata.TrackID()))
+}
+
+// Fetch fetches the art url with the default fetcher, without caching.
+func Fetch(ctx context.Context, artURL string) ([]byte, error) {
+	return (&Fetcher{}).Fetch(ctx, artURL, "")
+}
+
+func (f *Fetcher) maxSize() int64 {
+	if f.MaxSize > 0 {
+		return f.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+// readAll reads r failing with ErrTooLarge instead of reading more than the max size.
+func (f *Fetcher) readAll(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, f.maxSize()+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.maxSize() {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+func (f *Fetcher) readFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return f.readAll(file)
+}
+
+// decodeData decodes the part after "data:" of a data url, like "image/png;base64,iVBOR...".
+func (f *Fetcher) decodeData(data string) ([]byte, error) {
+	comma := strings.Index(data, ",")
+	if comma == -1 {
+		return nil, errors.New("malformed data url")
+	}
+	mediaType, payload := data[:comma], data[comma+1:]
+
+	var reader io.Reader
+	if strings.HasSuffix(mediaType, ";base64") {
+		reader = base64.NewDecoder(base64.StdEncoding, strings.NewReader(payload))
+	} else {
+		unescaped, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, err
+		}
+		reader = strings.NewReader(unescaped)
+	}
+	return f.readAll(reader)
+}
+
+func (f *Fetcher) download(ctx context.Context, artURL, trackID string) ([]byte, error) {
+	cachePath := f.cachePath(artURL, trackID)
+	if cachePath != "" {
+		if data, err := ioutil.ReadFile(cachePath); err == nil {
+			return data, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching art: %s", res.Status)
+	}
+
+	data, err := f.readAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(f.CacheDir, 0700); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(cachePath, data, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// cachePath returns where the art is cached, or an empty string when it's not cacheable.
+// The url is part of the key since some players reuse track ids.
+func (f *Fetcher) cachePath(artURL, trackID string) string {
+	if f.CacheDir == "" || trackID == "" || trackID == string(mpris.NoTrack) {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(trackID + "\x00" + artURL))
+	return filepath.Join(f.CacheDir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,96 @@
+package art
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var image = []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a}
+
+func TestFetchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "art")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cover.png")
+	if err := ioutil.WriteFile(path, image, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Fetch(context.Background(), "file://"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, image) {
+		t.Errorf("Unexpected art %v", data)
+	}
+}
+
+func TestFetchData(t *testing.T) {
+	data, err := Fetch(context.Background(), "data:image/png;base64,iVBORw0K")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, image) {
+		t.Errorf("Unexpected art %v", data)
+	}
+
+	data, err = Fetch(context.Background(), "data:text/plain,hello%20world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("Unexpected art %q", data)
+	}
+}
+
+func TestFetchHTTPCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(image)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "art")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fetcher := &Fetcher{Client: server.Client(), CacheDir: filepath.Join(dir, "cache")}
+	for i := 0; i < 2; i++ {
+		data, err := fetcher.Fetch(context.Background(), server.URL+"/cover.png", "/org/mpris/MediaPlayer2/Track/1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, image) {
+			t.Errorf("Unexpected art %v", data)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second fetch to hit the cache, got %d requests", requests)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	_, err := Fetch(context.Background(), "ftp://example.com/cover.png")
+	if !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("Expected ErrUnsupportedScheme, got %v", err)
+	}
+
+	fetcher := &Fetcher{MaxSize: 2}
+	_, err = fetcher.Fetch(context.Background(), "data:image/png;base64,iVBORw0K", "")
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package mpris
+
+import (
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// goCall sends the call without waiting for the answer. The error of the call, nil if it
+// succeeded, is sent on the returned channel once the player answers, which is then closed.
+func (i *Player) goCall(method string, args ...interface{}) <-chan error {
+	ctx, done := i.startCall(method, args)
+	call := i.obj.GoWithContext(ctx, method, i.flags, make(chan *dbus.Call, 1), args...)
+	result := make(chan error, 1)
+	go func() {
+		<-call.Done
+		done(call)
+		result <- call.Err
+		close(result)
+	}()
+	return result
+}
+
+// goSetProperty sets the property like setProperty, without waiting for the answer.
+func (i *Player) goSetProperty(iface string, prop string, val interface{}) <-chan error {
+	result := i.goCall(setPropertyMethod, iface, prop, dbus.MakeVariant(val))
+	if i.cache == nil {
+		return result
+	}
+	invalidated := make(chan error, 1)
+	go func() {
+		err := <-result
+		i.cache.invalidate(iface, prop)
+		invalidated <- err
+		close(invalidated)
+	}()
+	return invalidated
+}
+
+// NextAsync skips to the next track like Next, without waiting for the player to answer. The
+// error of the call is sent on the returned channel once it does, as for all the Async
+// methods, so a GUI can call them from its UI thread:
+//
+//	result := player.NextAsync()
+//	// ...
+//	if err := <-result; err != nil {
+//		log.Print(err)
+//	}
+func (i *Player) NextAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".Next")
+}
+
+// PreviousAsync skips to the previous track like Previous, without waiting for the player.
+func (i *Player) PreviousAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".Previous")
+}
+
+// PauseAsync pauses like Pause, without waiting for the player.
+func (i *Player) PauseAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".Pause")
+}
+
+// PlayPauseAsync toggles the playback like PlayPause, without waiting for the player.
+func (i *Player) PlayPauseAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".PlayPause")
+}
+
+// StopAsync stops like Stop, without waiting for the player.
+func (i *Player) StopAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".Stop")
+}
+
+// PlayAsync starts or resumes the playback like Play, without waiting for the player.
+func (i *Player) PlayAsync() <-chan error {
+	return i.goCall(PlayerInterface + ".Play")
+}
+
+// SeekByAsync seeks by the offset like SeekBy, without waiting for the player.
+func (i *Player) SeekByAsync(offset time.Duration) <-chan error {
+	return i.goCall(PlayerInterface+".Seek", durationToMicroseconds(offset))
+}
+
+// OpenUriAsync opens the uri like OpenUri, without waiting for the player.
+func (i *Player) OpenUriAsync(uri string) <-chan error {
+	return i.goCall(PlayerInterface+".OpenUri", uri)
+}
+
+// SetVolumeAsync sets the volume like SetVolume, without waiting for the player.
+func (i *Player) SetVolumeAsync(volume float64) <-chan error {
+	return i.goSetProperty(PlayerInterface, "Volume", volume)
+}
+
+// WaitAll waits for the results of Async calls and returns their errors joined, or nil if
+// they all succeeded. It batches commands sent to a player, which gets them in order:
+//
+//	err := mpris.WaitAll(player.PauseAsync(), player.SeekByAsync(-10*time.Second))
+func WaitAll(results ...<-chan error) error {
+	var errs []error
+	for _, result := range results {
+		if err := <-result; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestAsync(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	failed := errors.New("next failed")
+	fake.HandleFunc(PlayerInterface, "Next", func(args ...interface{}) *dbus.Error {
+		time.Sleep(200 * time.Millisecond)
+		return dbus.MakeFailedError(failed)
+	})
+
+	start := time.Now()
+	result := player.NextAsync()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected NextAsync to return right away, took %v", elapsed)
+	}
+	select {
+	case err := <-result:
+		t.Errorf("Expected the result once the player answers, got %v", err)
+	default:
+	}
+	if err := <-result; err == nil || err.Error() != failed.Error() {
+		t.Errorf("Expected the error of Next, got %v", err)
+	}
+	if _, ok := <-result; ok {
+		t.Error("Expected the result channel to be closed")
+	}
+
+	if err := fake.SetPosition(30000000); err != nil {
+		t.Fatal(err)
+	}
+	err := WaitAll(player.PauseAsync(), player.SeekByAsync(-10*time.Second), player.SetVolumeAsync(0.3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.GetVolume(); err != nil || volume != 0.3 {
+		t.Errorf("Expected the volume 0.3, got %v (%v)", volume, err)
+	}
+	if fake.Position() != 20000000 {
+		t.Errorf("Expected the position 20s, got %dµs", fake.Position())
+	}
+	if status, err := player.GetPlaybackStatus(); err != nil || status != PlaybackPaused {
+		t.Errorf("Expected the player to be paused, got %v (%v)", status, err)
+	}
+
+	err = WaitAll(player.PlayAsync(), player.NextAsync(), player.StopAsync())
+	if err == nil || err.Error() != failed.Error() {
+		t.Errorf("Expected the error of Next only, got %v", err)
+	}
+	if err := WaitAll(); err != nil {
+		t.Errorf("Expected no error without calls, got %v", err)
+	}
+}
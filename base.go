@@ -0,0 +1,7 @@
+package mpris
+
+// BaseClient calls the org.mpris.MediaPlayer2 interface of a player. Its methods are generated
+// from the spec, in bindings_gen.go.
+type BaseClient struct {
+	core *Player
+}
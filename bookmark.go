@@ -0,0 +1,163 @@
+package mpris
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Bookmark is a position in a track of a player, to come back to it later with
+// RestoreBookmark.
+type Bookmark struct {
+	// Player is the name of the player without the org.mpris.MediaPlayer2 prefix and the
+	// instance, as returned by SplitInstance, like "vlc".
+	Player string
+	// Identity is the player identity, like "VLC media player".
+	Identity string
+	URL      string
+	TrackID  TrackID
+	Position time.Duration
+}
+
+type jsonBookmark struct {
+	Player     string  `json:"player"`
+	Identity   string  `json:"identity,omitempty"`
+	URL        string  `json:"url,omitempty"`
+	TrackID    TrackID `json:"trackId,omitempty"`
+	PositionMs int64   `json:"positionMs"`
+}
+
+// MarshalJSON encodes the bookmark with the position in milliseconds as "positionMs".
+func (b Bookmark) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBookmark{
+		Player:     b.Player,
+		Identity:   b.Identity,
+		URL:        b.URL,
+		TrackID:    b.TrackID,
+		PositionMs: b.Position.Milliseconds(),
+	})
+}
+
+// UnmarshalJSON decodes a bookmark encoded by MarshalJSON.
+func (b *Bookmark) UnmarshalJSON(data []byte) error {
+	var decoded jsonBookmark
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*b = Bookmark{
+		Player:   decoded.Player,
+		Identity: decoded.Identity,
+		URL:      decoded.URL,
+		TrackID:  decoded.TrackID,
+		Position: time.Duration(decoded.PositionMs) * time.Millisecond,
+	}
+	return nil
+}
+
+// Bookmark returns the current track and position of the player.
+func (i *Player) Bookmark() (Bookmark, error) {
+	identity, err := i.GetIdentity()
+	if err != nil {
+		return Bookmark{}, err
+	}
+	props, err := i.getAllProperties(PlayerInterface)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	position, err := int64Value(props["Position"], "Position")
+	if err != nil {
+		return Bookmark{}, err
+	}
+	metadata, _ := props["Metadata"].Value().(map[string]dbus.Variant)
+	name, _ := SplitInstance(i.name)
+	return Bookmark{
+		Player:   name,
+		Identity: identity,
+		URL:      Metadata(metadata).URL(),
+		TrackID:  Metadata(metadata).TrackID(),
+		Position: microsecondsToDuration(position),
+	}, nil
+}
+
+// matches reports whether the metadata are of the bookmarked track, by their URLs or their
+// track ids for the bookmarks without URL.
+func (b Bookmark) matches(metadata Metadata) bool {
+	if b.URL != "" {
+		return sameURL(metadata.URL(), b.URL)
+	}
+	return b.TrackID != "" && !b.TrackID.IsNoTrack() && metadata.TrackID() == b.TrackID
+}
+
+// sameURL compares URLs ignoring the differences of escaping, as players often normalize the
+// URLs they're given, like "file:///a%20b.mp3" for "file:///a b.mp3".
+func sameURL(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.String() == ub.String()
+}
+
+// RestoreBookmark finds the bookmarked player, by its name or its identity, or starts it
+// with StartPlayer if it's not running. Unless the bookmarked track is already the
+// current one, it's opened with OpenUriAndWait, and once the player reports its metadata the
+// position is restored. It returns the player, created with opts.
+//
+// ErrNoURL is returned if the track must be opened but the bookmark has no URL, and
+// ErrPlayerNotFound if the player is neither running nor can be started.
+func RestoreBookmark(ctx context.Context, conn *dbus.Conn, bookmark Bookmark, opts ...Option) (*Player, error) {
+	player, err := findBookmarkPlayer(ctx, conn, bookmark, opts)
+	if err != nil {
+		return nil, err
+	}
+	bound := player.WithContext(ctx)
+
+	metadata, err := bound.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if !bookmark.matches(metadata) {
+		if bookmark.URL == "" {
+			return nil, ErrNoURL
+		}
+		metadata, err = bound.OpenUriAndWait(ctx, bookmark.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the track id of the loaded track is used, as the bookmarked one may be from another
+	// session of the player
+	if err := bound.SetPositionWithTrackID(metadata.TrackID(), bookmark.Position); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// findBookmarkPlayer returns the running player matching the bookmark, or the player started
+// with StartPlayer.
+func findBookmarkPlayer(ctx context.Context, conn *dbus.Conn, bookmark Bookmark, opts []Option) (*Player, error) {
+	names, err := List(conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if player, _ := SplitInstance(name); player == bookmark.Player {
+			return New(conn, name, opts...), nil
+		}
+	}
+	if bookmark.Identity != "" {
+		for _, name := range names {
+			player := New(conn, name, opts...)
+			if identity, err := player.WithContext(ctx).GetIdentity(); err == nil && identity == bookmark.Identity {
+				return player, nil
+			}
+		}
+	}
+
+	return StartPlayer(ctx, conn, bookmark.Player, opts...)
+}
@@ -0,0 +1,99 @@
+package mpris
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestBookmark(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	identity := fmt.Sprintf("Bookmark test %d", os.Getpid())
+	if err := fake.SetProperty(BaseInterface, "Identity", identity); err != nil {
+		t.Fatal(err)
+	}
+	track := func(id, url string) map[string]dbus.Variant {
+		return map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/" + id)),
+			"mpris:length":  dbus.MakeVariant(int64(300000000)),
+			"xesam:url":     dbus.MakeVariant(url),
+		}
+	}
+	if err := fake.SetMetadata(track("1", "file:///music/a%20song.mp3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPosition(42000000); err != nil {
+		t.Fatal(err)
+	}
+
+	bookmark, err := player.Bookmark()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bookmark.Player != "mpristest" || bookmark.Identity != identity || bookmark.Position != 42*time.Second {
+		t.Errorf("Unexpected bookmark %+v", bookmark)
+	}
+	data, err := json.Marshal(bookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Bookmark
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != bookmark {
+		t.Errorf("Expected the bookmark to survive JSON, got %+v from %s (%v)", decoded, data, err)
+	}
+
+	// the player loads the track some time after OpenUri, with a new track id and the URL
+	// differently escaped
+	if err := fake.SetMetadata(track("2", "file:///music/other.mp3")); err != nil {
+		t.Fatal(err)
+	}
+	fake.HandleFunc(PlayerInterface, "OpenUri", func(args ...interface{}) *dbus.Error {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			fake.SetMetadata(track("3", "file:///music/a song.mp3"))
+		}()
+		return nil
+	})
+	fake.SetPosition(0)
+
+	// the other fake players are called mpristest too, so the bookmark is found by identity
+	bookmark.Player = fmt.Sprintf("bookmarktest%d", os.Getpid())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	restored, err := RestoreBookmark(ctx, player.conn, bookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.name != fake.Name() {
+		t.Errorf("Expected the player %s, got %s", fake.Name(), restored.name)
+	}
+	if fake.Position() != 42000000 {
+		t.Errorf("Expected the position to be restored to 42s, got %dµs", fake.Position())
+	}
+
+	// the current track is only seeked
+	fake.SetPosition(0)
+	calls := len(fake.Calls())
+	if _, err := RestoreBookmark(ctx, player.conn, bookmark); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range fake.Calls()[calls:] {
+		if call.Method == "OpenUri" {
+			t.Error("Expected the current track to not be opened again")
+		}
+	}
+	if fake.Position() != 42000000 {
+		t.Errorf("Expected the position to be restored to 42s, got %dµs", fake.Position())
+	}
+
+	missing := Bookmark{Player: fmt.Sprintf("missing%d", os.Getpid()), URL: "file:///music/a.mp3"}
+	if _, err := RestoreBookmark(ctx, player.conn, missing); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package mpris
+
+import (
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// browsers are the names of the browsers, as returned by SplitInstance, which register a
+// player instance per media session, like org.mpris.MediaPlayer2.chromium.instance1234.
+var browsers = map[string]bool{
+	"chromium":                   true,
+	"chrome":                     true,
+	"brave":                      true,
+	"vivaldi":                    true,
+	"edge":                       true,
+	"opera":                      true,
+	"firefox":                    true,
+	"librewolf":                  true,
+	"plasma-browser-integration": true,
+}
+
+// IsBrowser reports whether the player name is one of a known browser, like
+// org.mpris.MediaPlayer2.firefox.instance_1_84.
+func IsBrowser(name string) bool {
+	player, _ := SplitInstance(name)
+	return browsers[player]
+}
+
+// FindActiveInstance returns the most active instance of the player, as FindActive does for
+// all the players, so the instances of a browser can be used as one player. The player is a
+// name returned by SplitInstance, like "chromium". ErrPlayerNotFound is returned when there
+// are no instances.
+func FindActiveInstance(conn *dbus.Conn, player string, opts ...Option) (*Player, error) {
+	instances, err := ListInstances(conn)
+	if err != nil {
+		return nil, err
+	}
+	return findActive(conn, instances[player], opts)
+}
+
+// InstanceGroup follows the instances of a player, like the media sessions of a browser that
+// come and go with its tabs, to use them as one player.
+type InstanceGroup struct {
+	conn    *dbus.Conn
+	player  string
+	opts    []Option
+	watcher *Watcher
+	done    chan struct{}
+}
+
+// NewInstanceGroup starts following the instances of the player, a name returned by
+// SplitInstance like "firefox". The players are created with opts.
+func NewInstanceGroup(conn *dbus.Conn, player string, opts ...Option) (*InstanceGroup, error) {
+	watcher, err := NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+	g := &InstanceGroup{
+		conn:    conn,
+		player:  player,
+		opts:    opts,
+		watcher: watcher,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(g.done)
+		// the names are kept by the watcher, the events are only drained
+		for range watcher.Events() {
+		}
+	}()
+	return g, nil
+}
+
+// Instances returns the names of the instances currently on the bus, sorted.
+func (g *InstanceGroup) Instances() []string {
+	names := instancesOf(g.watcher.Players(), g.player)
+	sort.Strings(names)
+	return names
+}
+
+// Active returns the most active instance, the playing ones first, as FindActive does.
+// ErrPlayerNotFound is returned when there are no instances.
+func (g *InstanceGroup) Active() (*Player, error) {
+	return findActive(g.conn, g.Instances(), g.opts)
+}
+
+// Close stops following the instances. The connection is not closed.
+func (g *InstanceGroup) Close() error {
+	err := g.watcher.Close()
+	<-g.done
+	return err
+}
@@ -0,0 +1,57 @@
+package mpris
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestIsBrowser(t *testing.T) {
+	if !IsBrowser("org.mpris.MediaPlayer2.chromium.instance1234") || !IsBrowser("org.mpris.MediaPlayer2.firefox.instance_1_84") {
+		t.Error("Expected the browser instances to be browsers")
+	}
+	if IsBrowser("org.mpris.MediaPlayer2.vlc") {
+		t.Error("Expected vlc to not be a browser")
+	}
+}
+
+func TestInstanceGroup(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	group := fmt.Sprintf("browsertest%d", os.Getpid())
+	first, err := mpristest.New(mpristest.PrivateConn(t), group+".instance1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+
+	instances, err := NewInstanceGroup(conn, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer instances.Close()
+
+	second, err := mpristest.New(mpristest.PrivateConn(t), group+".instance2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if err := second.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool { return len(instances.Instances()) == 2 }, "Expected the new instance to join the group")
+
+	if active, err := instances.Active(); err != nil || active.GetName() != second.Name() {
+		t.Errorf("Expected the playing instance to be active, got %v", err)
+	}
+	if active, err := FindActiveInstance(conn, group); err != nil || active.GetName() != second.Name() {
+		t.Errorf("Expected the playing instance to be found, got %v", err)
+	}
+
+	second.Close()
+	mpristest.Eventually(t, func() bool { return len(instances.Instances()) == 1 }, "Expected the closed instance to leave the group")
+	if active, err := instances.Active(); err != nil || active.GetName() != first.Name() {
+		t.Errorf("Expected the remaining instance to be active, got %v", err)
+	}
+}
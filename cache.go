@@ -0,0 +1,308 @@
+package mpris
+
+import (
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// propertyCache keeps the property values read from the player for a short time. The values
+// are updated by the PropertiesChanged signals, so a cached value is only stale when the
+// player doesn't announce its changes. It's shared by the copies made by WithContext.
+type propertyCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	values  map[string]cachedProperty
+	sub     *Subscription
+	signals chan *dbus.Signal
+	// generation is incremented when the values are invalidated, so a value read from the
+	// player before an invalidation isn't stored after it.
+	generation uint64
+	// identity is the identity cache of the player, if any, kept up to date by the signals too.
+	identity *identityCache
+}
+
+type cachedProperty struct {
+	value   dbus.Variant
+	expires time.Time
+}
+
+// WithPropertyCache caches the property values read from the player for up to ttl. The cache
+// is kept up to date by the PropertiesChanged signals, so repeated reads don't hit the bus.
+// The Position property is never cached, as the players don't announce its changes.
+func WithPropertyCache(ttl time.Duration) Option {
+	return func(p *Player) {
+		p.cache = newPropertyCache(ttl)
+	}
+}
+
+func newPropertyCache(ttl time.Duration) *propertyCache {
+	return &propertyCache{ttl: ttl, values: make(map[string]cachedProperty)}
+}
+
+func cacheKey(iface, prop string) string {
+	return iface + "." + prop
+}
+
+func isCacheable(iface, prop string) bool {
+	return !(iface == PlayerInterface && prop == "Position")
+}
+
+// get returns the cached value of the property and the current generation.
+func (c *propertyCache) get(iface, prop string) (dbus.Variant, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.values[cacheKey(iface, prop)]
+	if !ok || time.Now().After(cached.expires) {
+		return dbus.Variant{}, c.generation, false
+	}
+	return cached.value, c.generation, true
+}
+
+// store caches the values read from the player, unless the cache was invalidated since the
+// generation.
+func (c *propertyCache) store(iface string, props map[string]dbus.Variant, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sub == nil || generation != c.generation {
+		return
+	}
+	expires := time.Now().Add(c.ttl)
+	for prop, value := range props {
+		if isCacheable(iface, prop) {
+			c.values[cacheKey(iface, prop)] = cachedProperty{value, expires}
+		}
+	}
+}
+
+// invalidate removes the cached value of the property.
+func (c *propertyCache) invalidate(iface, prop string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	delete(c.values, cacheKey(iface, prop))
+}
+
+// watch subscribes to the player signals, if it's not already done. Without the signals the
+// values can't be kept up to date, so nothing is cached when it fails.
+func (c *propertyCache) watch(player *Player) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sub != nil {
+		return
+	}
+	signals := make(chan *dbus.Signal, player.signalBuffer)
+	sub, err := player.onSignal(signals, DropNone)
+	if err != nil {
+		return
+	}
+	c.sub = sub
+	c.signals = signals
+	go c.update(sub, signals)
+}
+
+func (c *propertyCache) update(sub *Subscription, signals chan *dbus.Signal) {
+	for {
+		select {
+		case <-sub.Done():
+			// the subscription is gone, so is the guarantee that the values are up to date
+			c.mu.Lock()
+			if c.sub == sub {
+				c.sub = nil
+				c.generation++
+				c.values = make(map[string]cachedProperty)
+			}
+			c.mu.Unlock()
+			return
+		case sig := <-signals:
+			event, ok := sub.player.decodeSignal(sig)
+			if !ok {
+				continue
+			}
+			if changed, ok := event.(OwnerChangedEvent); ok {
+				if changed.NewOwner == "" {
+					continue
+				}
+				// the player restarted, none of the values are known to be current
+				c.mu.Lock()
+				c.generation++
+				c.values = make(map[string]cachedProperty)
+				c.mu.Unlock()
+				if c.identity != nil {
+					c.identity.clear()
+				}
+				continue
+			}
+			changed, ok := event.(PropertiesChangedEvent)
+			if !ok {
+				continue
+			}
+			if c.identity != nil {
+				c.identity.apply(changed)
+			}
+			c.mu.Lock()
+			c.generation++
+			expires := time.Now().Add(c.ttl)
+			for prop, value := range changed.Changed {
+				if isCacheable(changed.Interface, prop) {
+					c.values[cacheKey(changed.Interface, prop)] = cachedProperty{value, expires}
+				}
+			}
+			for _, prop := range changed.Invalidated {
+				delete(c.values, cacheKey(changed.Interface, prop))
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// identityProperties are the properties of the org.mpris.MediaPlayer2 interface cached by
+// WithIdentityCache, which describe the player rather than its state.
+var identityProperties = map[string]bool{
+	"Identity":            true,
+	"DesktopEntry":        true,
+	"SupportedUriSchemes": true,
+	"SupportedMimeTypes":  true,
+	"HasTrackList":        true,
+}
+
+func isIdentityProperty(iface, prop string) bool {
+	return iface == BaseInterface && identityProperties[prop]
+}
+
+// identityCache keeps the identity properties read from the player for a fixed time, without
+// listening to the signals. It's shared by the copies made by WithContext.
+type identityCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	values map[string]cachedProperty
+	// owner is the unique name of the player the values were read from.
+	owner string
+	// generation is incremented by clear, like the one of propertyCache.
+	generation uint64
+}
+
+// WithIdentityCache caches the identity properties of the player, like Identity, DesktopEntry
+// and SupportedMimeTypes, for ttl. Unlike WithPropertyCache, the PropertiesChanged signals
+// aren't subscribed to: the values rarely change, so the player pickers refreshing every
+// second can read them from the cache until they expire, until the player restarts under the
+// same name, as followed by Owner, or until Invalidate is called. Along with WithPropertyCache,
+// the values announced by the signals replace the cached ones. The other properties of the
+// org.mpris.MediaPlayer2 interface, like Fullscreen, follow the state of the player and
+// aren't cached.
+func WithIdentityCache(ttl time.Duration) Option {
+	return func(p *Player) {
+		p.identity = newIdentityCache(ttl)
+	}
+}
+
+func newIdentityCache(ttl time.Duration) *identityCache {
+	return &identityCache{ttl: ttl, values: make(map[string]cachedProperty)}
+}
+
+// get returns the cached value of the property and the current generation.
+func (c *identityCache) get(prop string) (dbus.Variant, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.values[prop]
+	if !ok || time.Now().After(cached.expires) {
+		return dbus.Variant{}, c.generation, false
+	}
+	return cached.value, c.generation, true
+}
+
+// store caches the identity properties among the values of the interface, unless the cache was
+// cleared since the generation.
+func (c *identityCache) store(iface string, props map[string]dbus.Variant, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	expires := time.Now().Add(c.ttl)
+	for prop, value := range props {
+		if isIdentityProperty(iface, prop) {
+			c.values[prop] = cachedProperty{value, expires}
+		}
+	}
+}
+
+// follow clears the cache if the values were read from another owner than the current one.
+func (c *identityCache) follow(owner string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if owner == c.owner {
+		return
+	}
+	c.owner = owner
+	c.generation++
+	c.values = make(map[string]cachedProperty)
+}
+
+// apply updates the cache with the values announced by the signal.
+func (c *identityCache) apply(changed PropertiesChangedEvent) {
+	if changed.Interface != BaseInterface {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	expires := time.Now().Add(c.ttl)
+	for prop, value := range changed.Changed {
+		if identityProperties[prop] {
+			c.values[prop] = cachedProperty{value, expires}
+		}
+	}
+	for _, prop := range changed.Invalidated {
+		delete(c.values, prop)
+	}
+}
+
+// clear removes the cached values.
+func (c *identityCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.values = make(map[string]cachedProperty)
+}
+
+// clear removes the cached values, which are read from the player again until the next signal.
+func (c *propertyCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.values = make(map[string]cachedProperty)
+}
+
+// followIdentityOwner clears the identity cache if the player restarted since the values were
+// read. Without connection the owner can't be followed without a call, which the cache is
+// meant to save, so only the TTL applies.
+func (i *Player) followIdentityOwner() {
+	if i.conn == nil {
+		return
+	}
+	owner, _ := i.Owner()
+	i.identity.follow(owner)
+}
+
+// linkCaches makes the property cache keep the identity cache up to date too, once both are
+// set by the options.
+func (i *Player) linkCaches() {
+	if i.cache != nil {
+		i.cache.identity = i.identity
+	}
+}
+
+// Invalidate removes the values cached by WithIdentityCache and WithPropertyCache, so the next
+// reads ask the player, like after it was reconfigured. It does nothing without a cache.
+func (i *Player) Invalidate() {
+	if i.identity != nil {
+		i.identity.clear()
+	}
+	if i.cache != nil {
+		i.cache.clear()
+	}
+}
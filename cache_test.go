@@ -0,0 +1,134 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mprismem"
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestPropertyCache(t *testing.T) {
+	client, fake := newTestPlayer(t)
+	player := New(client.conn, fake.Name(), WithPropertyCache(time.Minute))
+	defer player.Close()
+
+	if status, err := player.GetPlaybackStatus(); err != nil || status != PlaybackStopped {
+		t.Fatalf("Expected the player to be stopped, got %s %v", status, err)
+	}
+
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		status, _ := player.GetPlaybackStatus()
+		return status == PlaybackPlaying
+	}, "The cached status wasn't updated by the signal")
+
+	if err := player.SetVolume(0.25); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.GetVolume(); err != nil || volume != 0.25 {
+		t.Errorf("Expected the volume to be 0.25 after setting it, got %f %v", volume, err)
+	}
+
+	// the player is gone, so only the cached values can be read
+	fake.Close()
+	if status, err := player.GetPlaybackStatus(); err != nil || status != PlaybackPlaying {
+		t.Errorf("Expected the cached status, got %s %v", status, err)
+	}
+	if _, err := player.GetPosition(); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected the position not to be cached, got %v", err)
+	}
+}
+
+func TestIdentityCache(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.mem")
+	obj.Set(BaseInterface, "Identity", "Mem")
+	obj.Set(BaseInterface, "Fullscreen", false)
+	player := NewWithObject(obj, WithIdentityCache(time.Minute))
+
+	for n := 0; n < 3; n++ {
+		if identity, err := player.GetIdentity(); err != nil || identity != "Mem" {
+			t.Fatalf("Expected the identity Mem, got %s %v", identity, err)
+		}
+		if _, err := player.Base.GetFullscreen(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := len(obj.Calls()); calls != 4 {
+		t.Errorf("Expected the identity to be read once and Fullscreen thrice, got %d calls", calls)
+	}
+
+	obj.Set(BaseInterface, "Identity", "Renamed")
+	if identity, _ := player.GetIdentity(); identity != "Mem" {
+		t.Errorf("Expected the cached identity, got %s", identity)
+	}
+	player.Invalidate()
+	if identity, _ := player.GetIdentity(); identity != "Renamed" {
+		t.Errorf("Expected the identity to be read again after Invalidate, got %s", identity)
+	}
+
+	expiring := NewWithObject(obj, WithIdentityCache(time.Millisecond))
+	if identity, _ := expiring.GetIdentity(); identity != "Renamed" {
+		t.Fatalf("Expected the identity Renamed, got %s", identity)
+	}
+	obj.Set(BaseInterface, "Identity", "Expired")
+	time.Sleep(5 * time.Millisecond)
+	if identity, _ := expiring.GetIdentity(); identity != "Expired" {
+		t.Errorf("Expected the identity to be read again once expired, got %s", identity)
+	}
+}
+
+func TestIdentityCacheOwner(t *testing.T) {
+	shortName := fmt.Sprintf("mpristest.identity%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := New(mpristest.PrivateConn(t), fake.Name(), WithIdentityCache(time.Hour))
+	defer player.Close()
+	if identity, err := player.GetIdentity(); err != nil || identity != "mpristest" {
+		t.Fatalf("Expected the identity mpristest, got %s %v", identity, err)
+	}
+
+	// the player restarts under the same name, with another identity
+	fake.Close()
+	restarted, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+	if err := restarted.SetProperty(BaseInterface, "Identity", "Restarted"); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		identity, _ := player.GetIdentity()
+		return identity == "Restarted"
+	}, "The identity of the restarted player wasn't read")
+}
+
+func TestIdentityCacheSignals(t *testing.T) {
+	client, fake := newTestPlayer(t)
+	player := New(client.conn, fake.Name(), WithIdentityCache(time.Hour), WithPropertyCache(time.Millisecond))
+	defer player.Close()
+	if identity, err := player.GetIdentity(); err != nil || identity != "mpristest" {
+		t.Fatalf("Expected the identity mpristest, got %s %v", identity, err)
+	}
+
+	if err := fake.SetProperty(BaseInterface, "Identity", "Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		identity, _ := player.GetIdentity()
+		return identity == "Renamed"
+	}, "The announced identity wasn't read")
+	// once the property cache expired, the identity cache has the announced value too
+	time.Sleep(5 * time.Millisecond)
+	if identity, _ := player.GetIdentity(); identity != "Renamed" {
+		t.Errorf("Expected the announced identity to be cached, got %s", identity)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/server"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// daemonInterface is the interface used to control the daemon, exported along with the
+	// proxied player.
+	daemonInterface = "com.github.pauloo27.mprisd"
+	daemonPath      = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+)
+
+// daemonName returns the bus name the daemon holds while it runs, even without players, so
+// the commands can reach it.
+func daemonName(name string) string {
+	return daemonInterface + "." + name
+}
+
+// daemon exports the most recently active player under a stable bus name. A player becomes
+// active when it starts playing or changes its track, and when it appears on the bus.
+type daemon struct {
+	conn      *dbus.Conn
+	name      string
+	statePath string
+	watcher   *mpris.Watcher
+	rule      []dbus.MatchOption
+	signals   chan *dbus.Signal
+
+	mu    sync.Mutex
+	order *order
+	proxy *server.Proxy
+	// player is the player proxied by proxy, closed when it's replaced.
+	player *mpris.Player
+	active string
+}
+
+// newDaemon starts tracking the players on the bus, ranked by the ordering saved at
+// statePath, and exports the active one as org.mpris.MediaPlayer2.<name>. The daemon itself is
+// reached at the name returned by daemonName.
+func newDaemon(conn *dbus.Conn, name, statePath string) (*daemon, error) {
+	d := &daemon{
+		conn:      conn,
+		name:      name,
+		statePath: statePath,
+		rule: []dbus.MatchOption{
+			dbus.WithMatchObjectPath(daemonPath),
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchOption("arg0", mpris.PlayerInterface),
+		},
+		signals: make(chan *dbus.Signal, 16),
+		order:   newOrder(),
+	}
+	if err := d.order.load(statePath); err != nil {
+		log.Printf("ignoring the saved ordering: %v", err)
+	}
+
+	if err := conn.AddMatchSignal(d.rule...); err != nil {
+		return nil, err
+	}
+	conn.Signal(d.signals)
+
+	watcher, err := mpris.NewWatcher(conn)
+	if err != nil {
+		d.removeMatch()
+		return nil, err
+	}
+	d.watcher = watcher
+
+	if err := conn.Export(daemonExport{d}, daemonPath, daemonInterface); err != nil {
+		d.Close()
+		return nil, err
+	}
+	reply, err := conn.RequestName(daemonName(name), dbus.NameFlagDoNotQueue)
+	if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+		err = fmt.Errorf("%s is already running", daemonName(name))
+	}
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	d.mu.Lock()
+	for _, player := range watcher.Players() {
+		if d.tracked(player) {
+			d.order.add(player)
+		}
+	}
+	d.update()
+	d.mu.Unlock()
+	return d, nil
+}
+
+// Run handles the bus events until the daemon is closed.
+func (d *daemon) Run() {
+	for {
+		select {
+		case event, ok := <-d.watcher.Events():
+			if !ok {
+				return
+			}
+			if !d.tracked(event.Name) {
+				continue
+			}
+			d.mu.Lock()
+			if event.Vanished() {
+				d.order.remove(event.Name)
+			} else {
+				d.order.activate(event.Name)
+			}
+			d.update()
+			d.mu.Unlock()
+		case sig, ok := <-d.signals:
+			if !ok {
+				return
+			}
+			d.handleSignal(sig)
+		}
+	}
+}
+
+// tracked reports whether the player is one the daemon can export, which excludes the daemon
+// itself.
+func (d *daemon) tracked(name string) bool {
+	return name != d.busName() && d.watcher.Owner(name) != d.conn.Names()[0]
+}
+
+func (d *daemon) busName() string {
+	return mpris.BaseInterface + "." + d.name
+}
+
+// handleSignal activates the player that sent the properties change, if it started playing or
+// changed its track.
+func (d *daemon) handleSignal(sig *dbus.Signal) {
+	if sig.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" || len(sig.Body) < 2 {
+		return
+	}
+	if iface, _ := sig.Body[0].(string); iface != mpris.PlayerInterface {
+		return
+	}
+	changed, _ := sig.Body[1].(map[string]dbus.Variant)
+	status, _ := changed["PlaybackStatus"].Value().(string)
+	_, newTrack := changed["Metadata"]
+	if status != string(mpris.PlaybackPlaying) && !newTrack {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, name := range d.order.players() {
+		if d.watcher.Owner(name) == sig.Sender {
+			d.order.activate(name)
+		}
+	}
+	d.update()
+}
+
+// update exports the active player and saves the ordering. It must be called with mu held.
+func (d *daemon) update() {
+	if err := d.order.save(d.statePath); err != nil {
+		log.Printf("saving the ordering: %v", err)
+	}
+
+	active := d.order.active()
+	if active == d.active {
+		return
+	}
+	d.active = active
+
+	if active == "" {
+		log.Print("no players left")
+		d.closeProxy()
+		return
+	}
+	log.Printf("exporting %s", active)
+	player := mpris.New(d.conn, active)
+	if d.proxy != nil {
+		err := d.proxy.SetPlayer(player)
+		if err == nil {
+			d.closePlayer()
+			d.player = player
+			return
+		}
+		log.Printf("switching to %s: %v", active, err)
+		d.closeProxy()
+	}
+	proxy, err := server.NewProxy(d.conn, player, d.name)
+	if err != nil {
+		log.Printf("exporting %s: %v", active, err)
+		player.Close()
+		d.active = ""
+		return
+	}
+	d.proxy, d.player = proxy, player
+}
+
+// closeProxy stops exporting the active player, and closes it. It must be called with mu held.
+func (d *daemon) closeProxy() {
+	if d.proxy == nil {
+		return
+	}
+	if err := d.proxy.Close(); err != nil {
+		log.Printf("closing the proxy: %v", err)
+	}
+	d.proxy = nil
+	d.closePlayer()
+}
+
+// closePlayer closes the proxied player, which the proxy doesn't close, so its subscriptions
+// and match rules don't outlive it.
+func (d *daemon) closePlayer() {
+	if d.player == nil {
+		return
+	}
+	if err := d.player.Close(); err != nil {
+		log.Printf("closing %s: %v", d.player.GetName(), err)
+	}
+	d.player = nil
+}
+
+func (d *daemon) removeMatch() {
+	d.conn.RemoveSignal(d.signals)
+	d.conn.RemoveMatchSignal(d.rule...)
+}
+
+// Close stops exporting the active player and tracking the players. The ordering is already
+// saved, as it's saved on every change.
+func (d *daemon) Close() error {
+	d.mu.Lock()
+	d.closeProxy()
+	d.mu.Unlock()
+	d.conn.Export(nil, daemonPath, daemonInterface)
+	d.conn.ReleaseName(daemonName(d.name))
+	d.removeMatch()
+	return d.watcher.Close()
+}
+
+// daemonExport is the daemon interface exported on the bus.
+type daemonExport struct {
+	d *daemon
+}
+
+// Shift makes the next player the active one and returns its name.
+func (e daemonExport) Shift() (string, *dbus.Error) {
+	e.d.mu.Lock()
+	defer e.d.mu.Unlock()
+	name := e.d.order.shift()
+	e.d.update()
+	return name, nil
+}
+
+// Unshift makes the least recently active player the active one and returns its name.
+func (e daemonExport) Unshift() (string, *dbus.Error) {
+	e.d.mu.Lock()
+	defer e.d.mu.Unlock()
+	name := e.d.order.unshift()
+	e.d.update()
+	return name, nil
+}
+
+// PlayerNames returns the names of the players, the active one first.
+func (e daemonExport) PlayerNames() ([]string, *dbus.Error) {
+	e.d.mu.Lock()
+	defer e.d.mu.Unlock()
+	return e.d.order.players(), nil
+}
@@ -0,0 +1,140 @@
+// Command go-mprisd exports the most recently active MPRIS player under a stable bus name,
+// like playerctld, so hotkeys can always target org.mpris.MediaPlayer2.mprisd.
+//
+// Usage:
+//
+//	go-mprisd [--bus BUS] [--name NAME] [--state FILE]
+//	go-mprisd [--bus BUS] [--name NAME] shift|unshift|list
+//
+// Without a command the daemon runs until it's interrupted. A player becomes the active one
+// when it appears, starts playing or changes its track. The shift command makes the next
+// player active and unshift undoes it. The ordering is saved to --state, so it survives
+// restarts of the daemon. The commands reach the daemon at com.github.pauloo27.mprisd.NAME,
+// which it holds even when there's no player to export.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+const usage = `Usage: go-mprisd [--bus BUS] [--name NAME] [--state FILE]
+       go-mprisd [--bus BUS] [--name NAME] shift|unshift|list
+
+Commands:
+  shift     make the next player the active one
+  unshift   make the previously active player the active one again
+  list      list the players, the active one first
+
+Flags:
+`
+
+func main() {
+	bus := flag.String("bus", mpris.SessionBus, "bus of the players: session, system or a D-Bus address")
+	name := flag.String("name", "mprisd", "name exported as org.mpris.MediaPlayer2.NAME")
+	state := flag.String("state", defaultStatePath(), "file where the ordering of the players is saved")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	if flag.NArg() == 0 {
+		err = runDaemon(*bus, *name, *state)
+	} else {
+		err = runCommand(*bus, *name, flag.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-mprisd:", err)
+		os.Exit(1)
+	}
+}
+
+// defaultStatePath returns the path of the ordering in the user cache directory.
+func defaultStatePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-mprisd", "players.json")
+}
+
+// runDaemon runs the daemon until it receives SIGINT or SIGTERM.
+func runDaemon(bus, name, statePath string) error {
+	conn, err := mpris.ConnectBus(bus)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	d, err := newDaemon(conn, name, statePath)
+	if err != nil {
+		return err
+	}
+	go d.Run()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	<-interrupt
+	log.Print("stopping")
+	return d.Close()
+}
+
+// runCommand calls the running daemon.
+func runCommand(bus, name, command string) error {
+	conn, err := mpris.ConnectBus(bus)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch command {
+	case "shift", "unshift":
+		var active string
+		method := "Shift"
+		if command == "unshift" {
+			method = "Unshift"
+		}
+		if err := callDaemon(conn, name, method, &active); err != nil {
+			return err
+		}
+		fmt.Println(shortName(active))
+	case "list":
+		var players []string
+		if err := callDaemon(conn, name, "PlayerNames", &players); err != nil {
+			return err
+		}
+		for _, player := range players {
+			fmt.Println(shortName(player))
+		}
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %s", command)
+	}
+	return nil
+}
+
+// callDaemon calls a method of the daemon, at the name it holds even without players.
+func callDaemon(conn *dbus.Conn, name, method string, result interface{}) error {
+	obj := conn.Object(daemonName(name), daemonPath)
+	err := obj.Call(daemonInterface+"."+method, 0).Store(result)
+	if dbusErr, ok := err.(dbus.Error); ok && dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
+		return errors.New("the daemon is not running")
+	}
+	return err
+}
+
+func shortName(name string) string {
+	return strings.TrimPrefix(name, mpris.BaseInterface+".")
+}
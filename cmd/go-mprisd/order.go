@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// maxRemembered is how many players the ordering keeps, counting the ones that quit.
+const maxRemembered = 32
+
+// order is the activity ordering of the players, the most recently active first. The players
+// that quit keep their rank, so they get it back when they restart, including after the
+// daemon restarts.
+type order struct {
+	names   []string
+	present map[string]bool
+	// changed is whether the ranks changed since the ordering was loaded or saved.
+	changed bool
+}
+
+func newOrder() *order {
+	return &order{present: make(map[string]bool)}
+}
+
+func (o *order) index(name string) int {
+	for i, known := range o.names {
+		if known == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// add marks the player as present, keeping its rank if it's known and ranking it last
+// otherwise.
+func (o *order) add(name string) {
+	if o.index(name) < 0 {
+		o.names = append(o.names, name)
+		o.changed = true
+	}
+	o.present[name] = true
+	o.trim()
+}
+
+// activate makes the player the most recently active one.
+func (o *order) activate(name string) {
+	o.present[name] = true
+	i := o.index(name)
+	if i == 0 {
+		return
+	}
+	if i > 0 {
+		o.names = append(o.names[:i], o.names[i+1:]...)
+	}
+	o.names = append([]string{name}, o.names...)
+	o.changed = true
+	o.trim()
+}
+
+// remove marks the player as gone, keeping its rank.
+func (o *order) remove(name string) {
+	delete(o.present, name)
+}
+
+// trim forgets the least recently active players that quit, above maxRemembered.
+func (o *order) trim() {
+	for i := len(o.names) - 1; i >= 0 && len(o.names) > maxRemembered; i-- {
+		if !o.present[o.names[i]] {
+			o.names = append(o.names[:i], o.names[i+1:]...)
+			o.changed = true
+		}
+	}
+}
+
+// players returns the present players, the most recently active first.
+func (o *order) players() []string {
+	players := []string{}
+	for _, name := range o.names {
+		if o.present[name] {
+			players = append(players, name)
+		}
+	}
+	return players
+}
+
+// active returns the most recently active present player, or an empty string if there's none.
+func (o *order) active() string {
+	if players := o.players(); len(players) > 0 {
+		return players[0]
+	}
+	return ""
+}
+
+// shift ranks the active player last, making the next one active, and returns it.
+func (o *order) shift() string {
+	players := o.players()
+	if len(players) < 2 {
+		return o.active()
+	}
+	i := o.index(players[0])
+	o.names = append(append(o.names[:i:i], o.names[i+1:]...), players[0])
+	o.changed = true
+	return o.active()
+}
+
+// unshift makes the least recently active present player the active one, undoing a shift,
+// and returns it.
+func (o *order) unshift() string {
+	players := o.players()
+	if len(players) < 2 {
+		return o.active()
+	}
+	o.activate(players[len(players)-1])
+	return o.active()
+}
+
+// load reads the ordering saved at path. A missing file is an empty ordering.
+func (o *order) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if o.index(name) < 0 {
+			o.names = append(o.names, name)
+		}
+	}
+	o.trim()
+	o.changed = false
+	return nil
+}
+
+// save writes the ordering to path if it changed, replacing the file at once so a crash
+// doesn't leave half of it.
+func (o *order) save(path string) error {
+	if !o.changed {
+		return nil
+	}
+	data, err := json.Marshal(o.names)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	o.changed = false
+	return nil
+}
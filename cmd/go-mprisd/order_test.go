@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOrder(t *testing.T) {
+	o := newOrder()
+	o.add("a")
+	o.add("b")
+	o.activate("c")
+	if players := o.players(); !reflect.DeepEqual(players, []string{"c", "a", "b"}) {
+		t.Errorf("Expected c, a and b, got %v", players)
+	}
+
+	if active := o.shift(); active != "a" {
+		t.Errorf("Expected a to be active after a shift, got %s", active)
+	}
+	if active := o.shift(); active != "b" {
+		t.Errorf("Expected b to be active after a second shift, got %s", active)
+	}
+	if active := o.unshift(); active != "a" {
+		t.Errorf("Expected a to be active after an unshift, got %s", active)
+	}
+
+	o.remove("a")
+	if active := o.active(); active != "b" {
+		t.Errorf("Expected b to be active once a quits, got %s", active)
+	}
+	o.add("a")
+	if active := o.active(); active != "a" {
+		t.Errorf("Expected a to get its rank back, got %s", active)
+	}
+
+	o.remove("a")
+	o.remove("b")
+	o.remove("c")
+	if active := o.active(); active != "" {
+		t.Errorf("Expected no active player, got %s", active)
+	}
+	if active := o.shift(); active != "" {
+		t.Errorf("Expected no active player after a shift, got %s", active)
+	}
+}
+
+func TestOrderPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "players.json")
+
+	o := newOrder()
+	if err := o.load(path); err != nil {
+		t.Fatalf("Expected a missing file to be an empty ordering, got %v", err)
+	}
+	o.activate("a")
+	o.activate("b")
+	o.remove("a")
+	if err := o.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newOrder()
+	if err := restored.load(path); err != nil {
+		t.Fatal(err)
+	}
+	restored.add("c")
+	restored.add("a")
+	restored.add("b")
+	if players := restored.players(); !reflect.DeepEqual(players, []string{"b", "a", "c"}) {
+		t.Errorf("Expected the saved ranks to be restored, got %v", players)
+	}
+}
+
+func TestOrderTrim(t *testing.T) {
+	o := newOrder()
+	for i := 0; i < maxRemembered+10; i++ {
+		name := string(rune('a' + i))
+		o.activate(name)
+		o.remove(name)
+	}
+	o.add("present")
+	if len(o.names) != maxRemembered {
+		t.Errorf("Expected %d remembered players, got %d", maxRemembered, len(o.names))
+	}
+	if active := o.active(); active != "present" {
+		t.Errorf("Expected the present player to be kept, got %s", active)
+	}
+}
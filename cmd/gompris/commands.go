@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+func runCommand(player *mpris.Player, command string, args []string) error {
+	switch command {
+	case "play":
+		return player.Play()
+	case "pause":
+		return player.Pause()
+	case "play-pause":
+		return player.PlayPause()
+	case "stop":
+		return player.Stop()
+	case "next":
+		return player.Next()
+	case "previous":
+		return player.Previous()
+	case "status":
+		status, err := player.GetPlaybackStatus()
+		if err != nil {
+			return err
+		}
+		fmt.Println(status)
+		return nil
+	case "metadata":
+		return printMetadata(player, args)
+	case "position":
+		return position(player, args)
+	case "volume":
+		return volume(player, args)
+	case "loop":
+		return loop(player, args)
+	case "shuffle":
+		return shuffle(player, args)
+	}
+	return fmt.Errorf("unknown command %s", command)
+}
+
+func printMetadata(player *mpris.Player, args []string) error {
+	metadata, err := player.GetMetadata()
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		value, ok := metadata[args[0]]
+		if !ok {
+			return fmt.Errorf("no metadata %s", args[0])
+		}
+		fmt.Println(formatValue(value.Value()))
+		return nil
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	name := strings.TrimPrefix(player.GetName(), mpris.BaseInterface+".")
+	for _, key := range keys {
+		fmt.Printf("%s %-24s %s\n", name, key, formatValue(metadata[key].Value()))
+	}
+	return nil
+}
+
+func formatValue(value interface{}) string {
+	if values, ok := value.([]string); ok {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+func position(player *mpris.Player, args []string) error {
+	if len(args) == 0 {
+		position, err := player.GetPosition()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%.6f\n", position)
+		return nil
+	}
+
+	offset, sign, err := parseOffset(args[0])
+	if err != nil {
+		return err
+	}
+	if sign != 0 {
+		return player.Seek(float64(sign) * offset)
+	}
+	return player.SetPosition(offset)
+}
+
+func volume(player *mpris.Player, args []string) error {
+	if len(args) == 0 {
+		volume, err := player.GetVolume()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%.6f\n", volume)
+		return nil
+	}
+
+	level, sign, err := parseOffset(args[0])
+	if err != nil {
+		return err
+	}
+	if sign != 0 {
+		current, err := player.GetVolume()
+		if err != nil {
+			return err
+		}
+		level = current + float64(sign)*level
+	}
+	return player.SetVolume(level)
+}
+
+func loop(player *mpris.Player, args []string) error {
+	if len(args) == 0 {
+		status, err := player.GetLoopStatus()
+		if err != nil {
+			return err
+		}
+		fmt.Println(status)
+		return nil
+	}
+
+	for _, status := range []mpris.LoopStatus{mpris.LoopNone, mpris.LoopTrack, mpris.LoopPlaylist} {
+		if strings.EqualFold(args[0], string(status)) {
+			return player.SetLoopStatus(status)
+		}
+	}
+	return fmt.Errorf("invalid loop status %s", args[0])
+}
+
+func shuffle(player *mpris.Player, args []string) error {
+	current, err := player.GetShuffle()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if current {
+			fmt.Println("On")
+		} else {
+			fmt.Println("Off")
+		}
+		return nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		return player.SetShuffle(true)
+	case "off":
+		return player.SetShuffle(false)
+	case "toggle":
+		return player.SetShuffle(!current)
+	}
+	return fmt.Errorf("invalid shuffle state %s", args[0])
+}
+
+// parseOffset parses values like "10", "10+" and "10-", returning the sign of relative
+// values or 0 for absolute ones.
+func parseOffset(arg string) (float64, int, error) {
+	sign := 0
+	switch {
+	case strings.HasSuffix(arg, "+"):
+		sign = 1
+	case strings.HasSuffix(arg, "-"):
+		sign = -1
+	}
+	if sign != 0 {
+		arg = arg[:len(arg)-1]
+	}
+
+	value, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid value %s", arg)
+	}
+	return value, sign, nil
+}
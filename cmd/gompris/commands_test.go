@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOffset(t *testing.T) {
+	cases := []struct {
+		arg   string
+		value float64
+		sign  int
+	}{
+		{"10", 10, 0},
+		{"10+", 10, 1},
+		{"0.5-", 0.5, -1},
+	}
+
+	for _, c := range cases {
+		value, sign, err := parseOffset(c.arg)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %v", c.arg, err)
+			continue
+		}
+		if value != c.value || sign != c.sign {
+			t.Errorf("Expected %s to be %f with sign %d, got %f with sign %d", c.arg, c.value, c.sign, value, sign)
+		}
+	}
+
+	if _, _, err := parseOffset("ten"); err == nil {
+		t.Error("Expected an error parsing ten")
+	}
+}
+
+func TestMatchPlayerName(t *testing.T) {
+	if !matchPlayerName("org.mpris.MediaPlayer2.vlc", "vlc") {
+		t.Error("Expected vlc to match")
+	}
+	if !matchPlayerName("org.mpris.MediaPlayer2.vlc.instance1234", "vlc") {
+		t.Error("Expected vlc instances to match")
+	}
+	if matchPlayerName("org.mpris.MediaPlayer2.vlcx", "vlc") {
+		t.Error("Expected vlcx to not match vlc")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90m":  90 * time.Minute,
+		"7d":   7 * 24 * time.Hour,
+		"0.5d": 12 * time.Hour,
+	}
+	for value, expected := range cases {
+		d, err := parseSince(value)
+		if err != nil || d != expected {
+			t.Errorf("Expected %s to be %v, got %v (%v)", value, expected, d, err)
+		}
+	}
+	for _, value := range []string{"week", "-1d", "-5m"} {
+		if _, err := parseSince(value); err == nil {
+			t.Errorf("Expected an error parsing %s", value)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// clock is a duration printed as a clock, like 3:05 or 1:02:03.
+type clock time.Duration
+
+func (c clock) String() string {
+	return formatDuration(time.Duration(c))
+}
+
+// formatData is the data available in --format templates.
+type formatData struct {
+	Player   string
+	Status   mpris.PlaybackStatus
+	Loop     mpris.LoopStatus
+	Shuffle  bool
+	Volume   float64
+	Position clock
+	Length   clock
+	TrackID  string
+	Title    string
+	Artist   string
+	Artists  []string
+	Album    string
+	ArtURL   string
+	URL      string
+	Metadata map[string]interface{}
+}
+
+var formatFuncs = template.FuncMap{
+	"duration": templateDuration,
+	"trunc":    truncate,
+	"lc": func(value interface{}) string {
+		return strings.ToLower(fmt.Sprint(value))
+	},
+	"uc": func(value interface{}) string {
+		return strings.ToUpper(fmt.Sprint(value))
+	},
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || fmt.Sprint(value) == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+func newFormatData(name string, state mpris.PlayerState) formatData {
+	metadata := make(map[string]interface{}, len(state.Metadata))
+	for key, value := range state.Metadata {
+		metadata[key] = value.Value()
+	}
+
+	return formatData{
+		Player:   strings.TrimPrefix(name, mpris.BaseInterface+"."),
+		Status:   state.PlaybackStatus,
+		Loop:     state.LoopStatus,
+		Shuffle:  state.Shuffle,
+		Volume:   state.Volume,
+		Position: clock(state.Position),
+		Length:   clock(state.Metadata.Length()),
+		TrackID:  string(state.Metadata.TrackID()),
+		Title:    state.Metadata.Title(),
+		Artist:   strings.Join(state.Metadata.Artists(), ", "),
+		Artists:  state.Metadata.Artists(),
+		Album:    state.Metadata.Album(),
+		ArtURL:   state.Metadata.ArtURL(),
+		URL:      state.Metadata.URL(),
+		Metadata: metadata,
+	}
+}
+
+func printFormat(player *mpris.Player, format string) error {
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format)
+	if err != nil {
+		return err
+	}
+
+	state, err := player.GetState()
+	if err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(os.Stdout, newFormatData(player.GetName(), state)); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+func formatDuration(duration time.Duration) string {
+	seconds := int64(duration / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// templateDuration formats durations and microseconds, as found in the mpris:length metadata.
+func templateDuration(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case clock:
+		return formatDuration(time.Duration(value)), nil
+	case time.Duration:
+		return formatDuration(value), nil
+	case int64:
+		return formatDuration(time.Duration(value) * time.Microsecond), nil
+	case uint64:
+		return formatDuration(time.Duration(value) * time.Microsecond), nil
+	case int:
+		return formatDuration(time.Duration(value) * time.Microsecond), nil
+	}
+	return "", fmt.Errorf("can't format %T as a duration", value)
+}
+
+// truncate shortens value to at most length characters, ending it with an ellipsis when cut.
+func truncate(length int, value interface{}) string {
+	runes := []rune(fmt.Sprint(value))
+	if length <= 0 || len(runes) <= length {
+		return string(runes)
+	}
+	if length == 1 {
+		return "…"
+	}
+	return string(runes[:length-1]) + "…"
+}
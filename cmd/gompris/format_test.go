@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestFormat(t *testing.T) {
+	state := mpris.PlayerState{
+		PlaybackStatus: mpris.PlaybackPlaying,
+		Position:       65 * time.Second,
+		Metadata: mpris.Metadata{
+			"xesam:title":  dbus.MakeVariant("A very long title"),
+			"xesam:artist": dbus.MakeVariant([]string{"Artist"}),
+			"xesam:genre":  dbus.MakeVariant([]string{"Rock"}),
+			"mpris:length": dbus.MakeVariant(int64(3723000000)),
+		},
+	}
+
+	cases := map[string]string{
+		"{{.Artist}} - {{.Title}} [{{.Position}}/{{.Length}}]": "Artist - A very long title [1:05/1:02:03]",
+		"{{trunc 6 .Title}}":                              "A ver…",
+		"{{uc .Status}} {{lc .Player}}":                   "PLAYING vlc",
+		"{{duration (index .Metadata \"mpris:length\")}}": "1:02:03",
+		"{{index .Metadata \"xesam:genre\"}}":             "[Rock]",
+		"{{default \"Unknown\" .Album}}":                  "Unknown",
+	}
+
+	for format, expected := range cases {
+		tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", format, err)
+			continue
+		}
+
+		var out strings.Builder
+		if err := tmpl.Execute(&out, newFormatData("org.mpris.MediaPlayer2.VLC", state)); err != nil {
+			t.Errorf("Failed to execute %s: %v", format, err)
+			continue
+		}
+		if out.String() != expected {
+			t.Errorf("Expected %s to be %q, got %q", format, expected, out.String())
+		}
+	}
+}
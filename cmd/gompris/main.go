@@ -0,0 +1,186 @@
+// Command gompris controls MPRIS media players, like playerctl.
+//
+// Usage:
+//
+//	gompris [--bus BUS] [--player NAME] [--list-all] [--format FORMAT] COMMAND [ARG]
+//	gompris [--bus BUS] [--player NAME] --statusbar waybar|polybar [--max-length N] [--scroll]
+//	gompris stats [--by artist|album|track] [--since DURATION] [--top N] FILE
+//
+// Without --player the most relevant player is used: a playing one, then a paused one and
+// finally a stopped one.
+//
+// The --bus flag selects the bus the players are on: session, the default, system or a D-Bus
+// address like "tcp:host=192.168.1.2,port=4000".
+//
+// The --format flag replaces the output of the status and metadata commands with a Go
+// template, like "{{.Artist}} - {{.Title}} [{{.Position}}/{{.Length}}]". Besides the track
+// and player fields, templates can use the duration, trunc, lc, uc and default functions.
+//
+// The --statusbar flag prints the track of the player for waybar or polybar, a line every time
+// it changes, until the player quits. Long texts are truncated to --max-length characters, or
+// scrolled with --scroll.
+//
+// The stats command prints the most listened artists, albums or tracks of a history file
+// written by the history package, optionally only over the last DURATION, like 7d.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/statusbar"
+	"github.com/godbus/dbus/v5"
+)
+
+const usage = `Usage: gompris [--bus BUS] [--player NAME] [--list-all] [--format FORMAT] COMMAND [ARG]
+       gompris [--bus BUS] [--player NAME] --statusbar waybar|polybar [--max-length N] [--scroll]
+       gompris stats [--by artist|album|track] [--since DURATION] [--top N] FILE
+
+Commands:
+  play                 start or resume playback
+  pause                pause playback
+  play-pause           toggle between play and pause
+  stop                 stop playback
+  next                 skip to the next track
+  previous             skip to the previous track
+  status               print the playback status
+  metadata [KEY]       print the track metadata, or only the value of KEY
+  position [OFFSET]    print the position in seconds, or set it to OFFSET ("10", "10+", "10-")
+  volume [LEVEL]       print the volume, or set it to LEVEL ("0.5", "0.1+", "0.1-")
+  loop [STATUS]        print the loop status, or set it to None, Track or Playlist
+  shuffle [STATE]      print the shuffle state, or set it to On, Off or Toggle
+  stats FILE           print the listening statistics of a history file
+
+Flags:
+`
+
+func main() {
+	bus := flag.String("bus", mpris.SessionBus, "bus of the players: session, system or a D-Bus address")
+	playerName := flag.String("player", "", "name of the player to control, like vlc or spotify")
+	listAll := flag.Bool("list-all", false, "list the names of the available players")
+	format := flag.String("format", "", "Go template used by the status and metadata commands")
+	bar := flag.String("statusbar", "", "follow the player for a status bar: waybar or polybar")
+	var barOpts statusbar.Options
+	flag.IntVar(&barOpts.MaxLength, "max-length", 0, "maximum length of the status bar text")
+	flag.BoolVar(&barOpts.Scroll, "scroll", false, "scroll the status bar texts longer than --max-length")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *bar != "" {
+		err := runStatusBar(*bus, *playerName, statusbar.Format(*bar), barOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gompris:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "stats" {
+		if err := runStats(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "gompris:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(*bus, *playerName, *listAll, *format, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "gompris:", err)
+		os.Exit(1)
+	}
+}
+
+func run(bus, playerName string, listAll bool, format string, args []string) error {
+	conn, err := mpris.ConnectBus(bus)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if listAll {
+		names, err := mpris.List(conn)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(strings.TrimPrefix(name, mpris.BaseInterface+"."))
+		}
+		return nil
+	}
+
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("missing command")
+	}
+
+	player, err := selectPlayer(conn, playerName)
+	if err != nil {
+		return err
+	}
+	if format != "" && (args[0] == "status" || args[0] == "metadata") {
+		return printFormat(player, format)
+	}
+	return runCommand(player, args[0], args[1:])
+}
+
+// runStatusBar prints the track of the player for a status bar until it quits.
+func runStatusBar(bus, playerName string, format statusbar.Format, opts statusbar.Options) error {
+	conn, err := mpris.ConnectBus(bus)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	player, err := selectPlayer(conn, playerName)
+	if err != nil {
+		return err
+	}
+	return statusbar.Run(context.Background(), player, os.Stdout, format, opts)
+}
+
+// selectPlayer returns the player with the name, or its most active instance like
+// vlc.instance1234 for vlc, or the active player if the name is empty.
+func selectPlayer(conn *dbus.Conn, name string) (*mpris.Player, error) {
+	if name == "" {
+		player, err := mpris.FindActive(conn)
+		if errors.Is(err, mpris.ErrPlayerNotFound) {
+			return nil, errors.New("no players found")
+		}
+		return player, err
+	}
+
+	names, err := mpris.List(conn)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, fullName := range names {
+		if strings.TrimPrefix(fullName, mpris.BaseInterface+".") == name {
+			return mpris.New(conn, fullName), nil
+		}
+		if matchPlayerName(fullName, name) {
+			matches = append(matches, fullName)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("player %s not found", name)
+	}
+	// the instances, like the tabs of a browser, are used as one player
+	player, err := mpris.FindActiveInstance(conn, name)
+	if errors.Is(err, mpris.ErrPlayerNotFound) {
+		return mpris.New(conn, matches[0]), nil
+	}
+	return player, err
+}
+
+func matchPlayerName(fullName, name string) bool {
+	shortName := strings.TrimPrefix(fullName, mpris.BaseInterface+".")
+	return shortName == name || strings.HasPrefix(shortName, name+".")
+}
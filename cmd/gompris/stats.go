@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/history"
+)
+
+const statsUsage = `Usage: gompris stats [--by artist|album|track] [--since DURATION] [--top N] FILE
+
+Prints the most listened artists, albums or tracks of a history file written by the history
+package, or of the standard input if FILE is "-".
+
+Flags:
+`
+
+// runStats prints the listening statistics of a history file.
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	by := flags.String("by", string(history.ByArtist), "group the statistics by artist, album or track")
+	since := flags.String("since", "", "only count the last DURATION, like 12h or 7d")
+	top := flags.Int("top", 10, "number of entries to print, 0 for all of them")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), statsUsage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected a history file")
+	}
+
+	groupBy := history.GroupBy(*by)
+	if groupBy != history.ByArtist && groupBy != history.ByAlbum && groupBy != history.ByTrack {
+		return fmt.Errorf("invalid group %s, expected artist, album or track", *by)
+	}
+	var window history.Window
+	if *since != "" {
+		d, err := parseSince(*since)
+		if err != nil {
+			return err
+		}
+		window = history.Since(d, time.Now())
+	}
+
+	entries, err := readHistory(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	stats := history.Aggregate(entries, groupBy, window)
+	if *top > 0 && len(stats.Items) > *top {
+		stats.Items = stats.Items[:*top]
+	}
+	return printStats(os.Stdout, stats)
+}
+
+func readHistory(path string) ([]history.Entry, error) {
+	if path == "-" {
+		return history.ReadJSONL(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return history.ReadJSONL(file)
+}
+
+// parseSince parses a duration, also accepting days like "7d".
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+	return d, nil
+}
+
+func printStats(w io.Writer, stats history.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LISTENED\tPLAYS\tCOMPLETED\tNAME")
+	for _, item := range stats.Items {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", formatDuration(item.Listened), item.Plays, item.Completed, item.Name())
+	}
+	fmt.Fprintf(tw, "%s\t%d\t\ttotal\n", formatDuration(stats.Listened), stats.Plays)
+	return tw.Flush()
+}
@@ -0,0 +1,163 @@
+package mpris
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// TypeError is returned when a property or metadata value has a type that can't be converted
+// to the expected one, like a string where a number is expected.
+type TypeError struct {
+	// Name is the name of the property or metadata key.
+	Name string
+	// Signature is the D-Bus signature of the received value, like "s".
+	Signature string
+	// Expected is the Go type the value was converted to.
+	Expected string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s has type %s, which can't be converted to %s", e.Name, e.Signature, e.Expected)
+}
+
+// toInt64 converts any D-Bus numeric value to an int64. Players send the integer properties
+// with different types, like int32 or uint64 lengths, and some send them as doubles. Values
+// that don't fit are rejected.
+func toInt64(value interface{}) (int64, bool) {
+	switch value := value.(type) {
+	case byte:
+		return int64(value), true
+	case int16:
+		return int64(value), true
+	case uint16:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case uint32:
+		return int64(value), true
+	case int64:
+		return value, true
+	case uint64:
+		if value > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	case float64:
+		if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	}
+	return 0, false
+}
+
+// toFloat64 converts any D-Bus numeric value to a float64.
+func toFloat64(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case byte:
+		return float64(value), true
+	case int16:
+		return float64(value), true
+	case uint16:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case uint32:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	}
+	return 0, false
+}
+
+// int64Value converts the variant with toInt64, returning ErrNilVariant for an empty variant
+// and a TypeError for a non numeric one.
+func int64Value(variant dbus.Variant, name string) (int64, error) {
+	if variant.Value() == nil {
+		return 0, ErrNilVariant
+	}
+	value, ok := toInt64(variant.Value())
+	if !ok {
+		return 0, &TypeError{name, variant.Signature().String(), "int64"}
+	}
+	return value, nil
+}
+
+// float64Value converts the variant with toFloat64, returning ErrNilVariant for an empty
+// variant and a TypeError for a non numeric one.
+func float64Value(variant dbus.Variant, name string) (float64, error) {
+	if variant.Value() == nil {
+		return 0, ErrNilVariant
+	}
+	value, ok := toFloat64(variant.Value())
+	if !ok {
+		return 0, &TypeError{name, variant.Signature().String(), "float64"}
+	}
+	return value, nil
+}
+
+// stringValue returns the string in the variant, ErrNilVariant for an empty variant and a
+// TypeError for any other type.
+func stringValue(variant dbus.Variant, name string) (string, error) {
+	switch value := variant.Value().(type) {
+	case string:
+		return value, nil
+	case nil:
+		return "", ErrNilVariant
+	}
+	return "", &TypeError{name, variant.Signature().String(), "string"}
+}
+
+// boolValue returns the boolean in the variant, ErrNilVariant for an empty variant and a
+// TypeError for any other type.
+func boolValue(variant dbus.Variant, name string) (bool, error) {
+	switch value := variant.Value().(type) {
+	case bool:
+		return value, nil
+	case nil:
+		return false, ErrNilVariant
+	}
+	return false, &TypeError{name, variant.Signature().String(), "bool"}
+}
+
+// stringsValue returns the strings in the variant. Lists of variants holding strings are
+// accepted too.
+func stringsValue(variant dbus.Variant, name string) ([]string, error) {
+	switch value := variant.Value().(type) {
+	case []string:
+		return value, nil
+	case []interface{}:
+		strs := make([]string, len(value))
+		for i, item := range value {
+			if variant, ok := item.(dbus.Variant); ok {
+				item = variant.Value()
+			}
+			str, ok := item.(string)
+			if !ok {
+				return nil, &TypeError{name, variant.Signature().String(), "[]string"}
+			}
+			strs[i] = str
+		}
+		return strs, nil
+	case nil:
+		return nil, ErrNilVariant
+	}
+	return nil, &TypeError{name, variant.Signature().String(), "[]string"}
+}
+
+// metadataValue returns the metadata map in the variant.
+func metadataValue(variant dbus.Variant, name string) (Metadata, error) {
+	switch value := variant.Value().(type) {
+	case map[string]dbus.Variant:
+		return Metadata(value), nil
+	case nil:
+		return nil, ErrNilVariant
+	}
+	return nil, &TypeError{name, variant.Signature().String(), "Metadata"}
+}
@@ -0,0 +1,111 @@
+package mpris
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestToInt64(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected int64
+		ok       bool
+	}{
+		{byte(7), 7, true},
+		{int16(-7), -7, true},
+		{uint16(7), 7, true},
+		{int32(-42), -42, true},
+		{uint32(42), 42, true},
+		{int64(42), 42, true},
+		{uint64(42), 42, true},
+		{float64(42.9), 42, true},
+		{uint64(math.MaxUint64), 0, false},
+		{math.Inf(1), 0, false},
+		{math.NaN(), 0, false},
+		{"42", 0, false},
+		{nil, 0, false},
+	}
+	for _, c := range cases {
+		value, ok := toInt64(c.value)
+		if value != c.expected || ok != c.ok {
+			t.Errorf("Expected %v to convert to %d %t, got %d %t", c.value, c.expected, c.ok, value, ok)
+		}
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{float64(0.5), 0.5, true},
+		{int32(1), 1, true},
+		{uint64(2), 2, true},
+		{byte(1), 1, true},
+		{true, 0, false},
+		{"0.5", 0, false},
+	}
+	for _, c := range cases {
+		value, ok := toFloat64(c.value)
+		if value != c.expected || ok != c.ok {
+			t.Errorf("Expected %v to convert to %f %t, got %f %t", c.value, c.expected, c.ok, value, ok)
+		}
+	}
+}
+
+func TestVariantValues(t *testing.T) {
+	if _, err := int64Value(dbus.Variant{}, "Position"); err != ErrNilVariant {
+		t.Errorf("Expected ErrNilVariant, got %v", err)
+	}
+
+	_, err := float64Value(dbus.MakeVariant("loud"), "Volume")
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) || typeErr.Name != "Volume" || typeErr.Signature != "s" {
+		t.Errorf("Expected a TypeError, got %v", err)
+	}
+
+	metadata := Metadata{"mpris:length": dbus.MakeVariant(uint64(3000000))}
+	if metadata.Length() != 3*time.Second {
+		t.Errorf("Expected a 3s length, got %s", metadata.Length())
+	}
+}
+
+func TestGettersDontPanic(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	if err := fake.SetProperty(BaseInterface, "Identity", int32(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(PlayerInterface, "Metadata", "not a map"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(PlayerInterface, "CanPlay", "yes"); err != nil {
+		t.Fatal(err)
+	}
+
+	var typeErr *TypeError
+	if _, err := player.GetIdentity(); !errors.As(err, &typeErr) {
+		t.Errorf("Expected a TypeError for the identity, got %v", err)
+	}
+	if _, err := player.GetMetadata(); !errors.As(err, &typeErr) {
+		t.Errorf("Expected a TypeError for the metadata, got %v", err)
+	}
+	if _, err := player.Player.CanPlay(); !errors.As(err, &typeErr) {
+		t.Errorf("Expected a TypeError for CanPlay, got %v", err)
+	}
+}
+
+func TestStringsValue(t *testing.T) {
+	values, err := stringsValue(dbus.MakeVariant([]interface{}{"a", dbus.MakeVariant("b")}), "test")
+	if err != nil || len(values) != 2 || values[0] != "a" || values[1] != "b" {
+		t.Errorf("Unexpected strings %v %v", values, err)
+	}
+	if _, err := stringsValue(dbus.MakeVariant([]interface{}{int32(1)}), "test"); err == nil {
+		t.Error("Expected an error for a list of numbers")
+	}
+}
@@ -0,0 +1,123 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+// TestConcurrentUse uses a player from several goroutines at once, subscribing, calling and
+// closing it, to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	shared, fake := newTestPlayer(t)
+	player := New(shared.conn, fake.Name(), WithPropertyCache(time.Minute), WithStats())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for volume := 0.0; ctx.Err() == nil; volume += 0.01 {
+			fake.SetProperty(PlayerInterface, "Volume", volume)
+			fake.EmitSeeked(int64(volume * 1000000))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	const goroutines, iterations = 8, 20
+	var wg sync.WaitGroup
+	for n := 0; n < goroutines; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			copied := player.WithContext(ctx)
+			for j := 0; j < iterations; j++ {
+				ch := make(chan *dbus.Signal, 16)
+				if _, err := copied.OnSignal(ch); err != nil {
+					t.Error(err)
+					return
+				}
+				subCtx, subCancel := context.WithCancel(ctx)
+				if _, err := copied.Subscribe(subCtx); err != nil {
+					t.Error(err)
+				}
+				if _, err := copied.GetVolume(); err != nil {
+					t.Error(err)
+				}
+				if _, err := copied.Owner(); err != nil {
+					t.Error(err)
+				}
+				if n%2 == 0 {
+					copied.ToggleMute()
+				} else if err := <-copied.PlayPauseAsync(); err != nil {
+					t.Error(err)
+				}
+				copied.Stats()
+				subCancel()
+				if err := player.RemoveSignal(ch); err != nil {
+					t.Error(err)
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	// the subscription of the owner and the one of the cache are kept, the ones of Subscribe
+	// are closed once they see their context canceled
+	mpristest.Eventually(t, func() bool {
+		player.subscriptions.mu.Lock()
+		defer player.subscriptions.mu.Unlock()
+		return len(player.subscriptions.channels) == 2
+	}, "Expected the subscriptions to be removed")
+
+	// closing while subscribing either closes the new subscriptions or refuses them
+	var subs sync.WaitGroup
+	for n := 0; n < goroutines; n++ {
+		subs.Add(1)
+		go func() {
+			defer subs.Done()
+			sub, err := player.OnSignal(make(chan *dbus.Signal, 16))
+			if err != nil {
+				if !errors.Is(err, ErrClosed) {
+					t.Error(err)
+				}
+				return
+			}
+			select {
+			case <-sub.Done():
+			case <-time.After(5 * time.Second):
+				t.Error("Expected the subscription to be closed with the player")
+			}
+		}()
+	}
+	if err := player.Close(); err != nil {
+		t.Error(err)
+	}
+	subs.Wait()
+}
+
+func TestConcurrentToggleMute(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	if err := fake.SetProperty(PlayerInterface, "Volume", 0.6); err != nil {
+		t.Fatal(err)
+	}
+
+	// an even number of toggles gets the volume back, whatever their order
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := player.WithContext(context.Background()).ToggleMute(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if volume, err := player.GetVolume(); err != nil || volume != 0.6 {
+		t.Errorf("Expected the volume 0.6 back, got %v (%v)", volume, err)
+	}
+}
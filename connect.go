@@ -0,0 +1,70 @@
+package mpris
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// NewFromSession returns a player using the session bus connection shared by the whole
+// program, which is created on the first use. The shared connection is never closed by the
+// player, since other parts of the program may use it.
+func NewFromSession(name string, opts ...Option) (*Player, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return New(conn, name, opts...), nil
+}
+
+// NewFromSessionPrivate returns a player using its own connection to the session bus, which is
+// closed along with the player by Close.
+func NewFromSessionPrivate(name string, opts ...Option) (*Player, error) {
+	return NewOnBus(SessionBus, name, opts...)
+}
+
+// The buses accepted by ConnectBus and NewOnBus besides D-Bus addresses.
+const (
+	SessionBus = "session"
+	SystemBus  = "system"
+)
+
+// ConnectBus opens a private connection to the bus, which is either SessionBus, SystemBus or a
+// D-Bus address, like "unix:path=/run/user/1000/bus" or "tcp:host=192.168.1.2,port=4000" for a
+// forwarded bus. It's meant for List, ListPlayers or a Manager, and has to be closed by the
+// caller.
+func ConnectBus(bus string) (*dbus.Conn, error) {
+	switch bus {
+	case SessionBus:
+		return setupPrivateConn(dbus.SessionBusPrivate())
+	case SystemBus:
+		return setupPrivateConn(dbus.SystemBusPrivate())
+	default:
+		return setupPrivateConn(dbus.Dial(bus))
+	}
+}
+
+// NewOnBus returns a player on the bus, connected like with ConnectBus. The connection is
+// closed along with the player by Close.
+func NewOnBus(bus, name string, opts ...Option) (*Player, error) {
+	conn, err := ConnectBus(bus)
+	if err != nil {
+		return nil, err
+	}
+	return New(conn, name, append(opts[:len(opts):len(opts)], WithOwnedConn())...), nil
+}
+
+// setupPrivateConn authenticates the new private connection and registers it on the bus, so
+// it can make calls and receive signals. The connection is closed if it fails.
+func setupPrivateConn(conn *dbus.Conn, err error) (*dbus.Conn, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.Hello(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,65 @@
+package mpris
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestNewFromSession(t *testing.T) {
+	_, fake := newTestPlayer(t)
+
+	shared, err := NewFromSession(fake.Name())
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := shared.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if err := shared.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := shared.Ping(); err != nil {
+		t.Errorf("Expected the shared connection to be left open, got %v", err)
+	}
+
+	private, err := NewFromSessionPrivate(fake.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := private.OnSignal(make(chan *dbus.Signal)); err != nil {
+		t.Fatal(err)
+	}
+	if identity, err := private.GetIdentity(); err != nil || identity == "" {
+		t.Errorf("Expected an identity, got %q %v", identity, err)
+	}
+	if err := private.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := private.Ping(); !errors.Is(err, dbus.ErrClosed) {
+		t.Errorf("Expected the private connection to be closed, got %v", err)
+	}
+}
+
+func TestNewOnBus(t *testing.T) {
+	_, fake := newTestPlayer(t)
+
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if address == "" {
+		t.Skip("no session bus address")
+	}
+	player, err := NewOnBus(address, fake.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Close()
+	if err := player.Ping(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewOnBus("nonexistent:path=/nowhere", fake.Name()); err == nil {
+		t.Error("Expected an error for an unsupported address")
+	}
+}
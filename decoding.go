@@ -0,0 +1,127 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// decodingMode is how a player handles the values with an unexpected type.
+type decodingMode int
+
+const (
+	// decodeDefault returns a TypeError for the properties and the zero value for the metadata
+	// values, as the Metadata accessors do.
+	decodeDefault decodingMode = iota
+	decodeStrict
+	decodeLenient
+)
+
+// WithStrictDecoding makes the metadata getters of the player, like GetTitle, return a
+// TypeError when the value has an unexpected type, as the property getters already do. It's
+// meant for development, to spot the players sending odd values.
+func WithStrictDecoding() Option {
+	return func(p *Player) {
+		p.decoding = decodeStrict
+	}
+}
+
+// WithLenientDecoding makes the property getters of the player convert the values with an
+// unexpected type as best they can, like "0.5" to 0.5 for the volume, and return the zero value
+// instead of a TypeError when they can't. It's meant for the programs that should keep going
+// whatever the players send, like status bars. Missing values still return ErrNilVariant.
+func WithLenientDecoding() Option {
+	return func(p *Player) {
+		p.decoding = decodeLenient
+	}
+}
+
+// lenient reports whether the error is a TypeError to ignore.
+func (i *Player) lenient(err error) bool {
+	var typeErr *TypeError
+	return i.decoding == decodeLenient && errors.As(err, &typeErr)
+}
+
+func (i *Player) int64Value(variant dbus.Variant, name string) (int64, error) {
+	value, err := int64Value(variant, name)
+	if i.lenient(err) {
+		if str, ok := variant.Value().(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+				value, _ = toInt64(f)
+			}
+		}
+		return value, nil
+	}
+	return value, err
+}
+
+func (i *Player) float64Value(variant dbus.Variant, name string) (float64, error) {
+	value, err := float64Value(variant, name)
+	if i.lenient(err) {
+		if str, ok := variant.Value().(string); ok {
+			value, _ = strconv.ParseFloat(strings.TrimSpace(str), 64)
+		}
+		return value, nil
+	}
+	return value, err
+}
+
+func (i *Player) stringValue(variant dbus.Variant, name string) (string, error) {
+	value, err := stringValue(variant, name)
+	if i.lenient(err) {
+		switch v := variant.Value().(type) {
+		case dbus.ObjectPath:
+			value = string(v)
+		case bool, byte, int16, uint16, int32, uint32, int64, uint64, float64:
+			value = fmt.Sprint(v)
+		}
+		return value, nil
+	}
+	return value, err
+}
+
+func (i *Player) boolValue(variant dbus.Variant, name string) (bool, error) {
+	value, err := boolValue(variant, name)
+	if i.lenient(err) {
+		if str, ok := variant.Value().(string); ok {
+			value, _ = strconv.ParseBool(strings.TrimSpace(str))
+		} else if n, ok := toFloat64(variant.Value()); ok {
+			value = n != 0
+		}
+		return value, nil
+	}
+	return value, err
+}
+
+func (i *Player) stringsValue(variant dbus.Variant, name string) ([]string, error) {
+	value, err := stringsValue(variant, name)
+	if i.lenient(err) {
+		return NormalizeStrings(variant.Value()), nil
+	}
+	return value, err
+}
+
+func (i *Player) metadataValue(variant dbus.Variant, name string) (Metadata, error) {
+	value, err := metadataValue(variant, name)
+	if i.lenient(err) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// metadataString returns the string of the metadata key, or with WithStrictDecoding a
+// TypeError if the key has another type.
+func (i *Player) metadataString(metadata Metadata, key string) (string, error) {
+	variant, ok := metadata[key]
+	if !ok {
+		return "", nil
+	}
+	value, err := stringValue(variant, key)
+	if err != nil && i.decoding != decodeStrict {
+		return value, nil
+	}
+	return value, err
+}
@@ -0,0 +1,325 @@
+package mpris
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mprismem"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestDecodingModes(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	lenient := New(player.conn, fake.Name(), WithLenientDecoding())
+	strict := New(player.conn, fake.Name(), WithStrictDecoding())
+
+	if err := fake.SetProperty(PlayerInterface, "Volume", "0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(PlayerInterface, "Shuffle", int32(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(PlayerInterface, "Rate", []string{"fast"}); err != nil {
+		t.Fatal(err)
+	}
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"xesam:title":  dbus.MakeVariant(int32(42)),
+		"xesam:album":  dbus.MakeVariant("Album"),
+		"xesam:artist": dbus.MakeVariant("Artist"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var typeErr *TypeError
+	if _, err := player.GetVolume(); !errors.As(err, &typeErr) {
+		t.Errorf("Expected a TypeError for the volume by default, got %v", err)
+	}
+	if volume, err := lenient.GetVolume(); err != nil || volume != 0.5 {
+		t.Errorf("Expected the lenient volume to be 0.5, got %f %v", volume, err)
+	}
+	if shuffle, err := lenient.GetShuffle(); err != nil || !shuffle {
+		t.Errorf("Expected the lenient shuffle to be true, got %t %v", shuffle, err)
+	}
+	if rate, err := lenient.GetRate(); err != nil || rate != 0 {
+		t.Errorf("Expected the lenient rate to be 0, got %f %v", rate, err)
+	}
+
+	if title, err := player.GetTitle(); err != nil || title != "" {
+		t.Errorf("Expected an empty title by default, got %q %v", title, err)
+	}
+	if _, err := strict.GetTitle(); !errors.As(err, &typeErr) || typeErr.Name != "xesam:title" {
+		t.Errorf("Expected a TypeError for the strict title, got %v", err)
+	}
+	if album, err := strict.GetAlbum(); err != nil || album != "Album" {
+		t.Errorf("Expected the strict album to be Album, got %q %v", album, err)
+	}
+	if artists, err := strict.GetArtists(); err != nil || len(artists) != 1 || artists[0] != "Artist" {
+		t.Errorf("Expected a single artist, got %v %v", artists, err)
+	}
+}
+
+// fuzzReader builds D-Bus values out of the fuzzed bytes, so the decoding is fed with any type
+// a player could send.
+type fuzzReader struct {
+	data []byte
+}
+
+func (r *fuzzReader) byte() byte {
+	if len(r.data) == 0 {
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *fuzzReader) uint64() uint64 {
+	var value uint64
+	for range 8 {
+		value = value<<8 | uint64(r.byte())
+	}
+	return value
+}
+
+func (r *fuzzReader) string() string {
+	n := min(int(r.byte()%32), len(r.data))
+	value := string(r.data[:n])
+	r.data = r.data[n:]
+	return value
+}
+
+// value returns a value of any type, the containers holding at most a few values down to a
+// few levels, or nil for a missing value.
+func (r *fuzzReader) value(depth int) interface{} {
+	kind := r.byte() % 17
+	if depth > 3 {
+		kind %= 12
+	}
+	switch kind {
+	case 1:
+		return r.byte()
+	case 2:
+		return r.byte()%2 == 0
+	case 3:
+		return int16(r.uint64())
+	case 4:
+		return uint16(r.uint64())
+	case 5:
+		return int32(r.uint64())
+	case 6:
+		return uint32(r.uint64())
+	case 7:
+		return int64(r.uint64())
+	case 8:
+		return r.uint64()
+	case 9:
+		return math.Float64frombits(r.uint64())
+	case 10:
+		return r.string()
+	case 11:
+		return dbus.ObjectPath(r.string())
+	case 12:
+		values := make([]string, r.byte()%4)
+		for n := range values {
+			values[n] = r.string()
+		}
+		return values
+	case 13:
+		var values []dbus.Variant
+		for range r.byte() % 4 {
+			if value, ok := r.variant(depth + 1); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	case 14:
+		// a struct, whose fields are never missing
+		values := make([]interface{}, r.byte()%4)
+		for n := range values {
+			if values[n] = r.value(depth + 1); values[n] == nil {
+				values[n] = ""
+			}
+		}
+		return values
+	case 15:
+		return r.variants(depth + 1)
+	case 16:
+		if value, ok := r.variant(depth + 1); ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// variant returns a variant of any type, or false for a missing value, as a variant always has
+// a value on the bus.
+func (r *fuzzReader) variant(depth int) (dbus.Variant, bool) {
+	value := r.value(depth)
+	if value == nil {
+		return dbus.Variant{}, false
+	}
+	return dbus.MakeVariant(value), true
+}
+
+// fuzzedKeys are the metadata keys read by the decoding, which the fuzzed maps use most of the time.
+var fuzzedKeys = []string{
+	"mpris:trackid", "mpris:length", "mpris:artUrl", "xesam:title", "xesam:album",
+	"xesam:artist", "xesam:albumArtist", "xesam:composer", "xesam:lyricist", "xesam:genre",
+	"xesam:url", "xesam:contentCreated", "xesam:firstUsed", "xesam:lastUsed",
+	"xesam:userRating", "xesam:autoRating", "xesam:trackNumber",
+}
+
+// variants returns a map of a few variants, with the keys of the metadata or random ones.
+func (r *fuzzReader) variants(depth int) map[string]dbus.Variant {
+	values := make(map[string]dbus.Variant)
+	for range r.byte() % 8 {
+		key := r.string()
+		if b := int(r.byte()); b < 4*len(fuzzedKeys) {
+			key = fuzzedKeys[b%len(fuzzedKeys)]
+		}
+		if value, ok := r.variant(depth); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+var fuzzSeeds = [][]byte{
+	{},
+	{1, 0, 10, 5, 'h', 'e', 'l', 'l', 'o'},
+	{7, 3, 8, 0, 0, 0, 0, 0, 0, 1, 0, 12, 3, 2, 'a', 'b', 0},
+	{7, 15, 3, 0, 14, 2, 9, 0xff, 0xf8, 0, 0, 0, 0, 0, 1},
+	// a NaN double in an extra key
+	{'1', 'A', '0', '0', '+', 0xff, 0xff},
+}
+
+func FuzzMetadata(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := &fuzzReader{data}
+		metadata := Metadata(r.variants(0))
+
+		metadata.TrackID()
+		metadata.Length()
+		metadata.ArtURL()
+		metadata.Title()
+		metadata.Album()
+		metadata.Artists()
+		metadata.AlbumArtists()
+		metadata.Composers()
+		metadata.Lyricists()
+		metadata.Genres()
+		metadata.URL()
+		metadata.ContentCreated()
+		metadata.FirstUsed()
+		metadata.LastUsed()
+		metadata.UserRating()
+		metadata.AutoRating()
+		metadata.TrackKey()
+		if _, err := metadata.MarshalJSON(); err != nil {
+			t.Errorf("Expected any metadata to be marshaled, got %v", err)
+		}
+		for _, value := range metadata {
+			NormalizeStrings(value.Value())
+		}
+
+		var track struct {
+			ID          TrackID       `mpris:"mpris:trackid"`
+			Length      time.Duration `mpris:"mpris:length"`
+			Title       string        `mpris:"xesam:title"`
+			Artists     []string      `mpris:"xesam:artist"`
+			Rating      *float64      `mpris:"xesam:userRating"`
+			TrackNumber int           `mpris:"xesam:trackNumber"`
+			Created     time.Time     `mpris:"xesam:contentCreated"`
+			URL         dbus.Variant  `mpris:"xesam:url"`
+			Genres      interface{}   `mpris:"xesam:genre"`
+		}
+		metadata.Unmarshal(&track)
+	})
+}
+
+func FuzzPlayerProperties(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := &fuzzReader{data}
+		obj := mprismem.New("org.mpris.MediaPlayer2.fuzz")
+		set := func(iface string, props ...string) {
+			for _, prop := range props {
+				if value, ok := r.variant(0); ok {
+					obj.Set(iface, prop, value)
+				}
+			}
+		}
+		set(BaseInterface, "Identity", "DesktopEntry", "HasTrackList", "SupportedUriSchemes", "SupportedMimeTypes")
+		set(PlayerInterface, "PlaybackStatus", "LoopStatus", "Rate", "Shuffle", "Metadata", "Volume", "Position", "CanSeek")
+		// the metadata is a map most of the time, to reach the metadata getters
+		if r.byte()%4 != 0 {
+			obj.Set(PlayerInterface, "Metadata", r.variants(0))
+		}
+
+		for _, opts := range [][]Option{nil, {WithStrictDecoding()}, {WithLenientDecoding()}} {
+			player := NewWithObject(obj, opts...)
+			player.GetIdentity()
+			player.GetDesktopEntry()
+			player.HasTrackList()
+			player.GetSupportedUriSchemes()
+			player.GetSupportedMimeTypes()
+			player.GetPlaybackStatus()
+			player.GetLoopStatus()
+			player.GetRate()
+			player.GetShuffle()
+			player.GetMetadata()
+			player.GetVolume()
+			player.GetLength()
+			player.GetLengthDuration()
+			player.GetPosition()
+			player.GetPositionDuration()
+			player.GetTitle()
+			player.GetArtists()
+			player.GetAlbum()
+			player.GetArtURL()
+			player.GetUserRating()
+			player.GetAutoRating()
+			player.CanSeek()
+			if state, err := player.GetState(); err == nil {
+				state.MarshalJSON()
+			}
+		}
+	})
+}
+
+func FuzzParseSignal(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	names := []string{
+		propertiesChangedSignal, seekedSignal, playlistChangedSignal, trackAddedSignal,
+		trackRemovedSignal, trackListReplacedSignal, trackMetadataChangedSignal,
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := &fuzzReader{data}
+		sig := &dbus.Signal{Name: names[int(r.byte())%len(names)]}
+		for range r.byte() % 4 {
+			if value := r.value(0); value != nil {
+				sig.Body = append(sig.Body, value)
+			}
+		}
+		// the player properties are announced as a map most of the time
+		if sig.Name == propertiesChangedSignal && r.byte()%4 != 0 {
+			sig.Body = []interface{}{PlayerInterface, r.variants(0), []string{r.string()}}
+		}
+
+		event, ok := parseSignal(sig)
+		if changed, isChanged := event.(PropertiesChangedEvent); ok && isChanged {
+			var state PlayerState
+			state.apply(changed.Changed)
+		}
+	})
+}
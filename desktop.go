@@ -0,0 +1,196 @@
+package mpris
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dataDirs returns the XDG data directories, the user one first, as defined by the XDG Base
+// Directory specification.
+func dataDirs() []string {
+	var dirs []string
+	if home := os.Getenv("XDG_DATA_HOME"); home != "" {
+		dirs = append(dirs, home)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+	system := os.Getenv("XDG_DATA_DIRS")
+	if system == "" {
+		system = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(system) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// FindDesktopFile returns the path of the desktop file of the entry, like the DesktopEntry of a
+// player, searched in the applications of the XDG data directories. The entry is the desktop
+// file id without the .desktop suffix, like "vlc" for vlc.desktop, whose dashes may also stand
+// for subdirectories, like applications/kde/okular.desktop for "kde-okular".
+// ErrNoDesktopFile is returned when there's no such file.
+func FindDesktopFile(entry string) (string, error) {
+	entry = strings.TrimSuffix(entry, ".desktop")
+	for _, dir := range dataDirs() {
+		applications := filepath.Join(dir, "applications")
+		for _, candidate := range desktopFileCandidates(entry) {
+			path := filepath.Join(applications, candidate)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+	return "", ErrNoDesktopFile
+}
+
+// desktopFileCandidates returns the relative paths a desktop file id may refer to, the
+// entry itself first.
+func desktopFileCandidates(entry string) []string {
+	candidates := []string{entry + ".desktop"}
+	for n := 1; n < len(entry); n++ {
+		if entry[n] == '-' {
+			candidates = append(candidates, filepath.Join(entry[:n], entry[n+1:]+".desktop"))
+		}
+	}
+	return candidates
+}
+
+// GetDesktopFile returns the path of the desktop file of the player, found from its
+// DesktopEntry by FindDesktopFile. ErrNoDesktopFile is returned when the player has no desktop
+// entry or its file is not found.
+func (i *Player) GetDesktopFile() (string, error) {
+	entry, err := i.GetDesktopEntry()
+	if err != nil {
+		return "", err
+	}
+	if entry == "" {
+		return "", ErrNoDesktopFile
+	}
+	return FindDesktopFile(entry)
+}
+
+// DesktopIcon returns the path of the icon of the desktop entry, found from the Icon key of
+// its desktop file by FindIcon, for the theme and the size in pixels.
+func DesktopIcon(entry, theme string, size int) (string, error) {
+	path, err := FindDesktopFile(entry)
+	if err != nil {
+		return "", err
+	}
+	keys, err := readDesktopEntry(path)
+	if err != nil {
+		return "", err
+	}
+	if keys["Icon"] == "" {
+		return "", ErrNoIcon
+	}
+	return FindIcon(keys["Icon"], theme, size)
+}
+
+// GetIconPath returns the path of the icon of the player, found from its DesktopEntry by
+// DesktopIcon, for the theme and the size in pixels, like:
+//
+//	path, err := player.GetIconPath("Adwaita", 48)
+func (i *Player) GetIconPath(theme string, size int) (string, error) {
+	entry, err := i.GetDesktopEntry()
+	if err != nil {
+		return "", err
+	}
+	if entry == "" {
+		return "", ErrNoDesktopFile
+	}
+	return DesktopIcon(entry, theme, size)
+}
+
+// readDesktopEntry returns the keys of the [Desktop Entry] group of the desktop file.
+func readDesktopEntry(path string) (map[string]string, error) {
+	groups, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return groups["Desktop Entry"], nil
+}
+
+// readKeyFile reads the groups of keys of a file in the format of the desktop files, like the
+// index.theme of the icon themes, without the localized keys.
+func readKeyFile(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := make(map[string]map[string]string)
+	var group map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := line[1 : len(line)-1]
+			if groups[name] == nil {
+				groups[name] = make(map[string]string)
+			}
+			group = groups[name]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if group == nil || !ok || strings.Contains(key, "[") {
+			continue
+		}
+		group[key] = strings.TrimSpace(value)
+	}
+	return groups, scanner.Err()
+}
+
+// parseExec splits the Exec key of a desktop file in arguments, following the quoting rules
+// of the Desktop Entry specification. The field codes, like %U for the files to open, are
+// removed, as the player is launched without any.
+func parseExec(exec string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for n := 0; n < len(exec); n++ {
+		c := exec[n]
+		switch {
+		case quoted && c == '\\' && n+1 < len(exec):
+			n++
+			arg.WriteByte(exec[n])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '%' && n+1 < len(exec):
+			n++
+			if exec[n] == '%' {
+				arg.WriteByte('%')
+				inArg = true
+			}
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote in Exec")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty Exec")
+	}
+	return args, nil
+}
@@ -0,0 +1,109 @@
+package mpris
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeDesktopFile writes a desktop file in the applications of the temporary
+// XDG_DATA_HOME.
+func writeDesktopFile(t *testing.T, home, id, content string) {
+	path := filepath.Join(home, "applications", id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindDesktopFile(t *testing.T) {
+	home := t.TempDir()
+	system := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	writeDesktopFile(t, system, "vlc.desktop", "")
+	writeDesktopFile(t, home, "vlc.desktop", "")
+	writeDesktopFile(t, system, "kde/okular.desktop", "")
+
+	tests := map[string]string{
+		"vlc":                filepath.Join(home, "applications", "vlc.desktop"),
+		"vlc.desktop":        filepath.Join(home, "applications", "vlc.desktop"),
+		"kde-okular":         filepath.Join(system, "applications", "kde", "okular.desktop"),
+		"org.example.player": "",
+	}
+	for entry, expected := range tests {
+		path, err := FindDesktopFile(entry)
+		if expected == "" {
+			if !errors.Is(err, ErrNoDesktopFile) {
+				t.Errorf("Expected no desktop file for %s, got %s (%v)", entry, path, err)
+			}
+			continue
+		}
+		if err != nil || path != expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", expected, entry, path, err)
+		}
+	}
+}
+
+func TestParseExec(t *testing.T) {
+	tests := map[string][]string{
+		"vlc --started-from-file %U":      {"vlc", "--started-from-file"},
+		`"/opt/My Player/player" %f`:      {"/opt/My Player/player"},
+		`sh -c "echo \"hi\" \\$HOME"`:     {"sh", "-c", `echo "hi" \$HOME`},
+		"player --volume=100%% --name=%c": {"player", "--volume=100%", "--name="},
+		"env  GDK_BACKEND=x11\tplayer %i": {"env", "GDK_BACKEND=x11", "player"},
+		`player ""`:                       {"player", ""},
+	}
+	for exec, expected := range tests {
+		args, err := parseExec(exec)
+		if err != nil || !reflect.DeepEqual(args, expected) {
+			t.Errorf("Expected %q for %s, got %q (%v)", expected, exec, args, err)
+		}
+	}
+	for _, exec := range []string{"", "%U", `player "unterminated`} {
+		if _, err := parseExec(exec); err == nil {
+			t.Errorf("Expected an error for %q", exec)
+		}
+	}
+}
+
+func TestGetIconPath(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	_, data, system := setupIconThemes(t)
+
+	if _, err := player.GetIconPath("", 48); !errors.Is(err, ErrNoDesktopFile) {
+		t.Errorf("Expected ErrNoDesktopFile, got %v", err)
+	}
+
+	writeDesktopFile(t, data, "mpristest.desktop", `[Desktop Entry]
+Type=Application
+Name=Test player
+Name[fr]=Lecteur de test
+Icon=player
+Icon[fr]=ignored
+Exec=player %U
+
+[Desktop Action Quit]
+Icon=ignored
+`)
+	desktopFile, err := player.GetDesktopFile()
+	if err != nil || desktopFile != filepath.Join(data, "applications", "mpristest.desktop") {
+		t.Errorf("Expected the desktop file, got %s (%v)", desktopFile, err)
+	}
+	path, err := player.GetIconPath("Test", 48)
+	if expected := filepath.Join(system, "icons", "hicolor", "48x48", "apps", "player.png"); err != nil || path != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, path, err)
+	}
+
+	if err := fake.SetProperty(BaseInterface, "DesktopEntry", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.GetDesktopFile(); !errors.Is(err, ErrNoDesktopFile) {
+		t.Errorf("Expected ErrNoDesktopFile without a desktop entry, got %v", err)
+	}
+}
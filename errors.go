@@ -0,0 +1,97 @@
+package mpris
+
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	// ErrNilVariant is returned when the player answers with an empty value.
+	ErrNilVariant = errors.New("variant value is nil")
+	// ErrNotSupported is returned when the player doesn't implement the called method or the
+	// requested property.
+	ErrNotSupported = errors.New("not supported by the player")
+	// ErrPlayerNotFound is returned when there is no player with the name on the bus, like
+	// when the player quits in the middle of a call.
+	ErrPlayerNotFound = errors.New("player not found")
+	// ErrClosed is returned when subscribing to the signals of a closed player.
+	ErrClosed = errors.New("player is closed")
+	// ErrNoURL is returned when restoring the bookmark of a track that's not the current one
+	// and has no URL to open it with.
+	ErrNoURL = errors.New("the bookmark has no URL")
+	// ErrNotAllowed is matched by the NotAllowedError returned by the Try methods, like
+	// TryPlay, when the player capabilities don't allow the command.
+	ErrNotAllowed = errors.New("not allowed by the player")
+	// ErrNoDesktopFile is returned when a desktop entry has no desktop file in the XDG data
+	// directories, or when the player has no desktop entry.
+	ErrNoDesktopFile = errors.New("desktop file not found")
+	// ErrNoIcon is returned when an icon is not found in the icon themes.
+	ErrNoIcon = errors.New("icon not found")
+	// ErrNoConnection is returned when subscribing to the signals of a player created by
+	// NewWithObject, which has no connection to receive them.
+	ErrNoConnection = errors.New("player has no connection")
+)
+
+// NotAllowedError is returned by the Try methods, like TryPlay, when a capability needed by the
+// command is false. It matches ErrNotAllowed with errors.Is.
+type NotAllowedError struct {
+	// Method is the called method, like "Play".
+	Method string
+	// Capability is the false capability, like "CanPlay".
+	Capability string
+}
+
+func (e *NotAllowedError) Error() string {
+	return e.Method + " not allowed by the player, " + e.Capability + " is false"
+}
+
+func (e *NotAllowedError) Is(target error) bool {
+	return target == ErrNotAllowed
+}
+
+// dbusErrors maps the D-Bus error names to the errors they match.
+var dbusErrors = map[string]error{
+	"org.freedesktop.DBus.Error.ServiceUnknown":   ErrPlayerNotFound,
+	"org.freedesktop.DBus.Error.NameHasNoOwner":   ErrPlayerNotFound,
+	"org.freedesktop.DBus.Error.UnknownMethod":    ErrNotSupported,
+	"org.freedesktop.DBus.Error.UnknownProperty":  ErrNotSupported,
+	"org.freedesktop.DBus.Error.UnknownInterface": ErrNotSupported,
+	"org.freedesktop.DBus.Error.UnknownObject":    ErrNotSupported,
+	"org.freedesktop.DBus.Error.NotSupported":     ErrNotSupported,
+}
+
+// dbusError is a D-Bus error that also matches one of the package errors with errors.Is.
+type dbusError struct {
+	err    dbus.Error
+	target error
+}
+
+func (e *dbusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *dbusError) Is(target error) bool {
+	return target == e.target
+}
+
+func (e *dbusError) Unwrap() error {
+	return e.err
+}
+
+// mapError wraps the known D-Bus errors so they can be checked with errors.Is.
+func mapError(err error) error {
+	var dbusErr dbus.Error
+	switch e := err.(type) {
+	case dbus.Error:
+		dbusErr = e
+	case *dbus.Error:
+		dbusErr = *e
+	default:
+		return err
+	}
+	if target, ok := dbusErrors[dbusErr.Name]; ok {
+		return &dbusError{dbusErr, target}
+	}
+	return err
+}
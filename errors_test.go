@@ -0,0 +1,52 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMapError(t *testing.T) {
+	cases := []struct {
+		err    error
+		target error
+	}{
+		{dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}, ErrPlayerNotFound},
+		{&dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownMethod"}, ErrNotSupported},
+		{dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownProperty"}, ErrNotSupported},
+	}
+
+	for _, c := range cases {
+		err := mapError(c.err)
+		if !errors.Is(err, c.target) {
+			t.Errorf("Expected %v to match %v", err, c.target)
+		}
+		var dbusErr dbus.Error
+		if !errors.As(err, &dbusErr) {
+			t.Errorf("Expected %v to unwrap to a dbus.Error", err)
+		}
+	}
+
+	unknown := dbus.Error{Name: "org.mpris.MediaPlayer2.Error.Custom"}
+	if err := mapError(unknown); errors.Is(err, ErrNotSupported) || errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected %v to not be mapped", err)
+	}
+	if mapError(nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+}
+
+func TestPlayerErrors(t *testing.T) {
+	player, _ := newTestPlayer(t)
+
+	_, err := player.GetProperty(PlayerInterface, "Unknown")
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+
+	missing := New(player.conn, BaseInterface+".gompris.missing")
+	if err := missing.Play(); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+package mpris
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Event is an event emitted by the player, received through Subscribe.
+type Event interface {
+	isEvent()
+}
+
+// PropertiesChangedEvent is emitted when the player announces that some properties changed.
+// Invalidated lists the properties that changed but whose new values weren't sent.
+type PropertiesChangedEvent struct {
+	Interface   string
+	Changed     map[string]dbus.Variant
+	Invalidated []string
+}
+
+// SeekedEvent is emitted when the track position jumps, like after a seek.
+type SeekedEvent struct {
+	Position time.Duration
+}
+
+// PlaylistChangedEvent is emitted when the name or the icon of a playlist changes.
+type PlaylistChangedEvent struct {
+	Playlist Playlist
+}
+
+// TrackAddedEvent is emitted when a track is added to the track list, after the track After or
+// at the start of the list if After is NoTrack.
+type TrackAddedEvent struct {
+	Metadata Metadata
+	After    TrackID
+}
+
+// TrackRemovedEvent is emitted when a track is removed from the track list.
+type TrackRemovedEvent struct {
+	TrackID TrackID
+}
+
+// TrackListReplacedEvent is emitted when the whole track list changes, like when another album
+// is played.
+type TrackListReplacedEvent struct {
+	Tracks  []TrackID
+	Current TrackID
+}
+
+// TrackMetadataChangedEvent is emitted when the metadata of a track of the track list changes.
+type TrackMetadataChangedEvent struct {
+	TrackID  TrackID
+	Metadata Metadata
+}
+
+// OwnerChangedEvent is emitted when the owner of the player name changes. NewOwner is empty
+// when the player quit, and the events of a player that starts again under the same name keep
+// coming after an event with the new owner.
+type OwnerChangedEvent struct {
+	OldOwner string
+	NewOwner string
+}
+
+// TrackChangeEvent is emitted by OnTrackChange when the current track changes.
+type TrackChangeEvent struct {
+	Metadata Metadata
+}
+
+func (PropertiesChangedEvent) isEvent()    {}
+func (SeekedEvent) isEvent()               {}
+func (PlaylistChangedEvent) isEvent()      {}
+func (TrackAddedEvent) isEvent()           {}
+func (TrackRemovedEvent) isEvent()         {}
+func (TrackListReplacedEvent) isEvent()    {}
+func (TrackMetadataChangedEvent) isEvent() {}
+func (OwnerChangedEvent) isEvent()         {}
+func (TrackChangeEvent) isEvent()          {}
+
+// Subscribe returns a channel receiving the player events until ctx is done or the connection
+// is closed, when the channel is closed. As with OnSignal, a subscription follows a player
+// that restarts, which is announced by an OwnerChangedEvent. The events can be shaped with
+// opts, like WithDebounce.
+//
+// The values of the properties announced as invalidated are fetched from the player, so
+// they're found in the Changed map of the events like the others.
+func (i *Player) Subscribe(ctx context.Context, opts ...SubscribeOption) (<-chan Event, error) {
+	signals := make(chan *dbus.Signal, i.signalBuffer)
+	sub, err := i.OnSignal(signals)
+	if err != nil {
+		return nil, err
+	}
+
+	// the state is read once subscribed, so no change is missed in between
+	state := func() (map[string]dbus.Variant, error) {
+		return i.WithContext(ctx).getAllProperties(PlayerInterface)
+	}
+	decoded := make(chan Event)
+	events, err := ShapeEvents(ctx, decoded, state, opts...)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	go func() {
+		defer close(decoded)
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.Done():
+				return
+			case sig := <-signals:
+				event, ok := i.decodeSignal(sig)
+				if !ok {
+					continue
+				}
+				if changed, ok := event.(PropertiesChangedEvent); ok && len(changed.Invalidated) > 0 {
+					event = i.WithContext(ctx).resolveInvalidated(changed)
+				}
+				select {
+				case decoded <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Events returns the player events as an iterator, for use with range. The subscription is
+// made when the iteration starts and closed when it stops, either because the loop exits or
+// because ctx is done. The iteration ends right away if the subscription fails, use Subscribe
+// to get the error.
+func (i *Player) Events(ctx context.Context, opts ...SubscribeOption) iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := i.Subscribe(ctx, opts...)
+		if err != nil {
+			return
+		}
+		for event := range events {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// resolveInvalidated fetches the values of the properties the player announced as changed
+// without sending them, and moves them to the changed ones. The properties that can't be
+// fetched are left as invalidated.
+func (i *Player) resolveInvalidated(event PropertiesChangedEvent) PropertiesChangedEvent {
+	var values map[string]dbus.Variant
+	if len(event.Invalidated) == 1 {
+		// the cache is skipped, it may not have seen the invalidation yet
+		var value dbus.Variant
+		if err := i.query(getPropertyMethod, event.Interface, event.Invalidated[0]).Store(&value); err != nil {
+			i.debug("resolving an invalidated property", "interface", event.Interface, "error", err)
+			return event
+		}
+		values = map[string]dbus.Variant{event.Invalidated[0]: value}
+	} else if err := i.query(getAllPropertiesMethod, event.Interface).Store(&values); err != nil {
+		i.debug("resolving invalidated properties", "interface", event.Interface, "error", err)
+		return event
+	}
+
+	resolved := PropertiesChangedEvent{Interface: event.Interface, Changed: make(map[string]dbus.Variant)}
+	for name, value := range event.Changed {
+		resolved.Changed[name] = value
+	}
+	for _, name := range event.Invalidated {
+		if value, ok := values[name]; ok {
+			resolved.Changed[name] = value
+		} else {
+			resolved.Invalidated = append(resolved.Invalidated, name)
+		}
+	}
+	return resolved
+}
+
+// parseSignal converts a player signal to an event, returning false for unknown or malformed
+// signals.
+func parseSignal(sig *dbus.Signal) (Event, bool) {
+	switch sig.Name {
+	case propertiesChangedSignal:
+		if len(sig.Body) < 3 {
+			return nil, false
+		}
+		iface, ok := sig.Body[0].(string)
+		if !ok {
+			return nil, false
+		}
+		changed, _ := sig.Body[1].(map[string]dbus.Variant)
+		invalidated, _ := sig.Body[2].([]string)
+		return PropertiesChangedEvent{iface, changed, invalidated}, true
+	case seekedSignal:
+		if len(sig.Body) < 1 {
+			return nil, false
+		}
+		position, ok := toInt64(sig.Body[0])
+		if !ok {
+			return nil, false
+		}
+		return SeekedEvent{microsecondsToDuration(position)}, true
+	case playlistChangedSignal:
+		var playlist Playlist
+		if err := dbus.Store(sig.Body, &playlist); err != nil {
+			return nil, false
+		}
+		return PlaylistChangedEvent{playlist}, true
+	case trackAddedSignal:
+		var metadata map[string]dbus.Variant
+		var after dbus.ObjectPath
+		if err := dbus.Store(sig.Body, &metadata, &after); err != nil {
+			return nil, false
+		}
+		return TrackAddedEvent{Metadata(metadata), TrackID(after)}, true
+	case trackRemovedSignal:
+		var trackID dbus.ObjectPath
+		if err := dbus.Store(sig.Body, &trackID); err != nil {
+			return nil, false
+		}
+		return TrackRemovedEvent{TrackID(trackID)}, true
+	case trackListReplacedSignal:
+		var paths []dbus.ObjectPath
+		var current dbus.ObjectPath
+		if err := dbus.Store(sig.Body, &paths, &current); err != nil {
+			return nil, false
+		}
+		tracks := make([]TrackID, len(paths))
+		for i, path := range paths {
+			tracks[i] = TrackID(path)
+		}
+		return TrackListReplacedEvent{tracks, TrackID(current)}, true
+	case trackMetadataChangedSignal:
+		var trackID dbus.ObjectPath
+		var metadata map[string]dbus.Variant
+		if err := dbus.Store(sig.Body, &trackID, &metadata); err != nil {
+			return nil, false
+		}
+		return TrackMetadataChangedEvent{TrackID(trackID), Metadata(metadata)}, true
+	case nameOwnerChangedSignal:
+		change, ok := parseNameOwnerChanged(sig)
+		if !ok {
+			return nil, false
+		}
+		return OwnerChangedEvent{change.OldOwner, change.NewOwner}, true
+	}
+	return nil, false
+}
+
+// OnTrackChange returns a channel receiving an event every time the current track changes,
+// until ctx is done. Metadata updates that keep the same track, like players filling the
+// metadata in several steps, are not reported.
+func (i *Player) OnTrackChange(ctx context.Context) (<-chan TrackChangeEvent, error) {
+	var current string
+	if metadata, err := i.GetMetadata(); err == nil {
+		current = metadata.TrackKey()
+	}
+
+	events, err := i.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan TrackChangeEvent)
+	go func() {
+		defer close(changes)
+		for event := range events {
+			changed, ok := event.(PropertiesChangedEvent)
+			if !ok || changed.Interface != PlayerInterface {
+				continue
+			}
+			value, ok := changed.Changed["Metadata"].Value().(map[string]dbus.Variant)
+			if !ok {
+				continue
+			}
+			metadata := Metadata(value)
+			key := metadata.TrackKey()
+			if key == current {
+				continue
+			}
+			current = key
+			select {
+			case changes <- TrackChangeEvent{metadata}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// TrackChanges returns the track changes as an iterator, like Events does for OnTrackChange.
+func (i *Player) TrackChanges(ctx context.Context) iter.Seq[TrackChangeEvent] {
+	return func(yield func(TrackChangeEvent) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		changes, err := i.OnTrackChange(ctx)
+		if err != nil {
+			return
+		}
+		for change := range changes {
+			if !yield(change) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestSubscribe(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EmitSeeked(42000000); err != nil {
+		t.Fatal(err)
+	}
+
+	event := receiveEvent(t, events)
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || changed.Interface != PlayerInterface || changed.Changed["PlaybackStatus"].Value() != "Playing" {
+		t.Errorf("Unexpected event %#v", event)
+	}
+
+	event = receiveEvent(t, events)
+	if seeked, ok := event.(SeekedEvent); !ok || seeked.Position != 42*time.Second {
+		t.Errorf("Unexpected event %#v", event)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no more events")
+		}
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for the channel to be closed")
+	}
+}
+
+func TestOnTrackChange(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := player.OnTrackChange(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setTrack := func(trackID, title string) {
+		err := fake.SetMetadata(map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(trackID)),
+			"xesam:title":   dbus.MakeVariant(title),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setTrack("/org/mpris/MediaPlayer2/Track/1", "First")
+	setTrack("/org/mpris/MediaPlayer2/Track/1", "First (updated)")
+	setTrack("/org/mpris/MediaPlayer2/Track/2", "Second")
+
+	for _, expected := range []string{"First", "Second"} {
+		select {
+		case change := <-changes:
+			if change.Metadata.Title() != expected {
+				t.Errorf("Expected track %s, got %s", expected, change.Metadata.Title())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for track %s", expected)
+		}
+	}
+
+	select {
+	case change := <-changes:
+		t.Errorf("Unexpected track change %v", change.Metadata.Title())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func receiveEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return nil
+}
+
+func TestEventsIterator(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		// the signal is sent repeatedly, as the subscription starts with the loop
+		for i := int64(0); ; i++ {
+			fake.EmitSeeked(i * 1000000)
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	received := 0
+	for event := range player.Events(ctx) {
+		if _, ok := event.(SeekedEvent); !ok {
+			t.Errorf("Unexpected event %#v", event)
+		}
+		received++
+		if received == 2 {
+			break
+		}
+	}
+	if received != 2 {
+		t.Fatalf("Expected 2 events, got %d", received)
+	}
+
+	// breaking out of the loop closes the subscription
+	mpristest.Eventually(t, func() bool {
+		player.subscriptions.mu.Lock()
+		defer player.subscriptions.mu.Unlock()
+		return len(player.subscriptions.channels) == 0
+	}, "The subscription wasn't closed")
+}
+
+func TestSubscribeInvalidated(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.EmitPropertiesChanged(PlayerInterface, nil, []string{"Volume", "Missing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	event := receiveEvent(t, events)
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || changed.Changed["Volume"].Value() != 1.0 {
+		t.Errorf("Expected the volume to be fetched, got %#v", event)
+	}
+	if len(changed.Invalidated) != 1 || changed.Invalidated[0] != "Missing" {
+		t.Errorf("Expected the unknown property to stay invalidated, got %v", changed.Invalidated)
+	}
+}
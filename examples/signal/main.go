@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/Pauloo27/go-mpris"
@@ -27,11 +28,12 @@ func main() {
 	player := mpris.New(conn, name)
 
 	ch := make(chan *dbus.Signal)
-	err := player.OnSignal(ch)
+	sub, err := player.OnSignal(ch)
 	if err != nil {
 		panic(err)
 	}
+	defer sub.Close()
 
 	sig := <-ch
-	fmt.Println(sig.Body)
+	fmt.Println(sig.Name, sig.Body)
 }
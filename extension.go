@@ -0,0 +1,44 @@
+package mpris
+
+import "github.com/godbus/dbus/v5"
+
+// InterfaceClient calls an interface of the player object that go-mpris doesn't know, like the
+// extensions of VLC or Kodi. The calls go through the player like the others, so they use its
+// context, timeout, logger, tracer and stats, and their errors match the package errors.
+type InterfaceClient struct {
+	core *Player
+	name string
+}
+
+// Interface returns a client of the interface of the player object, like
+// "org.mpris.MediaPlayer2.ExtensionSetRatings".
+func (i *Player) Interface(name string) InterfaceClient {
+	return InterfaceClient{core: i, name: name}
+}
+
+// Name returns the name of the interface.
+func (c InterfaceClient) Name() string {
+	return c.name
+}
+
+// Call calls the method of the interface, like "SetRating". The results can be read with the
+// Store method of the call, and its Err field holds the error. The call waits for the answer
+// even on the copies made by NoReply, as its results are meant to be read.
+func (c InterfaceClient) Call(method string, args ...interface{}) *dbus.Call {
+	return c.core.query(c.name+"."+method, args...)
+}
+
+// Get returns the value of the property of the interface.
+func (c InterfaceClient) Get(property string) (dbus.Variant, error) {
+	return c.core.getProperty(c.name, property)
+}
+
+// GetAll returns the values of all the properties of the interface.
+func (c InterfaceClient) GetAll() (map[string]dbus.Variant, error) {
+	return c.core.getAllProperties(c.name)
+}
+
+// Set sets the value of the property of the interface.
+func (c InterfaceClient) Set(property string, value interface{}) error {
+	return c.core.setProperty(c.name, property, value)
+}
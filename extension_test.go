@@ -0,0 +1,56 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+const testExtensionInterface = "org.mpristest.Extension"
+
+type testExtension struct{}
+
+func (testExtension) Echo(value string) (string, *dbus.Error) {
+	return value, nil
+}
+
+func TestInterface(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	fake, err := mpristest.New(conn, fmt.Sprintf("mpristest.extension%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	if err := conn.Export(testExtension{}, "/org/mpris/MediaPlayer2", testExtensionInterface); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(testExtensionInterface, "Mode", "normal"); err != nil {
+		t.Fatal(err)
+	}
+	extension := New(mpristest.PrivateConn(t), fake.Name()).Interface(testExtensionInterface)
+
+	var echoed string
+	if err := extension.Call("Echo", "hello").Store(&echoed); err != nil || echoed != "hello" {
+		t.Errorf("Expected the call to echo hello, got %q %v", echoed, err)
+	}
+	if err := New(mpristest.PrivateConn(t), fake.Name()).NoReply().Interface(testExtensionInterface).Call("Echo", "quiet").Store(&echoed); err != nil || echoed != "quiet" {
+		t.Errorf("Expected the call of a NoReply copy to be answered, got %q %v", echoed, err)
+	}
+	if err := extension.Call("Missing").Err; !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for a missing method, got %v", err)
+	}
+
+	if err := extension.Set("Mode", "party"); err != nil {
+		t.Fatal(err)
+	}
+	if mode, err := extension.Get("Mode"); err != nil || mode.Value() != "party" {
+		t.Errorf("Expected the mode to be party, got %v %v", mode, err)
+	}
+	if props, err := extension.GetAll(); err != nil || len(props) != 1 {
+		t.Errorf("Expected a single property, got %v %v", props, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package spotify deals with the quirks of the Spotify desktop client MPRIS support.
+//
+// Spotify identifies its tracks with Spotify URIs, like spotify:track:4uLU6hMCjMI75M1A2tKUQC,
+// sent either as a track id like /com/spotify/track/4uLU6hMCjMI75M1A2tKUQC or, by the recent
+// clients, as a plain string. ParseURI and TrackURI turn both into a URI, which gives the
+// open.spotify.com link of the track.
+//
+// The Player wraps a mpris.Player to report what Spotify doesn't support with
+// mpris.ErrNotSupported, instead of calls that are silently ignored.
+package spotify
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// BusName is the bus name of the Spotify desktop client.
+const BusName = mpris.BaseInterface + ".spotify"
+
+// trackIDPrefix is the prefix of the track ids sent as object paths.
+const trackIDPrefix = "/com/spotify/"
+
+// webHost is the host of the Spotify web links.
+const webHost = "open.spotify.com"
+
+// URI is a Spotify URI, like spotify:track:4uLU6hMCjMI75M1A2tKUQC.
+type URI struct {
+	// Kind is the kind of item, like "track", "episode" or "ad".
+	Kind string
+	// ID is the base62 id of the item.
+	ID string
+}
+
+// String returns the URI, like spotify:track:4uLU6hMCjMI75M1A2tKUQC.
+func (u URI) String() string {
+	return "spotify:" + u.Kind + ":" + u.ID
+}
+
+// WebURL returns the open.spotify.com link of the item, like
+// https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC.
+func (u URI) WebURL() string {
+	return "https://" + webHost + "/" + u.Kind + "/" + u.ID
+}
+
+// ParseURI parses the URIs and links of Spotify items: spotify:track:ID URIs, the
+// /com/spotify/track/ID track ids and the https://open.spotify.com/track/ID links.
+func ParseURI(value string) (URI, bool) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(value, "spotify:"):
+		parts = strings.Split(strings.TrimPrefix(value, "spotify:"), ":")
+	case strings.HasPrefix(value, trackIDPrefix):
+		parts = strings.Split(strings.TrimPrefix(value, trackIDPrefix), "/")
+	default:
+		link, err := url.Parse(value)
+		if err != nil || (link.Scheme != "https" && link.Scheme != "http") || link.Host != webHost {
+			return URI{}, false
+		}
+		parts = strings.Split(strings.Trim(link.Path, "/"), "/")
+	}
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return URI{}, false
+	}
+	return URI{Kind: parts[0], ID: parts[1]}, true
+}
+
+// TrackURI returns the URI of the track of the metadata, from its track id or, for the
+// clients that send an unrelated track id, its URL.
+func TrackURI(metadata mpris.Metadata) (URI, bool) {
+	if uri, ok := ParseURI(string(metadata.TrackID())); ok {
+		return uri, true
+	}
+	return ParseURI(metadata.URL())
+}
+
+// WebURL returns the open.spotify.com link of the track of the metadata, or an empty string
+// if it's not a Spotify track.
+func WebURL(metadata mpris.Metadata) string {
+	uri, ok := TrackURI(metadata)
+	if !ok {
+		return ""
+	}
+	return uri.WebURL()
+}
+
+// IsSpotify reports whether the bus name is the one of the Spotify client.
+func IsSpotify(name string) bool {
+	return name == BusName || strings.HasPrefix(name, BusName+".instance")
+}
+
+// Player is the Spotify client, with the methods Spotify handles differently from the other
+// players.
+type Player struct {
+	*mpris.Player
+}
+
+// New connects to the Spotify client in the connection conn, configured by opts.
+func New(conn *dbus.Conn, opts ...mpris.Option) *Player {
+	return &Player{mpris.New(conn, BusName, opts...)}
+}
+
+// Wrap returns the Spotify methods of a player, which should be the Spotify client.
+func Wrap(player *mpris.Player) *Player {
+	return &Player{player}
+}
+
+// GetPosition returns the position of the current track. Some versions of Spotify always
+// report 0, so mpris.ErrNotSupported is returned when the position is 0 while playing.
+func (p *Player) GetPosition() (time.Duration, error) {
+	state, err := p.GetState()
+	if err != nil {
+		return 0, err
+	}
+	if state.Position == 0 && state.PlaybackStatus == mpris.PlaybackPlaying {
+		return 0, mpris.ErrNotSupported
+	}
+	return state.Position, nil
+}
+
+// SeekTo sets the position of the current track. Spotify ignores the calls with the track
+// ids it doesn't send as object paths, and the ads can't be seeked, so
+// mpris.ErrNotSupported is returned for them, as when CanSeek is false.
+func (p *Player) SeekTo(position time.Duration) error {
+	trackID, err := p.seekableTrack()
+	if err != nil {
+		return err
+	}
+	return p.SetPositionWithTrackID(trackID, position)
+}
+
+// Seek moves the position of the current track by the offset, returning mpris.ErrNotSupported
+// like SeekTo.
+func (p *Player) Seek(offset time.Duration) error {
+	if _, err := p.seekableTrack(); err != nil {
+		return err
+	}
+	return p.Player.Player.Seek(offset)
+}
+
+// seekableTrack returns the id of the current track, or mpris.ErrNotSupported if it can't be
+// seeked.
+func (p *Player) seekableTrack() (mpris.TrackID, error) {
+	canSeek, err := p.CanSeek()
+	if err != nil {
+		return "", err
+	}
+	metadata, err := p.GetMetadata()
+	if err != nil {
+		return "", err
+	}
+	trackID := metadata.TrackID()
+	uri, _ := TrackURI(metadata)
+	if !canSeek || uri.Kind == "ad" || !trackID.IsValid() || trackID.IsNoTrack() {
+		return "", mpris.ErrNotSupported
+	}
+	return trackID, nil
+}
+
+// OpenUri plays the Spotify URI or open.spotify.com link. Spotify ignores the other URIs, like
+// files, so mpris.ErrNotSupported is returned for them.
+func (p *Player) OpenUri(uri string) error {
+	parsed, ok := ParseURI(uri)
+	if !ok {
+		return mpris.ErrNotSupported
+	}
+	return p.Player.OpenUri(parsed.String())
+}
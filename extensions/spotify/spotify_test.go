@@ -0,0 +1,101 @@
+package spotify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+func TestParseURI(t *testing.T) {
+	track := URI{Kind: "track", ID: "4uLU6hMCjMI75M1A2tKUQC"}
+	cases := []struct {
+		value string
+		uri   URI
+		ok    bool
+	}{
+		{"spotify:track:4uLU6hMCjMI75M1A2tKUQC", track, true},
+		{"/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC", track, true},
+		{"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC", track, true},
+		{"/com/spotify/ad/1", URI{Kind: "ad", ID: "1"}, true},
+		{"spotify:track:", URI{}, false},
+		{"https://example.com/track/4uLU6hMCjMI75M1A2tKUQC", URI{}, false},
+		{"file:///music/a.mp3", URI{}, false},
+	}
+	for _, c := range cases {
+		uri, ok := ParseURI(c.value)
+		if uri != c.uri || ok != c.ok {
+			t.Errorf("Expected %s to parse to %v %t, got %v %t", c.value, c.uri, c.ok, uri, ok)
+		}
+	}
+	if track.String() != "spotify:track:4uLU6hMCjMI75M1A2tKUQC" || track.WebURL() != "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC" {
+		t.Errorf("Unexpected URI formats %s %s", track, track.WebURL())
+	}
+
+	// the recent clients send the track id as a string
+	metadata := mpris.Metadata{"mpris:trackid": dbus.MakeVariant("spotify:track:4uLU6hMCjMI75M1A2tKUQC")}
+	if WebURL(metadata) != track.WebURL() {
+		t.Errorf("Expected the web URL of the track, got %q", WebURL(metadata))
+	}
+	if !IsSpotify("org.mpris.MediaPlayer2.spotify") || IsSpotify("org.mpris.MediaPlayer2.spotifyd") {
+		t.Error("Unexpected IsSpotify result")
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	fake, err := mpristest.New(mpristest.PrivateConn(t), fmt.Sprintf("mpristest.spotify%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	player := Wrap(mpris.New(mpristest.PrivateConn(t), fake.Name()))
+
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.GetPosition(); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for a position stuck at 0, got %v", err)
+	}
+	if err := player.SeekTo(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if position, err := player.GetPosition(); err != nil || position != time.Minute {
+		t.Errorf("Expected the position to be 1m, got %v %v", position, err)
+	}
+
+	if err := player.OpenUri("https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC"); err != nil {
+		t.Fatal(err)
+	}
+	calls := fake.Calls()
+	if last := calls[len(calls)-1]; last.Method != "OpenUri" || last.Args[0] != "spotify:track:4uLU6hMCjMI75M1A2tKUQC" {
+		t.Errorf("Expected the link to be opened as a URI, got %+v", last)
+	}
+	if err := player.OpenUri("file:///music/a.mp3"); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for a file, got %v", err)
+	}
+
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/com/spotify/ad/1")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := player.Seek(time.Second); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for an ad, got %v", err)
+	}
+}
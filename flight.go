@@ -0,0 +1,76 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// errFlightPanicked is the error of the callers sharing a read that panicked.
+var errFlightPanicked = errors.New("mpris: the shared property read panicked")
+
+// flightKey identifies the identical property reads, which are sent with the same flags.
+type flightKey struct {
+	method string
+	iface  string
+	prop   string
+	flags  dbus.Flags
+}
+
+// flight is a property read in progress, whose result is set before done is closed.
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// flightGroup deduplicates the identical property reads made at the same time, like the ones
+// of the widgets refreshing together, so only one D-Bus call is made and its result is shared
+// by the callers. It's shared by the copies made by WithContext.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[flightKey]*flight
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[flightKey]*flight)}
+}
+
+// do calls read, unless an identical read is in flight, in which case it waits for its result
+// or for ctx to be done. shared is true if the result is the one of another caller. A caller
+// whose shared read was canceled or timed out because of the context of the other caller calls
+// read itself.
+func (g *flightGroup) do(ctx context.Context, key flightKey, read func() (interface{}, error)) (value interface{}, shared bool, err error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			if isContextError(f.err) && ctx.Err() == nil {
+				value, err = read()
+				return value, false, err
+			}
+			return f.value, true, f.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{}), err: errFlightPanicked}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.value, f.err = read()
+	return f.value, false, f.err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,155 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mprismem"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestSharedPropertyReads(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.mem")
+	release := make(chan struct{})
+	obj.Handle(getPropertyMethod, func(args ...interface{}) ([]interface{}, *dbus.Error) {
+		<-release
+		return []interface{}{dbus.MakeVariant("Mem")}, nil
+	})
+	player := NewWithObject(obj)
+
+	var wg sync.WaitGroup
+	identities := make([]string, 5)
+	errs := make([]error, len(identities))
+	for n := range identities {
+		ctx := newWaitingContext(context.Background())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			identities[n], errs[n] = player.WithContext(ctx).GetIdentity()
+		}()
+		// the first read waits for the answer, the others for the read in flight
+		<-ctx.waiting
+	}
+	close(release)
+	wg.Wait()
+
+	for n, identity := range identities {
+		if errs[n] != nil || identity != "Mem" {
+			t.Errorf("Expected the shared identity Mem, got %s %v", identity, errs[n])
+		}
+	}
+	if calls := len(obj.Calls()); calls != 1 {
+		t.Errorf("Expected the concurrent reads to make one call, got %d", calls)
+	}
+}
+
+func TestSharedPropertyReadCanceled(t *testing.T) {
+	group := newFlightGroup()
+	key := flightKey{method: getPropertyMethod, iface: BaseInterface, prop: "Identity"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _, _ = group.do(context.Background(), key, func() (interface{}, error) {
+			close(started)
+			<-release
+			return nil, context.Canceled
+		})
+	}()
+	<-started
+
+	done := make(chan struct{})
+	waiting := newWaitingContext(context.Background())
+	go func() {
+		defer close(done)
+		value, shared, err := group.do(waiting, key, func() (interface{}, error) {
+			return "Mem", nil
+		})
+		if err != nil || shared || value != "Mem" {
+			t.Errorf("Expected the read to be made again after the cancellation, got %v %t %v", value, shared, err)
+		}
+	}()
+	<-waiting.waiting
+	close(release)
+	<-done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := make(chan struct{})
+	defer close(blocked)
+	go func() {
+		_, _, _ = group.do(context.Background(), key, func() (interface{}, error) {
+			<-blocked
+			return nil, nil
+		})
+	}()
+	mpristest.Eventually(t, func() bool {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		return group.flights[key] != nil
+	}, "The read wasn't started")
+	if _, _, err := group.do(ctx, key, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the waiting caller to stop with its context, got %v", err)
+	}
+}
+
+func TestSharedPropertyReadTimedOut(t *testing.T) {
+	group := newFlightGroup()
+	key := flightKey{method: getPropertyMethod, iface: BaseInterface, prop: "Identity"}
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	timedOut := make(chan struct{})
+	go func() {
+		defer close(timedOut)
+		_, _, err := group.do(short, key, func() (interface{}, error) {
+			close(started)
+			<-release
+			return nil, short.Err()
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the read with the short deadline to time out, got %v", err)
+		}
+	}()
+	<-started
+
+	waiting := newWaitingContext(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, shared, err := group.do(waiting, key, func() (interface{}, error) {
+			return "Mem", nil
+		})
+		if err != nil || shared || value != "Mem" {
+			t.Errorf("Expected the read to be made again after the timeout, got %v %t %v", value, shared, err)
+		}
+	}()
+	<-waiting.waiting
+	<-short.Done()
+	close(release)
+	<-done
+	<-timedOut
+}
+
+// waitingContext tells when its Done method is first called, which the callers of a read in
+// flight do once they wait for its result.
+type waitingContext struct {
+	context.Context
+	once    sync.Once
+	waiting chan struct{}
+}
+
+func newWaitingContext(ctx context.Context) *waitingContext {
+	return &waitingContext{Context: ctx, waiting: make(chan struct{})}
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.once.Do(func() { close(c.waiting) })
+	return c.Context.Done()
+}
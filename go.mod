@@ -1,6 +1,6 @@
 module github.com/Pauloo27/go-mpris
 
-go 1.14
+go 1.23
 
 require (
 	github.com/godbus/dbus/v5 v5.0.3
@@ -0,0 +1,166 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// HealthEvent is a change of the responsiveness of a player, sent by a Watchdog. It's either a
+// PlayerUnresponsiveEvent or a PlayerRecoveredEvent.
+type HealthEvent interface {
+	isHealthEvent()
+}
+
+// PlayerUnresponsiveEvent is sent when a player fails to answer in time, with the error of
+// the call, usually context.DeadlineExceeded.
+type PlayerUnresponsiveEvent struct {
+	Name string
+	Err  error
+}
+
+// PlayerRecoveredEvent is sent when an unresponsive player answers again.
+type PlayerRecoveredEvent struct {
+	Name string
+}
+
+func (PlayerUnresponsiveEvent) isHealthEvent() {}
+func (PlayerRecoveredEvent) isHealthEvent()    {}
+
+// Watchdog checks periodically that the players tracked by a Manager answer, so an interface
+// can grey out a hung player instead of blocking on its calls. The players are probed by
+// reading their playback status, as the D-Bus libraries answer the pings on behalf of the
+// players even when they're hung. It's safe for concurrent use.
+type Watchdog struct {
+	manager  *Manager
+	interval time.Duration
+	timeout  time.Duration
+	events   chan HealthEvent
+	done     chan struct{}
+	stopped  chan struct{}
+
+	mu           sync.RWMutex
+	unresponsive map[string]error
+
+	closeOnce sync.Once
+}
+
+// NewWatchdog starts probing the players of the manager every interval, a player being
+// unresponsive when it doesn't answer within timeout. The changes are sent to the Events
+// channel, which has to be drained, until Close is called.
+func NewWatchdog(manager *Manager, interval, timeout time.Duration) *Watchdog {
+	w := &Watchdog{
+		manager:      manager,
+		interval:     interval,
+		timeout:      timeout,
+		events:       make(chan HealthEvent),
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+		unresponsive: make(map[string]error),
+	}
+	go w.run()
+	return w
+}
+
+func (w *Watchdog) run() {
+	defer close(w.stopped)
+	defer close(w.events)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if !w.check() {
+			return
+		}
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check probes every player at once and sends the changes. It returns false if the watchdog
+// was closed in the meantime.
+func (w *Watchdog) check() bool {
+	players := w.manager.Players()
+	errs := make([]error, len(players))
+	var wg sync.WaitGroup
+	for n, player := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[n] = w.probe(player)
+		}()
+	}
+	wg.Wait()
+
+	var events []HealthEvent
+	tracked := make(map[string]bool, len(players))
+	w.mu.Lock()
+	for n, player := range players {
+		name, err := player.GetName(), errs[n]
+		tracked[name] = true
+		_, wasUnresponsive := w.unresponsive[name]
+		switch {
+		case err != nil && !wasUnresponsive:
+			w.unresponsive[name] = err
+			events = append(events, PlayerUnresponsiveEvent{Name: name, Err: err})
+		case err == nil && wasUnresponsive:
+			delete(w.unresponsive, name)
+			events = append(events, PlayerRecoveredEvent{Name: name})
+		}
+	}
+	// the players that left are forgotten
+	for name := range w.unresponsive {
+		if !tracked[name] {
+			delete(w.unresponsive, name)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, event := range events {
+		select {
+		case w.events <- event:
+		case <-w.done:
+			return false
+		}
+	}
+	return true
+}
+
+// probe returns the error of the player if it doesn't answer in time. The players that left
+// the bus in the meantime aren't unresponsive.
+func (w *Watchdog) probe(player *Player) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+	// the property is read from the player itself, not from the property cache
+	err := player.WithContext(ctx).query(getPropertyMethod, PlayerInterface, "PlaybackStatus").Err
+	if errors.Is(err, ErrPlayerNotFound) || errors.Is(err, ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// Events returns the channel receiving the changes. It's closed when the watchdog is closed.
+func (w *Watchdog) Events() <-chan HealthEvent {
+	return w.events
+}
+
+// Responsive reports whether the player with the name answered the last probe. The players
+// not probed yet are responsive.
+func (w *Watchdog) Responsive(name string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, unresponsive := w.unresponsive[name]
+	return !unresponsive
+}
+
+// Close stops the watchdog. The manager is not closed.
+func (w *Watchdog) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+	})
+	return nil
+}
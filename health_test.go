@@ -0,0 +1,94 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+// hangingPlayer answers the property reads, unless it's hung.
+type hangingPlayer struct {
+	hung atomic.Bool
+}
+
+func (p *hangingPlayer) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	if p.hung.Load() {
+		time.Sleep(500 * time.Millisecond)
+	}
+	return dbus.MakeVariant(string(PlaybackPlaying)), nil
+}
+
+// receiveHealthEvent returns the next event of the player with the name.
+func receiveHealthEvent(t *testing.T, watchdog *Watchdog, name string) HealthEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-watchdog.Events():
+			switch e := event.(type) {
+			case PlayerUnresponsiveEvent:
+				if e.Name == name {
+					return event
+				}
+			case PlayerRecoveredEvent:
+				if e.Name == name {
+					return event
+				}
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for a health event")
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	player := &hangingPlayer{}
+	if err := conn.Export(player, dbusObjectPath, propertiesInterface); err != nil {
+		t.Fatal(err)
+	}
+	name := fmt.Sprintf("%s.hangingtest%d", BaseInterface, os.Getpid())
+	if _, err := conn.RequestName(name, dbus.NameFlagDoNotQueue); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewManager(mpristest.PrivateConn(t), WithManagerPlayerOptions(WithCallTimeout(time.Second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	mpristest.Eventually(t, func() bool { return manager.Player(name) != nil }, "Expected the manager to track the player")
+
+	watchdog := NewWatchdog(manager, 50*time.Millisecond, 100*time.Millisecond)
+	defer watchdog.Close()
+
+	player.hung.Store(true)
+	event, ok := receiveHealthEvent(t, watchdog, name).(PlayerUnresponsiveEvent)
+	if !ok || !errors.Is(event.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the player to be unresponsive, got %#v", event)
+	}
+	if watchdog.Responsive(name) {
+		t.Error("Expected the player to be reported unresponsive")
+	}
+
+	player.hung.Store(false)
+	if event := receiveHealthEvent(t, watchdog, name); event != (PlayerRecoveredEvent{Name: name}) {
+		t.Errorf("Expected the player to recover, got %#v", event)
+	}
+	if !watchdog.Responsive(name) {
+		t.Error("Expected the player to be reported responsive")
+	}
+
+	if err := watchdog.Close(); err != nil {
+		t.Error(err)
+	}
+	for range watchdog.Events() {
+	}
+}
@@ -0,0 +1,225 @@
+// Package history records the tracks listened on the MPRIS players, like a local scrobbler.
+//
+// A Recorder follows every player of the bus and appends an Entry to a Sink each time a track
+// ends, either because another one started, the player stopped or the player quit. The entries
+// tell how long the track was listened, not counting pauses and seeks, and whether it was
+// played to completion or skipped.
+//
+// The entries can be kept in a JSON Lines file with JSONLSink, or in a SQLite database with
+// SQLSink, which works with any database/sql driver using ? placeholders.
+package history
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// Entry is a track listened on a player.
+type Entry struct {
+	// Player is the name of the player without the org.mpris.MediaPlayer2 prefix.
+	Player  string
+	TrackID mpris.TrackID
+	URL     string
+	Title   string
+	Album   string
+	Artists []string
+	Length  time.Duration
+
+	StartedAt time.Time
+	EndedAt   time.Time
+	// Listened is how long the track played, without the pauses and the parts skipped by
+	// seeking.
+	Listened time.Duration
+	// Completed is whether the track was played until its end, which is never the case for
+	// tracks of unknown length.
+	Completed bool
+}
+
+func newEntry(player string, metadata mpris.Metadata) Entry {
+	return Entry{
+		Player:  player,
+		TrackID: metadata.TrackID(),
+		URL:     metadata.URL(),
+		Title:   metadata.Title(),
+		Album:   metadata.Album(),
+		Artists: metadata.Artists(),
+		Length:  metadata.Length(),
+	}
+}
+
+type jsonEntry struct {
+	Player     string        `json:"player"`
+	TrackID    mpris.TrackID `json:"trackId,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	Title      string        `json:"title,omitempty"`
+	Album      string        `json:"album,omitempty"`
+	Artists    []string      `json:"artists,omitempty"`
+	LengthMs   int64         `json:"lengthMs,omitempty"`
+	StartedAt  time.Time     `json:"startedAt"`
+	EndedAt    time.Time     `json:"endedAt"`
+	ListenedMs int64         `json:"listenedMs"`
+	Completed  bool          `json:"completed"`
+}
+
+// MarshalJSON encodes the entry with the durations in milliseconds, as "lengthMs" and
+// "listenedMs".
+func (e Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEntry{
+		Player:     e.Player,
+		TrackID:    e.TrackID,
+		URL:        e.URL,
+		Title:      e.Title,
+		Album:      e.Album,
+		Artists:    e.Artists,
+		LengthMs:   e.Length.Milliseconds(),
+		StartedAt:  e.StartedAt,
+		EndedAt:    e.EndedAt,
+		ListenedMs: e.Listened.Milliseconds(),
+		Completed:  e.Completed,
+	})
+}
+
+// UnmarshalJSON decodes an entry encoded by MarshalJSON.
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var decoded jsonEntry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = Entry{
+		Player:    decoded.Player,
+		TrackID:   decoded.TrackID,
+		URL:       decoded.URL,
+		Title:     decoded.Title,
+		Album:     decoded.Album,
+		Artists:   decoded.Artists,
+		Length:    time.Duration(decoded.LengthMs) * time.Millisecond,
+		StartedAt: decoded.StartedAt,
+		EndedAt:   decoded.EndedAt,
+		Listened:  time.Duration(decoded.ListenedMs) * time.Millisecond,
+		Completed: decoded.Completed,
+	}
+	return nil
+}
+
+// Sink stores the entries of a Recorder. Record is never called concurrently.
+type Sink interface {
+	Record(entry Entry) error
+}
+
+// JSONLSink appends the entries to a file, one JSON object per line.
+type JSONLSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenJSONL opens the file at path to append entries to it, creating it if needed.
+func OpenJSONL(path string) (*JSONLSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLSink{file: file}, nil
+}
+
+// Record appends the entry to the file.
+func (s *JSONLSink) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// ReadJSONL reads the entries written by a JSONLSink, in the order they were recorded.
+func ReadJSONL(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// SQLSink inserts the entries in a table of a SQL database, like SQLite. The artists are
+// stored joined by "; ", the times as RFC 3339 strings and the durations in milliseconds.
+type SQLSink struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLSink creates the table if it doesn't exist, and returns a sink inserting the entries
+// in it. The driver must use ? placeholders, like the SQLite and MySQL ones. The table name is
+// made of letters, digits and underscores, not starting with a digit, as it's written in the
+// statements as it is.
+func NewSQLSink(db *sql.DB, table string) (*SQLSink, error) {
+	if !isIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+		player TEXT NOT NULL,
+		track_id TEXT,
+		url TEXT,
+		title TEXT,
+		album TEXT,
+		artists TEXT,
+		length_ms INTEGER,
+		started_at TEXT NOT NULL,
+		ended_at TEXT NOT NULL,
+		listened_ms INTEGER NOT NULL,
+		completed BOOLEAN NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLSink{db: db, table: table}, nil
+}
+
+// isIdentifier reports whether the name is a SQL identifier that needs no quoting.
+func isIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Record inserts the entry in the table.
+func (s *SQLSink) Record(entry Entry) error {
+	_, err := s.db.Exec(`INSERT INTO `+s.table+` (player, track_id, url, title, album, artists,
+		length_ms, started_at, ended_at, listened_ms, completed) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Player, string(entry.TrackID), entry.URL, entry.Title, entry.Album, strings.Join(entry.Artists, "; "),
+		entry.Length.Milliseconds(), entry.StartedAt.Format(time.RFC3339Nano),
+		entry.EndedAt.Format(time.RFC3339Nano), entry.Listened.Milliseconds(), entry.Completed)
+	return err
+}
@@ -0,0 +1,232 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+// memorySink keeps the entries in memory.
+type memorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *memorySink) Record(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memorySink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+func TestRecorder(t *testing.T) {
+	sink := &memorySink{}
+	recorder, err := NewRecorder(mpristest.PrivateConn(t), sink, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+
+	shortName := fmt.Sprintf("mpristest.history%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	setMetadata := func(metadata map[string]dbus.Variant) {
+		t.Helper()
+		if err := fake.SetMetadata(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setMetadata(testMetadata("1", 3*time.Minute))
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	setMetadata(testMetadata("2", 6*time.Second))
+	mpristest.Eventually(t, func() bool { return len(sink.Entries()) == 1 }, "The skipped track wasn't recorded")
+	skipped := sink.Entries()[0]
+	if skipped.Title != "Track 1" || skipped.Completed || skipped.Player != shortName {
+		t.Errorf("Expected the first track to be skipped, got %+v", skipped)
+	}
+	if skipped.Listened < 50*time.Millisecond || skipped.Listened > time.Second {
+		t.Errorf("Expected the first track to be listened about 100ms, got %v", skipped.Listened)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := fake.EmitSeeked(5500000); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := fake.SetPlaybackStatus("Stopped"); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool { return len(sink.Entries()) == 2 }, "The completed track wasn't recorded")
+	if completed := sink.Entries()[1]; completed.Title != "Track 2" || !completed.Completed {
+		t.Errorf("Expected the second track to be completed, got %+v", completed)
+	}
+
+	// a stopped player doesn't record anything
+	fake.Close()
+	time.Sleep(50 * time.Millisecond)
+	if entries := sink.Entries(); len(entries) != 2 {
+		t.Errorf("Expected no new entry, got %+v", entries)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	sink, err := OpenJSONL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Player: "vlc", Title: "a", Artists: []string{"x", "y"}, Length: time.Minute, StartedAt: start, EndedAt: start.Add(time.Minute), Listened: time.Minute, Completed: true},
+		{Player: "spotify", TrackID: "/org/track/2", StartedAt: start, EndedAt: start.Add(time.Second), Listened: time.Second},
+	}
+	for _, entry := range entries {
+		if err := sink.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", lines)
+	}
+	read, err := ReadJSONL(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, entries) {
+		t.Errorf("Expected %+v, got %+v", entries, read)
+	}
+
+	if _, err := ReadJSONL(strings.NewReader("{}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
+
+// recordingDriver is a database/sql driver recording the executed statements.
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []string
+	args  [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return recordingConn{d}, nil }
+func (d *recordingDriver) Driver() driver.Driver                 { return d }
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{d}, nil
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c.d, query}, nil
+}
+func (c recordingConn) Close() error              { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return nil, errors.New("no transactions") }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, s.query)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no queries")
+}
+
+func TestSQLSink(t *testing.T) {
+	recording := &recordingDriver{}
+	db := sql.OpenDB(recording)
+	defer db.Close()
+
+	sink, err := NewSQLSink(db, "plays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	err = sink.Record(Entry{Player: "vlc", Title: "a", Artists: []string{"x", "y"}, StartedAt: start, EndedAt: start, Listened: 2 * time.Second, Completed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recording.execs) != 2 || !strings.HasPrefix(recording.execs[0], "CREATE TABLE IF NOT EXISTS plays") ||
+		!strings.HasPrefix(recording.execs[1], "INSERT INTO plays") {
+		t.Fatalf("Unexpected statements %q", recording.execs)
+	}
+	args := recording.args[1]
+	if args[0] != "vlc" || args[5] != "x; y" || args[7] != "2024-05-01T20:00:00Z" || args[9] != int64(2000) || args[10] != true {
+		t.Errorf("Unexpected arguments %v", args)
+	}
+
+	for _, table := range []string{"", "1plays", "plays; DROP TABLE plays", "my plays"} {
+		if _, err := NewSQLSink(db, table); err == nil {
+			t.Errorf("Expected the table name %q to be rejected", table)
+		}
+	}
+	if len(recording.execs) != 2 {
+		t.Errorf("Expected no statement for the invalid table names, got %q", recording.execs[2:])
+	}
+}
+
+func TestRecorderSubscribeError(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	recorder, err := NewRecorder(conn, &memorySink{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+
+	// the subscription fails on the closed connection
+	conn.Close()
+	recorder.addPlayer(mpris.BaseInterface + ".mpristest.unsubscribed")
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.players) != 0 {
+		t.Errorf("Expected the player to be dropped when it can't be followed, got %d players", len(recorder.players))
+	}
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// Options configures a Recorder.
+type Options struct {
+	// MinListened is how long a track must be listened to be recorded. The tracks that never
+	// played are never recorded.
+	MinListened time.Duration
+	// OnError is called with the errors of the sink, which are ignored otherwise.
+	OnError func(err error)
+}
+
+// Recorder follows the players of the bus and records the tracks they play.
+type Recorder struct {
+	conn    *dbus.Conn
+	sink    Sink
+	opts    Options
+	watcher *mpris.Watcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu      sync.Mutex
+	players map[string]*recordedPlayer
+
+	// sinkMu makes sure the sink is never called concurrently.
+	sinkMu sync.Mutex
+}
+
+// recordedPlayer is a player followed by a Recorder.
+type recordedPlayer struct {
+	player  *mpris.Player
+	cancel  context.CancelFunc
+	tracker *tracker
+}
+
+// NewRecorder starts recording the tracks played on the bus to the sink.
+func NewRecorder(conn *dbus.Conn, sink Sink, opts Options) (*Recorder, error) {
+	watcher, err := mpris.NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Recorder{
+		conn:    conn,
+		sink:    sink,
+		opts:    opts,
+		watcher: watcher,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		players: make(map[string]*recordedPlayer),
+	}
+	for _, name := range watcher.Players() {
+		r.addPlayer(name)
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for event := range r.watcher.Events() {
+		if event.Vanished() {
+			r.removePlayer(event.Name)
+		} else if event.Appeared() {
+			r.addPlayer(event.Name)
+		}
+	}
+}
+
+func (r *Recorder) addPlayer(name string) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	player := mpris.New(r.conn, name)
+	tracker := newTracker(strings.TrimPrefix(name, mpris.BaseInterface+"."))
+	recorded := &recordedPlayer{player: player, cancel: cancel, tracker: tracker}
+
+	r.mu.Lock()
+	if _, ok := r.players[name]; ok {
+		r.mu.Unlock()
+		cancel()
+		return
+	}
+	r.players[name] = recorded
+	r.mu.Unlock()
+
+	// the subscription goes first, so a change made while fetching the state isn't missed
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		r.mu.Lock()
+		if r.players[name] == recorded {
+			delete(r.players, name)
+		}
+		r.mu.Unlock()
+		cancel()
+		player.Close()
+		return
+	}
+	if state, err := player.WithContext(ctx).GetState(); err == nil {
+		r.mu.Lock()
+		tracker.start(state, time.Now())
+		r.mu.Unlock()
+	}
+	go func() {
+		for event := range events {
+			r.handleEvent(recorded, event)
+		}
+	}()
+}
+
+// handleEvent updates the listening of the player with the event, recording the tracks that
+// ended.
+func (r *Recorder) handleEvent(recorded *recordedPlayer, event mpris.Event) {
+	r.mu.Lock()
+	ended := recorded.tracker.handle(event, time.Now())
+	r.mu.Unlock()
+	r.record(ended)
+}
+
+// record records the entries listened long enough.
+func (r *Recorder) record(entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	r.sinkMu.Lock()
+	defer r.sinkMu.Unlock()
+	for _, entry := range entries {
+		if entry.Listened < r.opts.MinListened {
+			continue
+		}
+		if err := r.sink.Record(entry); err != nil && r.opts.OnError != nil {
+			r.opts.OnError(err)
+		}
+	}
+}
+
+func (r *Recorder) removePlayer(name string) {
+	r.mu.Lock()
+	recorded, ok := r.players[name]
+	delete(r.players, name)
+	var ended []Entry
+	if ok {
+		ended = recorded.tracker.end(time.Now())
+	}
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	recorded.cancel()
+	recorded.player.Close()
+	r.record(ended)
+}
+
+// Close stops recording, recording the tracks being listened as if they ended now. Neither
+// the connection nor the sink are closed.
+func (r *Recorder) Close() error {
+	err := r.watcher.Close()
+	<-r.done
+	r.cancel()
+
+	now := time.Now()
+	r.mu.Lock()
+	players := r.players
+	r.players = make(map[string]*recordedPlayer)
+	var ended []Entry
+	for _, recorded := range players {
+		ended = append(ended, recorded.tracker.end(now)...)
+	}
+	r.mu.Unlock()
+
+	for _, recorded := range players {
+		recorded.player.Close()
+	}
+	r.record(ended)
+	return err
+}
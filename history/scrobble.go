@@ -0,0 +1,119 @@
+package history
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+const (
+	// scrobbleMinLength is the length a track must exceed to be scrobbled.
+	scrobbleMinLength = 30 * time.Second
+	// scrobbleMaxPoint is how long the tracks are listened at most before being scrobbled.
+	scrobbleMaxPoint = 4 * time.Minute
+)
+
+// ScrobblePoint returns how long a track of the length must be listened before it's
+// scrobbled, following the Last.fm rules: half of its length or 4 minutes, whichever comes
+// first. It returns false for the tracks of 30 seconds or less, which are never scrobbled. The
+// tracks of unknown length, 0, are scrobbled after 4 minutes.
+func ScrobblePoint(length time.Duration) (time.Duration, bool) {
+	if length <= 0 {
+		return scrobbleMaxPoint, true
+	}
+	if length <= scrobbleMinLength {
+		return 0, false
+	}
+	if half := length / 2; half < scrobbleMaxPoint {
+		return half, true
+	}
+	return scrobbleMaxPoint, true
+}
+
+// WatchScrobbles follows the player and sends a single entry per listened track, when it's
+// listened long enough to be scrobbled, as ScrobblePoint says. The pauses and the parts
+// skipped by seeking don't count, and a track played again after being stopped or replaced is
+// sent again. The entries have the listening so far, EndedAt being when the scrobble point was
+// reached, while StartedAt is the time expected by the scrobbling APIs.
+//
+// The channel is closed when ctx is done or the connection is closed.
+func WatchScrobbles(ctx context.Context, player *mpris.Player) (<-chan Entry, error) {
+	return watchScrobbles(ctx, player, ScrobblePoint)
+}
+
+// watchScrobbles implements WatchScrobbles with the scrobble point of the tracks given by
+// scrobblePoint.
+func watchScrobbles(ctx context.Context, player *mpris.Player, scrobblePoint func(time.Duration) (time.Duration, bool)) (<-chan Entry, error) {
+	// the subscription ends with the goroutine, or right away if the state can't be read
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	state, err := player.WithContext(ctx).GetState()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	name := strings.TrimPrefix(player.GetName(), mpris.BaseInterface+".")
+	tracker := newTracker(name)
+	tracker.start(state, time.Now())
+
+	scrobbles := make(chan Entry)
+	go func() {
+		defer close(scrobbles)
+		defer cancel()
+		// scrobbled is the session already sent, and timer fires when the current one may
+		// reach its scrobble point
+		var scrobbled *session
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			now := time.Now()
+			if s := tracker.session; s != nil && s != scrobbled {
+				point, ok := scrobblePoint(s.metadata.Length())
+				s.advance(now)
+				switch {
+				case !ok:
+					scrobbled = s
+				case s.listened >= point:
+					scrobbled = s
+					select {
+					case scrobbles <- s.entry(name, now):
+					case <-ctx.Done():
+						return
+					}
+				case s.playing:
+					timer = time.NewTimer(point - s.listened)
+					timeout = timer.C
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeout:
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				tracker.handle(event, time.Now())
+			}
+		}
+	}()
+	return scrobbles, nil
+}
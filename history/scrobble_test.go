@@ -0,0 +1,104 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestScrobblePoint(t *testing.T) {
+	cases := []struct {
+		length time.Duration
+		point  time.Duration
+		ok     bool
+	}{
+		{0, 4 * time.Minute, true},
+		{20 * time.Second, 0, false},
+		{30 * time.Second, 0, false},
+		{3 * time.Minute, 90 * time.Second, true},
+		{10 * time.Minute, 4 * time.Minute, true},
+	}
+	for _, c := range cases {
+		point, ok := ScrobblePoint(c.length)
+		if point != c.point || ok != c.ok {
+			t.Errorf("Expected the scrobble point of %v to be %v %t, got %v %t", c.length, c.point, c.ok, point, ok)
+		}
+	}
+}
+
+func TestWatchScrobbles(t *testing.T) {
+	fake, err := mpristest.New(mpristest.PrivateConn(t), fmt.Sprintf("mpristest.scrobble%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	if err := fake.SetMetadata(testMetadata("1", 3*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// the tracks reach their scrobble point a thousand times faster
+	fastPoint := func(length time.Duration) (time.Duration, bool) {
+		point, ok := ScrobblePoint(length)
+		return point / 1000, ok
+	}
+	scrobbles, err := watchScrobbles(ctx, mpris.New(mpristest.PrivateConn(t), fake.Name()), fastPoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 90ms of play are needed, the pause doesn't count
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if err := fake.SetPlaybackStatus("Paused"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case entry := <-scrobbles:
+		t.Fatalf("Expected no scrobble while paused, got %+v", entry)
+	default:
+	}
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case entry := <-scrobbles:
+		if entry.Title != "Track 1" || entry.Listened < 90*time.Millisecond || entry.Listened > time.Second {
+			t.Errorf("Unexpected scrobble %+v", entry)
+		}
+	case <-ctx.Done():
+		t.Fatal("The track wasn't scrobbled")
+	}
+
+	// a single scrobble per track, until the next one
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case entry := <-scrobbles:
+		t.Fatalf("Expected a single scrobble, got %+v", entry)
+	default:
+	}
+	if err := fake.SetMetadata(testMetadata("2", 20*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetMetadata(testMetadata("3", 2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case entry := <-scrobbles:
+		if entry.Title != "Track 3" {
+			t.Errorf("Expected the short track to not be scrobbled, got %+v", entry)
+		}
+	case <-ctx.Done():
+		t.Fatal("The next track wasn't scrobbled")
+	}
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// completionMargin is how close to its end a track must be played to be completed, as the
+// position is extrapolated and players often switch tracks a bit early.
+const completionMargin = 5 * time.Second
+
+// session follows the listening of a track. The listened time and the position are updated up
+// to since, and go on from there while the track is playing.
+type session struct {
+	metadata  mpris.Metadata
+	startedAt time.Time
+	playing   bool
+	rate      float64
+	listened  time.Duration
+	position  time.Duration
+	since     time.Time
+}
+
+func newSession(metadata mpris.Metadata, position time.Duration, playing bool, rate float64, now time.Time) *session {
+	return &session{
+		metadata:  metadata,
+		startedAt: now,
+		playing:   playing,
+		rate:      rate,
+		position:  position,
+		since:     now,
+	}
+}
+
+// advance accounts for the time played since the last update.
+func (s *session) advance(now time.Time) {
+	if s.playing && now.After(s.since) {
+		elapsed := now.Sub(s.since)
+		s.listened += elapsed
+		rate := s.rate
+		if rate <= 0 {
+			rate = 1
+		}
+		s.position += time.Duration(float64(elapsed) * rate)
+		if length := s.metadata.Length(); length > 0 && s.position > length {
+			s.position = length
+		}
+	}
+	s.since = now
+}
+
+// setPlaying starts or stops the accounting.
+func (s *session) setPlaying(playing bool, now time.Time) {
+	s.advance(now)
+	s.playing = playing
+}
+
+// setRate changes the speed of the position.
+func (s *session) setRate(rate float64, now time.Time) {
+	s.advance(now)
+	s.rate = rate
+}
+
+// seek moves the position, which doesn't count as listened.
+func (s *session) seek(position time.Duration, now time.Time) {
+	s.advance(now)
+	s.position = position
+}
+
+// completed reports whether the track was played until its end.
+func (s *session) completed() bool {
+	length := s.metadata.Length()
+	return length > 0 && s.position >= length-completionMargin
+}
+
+// entry ends the session at now and returns its entry.
+func (s *session) entry(player string, now time.Time) Entry {
+	s.advance(now)
+	entry := newEntry(player, s.metadata)
+	entry.StartedAt = s.startedAt
+	entry.EndedAt = now
+	entry.Listened = s.listened
+	entry.Completed = s.completed()
+	return entry
+}
+
+// sameTrack reports whether the metadata are of the same track, by their track ids or their
+// URLs for the players that don't set the ids.
+func sameTrack(a, b mpris.Metadata) bool {
+	if a.TrackID() != "" && a.TrackID() != mpris.NoTrack {
+		return a.TrackID() == b.TrackID()
+	}
+	return a.URL() == b.URL() && a.Title() == b.Title()
+}
+
+// hasTrack reports whether the metadata describe a track.
+func hasTrack(metadata mpris.Metadata) bool {
+	trackID := metadata.TrackID()
+	return len(metadata) > 0 && trackID != mpris.NoTrack && (trackID != "" || metadata.URL() != "" || metadata.Title() != "")
+}
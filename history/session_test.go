@@ -0,0 +1,64 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+func testMetadata(trackID string, length time.Duration) mpris.Metadata {
+	return mpris.Metadata{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/" + trackID)),
+		"mpris:length":  dbus.MakeVariant(length.Microseconds()),
+		"xesam:title":   dbus.MakeVariant("Track " + trackID),
+		"xesam:artist":  dbus.MakeVariant([]string{"Artist"}),
+	}
+}
+
+func TestSession(t *testing.T) {
+	start := time.Unix(1000, 0)
+	s := newSession(testMetadata("1", 3*time.Minute), 0, true, 1, start)
+
+	s.setPlaying(false, start.Add(time.Minute))
+	s.setPlaying(true, start.Add(10*time.Minute))
+	s.seek(2*time.Minute+40*time.Second, start.Add(10*time.Minute+30*time.Second))
+	entry := s.entry("player", start.Add(11*time.Minute))
+
+	if entry.Listened != 2*time.Minute {
+		t.Errorf("Expected 2 minutes listened without the pause, got %v", entry.Listened)
+	}
+	if !entry.Completed {
+		t.Errorf("Expected the track to be completed after seeking to its end, got %+v", entry)
+	}
+	if entry.Title != "Track 1" || entry.Player != "player" || !entry.StartedAt.Equal(start) {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+
+	skipped := newSession(testMetadata("2", 3*time.Minute), 0, true, 2, start)
+	if entry := skipped.entry("player", start.Add(time.Minute)); entry.Completed {
+		t.Errorf("Expected the track played for 2 minutes at rate 2 to be skipped, got %+v", entry)
+	}
+	if entry := newSession(testMetadata("3", 0), 0, true, 1, start).entry("player", start.Add(time.Hour)); entry.Completed {
+		t.Error("Expected a track of unknown length to never be completed")
+	}
+}
+
+func TestSameTrack(t *testing.T) {
+	if !sameTrack(testMetadata("1", time.Minute), testMetadata("1", 2*time.Minute)) {
+		t.Error("Expected the same track ids to be the same track")
+	}
+	if sameTrack(testMetadata("1", time.Minute), testMetadata("2", time.Minute)) {
+		t.Error("Expected different track ids to be different tracks")
+	}
+	byURL := func(url string) mpris.Metadata {
+		return mpris.Metadata{"xesam:url": dbus.MakeVariant(url)}
+	}
+	if !sameTrack(byURL("file:///a.mp3"), byURL("file:///a.mp3")) || sameTrack(byURL("file:///a.mp3"), byURL("file:///b.mp3")) {
+		t.Error("Expected the tracks without ids to be compared by URL")
+	}
+	if hasTrack(mpris.Metadata{"mpris:trackid": dbus.MakeVariant(mpris.NoTrack.ObjectPath())}) {
+		t.Error("Expected NoTrack to not be a track")
+	}
+}
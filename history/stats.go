@@ -0,0 +1,153 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupBy selects what the statistics of Aggregate are computed for.
+type GroupBy string
+
+const (
+	ByArtist GroupBy = "artist"
+	ByAlbum  GroupBy = "album"
+	ByTrack  GroupBy = "track"
+)
+
+// Window is the time range of the entries to aggregate, the ones started from From included
+// to To excluded. A zero From or To leaves that side unbounded.
+type Window struct {
+	From time.Time
+	To   time.Time
+}
+
+// Since returns the window of the last d until now.
+func Since(d time.Duration, now time.Time) Window {
+	return Window{From: now.Add(-d)}
+}
+
+// Contains reports whether the time is in the window.
+func (w Window) Contains(t time.Time) bool {
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || t.Before(w.To))
+}
+
+// Stat is the listening of an artist, an album or a track.
+type Stat struct {
+	// Artist is the artist, or the artists joined by ", " for the albums and tracks.
+	Artist string
+	// Album is empty for the artists.
+	Album string
+	// Title is empty for the artists and the albums.
+	Title string
+
+	Plays     int
+	Completed int
+	Listened  time.Duration
+
+	FirstPlayed time.Time
+	LastPlayed  time.Time
+}
+
+// Name describes the stat for humans, like "Artist - Title".
+func (s Stat) Name() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{s.Artist, s.Album, s.Title} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// Stats are the statistics of the entries in a window.
+type Stats struct {
+	Window Window
+	// Plays and Listened are the totals of the window, each entry counting once even when it
+	// is in several stats, like the tracks of several artists.
+	Plays    int
+	Listened time.Duration
+	// Items are sorted by listening time, the most listened first.
+	Items []Stat
+}
+
+// Aggregate computes the play counts and listening times of the entries started in the
+// window, grouped by artist, album or track. A track of several artists counts for each of
+// them, and the entries without an artist or an album are left out of those groups.
+func Aggregate(entries []Entry, by GroupBy, window Window) Stats {
+	stats := Stats{Window: window}
+	items := make(map[string]*Stat)
+	for _, entry := range entries {
+		if !window.Contains(entry.StartedAt) {
+			continue
+		}
+		stats.Plays++
+		stats.Listened += entry.Listened
+		for _, key := range statKeys(entry, by) {
+			item, ok := items[key.Name()]
+			if !ok {
+				item = &Stat{Artist: key.Artist, Album: key.Album, Title: key.Title}
+				items[key.Name()] = item
+			}
+			item.add(entry)
+		}
+	}
+
+	stats.Items = make([]Stat, 0, len(items))
+	for _, item := range items {
+		stats.Items = append(stats.Items, *item)
+	}
+	sort.Slice(stats.Items, func(a, b int) bool {
+		x, y := stats.Items[a], stats.Items[b]
+		if x.Listened != y.Listened {
+			return x.Listened > y.Listened
+		}
+		if x.Plays != y.Plays {
+			return x.Plays > y.Plays
+		}
+		return x.Name() < y.Name()
+	})
+	return stats
+}
+
+// statKeys returns the stats the entry counts for, without their counts.
+func statKeys(entry Entry, by GroupBy) []Stat {
+	artists := strings.Join(entry.Artists, ", ")
+	switch by {
+	case ByArtist:
+		keys := make([]Stat, 0, len(entry.Artists))
+		for _, artist := range entry.Artists {
+			keys = append(keys, Stat{Artist: artist})
+		}
+		return keys
+	case ByAlbum:
+		if entry.Album == "" {
+			return nil
+		}
+		return []Stat{{Artist: artists, Album: entry.Album}}
+	case ByTrack:
+		title := entry.Title
+		if title == "" {
+			title = entry.URL
+		}
+		if title == "" {
+			return nil
+		}
+		return []Stat{{Artist: artists, Album: entry.Album, Title: title}}
+	}
+	return nil
+}
+
+func (s *Stat) add(entry Entry) {
+	s.Plays++
+	if entry.Completed {
+		s.Completed++
+	}
+	s.Listened += entry.Listened
+	if s.FirstPlayed.IsZero() || entry.StartedAt.Before(s.FirstPlayed) {
+		s.FirstPlayed = entry.StartedAt
+	}
+	if entry.StartedAt.After(s.LastPlayed) {
+		s.LastPlayed = entry.StartedAt
+	}
+}
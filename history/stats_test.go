@@ -0,0 +1,48 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	start := time.Unix(1000, 0)
+	entries := []Entry{
+		{Artists: []string{"A"}, Album: "X", Title: "One", StartedAt: start, Listened: 3 * time.Minute, Completed: true},
+		{Artists: []string{"A", "B"}, Album: "X", Title: "Two", StartedAt: start.Add(time.Hour), Listened: time.Minute},
+		{Artists: []string{"B"}, Title: "Three", StartedAt: start.Add(2 * time.Hour), Listened: 5 * time.Minute, Completed: true},
+		{Artists: []string{"A"}, Album: "X", Title: "One", StartedAt: start.Add(3 * time.Hour), Listened: 3 * time.Minute, Completed: true},
+	}
+
+	artists := Aggregate(entries, ByArtist, Window{})
+	if artists.Plays != 4 || artists.Listened != 12*time.Minute {
+		t.Errorf("Expected 4 plays of 12 minutes, got %d of %v", artists.Plays, artists.Listened)
+	}
+	if len(artists.Items) != 2 {
+		t.Fatalf("Expected 2 artists, got %+v", artists.Items)
+	}
+	a := artists.Items[0]
+	if a.Name() != "A" || a.Plays != 3 || a.Completed != 2 || a.Listened != 7*time.Minute {
+		t.Errorf("Unexpected stat of A %+v", a)
+	}
+	if !a.FirstPlayed.Equal(start) || !a.LastPlayed.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("Unexpected play times of A %+v", a)
+	}
+	if b := artists.Items[1]; b.Name() != "B" || b.Plays != 2 || b.Listened != 6*time.Minute {
+		t.Errorf("Unexpected stat of B %+v", b)
+	}
+
+	albums := Aggregate(entries, ByAlbum, Window{})
+	if len(albums.Items) != 2 || albums.Items[0].Name() != "A - X" || albums.Items[0].Plays != 2 {
+		t.Errorf("Unexpected albums %+v", albums.Items)
+	}
+
+	window := Window{From: start.Add(time.Hour), To: start.Add(3 * time.Hour)}
+	tracks := Aggregate(entries, ByTrack, window)
+	if tracks.Plays != 2 || len(tracks.Items) != 2 {
+		t.Fatalf("Expected 2 tracks in the window, got %+v", tracks)
+	}
+	if tracks.Items[0].Name() != "B - Three" || tracks.Items[1].Name() != "A, B - X - Two" {
+		t.Errorf("Unexpected tracks %+v", tracks.Items)
+	}
+}
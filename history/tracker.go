@@ -0,0 +1,97 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// tracker follows the listening of the tracks of a player from its events. The session is nil
+// when no track is being listened.
+type tracker struct {
+	player   string
+	status   mpris.PlaybackStatus
+	rate     float64
+	metadata mpris.Metadata
+	session  *session
+}
+
+// newTracker returns a tracker of the player, named without the org.mpris.MediaPlayer2 prefix.
+func newTracker(player string) *tracker {
+	return &tracker{player: player, rate: 1}
+}
+
+// start initializes the tracker with the state of the player.
+func (t *tracker) start(state mpris.PlayerState, now time.Time) {
+	t.status = state.PlaybackStatus
+	if state.Rate > 0 {
+		t.rate = state.Rate
+	}
+	t.metadata = state.Metadata
+	if state.PlaybackStatus != mpris.PlaybackStopped && hasTrack(state.Metadata) {
+		t.session = newSession(state.Metadata, state.Position, state.PlaybackStatus == mpris.PlaybackPlaying, t.rate, now)
+	}
+}
+
+// handle updates the listening with the event, returning the entries of the tracks that ended.
+func (t *tracker) handle(event mpris.Event, now time.Time) []Entry {
+	var ended []Entry
+	switch event := event.(type) {
+	case mpris.SeekedEvent:
+		if t.session != nil {
+			t.session.seek(event.Position, now)
+		}
+	case mpris.OwnerChangedEvent:
+		ended = append(ended, t.end(now)...)
+	case mpris.PropertiesChangedEvent:
+		if event.Interface != mpris.PlayerInterface {
+			break
+		}
+		if rate, ok := event.Changed["Rate"].Value().(float64); ok && rate > 0 {
+			t.rate = rate
+			if t.session != nil {
+				t.session.setRate(rate, now)
+			}
+		}
+		if value, ok := event.Changed["Metadata"].Value().(map[string]dbus.Variant); ok {
+			metadata := mpris.Metadata(value)
+			t.metadata = metadata
+			if t.session != nil && !sameTrack(t.session.metadata, metadata) {
+				ended = append(ended, t.end(now)...)
+			}
+			if t.session != nil {
+				// the metadata of the same track may be completed later, like its length
+				t.session.metadata = metadata
+			} else if t.status != mpris.PlaybackStopped && hasTrack(metadata) {
+				t.session = newSession(metadata, 0, t.status == mpris.PlaybackPlaying, t.rate, now)
+			}
+		}
+		if status, ok := event.Changed["PlaybackStatus"].Value().(string); ok {
+			t.status = mpris.PlaybackStatus(status)
+			switch {
+			case t.status == mpris.PlaybackStopped:
+				ended = append(ended, t.end(now)...)
+			case t.session != nil:
+				t.session.setPlaying(t.status == mpris.PlaybackPlaying, now)
+			case hasTrack(t.metadata):
+				// the track is played again after being stopped
+				t.session = newSession(t.metadata, 0, t.status == mpris.PlaybackPlaying, t.rate, now)
+			}
+		}
+	}
+	return ended
+}
+
+// end ends the listening of the current track, returning its entry unless it never played.
+func (t *tracker) end(now time.Time) []Entry {
+	if t.session == nil {
+		return nil
+	}
+	entry := t.session.entry(t.player, now)
+	t.session = nil
+	if entry.Listened <= 0 {
+		return nil
+	}
+	return []Entry{entry}
+}
@@ -0,0 +1,188 @@
+package mpris
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// iconExtensions are the extensions of the icon files, by order of preference.
+var iconExtensions = []string{".png", ".svg", ".xpm"}
+
+// fallbackTheme is the theme every icon theme falls back to.
+const fallbackTheme = "hicolor"
+
+// iconDirs returns the base directories of the icons, as defined by the Icon Theme
+// specification.
+func iconDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".icons"))
+	}
+	for _, dir := range dataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "icons"))
+	}
+	return append(dirs, "/usr/share/pixmaps")
+}
+
+// FindIcon returns the path of the icon with the name, like the Icon of a desktop file, as
+// the Icon Theme specification looks it up: in the theme, like "Adwaita", and the themes it
+// inherits, then in the hicolor theme and finally out of any theme, like in
+// /usr/share/pixmaps. The icon with the closest size in pixels is returned. An empty theme
+// looks up the hicolor theme only, and a name that's an absolute path is returned as is if the
+// file exists. ErrNoIcon is returned when there's no such icon.
+func FindIcon(name, theme string, size int) (string, error) {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err != nil {
+			return "", ErrNoIcon
+		}
+		return name, nil
+	}
+	for _, ext := range iconExtensions {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	dirs := iconDirs()
+	visited := make(map[string]bool)
+	if theme != "" {
+		if path := findThemedIcon(dirs, name, theme, size, visited); path != "" {
+			return path, nil
+		}
+	}
+	if path := findThemedIcon(dirs, name, fallbackTheme, size, visited); path != "" {
+		return path, nil
+	}
+	for _, dir := range dirs {
+		if path := findIconFile(dir, name); path != "" {
+			return path, nil
+		}
+	}
+	return "", ErrNoIcon
+}
+
+// findThemedIcon looks up the icon in the theme and then in the themes it inherits, skipping
+// the visited ones.
+func findThemedIcon(dirs []string, name, theme string, size int, visited map[string]bool) string {
+	if visited[theme] {
+		return ""
+	}
+	visited[theme] = true
+
+	index := readIconTheme(dirs, theme)
+	if index == nil {
+		return ""
+	}
+	if path := lookupIcon(dirs, name, theme, index, size); path != "" {
+		return path
+	}
+	for _, parent := range strings.Split(index["Icon Theme"]["Inherits"], ",") {
+		if parent = strings.TrimSpace(parent); parent != "" {
+			if path := findThemedIcon(dirs, name, parent, size, visited); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// readIconTheme returns the index.theme of the theme, from the first base directory that has
+// it, or nil.
+func readIconTheme(dirs []string, theme string) map[string]map[string]string {
+	for _, dir := range dirs {
+		if index, err := readKeyFile(filepath.Join(dir, theme, "index.theme")); err == nil {
+			return index
+		}
+	}
+	return nil
+}
+
+// lookupIcon returns the icon of the theme in a directory matching the size or, if none does,
+// the one in the directory with the closest size.
+func lookupIcon(dirs []string, name, theme string, index map[string]map[string]string, size int) string {
+	closest, distance := "", -1
+	for _, subdir := range strings.Split(index["Icon Theme"]["Directories"], ",") {
+		subdir = strings.TrimSpace(subdir)
+		iconDir, ok := parseIconDir(index[subdir])
+		if subdir == "" || !ok {
+			continue
+		}
+		for _, dir := range dirs {
+			path := findIconFile(filepath.Join(dir, theme, subdir), name)
+			if path == "" {
+				continue
+			}
+			d := iconDir.distance(size)
+			if d == 0 {
+				return path
+			}
+			if distance < 0 || d < distance {
+				closest, distance = path, d
+			}
+		}
+	}
+	return closest
+}
+
+// findIconFile returns the path of the icon in the directory, with any of the icon
+// extensions, or an empty string.
+func findIconFile(dir, name string) string {
+	for _, ext := range iconExtensions {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// iconDir is the group describing a directory in the index.theme of a theme.
+type iconDir struct {
+	kind                   string
+	size, minSize, maxSize int
+	threshold              int
+}
+
+// parseIconDir parses the keys of a directory of a theme, ignoring the directories of scaled
+// icons, meant for HiDPI screens.
+func parseIconDir(keys map[string]string) (iconDir, bool) {
+	number := func(key string, value int) int {
+		if n, err := strconv.Atoi(keys[key]); err == nil {
+			return n
+		}
+		return value
+	}
+	size := number("Size", 0)
+	if size <= 0 || number("Scale", 1) != 1 {
+		return iconDir{}, false
+	}
+	kind := keys["Type"]
+	if kind == "" {
+		kind = "Threshold"
+	}
+	return iconDir{
+		kind:      kind,
+		size:      size,
+		minSize:   number("MinSize", size),
+		maxSize:   number("MaxSize", size),
+		threshold: number("Threshold", 2),
+	}, true
+}
+
+// distance returns how far the size is from the sizes of the directory, 0 when it matches.
+func (d iconDir) distance(size int) int {
+	minSize, maxSize := d.size, d.size
+	switch d.kind {
+	case "Scalable":
+		minSize, maxSize = d.minSize, d.maxSize
+	case "Threshold":
+		minSize, maxSize = d.size-d.threshold, d.size+d.threshold
+	}
+	switch {
+	case size < minSize:
+		return minSize - size
+	case size > maxSize:
+		return size - maxSize
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package mpris
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes the file, creating its directories.
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupIconThemes creates the icon themes of the tests in temporary XDG directories: hicolor,
+// Test inheriting Parent, and Parent inheriting Test back.
+func setupIconThemes(t *testing.T) (home, data, system string) {
+	home, data, system = t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	hicolor := filepath.Join(system, "icons", "hicolor")
+	writeFile(t, filepath.Join(hicolor, "index.theme"), `[Icon Theme]
+Name=Hicolor
+Directories=16x16/apps,48x48/apps,scalable/apps,48x48@2/apps
+
+[16x16/apps]
+Size=16
+Type=Threshold
+
+[48x48/apps]
+Size=48
+Type=Fixed
+
+[48x48@2/apps]
+Size=48
+Scale=2
+Type=Fixed
+
+[scalable/apps]
+Size=128
+MinSize=8
+MaxSize=512
+Type=Scalable
+`)
+	writeFile(t, filepath.Join(hicolor, "16x16", "apps", "player.png"), "")
+	writeFile(t, filepath.Join(hicolor, "48x48", "apps", "player.png"), "")
+	writeFile(t, filepath.Join(hicolor, "48x48@2", "apps", "player.png"), "")
+	writeFile(t, filepath.Join(hicolor, "scalable", "apps", "player.svg"), "")
+
+	writeFile(t, filepath.Join(home, ".icons", "Test", "index.theme"), `[Icon Theme]
+Name=Test
+Inherits=Parent
+Directories=32x32/apps
+
+[32x32/apps]
+Size=32
+Type=Fixed
+`)
+	writeFile(t, filepath.Join(home, ".icons", "Test", "32x32", "apps", "themed.png"), "")
+
+	writeFile(t, filepath.Join(data, "icons", "Parent", "index.theme"), `[Icon Theme]
+Name=Parent
+Inherits=Test
+Directories=apps
+
+[apps]
+Size=48
+Type=Fixed
+`)
+	writeFile(t, filepath.Join(data, "icons", "Parent", "apps", "parented.svg"), "")
+
+	writeFile(t, filepath.Join(system, "icons", "loose.xpm"), "")
+	return home, data, system
+}
+
+func TestFindIcon(t *testing.T) {
+	home, data, system := setupIconThemes(t)
+	hicolor := filepath.Join(system, "icons", "hicolor")
+
+	tests := []struct {
+		name, theme string
+		size        int
+		expected    string
+	}{
+		{"player", "", 48, filepath.Join(hicolor, "48x48", "apps", "player.png")},
+		{"player.png", "", 48, filepath.Join(hicolor, "48x48", "apps", "player.png")},
+		{"player", "", 17, filepath.Join(hicolor, "16x16", "apps", "player.png")},
+		{"player", "", 64, filepath.Join(hicolor, "scalable", "apps", "player.svg")},
+		{"player", "Test", 48, filepath.Join(hicolor, "48x48", "apps", "player.png")},
+		{"themed", "Test", 48, filepath.Join(home, ".icons", "Test", "32x32", "apps", "themed.png")},
+		{"parented", "Test", 24, filepath.Join(data, "icons", "Parent", "apps", "parented.svg")},
+		{"loose", "Test", 48, filepath.Join(system, "icons", "loose.xpm")},
+		{filepath.Join(system, "icons", "loose.xpm"), "", 48, filepath.Join(system, "icons", "loose.xpm")},
+	}
+	for _, test := range tests {
+		path, err := FindIcon(test.name, test.theme, test.size)
+		if err != nil || path != test.expected {
+			t.Errorf("Expected %s for %s in %q at %dpx, got %s (%v)", test.expected, test.name, test.theme, test.size, path, err)
+		}
+	}
+
+	for _, name := range []string{"missing", "themed", filepath.Join(system, "missing.png")} {
+		if path, err := FindIcon(name, "", 48); !errors.Is(err, ErrNoIcon) {
+			t.Errorf("Expected ErrNoIcon for %s, got %s (%v)", name, path, err)
+		}
+	}
+}
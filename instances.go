@@ -0,0 +1,72 @@
+package mpris
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const getConnectionUnixProcessIDMethod = "org.freedesktop.DBus.GetConnectionUnixProcessID"
+
+// SplitInstance splits a player name like org.mpris.MediaPlayer2.chromium.instance1234 into
+// the name of the player, "chromium", and the instance, "instance1234". The instance is empty
+// for players running a single instance, like org.mpris.MediaPlayer2.spotify.
+func SplitInstance(name string) (player, instance string) {
+	shortName := strings.TrimPrefix(name, BaseInterface+".")
+	dot := strings.LastIndex(shortName, ".")
+	if dot == -1 || !strings.HasPrefix(shortName[dot+1:], "instance") {
+		return shortName, ""
+	}
+	return shortName[:dot], shortName[dot+1:]
+}
+
+// ListInstances lists the available players grouped by the name of the player, as returned
+// by SplitInstance, so the instances of a player like chromium are found together.
+func ListInstances(conn *dbus.Conn) (map[string][]string, error) {
+	names, err := List(conn)
+	if err != nil {
+		return nil, err
+	}
+	instances := make(map[string][]string)
+	for _, name := range names {
+		player, _ := SplitInstance(name)
+		instances[player] = append(instances[player], name)
+	}
+	for _, names := range instances {
+		sort.Strings(names)
+	}
+	return instances, nil
+}
+
+// NewFromOwner returns the player whose name is owned by the unique name, like ":1.42".
+// When the connection owns several player names the first one in alphabetical order is
+// used. ErrPlayerNotFound is returned when it owns none.
+func NewFromOwner(conn *dbus.Conn, owner string, opts ...Option) (*Player, error) {
+	names, err := List(conn)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var nameOwner string
+		err := conn.BusObject().Call(getNameOwnerMethod, 0, name).Store(&nameOwner)
+		if err != nil {
+			// the player quit in the meantime
+			continue
+		}
+		if nameOwner == owner {
+			return New(conn, name, opts...), nil
+		}
+	}
+	return nil, ErrPlayerNotFound
+}
+
+// PID returns the id of the process owning the player name.
+func (i *Player) PID() (uint32, error) {
+	var pid uint32
+	if err := i.busCall(getConnectionUnixProcessIDMethod, i.name).Store(&pid); err != nil {
+		return 0, err
+	}
+	return pid, nil
+}
@@ -0,0 +1,56 @@
+package mpris
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSplitInstance(t *testing.T) {
+	tests := []struct {
+		name, player, instance string
+	}{
+		{BaseInterface + ".spotify", "spotify", ""},
+		{BaseInterface + ".chromium.instance1234", "chromium", "instance1234"},
+		{BaseInterface + ".vlc.instance7389", "vlc", "instance7389"},
+		{BaseInterface + ".mpristest.other", "mpristest.other", ""},
+	}
+	for _, test := range tests {
+		player, instance := SplitInstance(test.name)
+		if player != test.player || instance != test.instance {
+			t.Errorf("Expected %s to be split into %q %q, got %q %q", test.name, test.player, test.instance, player, instance)
+		}
+	}
+}
+
+func TestInstances(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	instances, err := ListInstances(player.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, name := range instances["mpristest"] {
+		found = found || name == fake.Name()
+	}
+	if !found {
+		t.Errorf("Expected %s in the mpristest instances, got %v", fake.Name(), instances)
+	}
+
+	owner, err := player.Owner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromOwner, err := NewFromOwner(player.conn, owner)
+	if err != nil || fromOwner.GetName() != fake.Name() {
+		t.Errorf("Expected the player to be found from its owner, got %v %v", fromOwner, err)
+	}
+	if _, err := NewFromOwner(player.conn, ":0.0"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound for an unknown owner, got %v", err)
+	}
+
+	if pid, err := player.PID(); err != nil || pid != uint32(os.Getpid()) {
+		t.Errorf("Expected the pid to be %d, got %d %v", os.Getpid(), pid, err)
+	}
+}
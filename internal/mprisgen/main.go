@@ -0,0 +1,338 @@
+// Command mprisgen generates the bindings of the MPRIS interfaces from the introspection XML
+// embedded in the spec package: the methods and property accessors of the clients of go-mpris,
+// or with -server the property getters of the server package. It's run by go generate:
+//
+//	go generate ./...
+//
+// A new property or method of the spec is bound by adding it to the XML and regenerating. The
+// members whose type has no Go type here, or which need more than a conversion, are written by
+// hand and listed in handWritten, so that a member nobody bound makes the generation fail.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Pauloo27/go-mpris/spec"
+)
+
+// clientInterfaces are the client types and the constants of the interfaces, by interface.
+var clientInterfaces = map[string][2]string{
+	"org.mpris.MediaPlayer2":           {"BaseClient", "BaseInterface"},
+	"org.mpris.MediaPlayer2.Player":    {"PlayerClient", "PlayerInterface"},
+	"org.mpris.MediaPlayer2.TrackList": {"TrackListClient", "TrackListInterface"},
+	"org.mpris.MediaPlayer2.Playlists": {"PlaylistsClient", "PlaylistsInterface"},
+}
+
+// serverInterfaces are the adapter types of the server, by interface.
+var serverInterfaces = map[string]string{
+	"org.mpris.MediaPlayer2":           "RootAdapter",
+	"org.mpris.MediaPlayer2.Player":    "PlayerAdapter",
+	"org.mpris.MediaPlayer2.TrackList": "TrackListAdapter",
+	"org.mpris.MediaPlayer2.Playlists": "PlaylistsAdapter",
+}
+
+// handWritten are the members implemented by hand, by full name, with the reason why.
+var handWritten = map[string]map[string]string{
+	"client": {
+		"org.mpris.MediaPlayer2.Player.SetPosition":          "checks the track id",
+		"org.mpris.MediaPlayer2.TrackList.GetTracksMetadata": "converts the answer",
+		"org.mpris.MediaPlayer2.TrackList.Tracks":            "converts the object paths",
+		"org.mpris.MediaPlayer2.Playlists.GetPlaylists":      "converts the answer",
+		"org.mpris.MediaPlayer2.Playlists.Orderings":         "converts the strings",
+		"org.mpris.MediaPlayer2.Playlists.ActivePlaylist":    "decodes a struct",
+	},
+	"server": {
+		"org.mpris.MediaPlayer2.HasTrackList":             "given by the server",
+		"org.mpris.MediaPlayer2.Fullscreen":               "not exported",
+		"org.mpris.MediaPlayer2.CanSetFullscreen":         "not exported",
+		"org.mpris.MediaPlayer2.Playlists.Orderings":      "converts the strings",
+		"org.mpris.MediaPlayer2.Playlists.ActivePlaylist": "replaces the missing playlist",
+	},
+}
+
+// goType is the Go type of a D-Bus type.
+type goType struct {
+	name string
+	zero string
+	// decode is the method of the player decoding the variant, and convert the format
+	// converting its result, if needed. The types without decode are only used for arguments.
+	decode  string
+	convert string
+	// encode is the format converting a Go value to the value sent.
+	encode string
+	// serve is the format converting the value returned by an adapter to the value sent.
+	serve   string
+	imports []string
+}
+
+// goTypes are the Go types, by D-Bus signature and spec type.
+var goTypes = map[string]goType{
+	"b":     {name: "bool", zero: "false", decode: "boolValue"},
+	"s":     {name: "string", zero: `""`, decode: "stringValue"},
+	"s/Uri": {name: "string", zero: `""`, decode: "stringValue"},
+	"s/Playback_Status": {
+		name: "PlaybackStatus", zero: `""`, decode: "stringValue", convert: "PlaybackStatus(%s)",
+		encode: "string(%s)", serve: "string(%s)",
+	},
+	"s/Loop_Status": {
+		name: "LoopStatus", zero: `""`, decode: "stringValue", convert: "LoopStatus(%s)",
+		encode: "string(%s)", serve: "string(%s)",
+	},
+	"d":               {name: "float64", zero: "0", decode: "float64Value"},
+	"d/Playback_Rate": {name: "float64", zero: "0", decode: "float64Value"},
+	"d/Volume":        {name: "float64", zero: "0", decode: "float64Value"},
+	"u":               {name: "uint32", zero: "0", decode: "int64Value", convert: "uint32(%s)"},
+	"as":              {name: "[]string", zero: "nil", decode: "stringsValue", serve: "nonNilStrings(%s)"},
+	"a{sv}/Metadata_Map": {
+		name: "Metadata", zero: "nil", decode: "metadataValue", serve: "metadataValue(%s)",
+	},
+	"x/Time_In_Us": {
+		name: "time.Duration", zero: "0", decode: "int64Value", convert: "microsecondsToDuration(%s)",
+		encode: "durationToMicroseconds(%s)", serve: "durationToMicroseconds(%s)",
+		imports: []string{"time"},
+	},
+	"o/Track_Id":    {name: "TrackID", encode: "%s.ObjectPath()"},
+	"o/Playlist_Id": {name: "dbus.ObjectPath", imports: []string{"github.com/godbus/dbus/v5"}},
+	"ao/Track_Id[]": {serve: "trackPaths(%s)"},
+}
+
+func lookupType(typ, typeName string) (goType, bool) {
+	key := typ
+	if typeName != "" {
+		key += "/" + typeName
+	}
+	t, ok := goTypes[key]
+	return t, ok
+}
+
+// apply applies the format to the value, if any.
+func apply(format, value string) string {
+	if format == "" {
+		return value
+	}
+	return fmt.Sprintf(format, value)
+}
+
+// generator writes the bindings to a buffer.
+type generator struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+	// unbound are the members neither generated nor written by hand.
+	unbound []string
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// comment writes the text as a doc comment, wrapped at 100 columns.
+func (g *generator) comment(text string) {
+	line := "//"
+	for _, word := range strings.Fields(text) {
+		if len(line)+1+len(word) > 100 && line != "//" {
+			g.printf("%s\n", line)
+			line = "//"
+		}
+		line += " " + word
+	}
+	g.printf("%s\n", line)
+}
+
+func (g *generator) use(t goType) {
+	for _, path := range t.imports {
+		g.imports[path] = true
+	}
+}
+
+// source returns the formatted source, with the header and the imports.
+func (g *generator) source(pkg string) ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by mprisgen from the MPRIS introspection XML. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		var std, others []string
+		for path := range g.imports {
+			if strings.Contains(path, ".") {
+				others = append(others, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+		slices.Sort(std)
+		slices.Sort(others)
+		fmt.Fprintf(&src, "import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(others) > 0 {
+			fmt.Fprintf(&src, "\n")
+		}
+		for _, path := range others {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		fmt.Fprintf(&src, ")\n\n")
+	}
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// lowerFirst lowercases the first letter of the text, unless it starts an acronym like "URI".
+func lowerFirst(text string) string {
+	runes := []rune(text)
+	if len(runes) > 1 && unicode.IsUpper(runes[1]) {
+		return text
+	}
+	return strings.ToLower(text[:1]) + text[1:]
+}
+
+// goName returns the Go name of an argument, like "trackID" for "TrackId".
+func goName(name string) string {
+	return strings.ReplaceAll(lowerFirst(name), "Id", "ID")
+}
+
+// getterName returns the name of the getter of the property, like "GetVolume", or "CanSeek" for
+// the boolean flags.
+func getterName(prop spec.Property) string {
+	if prop.Type == "b" && (strings.HasPrefix(prop.Name, "Can") || strings.HasPrefix(prop.Name, "Has")) {
+		return prop.Name
+	}
+	return "Get" + prop.Name
+}
+
+// adapterName returns the name of the adapter method returning the property, like
+// "SupportedURISchemes".
+func adapterName(prop spec.Property) string {
+	return strings.ReplaceAll(prop.Name, "Uri", "URI")
+}
+
+func (g *generator) client(iface spec.Interface) {
+	client, constant := clientInterfaces[iface.Name][0], clientInterfaces[iface.Name][1]
+	for _, method := range iface.Methods {
+		g.clientMethod(iface, client, constant, method)
+	}
+	for _, prop := range iface.Properties {
+		g.clientProperty(iface, client, constant, prop)
+	}
+}
+
+func (g *generator) clientMethod(iface spec.Interface, client, constant string, method spec.Method) {
+	name := iface.Name + "." + method.Name
+	if handWritten["client"][name] != "" {
+		return
+	}
+	var params, values []string
+	for _, arg := range method.Args {
+		t, ok := lookupType(arg.Type, arg.TypeName)
+		if !ok || arg.Direction != "in" {
+			g.unbound = append(g.unbound, name)
+			return
+		}
+		g.use(t)
+		params = append(params, goName(arg.Name)+" "+t.name)
+		values = append(values, ", "+apply(t.encode, goName(arg.Name)))
+	}
+
+	g.comment(method.Name + " " + lowerFirst(method.Doc))
+	g.printf("func (c %s) %s(%s) error {\n", client, method.Name, strings.Join(params, ", "))
+	g.printf("\treturn c.core.call(%s+%q%s).Err\n", constant, "."+method.Name, strings.Join(values, ""))
+	g.printf("}\n\n")
+}
+
+func (g *generator) clientProperty(iface spec.Interface, client, constant string, prop spec.Property) {
+	name := iface.Name + "." + prop.Name
+	if handWritten["client"][name] != "" {
+		return
+	}
+	t, ok := lookupType(prop.Type, prop.TypeName)
+	if !ok || t.decode == "" {
+		g.unbound = append(g.unbound, name)
+		return
+	}
+	g.use(t)
+
+	getter := getterName(prop)
+	g.comment(getter + " returns " + lowerFirst(prop.Doc))
+	g.printf("func (c %s) %s() (%s, error) {\n", client, getter, t.name)
+	g.printf("\tvariant, err := c.core.getProperty(%s, %q)\n", constant, prop.Name)
+	g.printf("\tif err != nil {\n\t\treturn %s, err\n\t}\n", t.zero)
+	if t.convert == "" {
+		g.printf("\treturn c.core.%s(variant, %q)\n", t.decode, prop.Name)
+	} else {
+		g.printf("\tvalue, err := c.core.%s(variant, %q)\n", t.decode, prop.Name)
+		g.printf("\treturn %s, err\n", apply(t.convert, "value"))
+	}
+	g.printf("}\n\n")
+
+	if !prop.Writable() {
+		return
+	}
+	param := goName(prop.Name)
+	g.comment("Set" + prop.Name + " sets " + lowerFirst(prop.Doc))
+	g.printf("func (c %s) Set%s(%s %s) error {\n", client, prop.Name, param, t.name)
+	g.printf("\treturn c.core.setProperty(%s, %q, %s)\n", constant, prop.Name, apply(t.encode, param))
+	g.printf("}\n\n")
+}
+
+func (g *generator) server(iface spec.Interface) {
+	adapter := serverInterfaces[iface.Name]
+	function := lowerFirst(strings.TrimSuffix(adapter, "Adapter")) + "Properties"
+	g.comment(fmt.Sprintf("%s returns the getters of the properties of the %s interface.", function, iface.Name))
+	g.printf("func %s(a %s) map[string]property {\n", function, adapter)
+	g.printf("\treturn map[string]property{\n")
+	for _, prop := range iface.Properties {
+		name := iface.Name + "." + prop.Name
+		if handWritten["server"][name] != "" {
+			continue
+		}
+		t, ok := lookupType(prop.Type, prop.TypeName)
+		if !ok {
+			g.unbound = append(g.unbound, name)
+			continue
+		}
+		value := apply(t.serve, "a."+adapterName(prop)+"()")
+		g.printf("\t\t%q: {get: func() interface{} { return %s }},\n", prop.Name, value)
+	}
+	g.printf("\t}\n}\n\n")
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mprisgen: ")
+	server := flag.Bool("server", false, "generate the bindings of the server package instead of the clients")
+	out := flag.String("o", "bindings_gen.go", "the file to write")
+	flag.Parse()
+
+	src, err := generate(*server)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the source of the bindings of the clients, or of the server.
+func generate(server bool) ([]byte, error) {
+	g := &generator{imports: make(map[string]bool)}
+	pkg := "mpris"
+	for _, iface := range spec.Interfaces() {
+		if server {
+			pkg = "server"
+			g.server(iface)
+		} else {
+			g.client(iface)
+		}
+	}
+	if len(g.unbound) > 0 {
+		return nil, fmt.Errorf("no binding for %s: add their types to goTypes, or list them in handWritten", strings.Join(g.unbound, ", "))
+	}
+	return g.source(pkg)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/spec"
+)
+
+func TestGenerated(t *testing.T) {
+	for file, server := range map[string]bool{
+		"../../bindings_gen.go":        false,
+		"../../server/bindings_gen.go": true,
+	} {
+		src, err := generate(server)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, current) {
+			t.Errorf("%s is out of date, run go generate", file)
+		}
+	}
+}
+
+func TestHandWritten(t *testing.T) {
+	members := make(map[string]bool)
+	for _, iface := range spec.Interfaces() {
+		for _, method := range iface.Methods {
+			members[iface.Name+"."+method.Name] = true
+		}
+		for _, prop := range iface.Properties {
+			members[iface.Name+"."+prop.Name] = true
+		}
+	}
+	for _, names := range handWritten {
+		for name := range names {
+			if !members[name] {
+				t.Errorf("%s is listed as hand written but isn't in the spec", name)
+			}
+		}
+	}
+}
+
+func TestUnbound(t *testing.T) {
+	delete(handWritten["client"], "org.mpris.MediaPlayer2.Playlists.ActivePlaylist")
+	defer func() {
+		handWritten["client"]["org.mpris.MediaPlayer2.Playlists.ActivePlaylist"] = "decodes a struct"
+	}()
+	if _, err := generate(false); err == nil || !strings.Contains(err.Error(), "ActivePlaylist") {
+		t.Errorf("Expected the generation to fail for ActivePlaylist, got %v", err)
+	}
+}
+
+func TestLowerFirst(t *testing.T) {
+	for text, want := range map[string]string{
+		"Whether the player can quit.": "whether the player can quit.",
+		"URI schemes":                  "URI schemes",
+		"TrackId":                      "trackId",
+	} {
+		if got := lowerFirst(text); got != want {
+			t.Errorf("Expected %q for %q, got %q", want, text, got)
+		}
+	}
+	if got := goName("PlaylistId"); got != "playlistID" {
+		t.Errorf("Expected playlistID, got %q", got)
+	}
+}
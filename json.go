@@ -0,0 +1,127 @@
+package mpris
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// metadataKeys are the metadata keys given their own field in the JSON encoding.
+var metadataKeys = map[string]bool{
+	"mpris:trackid": true,
+	"mpris:length":  true,
+	"mpris:artUrl":  true,
+	"xesam:title":   true,
+	"xesam:album":   true,
+	"xesam:artist":  true,
+	"xesam:url":     true,
+}
+
+type jsonMetadata struct {
+	TrackID  TrackID                `json:"trackId,omitempty"`
+	Title    string                 `json:"title,omitempty"`
+	Album    string                 `json:"album,omitempty"`
+	Artists  []string               `json:"artists,omitempty"`
+	ArtURL   string                 `json:"artUrl,omitempty"`
+	URL      string                 `json:"url,omitempty"`
+	LengthMs int64                  `json:"lengthMs,omitempty"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+}
+
+// MarshalJSON encodes the metadata as an object with the fields of the typed accessors, like
+// "title" and "artists", and the length in milliseconds as "lengthMs". The other keys, like
+// "xesam:genre", are kept in "extra" with their D-Bus values.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	encoded := jsonMetadata{
+		TrackID:  m.TrackID(),
+		Title:    m.Title(),
+		Album:    m.Album(),
+		Artists:  m.Artists(),
+		ArtURL:   m.ArtURL(),
+		URL:      m.URL(),
+		LengthMs: m.Length().Milliseconds(),
+	}
+	for key, value := range m {
+		if metadataKeys[key] {
+			continue
+		}
+		if encoded.Extra == nil {
+			encoded.Extra = make(map[string]interface{})
+		}
+		encoded.Extra[key] = JSONValue(value.Value())
+	}
+	return json.Marshal(encoded)
+}
+
+// JSONValue unwraps the variants nested in a D-Bus value, which have no JSON encoding, like
+// the values of PropertiesChangedEvent. The infinite and NaN doubles, which have none either,
+// are encoded as null.
+func JSONValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case dbus.Variant:
+		return JSONValue(value.Value())
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil
+		}
+	case []dbus.Variant:
+		values := make([]interface{}, len(value))
+		for i, item := range value {
+			values[i] = JSONValue(item.Value())
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, len(value))
+		for i, item := range value {
+			values[i] = JSONValue(item)
+		}
+		return values
+	case map[string]dbus.Variant:
+		values := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			values[key] = JSONValue(item.Value())
+		}
+		return values
+	case []map[string]dbus.Variant:
+		values := make([]interface{}, len(value))
+		for i, item := range value {
+			values[i] = JSONValue(item)
+		}
+		return values
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			values[key] = JSONValue(item)
+		}
+		return values
+	}
+	return value
+}
+
+type jsonPlayerState struct {
+	PlaybackStatus PlaybackStatus `json:"playbackStatus"`
+	LoopStatus     LoopStatus     `json:"loopStatus,omitempty"`
+	Shuffle        bool           `json:"shuffle"`
+	Volume         interface{}    `json:"volume"`
+	Rate           interface{}    `json:"rate"`
+	PositionMs     int64          `json:"positionMs"`
+	Metadata       Metadata       `json:"metadata"`
+}
+
+// MarshalJSON encodes the state with the position in milliseconds as "positionMs". An
+// infinite or NaN volume or rate is encoded as null.
+func (s PlayerState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPlayerState{
+		PlaybackStatus: s.PlaybackStatus,
+		LoopStatus:     s.LoopStatus,
+		Shuffle:        s.Shuffle,
+		Volume:         JSONValue(s.Volume),
+		Rate:           JSONValue(s.Rate),
+		PositionMs:     s.Position.Milliseconds(),
+		Metadata:       s.Metadata,
+	})
+}
@@ -0,0 +1,67 @@
+package mpris
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	state := PlayerState{
+		PlaybackStatus: PlaybackPlaying,
+		LoopStatus:     LoopNone,
+		Volume:         0.5,
+		Rate:           1,
+		Position:       1500 * time.Millisecond,
+		Metadata: Metadata{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1")),
+			"mpris:length":  dbus.MakeVariant(int64(210000000)),
+			"xesam:title":   dbus.MakeVariant("Title"),
+			"xesam:artist":  dbus.MakeVariant([]string{"First", "Second"}),
+			"xesam:genre":   dbus.MakeVariant([]string{"Rock"}),
+			"custom:nested": dbus.MakeVariant(map[string]dbus.Variant{"key": dbus.MakeVariant(int32(1))}),
+		},
+	}
+
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"playbackStatus":"Playing","loopStatus":"None","shuffle":false,"volume":0.5,"rate":1,` +
+		`"positionMs":1500,"metadata":{"trackId":"/track/1","title":"Title","artists":["First","Second"],` +
+		`"lengthMs":210000,"extra":{"custom:nested":{"key":1},"xesam:genre":["Rock"]}}}`
+	if string(encoded) != expected {
+		t.Errorf("Unexpected encoding\n%s\nexpected\n%s", encoded, expected)
+	}
+
+	if encoded, err := json.Marshal(PlayerState{}); err != nil || string(encoded) !=
+		`{"playbackStatus":"","shuffle":false,"volume":0,"rate":0,"positionMs":0,"metadata":null}` {
+		t.Errorf("Unexpected encoding of an empty state %s %v", encoded, err)
+	}
+
+	nonFinite := PlayerState{Volume: math.NaN(), Rate: math.Inf(1)}
+	if encoded, err := json.Marshal(nonFinite); err != nil || string(encoded) !=
+		`{"playbackStatus":"","shuffle":false,"volume":null,"rate":null,"positionMs":0,"metadata":null}` {
+		t.Errorf("Expected the infinite and NaN doubles to be null, got %s %v", encoded, err)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	tracks := []map[string]dbus.Variant{
+		{"xesam:title": dbus.MakeVariant("First")},
+		{"xesam:title": dbus.MakeVariant("Second")},
+	}
+	options := map[string]interface{}{"nested": dbus.MakeVariant(int32(1)), "volume": math.NaN()}
+
+	encoded, err := json.Marshal([]interface{}{JSONValue(tracks), JSONValue(options)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[[{"xesam:title":"First"},{"xesam:title":"Second"}],{"nested":1,"volume":null}]`
+	if string(encoded) != expected {
+		t.Errorf("Expected the nested variants to be unwrapped, got %s", encoded)
+	}
+}
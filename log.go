@@ -0,0 +1,35 @@
+package mpris
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// WithLogger makes the player log at debug level its D-Bus calls, the changes of its signal
+// subscriptions, the restarts of the player and the signals that can't be decoded, which are
+// dropped otherwise. Every record has a player attribute with the name of the player.
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Player) {
+		p.logger = logger
+	}
+}
+
+// debug logs a debug record if a logger was set with WithLogger.
+func (i *Player) debug(msg string, args ...interface{}) {
+	if i.logger == nil || !i.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	i.logger.Debug(msg, append([]interface{}{"player", i.name}, args...)...)
+}
+
+// decodeSignal converts a player signal to an event like parseSignal, logging the signals that
+// can't be decoded.
+func (i *Player) decodeSignal(sig *dbus.Signal) (Event, bool) {
+	event, ok := parseSignal(sig)
+	if !ok {
+		i.debug("dropping undecodable signal", "signal", sig.Name, "sender", sig.Sender, "signature", dbus.SignatureOf(sig.Body...).String())
+	}
+	return event, ok
+}
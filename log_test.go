@@ -0,0 +1,70 @@
+package mpris
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+// syncBuffer is a buffer safe for the concurrent writes of the subscriptions.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithLogger(t *testing.T) {
+	testPlayer, _ := newTestPlayer(t)
+	var logs syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	player := New(testPlayer.conn, testPlayer.name, WithLogger(logger))
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), `msg="D-Bus call" player=`+player.name+" method="+PlayerInterface+".Play") {
+		t.Errorf("Expected the call to be logged, got %q", logs.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := player.Subscribe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	mpristest.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "unsubscribed from signals")
+	}, "Expected the subscription changes to be logged")
+	if !strings.Contains(logs.String(), "subscribed to signals") {
+		t.Errorf("Expected the subscription to be logged, got %q", logs.String())
+	}
+
+	malformed := &dbus.Signal{Name: seekedSignal, Body: []interface{}{"not a position"}}
+	if _, ok := player.decodeSignal(malformed); ok {
+		t.Error("Expected the malformed signal to not be decoded")
+	}
+	if !strings.Contains(logs.String(), `msg="dropping undecodable signal"`) {
+		t.Errorf("Expected the malformed signal to be logged, got %q", logs.String())
+	}
+
+	silent := New(testPlayer.conn, testPlayer.name)
+	if err := silent.Play(); err != nil {
+		t.Errorf("Expected a player without logger to work, got %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package mpris
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// autoPauseGrace is how long after being paused by the auto pause a player that reports it's
+// playing is considered to be sending a stale status, instead of being started again.
+const autoPauseGrace = time.Second
+
+// managedPlayer is a player tracked by a Manager.
+type managedPlayer struct {
+	player     *Player
+	owner      string
+	status     PlaybackStatus
+	lastActive time.Time
+	// autoPausedAt is when the player was last paused by the auto pause.
+	autoPausedAt time.Time
+}
+
+// Manager tracks all the players on the bus, ordered by the most recently active first.
+// A player becomes active when it announces a change of its player properties, like a new
+// track or playback status. It's safe for concurrent use.
+type Manager struct {
+	conn    *dbus.Conn
+	opts    []Option
+	watcher *Watcher
+	rule    []dbus.MatchOption
+	signals chan *dbus.Signal
+	done    chan struct{}
+	stopped chan struct{}
+
+	mu        sync.RWMutex
+	players   map[string]*managedPlayer
+	autoPause bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*managerConfig)
+
+type managerConfig struct {
+	playerOpts   []Option
+	signalBuffer int
+}
+
+// WithManagerPlayerOptions sets the options the players tracked by the manager are created with.
+func WithManagerPlayerOptions(opts ...Option) ManagerOption {
+	return func(c *managerConfig) {
+		c.playerOpts = append(c.playerOpts, opts...)
+	}
+}
+
+// WithManagerSignalBuffer sets how many of the signals telling the active player the manager
+// buffers, 16 by default. The size is at least 1.
+func WithManagerSignalBuffer(size int) ManagerOption {
+	return func(c *managerConfig) {
+		c.signalBuffer = max(size, 1)
+	}
+}
+
+// NewManager starts tracking the players on the bus, configured with opts.
+func NewManager(conn *dbus.Conn, opts ...ManagerOption) (*Manager, error) {
+	config := managerConfig{signalBuffer: defaultSignalBuffer}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	m := &Manager{
+		conn: conn,
+		opts: config.playerOpts,
+		rule: []dbus.MatchOption{
+			dbus.WithMatchObjectPath(dbusObjectPath),
+			dbus.WithMatchInterface(propertiesInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchOption("arg0", PlayerInterface),
+		},
+		signals: make(chan *dbus.Signal, config.signalBuffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		players: make(map[string]*managedPlayer),
+	}
+
+	if err := conn.AddMatchSignal(m.rule...); err != nil {
+		return nil, err
+	}
+	conn.Signal(m.signals)
+
+	watcher, err := NewWatcher(conn)
+	if err != nil {
+		conn.RemoveSignal(m.signals)
+		conn.RemoveMatchSignal(m.rule...)
+		return nil, err
+	}
+	m.watcher = watcher
+
+	for _, name := range watcher.Players() {
+		m.addPlayer(name, watcher.Owner(name))
+	}
+
+	go m.run()
+	return m, nil
+}
+
+func (m *Manager) run() {
+	defer close(m.stopped)
+	for {
+		select {
+		case <-m.done:
+			return
+		case event, ok := <-m.watcher.Events():
+			if !ok {
+				return
+			}
+			if event.NewOwner == "" {
+				m.removePlayer(event.Name)
+			} else {
+				m.addPlayer(event.Name, event.NewOwner)
+			}
+		case sig, ok := <-m.signals:
+			if !ok {
+				return
+			}
+			m.handleSignal(sig)
+		}
+	}
+}
+
+func (m *Manager) addPlayer(name, owner string) {
+	m.mu.RLock()
+	tracked, ok := m.players[name]
+	m.mu.RUnlock()
+	if ok && tracked.owner == owner {
+		return
+	}
+
+	var player *Player
+	if ok {
+		// the player restarted, its subscriptions follow the new owner
+		player = tracked.player
+	} else {
+		player = New(m.conn, name, m.opts...)
+	}
+	// the status is only used for ordering, so a player that fails to answer is still tracked
+	status, _ := player.GetPlaybackStatus()
+
+	m.mu.Lock()
+	if ok {
+		// the activity is kept, for the ordering and the auto pause grace
+		tracked.owner = owner
+		tracked.status = status
+	} else {
+		m.players[name] = &managedPlayer{player: player, owner: owner, status: status}
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) removePlayer(name string) {
+	m.mu.Lock()
+	tracked, ok := m.players[name]
+	delete(m.players, name)
+	m.mu.Unlock()
+	// closing removes the match rules, which waits for the bus, so it's done without the lock
+	if ok {
+		tracked.player.closeSubscriptions()
+	}
+}
+
+func (m *Manager) handleSignal(sig *dbus.Signal) {
+	event, ok := parseSignal(sig)
+	if !ok || sig.Path != dbusObjectPath {
+		return
+	}
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || changed.Interface != PlayerInterface {
+		return
+	}
+
+	now := time.Now()
+	var started []string
+	m.mu.Lock()
+	for name, tracked := range m.players {
+		if tracked.owner != sig.Sender {
+			continue
+		}
+		tracked.lastActive = now
+		if status, ok := changed.Changed["PlaybackStatus"].Value().(string); ok {
+			if status == string(PlaybackPlaying) && tracked.status != PlaybackPlaying {
+				started = append(started, name)
+			}
+			tracked.status = PlaybackStatus(status)
+		}
+	}
+	autoPause := m.autoPause
+	m.mu.Unlock()
+
+	if autoPause {
+		for _, name := range started {
+			m.arbitrate(name)
+		}
+	}
+}
+
+// SetAutoPause enables or disables the auto pause, that pauses every other player when one
+// starts playing, so only one plays at a time.
+func (m *Manager) SetAutoPause(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoPause = enabled
+}
+
+// arbitrate pauses the players that are playing along with the player that just started.
+// Pausing a player can race with it starting, so a player that reports it's playing right
+// after being paused is paused again once, instead of pausing the player that took over.
+func (m *Manager) arbitrate(name string) {
+	now := time.Now()
+	var toPause []*Player
+
+	m.mu.Lock()
+	started, ok := m.players[name]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	if now.Sub(started.autoPausedAt) < autoPauseGrace {
+		started.autoPausedAt = time.Time{}
+		toPause = append(toPause, started.player)
+	} else {
+		for otherName, tracked := range m.players {
+			if otherName == name || tracked.status != PlaybackPlaying {
+				continue
+			}
+			tracked.autoPausedAt = now
+			toPause = append(toPause, tracked.player)
+		}
+	}
+	m.mu.Unlock()
+
+	// the calls are made outside of the manager loop, so a slow player doesn't delay the
+	// signal handling
+	go func() {
+		for _, player := range toPause {
+			player.Pause()
+		}
+	}()
+}
+
+// Players returns the tracked players, the most recently active first. Players that weren't
+// active since the manager started are ordered by their playback status, playing first.
+//
+// The signal subscriptions of a player, like the ones of Subscribe, are closed when it quits
+// or the manager is closed, even if the player is still used.
+func (m *Manager) Players() []*Player {
+	m.mu.RLock()
+	tracked := make([]*managedPlayer, 0, len(m.players))
+	for _, player := range m.players {
+		copied := *player
+		tracked = append(tracked, &copied)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(tracked, func(a, b int) bool {
+		if !tracked[a].lastActive.Equal(tracked[b].lastActive) {
+			return tracked[a].lastActive.After(tracked[b].lastActive)
+		}
+		if playbackPriority[tracked[a].status] != playbackPriority[tracked[b].status] {
+			return playbackPriority[tracked[a].status] > playbackPriority[tracked[b].status]
+		}
+		return tracked[a].player.name < tracked[b].player.name
+	})
+
+	players := make([]*Player, len(tracked))
+	for i, player := range tracked {
+		players[i] = player.player
+	}
+	return players
+}
+
+// Player returns the tracked player with the name, or nil if there's none. Its signal
+// subscriptions are closed like the ones of the players returned by Players.
+func (m *Manager) Player(name string) *Player {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if tracked, ok := m.players[name]; ok {
+		return tracked.player
+	}
+	return nil
+}
+
+// Active returns the most recently active player, or nil if there are no players.
+func (m *Manager) Active() *Player {
+	players := m.Players()
+	if len(players) == 0 {
+		return nil
+	}
+	return players[0]
+}
+
+// PauseAll pauses every player.
+func (m *Manager) PauseAll() error {
+	return m.PauseAllExcept("")
+}
+
+// PauseAllExcept pauses every player but the one with the name. Every player is paused even
+// if some of them fail, the first error being returned. Players that quit in the meantime
+// are ignored.
+func (m *Manager) PauseAllExcept(name string) error {
+	var firstErr error
+	for _, player := range m.Players() {
+		if player.name == name {
+			continue
+		}
+		err := player.Pause()
+		if err != nil && !errors.Is(err, ErrPlayerNotFound) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops tracking the players and removes the match rules, along with the signal
+// subscriptions of the tracked players. The connection is never closed by the manager.
+func (m *Manager) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+		<-m.stopped
+		m.conn.RemoveSignal(m.signals)
+		m.closeErr = m.conn.RemoveMatchSignal(m.rule...)
+		if err := m.watcher.Close(); err != nil && m.closeErr == nil {
+			m.closeErr = err
+		}
+
+		m.mu.RLock()
+		players := make([]*Player, 0, len(m.players))
+		for _, tracked := range m.players {
+			players = append(players, tracked.player)
+		}
+		m.mu.RUnlock()
+		for _, player := range players {
+			if err := player.closeSubscriptions(); err != nil && m.closeErr == nil {
+				m.closeErr = err
+			}
+		}
+	})
+	return m.closeErr
+}
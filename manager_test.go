@@ -0,0 +1,172 @@
+package mpris
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestManager(t *testing.T) {
+	first, _ := newTestPlayer(t)
+
+	manager, err := NewManager(first.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if manager.Player(first.GetName()) == nil {
+		t.Errorf("Expected %s to be tracked", first.GetName())
+	}
+
+	second, secondFake := newTestPlayer(t)
+	mpristest.Eventually(t, func() bool {
+		return manager.Player(second.GetName()) != nil
+	}, "Second player not tracked")
+
+	if err := secondFake.SetPlaybackStatus(string(PlaybackPlaying)); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		active := manager.Active()
+		return active != nil && active.GetName() == second.GetName()
+	}, "Second player not active")
+
+	if err := secondFake.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return manager.Player(second.GetName()) == nil
+	}, "Second player still tracked after quitting")
+}
+
+func TestWatcher(t *testing.T) {
+	player, _ := newTestPlayer(t)
+
+	watcher, err := NewWatcher(player.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if watcher.Owner(player.GetName()) == "" {
+		t.Errorf("Expected %s to have an owner", player.GetName())
+	}
+
+	other, otherFake := newTestPlayer(t)
+	otherFake.Close()
+
+	var appeared, vanished bool
+	timeout := time.After(2 * time.Second)
+	for !appeared || !vanished {
+		select {
+		case event := <-watcher.Events():
+			if event.Name != other.GetName() {
+				continue
+			}
+			appeared = appeared || event.Appeared()
+			vanished = vanished || event.Vanished()
+		case <-timeout:
+			t.Fatalf("Timed out, appeared: %v, vanished: %v", appeared, vanished)
+		}
+	}
+
+	if err := watcher.Close(); err != nil {
+		t.Error(err)
+	}
+	drained := make(chan struct{})
+	go func() {
+		for range watcher.Events() {
+		}
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Error("Expected the events channel to be closed")
+	}
+}
+
+func TestManagerAutoPause(t *testing.T) {
+	first, firstFake := newTestPlayer(t)
+	second, _ := newTestPlayer(t)
+
+	manager, err := NewManager(first.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	manager.SetAutoPause(true)
+
+	statusOf := func(player *Player) PlaybackStatus {
+		status, _ := player.GetPlaybackStatus()
+		return status
+	}
+
+	if err := first.Play(); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return manager.Active() != nil && manager.Active().GetName() == first.GetName()
+	}, "First player not active")
+
+	if err := second.Play(); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return statusOf(first) == PlaybackPaused
+	}, "First player not paused when the second started")
+
+	// a stale playing status right after the pause doesn't take over
+	if err := firstFake.SetPlaybackStatus(string(PlaybackPlaying)); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return statusOf(first) == PlaybackPaused
+	}, "First player not paused again")
+	if status := statusOf(second); status != PlaybackPlaying {
+		t.Errorf("Expected the second player to keep playing, got %s", status)
+	}
+}
+
+func TestManagerOptions(t *testing.T) {
+	first, _ := newTestPlayer(t)
+
+	manager, err := NewManager(first.conn, WithManagerSignalBuffer(64), WithManagerPlayerOptions(WithStats()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if size := cap(manager.signals); size != 64 {
+		t.Errorf("Expected a buffer of 64 signals, got %d", size)
+	}
+	if player := manager.Player(first.GetName()); player == nil || player.Stats() == nil {
+		t.Error("Expected the players to be created with the options")
+	}
+}
+
+func TestManagerRestart(t *testing.T) {
+	first, _ := newTestPlayer(t)
+
+	manager, err := NewManager(first.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	active := time.Now().Add(-time.Minute)
+	manager.mu.Lock()
+	manager.players[first.GetName()].lastActive = active
+	manager.mu.Unlock()
+
+	// the same name taken by a new owner, as when the player restarts
+	manager.addPlayer(first.GetName(), ":1.restarted")
+	manager.mu.RLock()
+	tracked := *manager.players[first.GetName()]
+	manager.mu.RUnlock()
+	if tracked.owner != ":1.restarted" || !tracked.lastActive.Equal(active) {
+		t.Errorf("Expected the restarted player to keep its activity, got %s %v", tracked.owner, tracked.lastActive)
+	}
+}
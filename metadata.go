@@ -0,0 +1,226 @@
+package mpris
+
+import (
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Metadata is the metadata of a track, indexed by the MPRIS and xesam keys like "xesam:title".
+// The typed accessors return the zero value when the key is missing or has an unexpected type.
+type Metadata map[string]dbus.Variant
+
+// TrackID returns the "mpris:trackid" value.
+func (m Metadata) TrackID() TrackID {
+	trackID, _ := trackIDValue(m["mpris:trackid"])
+	return trackID
+}
+
+// TrackKey identifies the track described by the metadata, to tell the tracks apart like
+// OnTrackChange does. Not every player sends a track id, so the url, title and artists are
+// used when it's missing.
+func (m Metadata) TrackKey() string {
+	if trackID := m.TrackID(); trackID != "" {
+		return string(trackID)
+	}
+	key := m.URL() + "\x00" + m.Title()
+	for _, artist := range m.Artists() {
+		key += "\x00" + artist
+	}
+	return key
+}
+
+// Length returns the "mpris:length" value.
+func (m Metadata) Length() time.Duration {
+	length, _ := toInt64(m["mpris:length"].Value())
+	return microsecondsToDuration(length)
+}
+
+// ArtURL returns the "mpris:artUrl" value.
+func (m Metadata) ArtURL() string {
+	artURL, _ := m["mpris:artUrl"].Value().(string)
+	return artURL
+}
+
+// Title returns the "xesam:title" value.
+func (m Metadata) Title() string {
+	title, _ := m["xesam:title"].Value().(string)
+	return title
+}
+
+// Album returns the "xesam:album" value.
+func (m Metadata) Album() string {
+	album, _ := m["xesam:album"].Value().(string)
+	return album
+}
+
+// Artists returns the "xesam:artist" value. Some players send a single string instead of
+// a list, so both are accepted.
+func (m Metadata) Artists() []string {
+	return NormalizeStrings(m["xesam:artist"].Value())
+}
+
+// AlbumArtists returns the "xesam:albumArtist" value, normalized like Artists.
+func (m Metadata) AlbumArtists() []string {
+	return NormalizeStrings(m["xesam:albumArtist"].Value())
+}
+
+// Composers returns the "xesam:composer" value, normalized like Artists.
+func (m Metadata) Composers() []string {
+	return NormalizeStrings(m["xesam:composer"].Value())
+}
+
+// Lyricists returns the "xesam:lyricist" value, normalized like Artists.
+func (m Metadata) Lyricists() []string {
+	return NormalizeStrings(m["xesam:lyricist"].Value())
+}
+
+// Genres returns the "xesam:genre" value, normalized like Artists.
+func (m Metadata) Genres() []string {
+	return NormalizeStrings(m["xesam:genre"].Value())
+}
+
+// URL returns the "xesam:url" value.
+func (m Metadata) URL() string {
+	url, _ := m["xesam:url"].Value().(string)
+	return url
+}
+
+// ContentCreated returns the "xesam:contentCreated" date, when the track was created, often
+// only its year. It's the zero time when it's missing or can't be parsed.
+func (m Metadata) ContentCreated() time.Time {
+	return m.date("xesam:contentCreated")
+}
+
+// FirstUsed returns the "xesam:firstUsed" date, when the track was first played.
+func (m Metadata) FirstUsed() time.Time {
+	return m.date("xesam:firstUsed")
+}
+
+// LastUsed returns the "xesam:lastUsed" date, when the track was last played.
+func (m Metadata) LastUsed() time.Time {
+	return m.date("xesam:lastUsed")
+}
+
+func (m Metadata) date(key string) time.Time {
+	value, _ := m[key].Value().(string)
+	date, _ := ParseDate(value)
+	return date
+}
+
+// dateLayouts are the layouts tried by ParseDate, the ISO 8601 ones and the variants sent by
+// players like VLC: a space instead of the T, no time zone, a time zone without a colon and
+// partial dates.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+	"20060102",
+}
+
+// ParseDate parses the ISO 8601 dates of the xesam metadata, like "2010-04-01T12:30:00+02:00",
+// also accepting the sloppy variants some players send, like "2010-04-01 12:30:00" or "2010".
+// The dates without a time zone are in UTC.
+func ParseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NormalizeStrings converts the value of a list metadata key, like "xesam:artist" or
+// "xesam:genre", to a list of strings. Depending on the player it's a list of strings, a list of
+// variants or a single string. The strings are trimmed and the empty ones are left out, and nil
+// is returned for the values of other types.
+func NormalizeStrings(value interface{}) []string {
+	var values []string
+	add := func(item interface{}) {
+		if variant, ok := item.(dbus.Variant); ok {
+			item = variant.Value()
+		}
+		if str, ok := item.(string); ok {
+			if str = strings.TrimSpace(str); str != "" {
+				values = append(values, str)
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case dbus.Variant:
+		return NormalizeStrings(value.Value())
+	case string:
+		add(value)
+	case []string:
+		for _, item := range value {
+			add(item)
+		}
+	case []interface{}:
+		for _, item := range value {
+			add(item)
+		}
+	case []dbus.Variant:
+		for _, item := range value {
+			add(item)
+		}
+	}
+	return values
+}
+
+// GetTitle returns the current track title.
+func (i *Player) GetTitle() (string, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return "", err
+	}
+	return i.metadataString(metadata, "xesam:title")
+}
+
+// GetArtists returns the current track artists.
+func (i *Player) GetArtists() ([]string, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if variant, ok := metadata["xesam:artist"]; ok && i.decoding == decodeStrict {
+		// a single string is a common quirk rather than a mistake
+		if artist, ok := variant.Value().(string); ok {
+			return []string{artist}, nil
+		}
+		return stringsValue(variant, "xesam:artist")
+	}
+	return metadata.Artists(), nil
+}
+
+// GetAlbum returns the current track album.
+func (i *Player) GetAlbum() (string, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return "", err
+	}
+	return i.metadataString(metadata, "xesam:album")
+}
+
+// GetArtURL returns the current track art url.
+func (i *Player) GetArtURL() (string, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return "", err
+	}
+	return i.metadataString(metadata, "mpris:artUrl")
+}
@@ -0,0 +1,173 @@
+package mpris
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMetadata(t *testing.T) {
+	metadata := Metadata{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(210000000)),
+		"mpris:artUrl":  dbus.MakeVariant("file:///tmp/cover.png"),
+		"xesam:title":   dbus.MakeVariant("Title"),
+		"xesam:album":   dbus.MakeVariant("Album"),
+		"xesam:artist":  dbus.MakeVariant([]string{"First", "Second"}),
+		"xesam:url":     dbus.MakeVariant("file:///tmp/song.mp3"),
+	}
+
+	if metadata.TrackID() != "/org/mpris/MediaPlayer2/Track/1" {
+		t.Errorf("Unexpected track id %s", metadata.TrackID())
+	}
+	if metadata.Length() != 3*time.Minute+30*time.Second {
+		t.Errorf("Unexpected length %s", metadata.Length())
+	}
+	if metadata.ArtURL() != "file:///tmp/cover.png" {
+		t.Errorf("Unexpected art url %s", metadata.ArtURL())
+	}
+	if metadata.Title() != "Title" || metadata.Album() != "Album" {
+		t.Errorf("Unexpected title or album %s, %s", metadata.Title(), metadata.Album())
+	}
+	if !reflect.DeepEqual(metadata.Artists(), []string{"First", "Second"}) {
+		t.Errorf("Unexpected artists %v", metadata.Artists())
+	}
+	if metadata.URL() != "file:///tmp/song.mp3" {
+		t.Errorf("Unexpected url %s", metadata.URL())
+	}
+}
+
+func TestMetadataArtistQuirks(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected []string
+	}{
+		{"Single", []string{"Single"}},
+		{[]interface{}{"First", "Second"}, []string{"First", "Second"}},
+		{int32(42), nil},
+	}
+
+	for _, c := range cases {
+		metadata := Metadata{"xesam:artist": dbus.MakeVariant(c.value)}
+		if artists := metadata.Artists(); !reflect.DeepEqual(artists, c.expected) {
+			t.Errorf("Expected %v, got %v", c.expected, artists)
+		}
+	}
+
+	if (Metadata{}).Title() != "" || Metadata(nil).Artists() != nil {
+		t.Error("Expected missing keys to return zero values")
+	}
+}
+
+func TestPlayerMetadata(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"xesam:title":  dbus.MakeVariant("Title"),
+		"xesam:artist": dbus.MakeVariant("Artist"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title, err := player.GetTitle()
+	if err != nil || title != "Title" {
+		t.Errorf("Expected title Title, got %q (%v)", title, err)
+	}
+	artists, err := player.GetArtists()
+	if err != nil || !reflect.DeepEqual(artists, []string{"Artist"}) {
+		t.Errorf("Expected artists [Artist], got %v (%v)", artists, err)
+	}
+}
+
+func TestNormalizeStrings(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected []string
+	}{
+		{"Rock", []string{"Rock"}},
+		{" ", nil},
+		{[]string{"Rock", "", " Pop "}, []string{"Rock", "Pop"}},
+		{[]interface{}{dbus.MakeVariant("Rock"), "Pop", int32(1)}, []string{"Rock", "Pop"}},
+		{[]dbus.Variant{dbus.MakeVariant("Rock")}, []string{"Rock"}},
+		{dbus.MakeVariant([]string{"Rock"}), []string{"Rock"}},
+		{int32(42), nil},
+		{nil, nil},
+	}
+	for _, c := range cases {
+		if values := NormalizeStrings(c.value); !reflect.DeepEqual(values, c.expected) {
+			t.Errorf("Expected %#v to normalize to %v, got %v", c.value, c.expected, values)
+		}
+	}
+
+	metadata := Metadata{
+		"xesam:albumArtist": dbus.MakeVariant("Album Artist"),
+		"xesam:composer":    dbus.MakeVariant([]interface{}{"Composer"}),
+		"xesam:lyricist":    dbus.MakeVariant([]string{"Lyricist"}),
+		"xesam:genre":       dbus.MakeVariant([]string{"Rock", "Pop"}),
+	}
+	if !reflect.DeepEqual(metadata.AlbumArtists(), []string{"Album Artist"}) || !reflect.DeepEqual(metadata.Composers(), []string{"Composer"}) ||
+		!reflect.DeepEqual(metadata.Lyricists(), []string{"Lyricist"}) || !reflect.DeepEqual(metadata.Genres(), []string{"Rock", "Pop"}) {
+		t.Errorf("Unexpected lists in %v", metadata)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"2010-04-01T12:30:00+02:00", time.Date(2010, 4, 1, 12, 30, 0, 0, time.FixedZone("", 2*3600))},
+		{"2010-04-01T12:30:00.5Z", time.Date(2010, 4, 1, 12, 30, 0, 5e8, time.UTC)},
+		{"2010-04-01T12:30:00+0200", time.Date(2010, 4, 1, 12, 30, 0, 0, time.FixedZone("", 2*3600))},
+		{"2010-04-01 12:30:00", time.Date(2010, 4, 1, 12, 30, 0, 0, time.UTC)},
+		{"2010-04-01T12:30", time.Date(2010, 4, 1, 12, 30, 0, 0, time.UTC)},
+		{" 2010-04-01 ", time.Date(2010, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"2010-04", time.Date(2010, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"2010", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		date, ok := ParseDate(c.value)
+		if !ok || !date.Equal(c.expected) {
+			t.Errorf("Expected %q to parse to %v, got %v %t", c.value, c.expected, date, ok)
+		}
+	}
+	for _, value := range []string{"", "yesterday", "2010-13-01"} {
+		if _, ok := ParseDate(value); ok {
+			t.Errorf("Expected %q to not parse", value)
+		}
+	}
+
+	metadata := Metadata{
+		"xesam:contentCreated": dbus.MakeVariant("1997"),
+		"xesam:firstUsed":      dbus.MakeVariant("2020-01-02T03:04:05Z"),
+		"xesam:lastUsed":       dbus.MakeVariant(int32(1)),
+	}
+	if metadata.ContentCreated().Year() != 1997 || metadata.FirstUsed().Day() != 2 || !metadata.LastUsed().IsZero() {
+		t.Errorf("Unexpected dates %v %v %v", metadata.ContentCreated(), metadata.FirstUsed(), metadata.LastUsed())
+	}
+	var dates struct {
+		Created time.Time `mpris:"xesam:contentCreated"`
+	}
+	if err := metadata.Unmarshal(&dates); err != nil || dates.Created.Year() != 1997 {
+		t.Errorf("Expected the date to be unmarshaled, got %v %v", dates.Created, err)
+	}
+}
+
+func TestMetadataTrackKey(t *testing.T) {
+	withID := Metadata{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1")),
+		"xesam:title":   dbus.MakeVariant("Song"),
+	}
+	if key := withID.TrackKey(); key != "/track/1" {
+		t.Errorf("Expected the track id as key, got %q", key)
+	}
+
+	song := Metadata{"xesam:url": dbus.MakeVariant("file:///song.mp3"), "xesam:title": dbus.MakeVariant("Song")}
+	other := Metadata{"xesam:url": dbus.MakeVariant("file:///song.mp3"), "xesam:title": dbus.MakeVariant("Other")}
+	if song.TrackKey() == other.TrackKey() {
+		t.Error("Expected the tracks without id to be told apart by their title")
+	}
+}
@@ -0,0 +1,330 @@
+// Package metrics exports the state of the MPRIS players as Prometheus metrics, for people who
+// graph their listening habits. The metrics are updated by the player events, so scraping
+// them doesn't call the players:
+//
+//	mpris_player_playback_status{player,status}  1 for the current status, 0 for the others
+//	mpris_player_volume{player}                  the volume, 1 being the full volume
+//	mpris_player_position_seconds{player}        the position in the current track
+//	mpris_player_track_length_seconds{player}    the length of the current track
+//	mpris_player_track_changes_total{player}     how many times the track changed
+//	mpris_player_seeks_total{player}             how many times the position jumped
+//
+// The player label is the player name without the org.mpris.MediaPlayer2 prefix. The
+// package doesn't depend on the Prometheus client library, the Exporter serves the metrics in
+// the text format itself:
+//
+//	exporter, err := metrics.NewExporter(conn)
+//	...
+//	http.Handle("/metrics", exporter)
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// contentType is the content type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// statuses are the playback statuses reported by mpris_player_playback_status.
+var statuses = []mpris.PlaybackStatus{mpris.PlaybackPlaying, mpris.PlaybackPaused, mpris.PlaybackStopped}
+
+// Exporter tracks the players of the bus and serves their metrics. It implements
+// http.Handler.
+type Exporter struct {
+	conn    *dbus.Conn
+	watcher *mpris.Watcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu      sync.Mutex
+	players map[string]*playerMetrics
+}
+
+// playerMetrics are the metrics of a player. The position is extrapolated from where it was
+// at positionAt, as the players don't announce its changes while playing.
+type playerMetrics struct {
+	player *mpris.Player
+	cancel context.CancelFunc
+
+	status       mpris.PlaybackStatus
+	volume       float64
+	rate         float64
+	position     time.Duration
+	positionAt   time.Time
+	length       time.Duration
+	track        string
+	trackChanges uint64
+	seeks        uint64
+}
+
+// NewExporter starts tracking the players of the bus.
+func NewExporter(conn *dbus.Conn) (*Exporter, error) {
+	watcher, err := mpris.NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Exporter{
+		conn:    conn,
+		watcher: watcher,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		players: make(map[string]*playerMetrics),
+	}
+	for _, name := range watcher.Players() {
+		e.addPlayer(name)
+	}
+	go e.run()
+	return e, nil
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	for event := range e.watcher.Events() {
+		if event.Vanished() {
+			e.removePlayer(event.Name)
+		} else if event.Appeared() {
+			e.addPlayer(event.Name)
+		}
+	}
+}
+
+func (e *Exporter) addPlayer(name string) {
+	ctx, cancel := context.WithCancel(e.ctx)
+	player := mpris.New(e.conn, name)
+	metrics := &playerMetrics{player: player, cancel: cancel}
+
+	e.mu.Lock()
+	if _, ok := e.players[name]; ok {
+		e.mu.Unlock()
+		cancel()
+		return
+	}
+	e.players[name] = metrics
+	e.mu.Unlock()
+
+	// the subscription goes first, so a change made while fetching the state isn't missed
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		return
+	}
+	if state, err := player.WithContext(ctx).GetState(); err == nil {
+		e.mu.Lock()
+		metrics.status = state.PlaybackStatus
+		metrics.volume = state.Volume
+		metrics.rate = state.Rate
+		metrics.setPosition(state.Position)
+		metrics.length = state.Metadata.Length()
+		metrics.track = state.Metadata.TrackKey()
+		e.mu.Unlock()
+	}
+	go func() {
+		for event := range events {
+			e.handleEvent(ctx, metrics, event)
+		}
+	}()
+}
+
+// handleEvent updates the metrics of the player with the event.
+func (e *Exporter) handleEvent(ctx context.Context, metrics *playerMetrics, event mpris.Event) {
+	switch event := event.(type) {
+	case mpris.SeekedEvent:
+		e.mu.Lock()
+		metrics.seeks++
+		metrics.setPosition(event.Position)
+		e.mu.Unlock()
+	case mpris.PropertiesChangedEvent:
+		if event.Interface != mpris.PlayerInterface {
+			return
+		}
+		e.mu.Lock()
+		// the position so far is kept, as the status and rate change how it goes on
+		metrics.setPosition(metrics.currentPosition(time.Now()))
+		if status, ok := event.Changed["PlaybackStatus"].Value().(string); ok {
+			metrics.status = mpris.PlaybackStatus(status)
+		}
+		if volume, ok := event.Changed["Volume"].Value().(float64); ok {
+			metrics.volume = volume
+		}
+		if rate, ok := event.Changed["Rate"].Value().(float64); ok {
+			metrics.rate = rate
+		}
+		newTrack := false
+		if value, ok := event.Changed["Metadata"].Value().(map[string]dbus.Variant); ok {
+			metadata := mpris.Metadata(value)
+			metrics.length = metadata.Length()
+			if track := metadata.TrackKey(); track != metrics.track {
+				metrics.track = track
+				metrics.trackChanges++
+				newTrack = true
+			}
+		}
+		e.mu.Unlock()
+
+		if newTrack {
+			// the new track may not start at the beginning, like when resuming it
+			position, err := metrics.player.WithContext(ctx).Player.GetPosition()
+			if err != nil {
+				return
+			}
+			e.mu.Lock()
+			metrics.setPosition(position)
+			e.mu.Unlock()
+		}
+	}
+}
+
+func (m *playerMetrics) setPosition(position time.Duration) {
+	m.position = position
+	m.positionAt = time.Now()
+}
+
+// currentPosition extrapolates the position at now, without going past the track end.
+func (m *playerMetrics) currentPosition(now time.Time) time.Duration {
+	if m.status != mpris.PlaybackPlaying {
+		return m.position
+	}
+	rate := m.rate
+	if rate == 0 {
+		rate = 1
+	}
+	position := m.position + time.Duration(float64(now.Sub(m.positionAt))*rate)
+	if m.length > 0 && position > m.length {
+		position = m.length
+	}
+	return position
+}
+
+func (e *Exporter) removePlayer(name string) {
+	e.mu.Lock()
+	metrics, ok := e.players[name]
+	delete(e.players, name)
+	e.mu.Unlock()
+	if !ok {
+		return
+	}
+	metrics.cancel()
+	metrics.player.Close()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	e.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	type sample struct {
+		player       string
+		status       mpris.PlaybackStatus
+		volume       float64
+		position     time.Duration
+		length       time.Duration
+		trackChanges uint64
+		seeks        uint64
+	}
+
+	now := time.Now()
+	e.mu.Lock()
+	samples := make([]sample, 0, len(e.players))
+	for name, metrics := range e.players {
+		samples = append(samples, sample{
+			player:       strings.TrimPrefix(name, mpris.BaseInterface+"."),
+			status:       metrics.status,
+			volume:       metrics.volume,
+			position:     metrics.currentPosition(now),
+			length:       metrics.length,
+			trackChanges: metrics.trackChanges,
+			seeks:        metrics.seeks,
+		})
+	}
+	e.mu.Unlock()
+	sort.Slice(samples, func(a, b int) bool { return samples[a].player < samples[b].player })
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	family := func(name, kind, help string, value func(s sample) string) {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range samples {
+			fmt.Fprintf(buf, "%s{player=%s} %s\n", name, labelValue(s.player), value(s))
+		}
+	}
+
+	fmt.Fprint(buf, "# HELP mpris_player_playback_status The playback status of the player, 1 for the current one.\n")
+	fmt.Fprint(buf, "# TYPE mpris_player_playback_status gauge\n")
+	for _, s := range samples {
+		for _, status := range statuses {
+			value := 0
+			if s.status == status {
+				value = 1
+			}
+			fmt.Fprintf(buf, "mpris_player_playback_status{player=%s,status=%s} %d\n", labelValue(s.player), labelValue(string(status)), value)
+		}
+	}
+	family("mpris_player_volume", "gauge", "The volume of the player, 1 being the full volume.", func(s sample) string {
+		return fmt.Sprint(s.volume)
+	})
+	family("mpris_player_position_seconds", "gauge", "The position in the current track.", func(s sample) string {
+		return fmt.Sprint(s.position.Seconds())
+	})
+	family("mpris_player_track_length_seconds", "gauge", "The length of the current track.", func(s sample) string {
+		return fmt.Sprint(s.length.Seconds())
+	})
+	family("mpris_player_track_changes_total", "counter", "How many times the track changed.", func(s sample) string {
+		return fmt.Sprint(s.trackChanges)
+	})
+	family("mpris_player_seeks_total", "counter", "How many times the position jumped.", func(s sample) string {
+		return fmt.Sprint(s.seeks)
+	})
+
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// labelValue quotes a label value, escaping it as the text format requires.
+func labelValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Close stops tracking the players. The connection is not closed.
+func (e *Exporter) Close() error {
+	err := e.watcher.Close()
+	<-e.done
+	e.cancel()
+
+	e.mu.Lock()
+	players := e.players
+	e.players = make(map[string]*playerMetrics)
+	e.mu.Unlock()
+	for _, metrics := range players {
+		metrics.player.Close()
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+func scrape(exporter *Exporter) string {
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	return recorder.Body.String()
+}
+
+func TestExporter(t *testing.T) {
+	exporter, err := NewExporter(mpristest.PrivateConn(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Close()
+
+	shortName := fmt.Sprintf("mpristest.metrics%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	label := `{player="` + shortName + `"}`
+
+	expect := func(line, message string) {
+		t.Helper()
+		mpristest.Eventually(t, func() bool {
+			return strings.Contains(scrape(exporter), line+"\n")
+		}, message+": "+scrape(exporter))
+	}
+	expect(`mpris_player_playback_status{player="`+shortName+`",status="Stopped"} 1`, "The new player wasn't exported")
+	expect("mpris_player_volume"+label+" 1", "The volume wasn't exported")
+
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(180000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect("mpris_player_track_changes_total"+label+" 1", "The track change wasn't counted")
+	expect("mpris_player_track_length_seconds"+label+" 180", "The track length wasn't exported")
+
+	if err := fake.SetPosition(30000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EmitSeeked(30000000); err != nil {
+		t.Fatal(err)
+	}
+	expect("mpris_player_seeks_total"+label+" 1", "The seek wasn't counted")
+	expect("mpris_player_position_seconds"+label+" 30", "The position wasn't exported")
+
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	expect(`mpris_player_playback_status{player="`+shortName+`",status="Playing"} 1`, "The status change wasn't exported")
+	mpristest.Eventually(t, func() bool {
+		return !strings.Contains(scrape(exporter), "mpris_player_position_seconds"+label+" 30\n")
+	}, "The position doesn't move while playing")
+
+	fake.Close()
+	mpristest.Eventually(t, func() bool {
+		return !strings.Contains(scrape(exporter), label)
+	}, "The player that quit is still exported")
+}
+
+func TestLabelValue(t *testing.T) {
+	if value := labelValue("a\"b\\c\nd"); value != `"a\"b\\c\nd"` {
+		t.Errorf("Unexpected escaping %s", value)
+	}
+}
@@ -1,38 +1,44 @@
 package mpris
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 )
 
+//go:generate go run ./internal/mprisgen -o bindings_gen.go
+
 const (
 	dbusObjectPath          = "/org/mpris/MediaPlayer2"
-	propertiesChangedSignal = "org.freedesktop.DBus.Properties.PropertiesChanged"
+	propertiesInterface     = "org.freedesktop.DBus.Properties"
+	propertiesChangedSignal = propertiesInterface + ".PropertiesChanged"
+	seekedSignal            = PlayerInterface + ".Seeked"
+	playlistChangedSignal   = PlaylistsInterface + ".PlaylistChanged"
+
+	trackAddedSignal           = TrackListInterface + ".TrackAdded"
+	trackRemovedSignal         = TrackListInterface + ".TrackRemoved"
+	trackListReplacedSignal    = TrackListInterface + ".TrackListReplaced"
+	trackMetadataChangedSignal = TrackListInterface + ".TrackMetadataChanged"
 
 	BaseInterface      = "org.mpris.MediaPlayer2"
 	PlayerInterface    = "org.mpris.MediaPlayer2.Player"
 	TrackListInterface = "org.mpris.MediaPlayer2.TrackList"
 	PlaylistsInterface = "org.mpris.MediaPlayer2.Playlists"
 
-	getPropertyMethod = "org.freedesktop.DBus.Properties.Get"
-	setPropertyMethod = "org.freedesktop.DBus.Properties.Set"
-)
-
-func getProperty(obj *dbus.Object, iface string, prop string) (dbus.Variant, error) {
-	result := dbus.Variant{}
-	err := obj.Call(getPropertyMethod, 0, iface, prop).Store(&result)
-	if err != nil {
-		return dbus.Variant{}, err
-	}
-	return result, nil
-}
+	nameHasOwnerMethod = "org.freedesktop.DBus.NameHasOwner"
+	getNameOwnerMethod = "org.freedesktop.DBus.GetNameOwner"
+	pingMethod         = "org.freedesktop.DBus.Peer.Ping"
 
-func setProperty(obj *dbus.Object, iface string, prop string, val interface{}) error {
-	call := obj.Call(setPropertyMethod, 0, iface, prop, dbus.MakeVariant(val))
-	return call.Err
-}
+	getPropertyMethod      = "org.freedesktop.DBus.Properties.Get"
+	getAllPropertiesMethod = "org.freedesktop.DBus.Properties.GetAll"
+	setPropertyMethod      = "org.freedesktop.DBus.Properties.Set"
+)
 
 func convertToMicroseconds(seconds float64) int64 {
 	return int64(seconds * 1000000)
@@ -42,6 +48,14 @@ func convertToSeconds(microseconds int64) float64 {
 	return float64(microseconds) / 1000000.0
 }
 
+func durationToMicroseconds(duration time.Duration) int64 {
+	return int64(duration / time.Microsecond)
+}
+
+func microsecondsToDuration(microseconds int64) time.Duration {
+	return time.Duration(microseconds) * time.Microsecond
+}
+
 // List lists the available players.
 func List(conn *dbus.Conn) ([]string, error) {
 	var names []string
@@ -59,11 +73,344 @@ func List(conn *dbus.Conn) ([]string, error) {
 	return mprisNames, nil
 }
 
+// PlayerInfo is a player found on the bus along with the names it can be shown with.
+type PlayerInfo struct {
+	Player       *Player
+	Name         string
+	Identity     string
+	DesktopEntry string
+}
+
+// ListPlayers lists the available players with their identity and desktop entry, fetched in a
+// single call per player. Players that quit while being listed are left out.
+func ListPlayers(conn *dbus.Conn, opts ...Option) ([]PlayerInfo, error) {
+	names, err := List(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var players []PlayerInfo
+	for _, name := range names {
+		player := New(conn, name, opts...)
+		props, err := player.getAllProperties(BaseInterface)
+		if errors.Is(err, ErrPlayerNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info := PlayerInfo{Player: player, Name: name}
+		info.Identity, _ = props["Identity"].Value().(string)
+		info.DesktopEntry, _ = props["DesktopEntry"].Value().(string)
+		players = append(players, info)
+	}
+	return players, nil
+}
+
+// playbackPriority ranks the playback statuses for FindActive, the higher the better.
+var playbackPriority = map[PlaybackStatus]int{
+	PlaybackPlaying: 3,
+	PlaybackPaused:  2,
+	PlaybackStopped: 1,
+}
+
+// FindActive returns the player most likely to be the one the user is interacting with,
+// preferring playing players over paused ones, and paused ones over stopped ones.
+// Players with the same status are ordered by the most recently connected first.
+// ErrPlayerNotFound is returned when there are no players.
+func FindActive(conn *dbus.Conn, opts ...Option) (*Player, error) {
+	names, err := List(conn)
+	if err != nil {
+		return nil, err
+	}
+	return findActive(conn, names, opts)
+}
+
+// findActive returns the most active player among the names, as FindActive does.
+func findActive(conn *dbus.Conn, names []string, opts []Option) (*Player, error) {
+	var active *Player
+	var activePriority int
+	var activeOwner string
+	for _, name := range names {
+		player := New(conn, name, opts...)
+		status, err := player.GetPlaybackStatus()
+		if errors.Is(err, ErrPlayerNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		owner, err := player.getNameOwner()
+		if errors.Is(err, ErrPlayerNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		priority := playbackPriority[status]
+		if active == nil || priority > activePriority ||
+			(priority == activePriority && compareUniqueNames(owner, activeOwner) > 0) {
+			active, activePriority, activeOwner = player, priority, owner
+		}
+	}
+
+	if active == nil {
+		return nil, ErrPlayerNotFound
+	}
+	return active, nil
+}
+
+// compareUniqueNames compares two unique bus names like ":1.42". The bus assigns them in
+// increasing order, so the greater name belongs to the most recent connection.
+func compareUniqueNames(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, ":"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, ":"), ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			return strings.Compare(aParts[i], bParts[i])
+		}
+		if aNum != bNum {
+			if aNum > bNum {
+				return 1
+			}
+			return -1
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
 // Player represents a mpris player.
+//
+// A Player is safe for concurrent use by multiple goroutines, and so are its copies made by
+// WithContext, WithFlags and NoReply, which share its signal subscriptions, property caches,
+// call statistics and muted volume. Subscriptions can be added and closed while calls are in
+// flight, and a subscription racing with Close is either refused with ErrClosed or closed
+// along with the others. The identical property reads made at the same time, by several
+// goroutines or copies, are sent once and their result is shared by the callers.
 type Player struct {
 	conn *dbus.Conn
-	obj  *dbus.Object
+	obj  dbus.BusObject
 	name string
+	ctx  context.Context
+
+	path    dbus.ObjectPath
+	timeout time.Duration
+	flags   dbus.Flags
+
+	signalBuffer int
+	dropPolicy   DropPolicy
+
+	// Base, Player, TrackList and Playlists are the clients of the MPRIS interfaces. Most of
+	// the methods of Base and Player are also available on the player itself.
+	Base      BaseClient
+	Player    PlayerClient
+	TrackList TrackListClient
+	Playlists PlaylistsClient
+
+	subscriptions *subscriptions
+	ownsConn      bool
+	decoding      decodingMode
+	logger        *slog.Logger
+	tracer        CallTracer
+	stats         *callStats
+	cache         *propertyCache
+	identity      *identityCache
+	flights       *flightGroup
+	mute          *muteState
+	owner         *ownerState
+}
+
+// WithContext returns a shallow copy of the player whose D-Bus calls are bound to ctx.
+// If ctx is canceled or times out the pending call returns with the context error,
+// so a hung player can't block the caller forever.
+func (i *Player) WithContext(ctx context.Context) *Player {
+	if ctx == nil {
+		panic("nil context")
+	}
+	player := *i
+	player.ctx = ctx
+	player.bind()
+	return &player
+}
+
+// Context returns the player's context. It defaults to context.Background.
+func (i *Player) Context() context.Context {
+	if i.ctx != nil {
+		return i.ctx
+	}
+	return context.Background()
+}
+
+// WithFlags returns a shallow copy of the player whose D-Bus calls are sent with the flags, on
+// top of the ones set with WithDefaultFlags. With dbus.FlagNoReplyExpected the commands, like
+// Next or Pause, are sent without waiting for the player to answer, so they return right away
+// but their errors are not reported. The getters, which need the answer, still wait for it.
+func (i *Player) WithFlags(flags dbus.Flags) *Player {
+	player := *i
+	player.flags |= flags
+	player.bind()
+	return &player
+}
+
+// NoReply returns a shallow copy of the player whose commands are fire-and-forget, as with
+// WithFlags(dbus.FlagNoReplyExpected). It's meant for the callers that don't care about the
+// result but about the latency, like the hotkey handlers:
+//
+//	player.NoReply().PlayPause()
+func (i *Player) NoReply() *Player {
+	return i.WithFlags(dbus.FlagNoReplyExpected)
+}
+
+func (i *Player) call(method string, args ...interface{}) *dbus.Call {
+	return i.callWithFlags(i.flags, method, args...)
+}
+
+// query calls a method whose answer is read, so never with dbus.FlagNoReplyExpected.
+func (i *Player) query(method string, args ...interface{}) *dbus.Call {
+	return i.callWithFlags(i.flags&^dbus.FlagNoReplyExpected, method, args...)
+}
+
+func (i *Player) callWithFlags(flags dbus.Flags, method string, args ...interface{}) *dbus.Call {
+	ctx, done := i.startCall(method, args)
+	call := i.obj.CallWithContext(ctx, method, flags, args...)
+	done(call)
+	return call
+}
+
+// busCall calls a method of the bus daemon about the player, like the calls of the bindings.
+func (i *Player) busCall(method string, args ...interface{}) *dbus.Call {
+	ctx, done := i.startCall(method, args)
+	call := i.busObject().CallWithContext(ctx, method, 0, args...)
+	done(call)
+	return call
+}
+
+// startCall returns the context of a call, with the timeout and the tracer, and the function
+// to call with the answered call, which maps its error and records it.
+func (i *Player) startCall(method string, args []interface{}) (context.Context, func(*dbus.Call)) {
+	ctx := i.Context()
+	cancel := func() {}
+	if i.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+	}
+	var info CallInfo
+	if i.tracer != nil || i.stats != nil {
+		info = newCallInfo(i.name, method, args)
+	}
+	var end func(time.Duration, error)
+	if i.tracer != nil {
+		ctx, end = i.tracer(ctx, info)
+	}
+	start := time.Now()
+	return ctx, func(call *dbus.Call) {
+		cancel()
+		call.Err = mapError(call.Err)
+		latency := time.Since(start)
+		if end != nil {
+			end(latency, call.Err)
+		}
+		if i.stats != nil {
+			i.stats.record(info, latency, call.Err)
+		}
+		if call.Err != nil {
+			i.debug("D-Bus call failed", "method", method, "duration", latency, "error", call.Err)
+		} else {
+			i.debug("D-Bus call", "method", method, "duration", latency)
+		}
+	}
+}
+
+func (i *Player) getProperty(iface string, prop string) (dbus.Variant, error) {
+	// the values of the property cache, kept up to date by the signals, go first
+	var generation uint64
+	if i.cache != nil && isCacheable(iface, prop) {
+		i.cache.watch(i)
+		var cached dbus.Variant
+		var ok bool
+		if cached, generation, ok = i.cache.get(iface, prop); ok {
+			return cached, nil
+		}
+	}
+	var identityGeneration uint64
+	if i.identity != nil && isIdentityProperty(iface, prop) {
+		i.followIdentityOwner()
+		var cached dbus.Variant
+		var ok bool
+		if cached, identityGeneration, ok = i.identity.get(prop); ok {
+			return cached, nil
+		}
+	}
+
+	value, shared, err := i.flights.do(i.Context(), i.flightKey(getPropertyMethod, iface, prop), func() (interface{}, error) {
+		result := dbus.Variant{}
+		err := i.query(getPropertyMethod, iface, prop).Store(&result)
+		return result, err
+	})
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	result := value.(dbus.Variant)
+	if shared {
+		// the caller that made the call cached the result
+		return result, nil
+	}
+	if i.identity != nil {
+		i.identity.store(iface, map[string]dbus.Variant{prop: result}, identityGeneration)
+	}
+	if i.cache != nil {
+		i.cache.store(iface, map[string]dbus.Variant{prop: result}, generation)
+	}
+	return result, nil
+}
+
+func (i *Player) getAllProperties(iface string) (map[string]dbus.Variant, error) {
+	var identityGeneration uint64
+	if i.identity != nil {
+		i.followIdentityOwner()
+		_, identityGeneration, _ = i.identity.get("")
+	}
+	var generation uint64
+	if i.cache != nil {
+		i.cache.watch(i)
+		_, generation, _ = i.cache.get(iface, "")
+	}
+
+	value, shared, err := i.flights.do(i.Context(), i.flightKey(getAllPropertiesMethod, iface, ""), func() (interface{}, error) {
+		var result map[string]dbus.Variant
+		err := i.query(getAllPropertiesMethod, iface).Store(&result)
+		return result, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := value.(map[string]dbus.Variant)
+	if shared {
+		// the callers get their own map, as it's theirs to change
+		return maps.Clone(result), nil
+	}
+	if i.identity != nil {
+		i.identity.store(iface, result, identityGeneration)
+	}
+	if i.cache != nil {
+		i.cache.store(iface, result, generation)
+	}
+	return result, nil
+}
+
+// flightKey returns the key of a property read, made with the flags of query.
+func (i *Player) flightKey(method, iface, prop string) flightKey {
+	return flightKey{method, iface, prop, i.flags &^ dbus.FlagNoReplyExpected}
+}
+
+func (i *Player) setProperty(iface string, prop string, val interface{}) error {
+	if i.cache != nil {
+		defer i.cache.invalidate(iface, prop)
+	}
+	return i.call(setPropertyMethod, iface, prop, dbus.MakeVariant(val)).Err
 }
 
 // GetName gets the player full name.
@@ -71,67 +418,115 @@ func (i *Player) GetName() string {
 	return i.name
 }
 
-// Raise raises player priority.
+// Conn returns the D-Bus connection of the player, or nil for the players created by
+// NewWithObject. It must not be closed while the player is in use, and it's closed by Close
+// with WithOwnedConn.
+func (i *Player) Conn() *dbus.Conn {
+	return i.conn
+}
+
+// Object returns the D-Bus object of the player, at /org/mpris/MediaPlayer2 or at the path
+// set with WithObjectPath. The calls made directly on it skip the timeout, flags, tracer and
+// statistics of the player, which Call applies.
+func (i *Player) Object() dbus.BusObject {
+	return i.obj
+}
+
+// Call calls a method of the player object, with its full name like
+// "org.mpris.MediaPlayer2.Player.Next", for the methods and interfaces the bindings don't
+// cover. The call is made like the ones of the bindings: bound to the player context and
+// timeout, sent with its flags, traced and counted, with the D-Bus errors mapped to the ones
+// of the package. The answer can be read with Store, as the call waits for it even on the
+// copies made by NoReply.
+func (i *Player) Call(method string, args ...interface{}) *dbus.Call {
+	return i.query(method, args...)
+}
+
+// Raise is a shortcut for BaseClient.Raise.
 func (i *Player) Raise() error {
-	return i.obj.Call(BaseInterface+".Raise", 0).Err
+	return i.Base.Raise()
 }
 
-// Quit closes the player.
+// Quit is a shortcut for BaseClient.Quit.
 func (i *Player) Quit() error {
-	return i.obj.Call(BaseInterface+".Quit", 0).Err
+	return i.Base.Quit()
 }
 
-// GetIdentity returns the player identity.
+// GetIdentity is a shortcut for BaseClient.GetIdentity.
 func (i *Player) GetIdentity() (string, error) {
-	value, err := getProperty(i.obj, BaseInterface, "Identity")
+	return i.Base.GetIdentity()
+}
 
-	return value.Value().(string), err
+// GetDesktopEntry is a shortcut for BaseClient.GetDesktopEntry.
+func (i *Player) GetDesktopEntry() (string, error) {
+	return i.Base.GetDesktopEntry()
 }
 
-// Next skips to the next track in the tracklist.
+// HasTrackList is a shortcut for BaseClient.HasTrackList.
+func (i *Player) HasTrackList() (bool, error) {
+	return i.Base.HasTrackList()
+}
+
+// GetSupportedUriSchemes is a shortcut for BaseClient.GetSupportedUriSchemes.
+func (i *Player) GetSupportedUriSchemes() ([]string, error) {
+	return i.Base.GetSupportedUriSchemes()
+}
+
+// GetSupportedMimeTypes is a shortcut for BaseClient.GetSupportedMimeTypes.
+func (i *Player) GetSupportedMimeTypes() ([]string, error) {
+	return i.Base.GetSupportedMimeTypes()
+}
+
+// Next is a shortcut for PlayerClient.Next.
 func (i *Player) Next() error {
-	return i.obj.Call(PlayerInterface+".Next", 0).Err
+	return i.Player.Next()
 }
 
-// Previous skips to the previous track in the tracklist.
+// Previous is a shortcut for PlayerClient.Previous.
 func (i *Player) Previous() error {
-	return i.obj.Call(PlayerInterface+".Previous", 0).Err
+	return i.Player.Previous()
 }
 
-// Pause pauses the current track.
+// Pause is a shortcut for PlayerClient.Pause.
 func (i *Player) Pause() error {
-	return i.obj.Call(PlayerInterface+".Pause", 0).Err
+	return i.Player.Pause()
 }
 
-// PlayPause resumes the current track if it's paused and pauses it if it's playing.
+// PlayPause is a shortcut for PlayerClient.PlayPause.
 func (i *Player) PlayPause() error {
-	return i.obj.Call(PlayerInterface+".PlayPause", 0).Err
+	return i.Player.PlayPause()
 }
 
-// Stop stops the current track.
+// Stop is a shortcut for PlayerClient.Stop.
 func (i *Player) Stop() error {
-	return i.obj.Call(PlayerInterface+".Stop", 0).Err
+	return i.Player.Stop()
 }
 
-// Play starts or resumes the current track.
+// Play is a shortcut for PlayerClient.Play.
 func (i *Player) Play() error {
-	return i.obj.Call(PlayerInterface+".Play", 0).Err
+	return i.Player.Play()
 }
 
 // Seek seeks the current track position by the offset. The offset should be in seconds.
 // If the offset is negative it's seeked back.
 func (i *Player) Seek(offset float64) error {
-	return i.obj.Call(PlayerInterface+".Seek", 0, convertToMicroseconds(offset)).Err
+	return i.call(PlayerInterface+".Seek", convertToMicroseconds(offset)).Err
+}
+
+// SeekBy is a shortcut for PlayerClient.Seek.
+func (i *Player) SeekBy(offset time.Duration) error {
+	return i.Player.Seek(offset)
 }
 
 // SetTrackPosition sets the position of a track. The position should be in seconds.
+// SetPositionWithTrackID is the checked alternative.
 func (i *Player) SetTrackPosition(trackId *dbus.ObjectPath, position float64) error {
-	return i.obj.Call(PlayerInterface+".SetPosition", 0, trackId, convertToMicroseconds(position)).Err
+	return i.call(PlayerInterface+".SetPosition", trackId, convertToMicroseconds(position)).Err
 }
 
-// OpenUri opens and plays the uri if supported.
+// OpenUri is a shortcut for PlayerClient.OpenUri.
 func (i *Player) OpenUri(uri string) error {
-	return i.obj.Call(PlayerInterface+".OpenUri", 0, uri).Err
+	return i.Player.OpenUri(uri)
 }
 
 // PlaybackStatus the status of the playback. It can be "Playing", "Paused" or "Stopped".
@@ -143,16 +538,9 @@ const (
 	PlaybackStopped PlaybackStatus = "Stopped"
 )
 
-// GetPlaybackStatus gets the playback status.
+// GetPlaybackStatus is a shortcut for PlayerClient.GetPlaybackStatus.
 func (i *Player) GetPlaybackStatus() (PlaybackStatus, error) {
-	variant, err := i.obj.GetProperty(PlayerInterface + ".PlaybackStatus")
-	if err != nil {
-		return "", err
-	}
-	if variant.Value() == nil {
-		return "", fmt.Errorf("Variant value is nil")
-	}
-	return PlaybackStatus(variant.Value().(string)), nil
+	return i.Player.GetPlaybackStatus()
 }
 
 // LoopStatus the status of the player loop. It can be "None", "Track" or "Playlist".
@@ -164,152 +552,311 @@ const (
 	LoopPlaylist LoopStatus = "Playlist"
 )
 
-// GetLoopStatus returns the loop status.
+// GetLoopStatus is a shortcut for PlayerClient.GetLoopStatus.
 func (i *Player) GetLoopStatus() (LoopStatus, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "LoopStatus")
-	if err != nil {
-		return LoopStatus(""), err
-	}
-	if variant.Value() == nil {
-		return "", fmt.Errorf("Variant value is nil")
-	}
-	return LoopStatus(variant.Value().(string)), nil
+	return i.Player.GetLoopStatus()
 }
 
-// SetLoopStatus sets the loop status to loopStatus.
+// SetLoopStatus is a shortcut for PlayerClient.SetLoopStatus.
 func (i *Player) SetLoopStatus(loopStatus LoopStatus) error {
-	return i.SetPlayerProperty("LoopStatus", loopStatus)
+	return i.Player.SetLoopStatus(loopStatus)
+}
+
+// nextLoopStatus is the order followed by CycleLoopStatus.
+var nextLoopStatus = map[LoopStatus]LoopStatus{
+	LoopNone:     LoopPlaylist,
+	LoopPlaylist: LoopTrack,
+	LoopTrack:    LoopNone,
+}
+
+// CycleLoopStatus sets the loop status that follows the current one, going from "None" to
+// "Playlist", "Track" and back to "None". It returns the new loop status.
+func (i *Player) CycleLoopStatus() (LoopStatus, error) {
+	current, err := i.GetLoopStatus()
+	if err != nil {
+		return "", err
+	}
+	next, ok := nextLoopStatus[current]
+	if !ok {
+		next = LoopNone
+	}
+	return next, i.SetLoopStatus(next)
 }
 
 // SetProperty sets the value of a propertyName in the targetInterface.
 func (i *Player) SetProperty(targetInterface, propertyName string, value interface{}) error {
-	return setProperty(i.obj, targetInterface, propertyName, value)
+	return i.setProperty(targetInterface, propertyName, value)
 }
 
 // SetPlayerProperty sets the propertyName from the player interface.
 func (i *Player) SetPlayerProperty(propertyName string, value interface{}) error {
-	return setProperty(i.obj, PlayerInterface, propertyName, value)
+	return i.setProperty(PlayerInterface, propertyName, value)
 }
 
 // GetProperty returns the properityName in the targetInterface.
 func (i *Player) GetProperty(targetInterface, properityName string) (dbus.Variant, error) {
-	return getProperty(i.obj, targetInterface, properityName)
+	return i.getProperty(targetInterface, properityName)
 }
 
 // GetPlayerProperty returns the properityName from the player interface.
 func (i *Player) GetPlayerProperty(properityName string) (dbus.Variant, error) {
-	return getProperty(i.obj, PlayerInterface, properityName)
+	return i.getProperty(PlayerInterface, properityName)
 }
 
-// Returns the current playback rate.
+// GetRate is a shortcut for PlayerClient.GetRate.
 func (i *Player) GetRate() (float64, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "Rate")
-	if err != nil {
-		return 0.0, err
-	}
-	if variant.Value() == nil {
-		return 0.0, fmt.Errorf("Variant value is nil")
-	}
-	return variant.Value().(float64), nil
+	return i.Player.GetRate()
 }
 
-// GetShuffle returns false if the player is going linearly through a playlist and false if it's
-// in some other order.
+// GetShuffle is a shortcut for PlayerClient.GetShuffle.
 func (i *Player) GetShuffle() (bool, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "Shuffle")
-	if err != nil {
-		return false, err
-	}
-	if variant.Value() == nil {
-		return false, fmt.Errorf("Variant value is nil")
-	}
-	return variant.Value().(bool), nil
+	return i.Player.GetShuffle()
 }
 
-// SetShuffle sets the shuffle playlist mode.
+// SetShuffle is a shortcut for PlayerClient.SetShuffle.
 func (i *Player) SetShuffle(value bool) error {
-	return setProperty(i.obj, PlayerInterface, "Shuffle", value)
+	return i.Player.SetShuffle(value)
 }
 
-// GetMetadata returns the metadata.
-func (i *Player) GetMetadata() (map[string]dbus.Variant, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "Metadata")
+// ToggleShuffle enables the shuffle if it's disabled and disables it otherwise. It returns the
+// new shuffle value.
+func (i *Player) ToggleShuffle() (bool, error) {
+	shuffle, err := i.GetShuffle()
 	if err != nil {
-		return nil, err
-	}
-	if variant.Value() == nil {
-		return nil, fmt.Errorf("Variant value is nil")
+		return false, err
 	}
-	return variant.Value().(map[string]dbus.Variant), nil
+	return !shuffle, i.SetShuffle(!shuffle)
+}
+
+// GetMetadata is a shortcut for PlayerClient.GetMetadata.
+func (i *Player) GetMetadata() (Metadata, error) {
+	return i.Player.GetMetadata()
 }
 
-// GetVolume returns the volume.
+// GetVolume is a shortcut for PlayerClient.GetVolume.
 func (i *Player) GetVolume() (float64, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "Volume")
-	if err != nil {
-		return 0.0, err
-	}
-	if variant.Value() == nil {
-		return 0.0, fmt.Errorf("Variant value is nil")
-	}
-	return variant.Value().(float64), nil
+	return i.Player.GetVolume()
 }
 
-// SetVolume sets the volume.
+// SetVolume is a shortcut for PlayerClient.SetVolume.
 func (i *Player) SetVolume(volume float64) error {
-	return setProperty(i.obj, PlayerInterface, "Volume", volume)
+	return i.Player.SetVolume(volume)
+}
+
+func (i *Player) getLength() (int64, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return 0, err
+	}
+	return i.int64Value(metadata["mpris:length"], "mpris:length")
 }
 
 // GetLength returns the current track length in seconds.
 func (i *Player) GetLength() (float64, error) {
-	metadata, err := i.GetMetadata()
+	length, err := i.getLength()
 	if err != nil {
 		return 0.0, err
 	}
-	if metadata == nil || metadata["mpris:length"].Value() == nil {
-		return 0.0, fmt.Errorf("Variant value is nil")
+	return convertToSeconds(length), nil
+}
+
+// GetLengthDuration returns the current track length.
+func (i *Player) GetLengthDuration() (time.Duration, error) {
+	length, err := i.getLength()
+	if err != nil {
+		return 0, err
+	}
+	return microsecondsToDuration(length), nil
+}
+
+func (i *Player) getPosition() (int64, error) {
+	variant, err := i.getProperty(PlayerInterface, "Position")
+	if err != nil {
+		return 0, err
 	}
-	return convertToSeconds(metadata["mpris:length"].Value().(int64)), nil
+	return i.int64Value(variant, "Position")
 }
 
 // GetPosition returns the position in seconds of the current track.
 func (i *Player) GetPosition() (float64, error) {
-	variant, err := getProperty(i.obj, PlayerInterface, "Position")
+	position, err := i.getPosition()
 	if err != nil {
 		return 0.0, err
 	}
-	if variant.Value() == nil {
-		return 0.0, fmt.Errorf("Variant value is nil")
+	return convertToSeconds(position), nil
+}
+
+// GetPositionDuration is a shortcut for PlayerClient.GetPosition.
+func (i *Player) GetPositionDuration() (time.Duration, error) {
+	return i.Player.GetPosition()
+}
+
+// currentTrackID returns the track id of the current track. With WithPropertyCache, the
+// metadata kept up to date by the signals is used instead of asking the player.
+func (i *Player) currentTrackID() (TrackID, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return "", err
 	}
-	return convertToSeconds(variant.Value().(int64)), nil
+	return trackIDValue(metadata["mpris:trackid"])
 }
 
 // SetPosition sets the position of the current track. The position should be in seconds.
+// The track id is read from the metadata, which takes an extra round trip unless the property
+// cache is enabled. Use SetPositionWithTrackID when the track id is already known.
 func (i *Player) SetPosition(position float64) error {
-	metadata, err := i.GetMetadata()
+	trackID, err := i.currentTrackID()
 	if err != nil {
 		return err
 	}
-	if metadata == nil || metadata["mpris:trackid"].Value() == nil {
-		return fmt.Errorf("Variant value is nil")
+	return i.SetPositionWithTrackID(trackID, time.Duration(position*float64(time.Second)))
+}
+
+// SeekTo sets the position of the current track, like SetPosition.
+func (i *Player) SeekTo(position time.Duration) error {
+	trackID, err := i.currentTrackID()
+	if err != nil {
+		return err
 	}
-	trackId := metadata["mpris:trackid"].Value().(dbus.ObjectPath)
-	i.SetTrackPosition(&trackId, position)
-	return nil
+	return i.SetPositionWithTrackID(trackID, position)
+}
+
+// SetPositionWithTrackID is a shortcut for PlayerClient.SetPosition.
+func (i *Player) SetPositionWithTrackID(trackID TrackID, position time.Duration) error {
+	return i.Player.SetPosition(trackID, position)
 }
 
-// New connects the the player with the name in the connection conn.
-func New(conn *dbus.Conn, name string) *Player {
-	obj := conn.Object(name, dbusObjectPath).(*dbus.Object)
+// New connects the the player with the name in the connection conn, configured by opts.
+func New(conn *dbus.Conn, name string, opts ...Option) *Player {
+	player := newPlayer(conn, name, opts)
+	player.obj = conn.Object(name, player.path)
+	player.bind()
 
-	return &Player{conn, obj, name}
+	return player
 }
 
-// OnSignal adds a handler to the player's properties change signal.
-func (i *Player) OnSignal(ch chan<- *dbus.Signal) (err error) {
-	err = i.conn.AddMatchSignal()
-	if err == nil {
-		i.conn.Signal(ch)
+// NewWithObject returns a player making its calls on obj instead of a connection, like the
+// in-memory object of the mprismem package, so the code using it can be tested without a bus.
+// The name and path of the player are the ones of obj. The calls to the bus daemon, like
+// Exists, are made on obj too, and the signals can't be subscribed to, ErrNoConnection being
+// returned instead.
+func NewWithObject(obj dbus.BusObject, opts ...Option) *Player {
+	player := newPlayer(nil, obj.Destination(), opts)
+	player.path = obj.Path()
+	player.obj = obj
+	player.bind()
+
+	return player
+}
+
+func newPlayer(conn *dbus.Conn, name string, opts []Option) *Player {
+	player := &Player{
+		conn:          conn,
+		name:          name,
+		path:          dbusObjectPath,
+		signalBuffer:  defaultSignalBuffer,
+		subscriptions: newSubscriptions(),
+		mute:          &muteState{},
+		owner:         &ownerState{},
+		flights:       newFlightGroup(),
+	}
+	for _, opt := range opts {
+		opt(player)
+	}
+	player.linkCaches()
+	return player
+}
+
+// bind points the interface clients to the player, which must be done by every copy.
+func (i *Player) bind() {
+	i.Base = BaseClient{i}
+	i.Player = PlayerClient{i}
+	i.TrackList = TrackListClient{i}
+	i.Playlists = PlaylistsClient{i}
+}
+
+// withName returns a player with the same configuration but another name. The new player has
+// its own subscriptions and state, and never owns the connection.
+func (i *Player) withName(name string) *Player {
+	player := *i
+	player.name = name
+	player.obj = i.conn.Object(name, i.path)
+	player.subscriptions = newSubscriptions()
+	player.mute = &muteState{}
+	player.owner = &ownerState{}
+	player.flights = newFlightGroup()
+	player.ownsConn = false
+	if i.cache != nil {
+		player.cache = newPropertyCache(i.cache.ttl)
+	}
+	if i.identity != nil {
+		player.identity = newIdentityCache(i.identity.ttl)
+	}
+	player.linkCaches()
+	if i.stats != nil {
+		player.stats = newCallStats()
+	}
+	player.bind()
+	return &player
+}
+
+// NewChecked connects to the player like New, but first checks that the name has an owner on
+// the bus, returning ErrPlayerNotFound otherwise.
+func NewChecked(conn *dbus.Conn, name string, opts ...Option) (*Player, error) {
+	player := New(conn, name, opts...)
+	exists, err := player.Exists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPlayerNotFound
+	}
+	return player, nil
+}
+
+// Exists returns true if the player name has an owner on the bus.
+func (i *Player) Exists() (bool, error) {
+	var exists bool
+	err := i.busCall(nameHasOwnerMethod, i.name).Store(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// busObject returns the object of the bus daemon, or the object of the player when it has no
+// connection.
+func (i *Player) busObject() dbus.BusObject {
+	if i.conn == nil {
+		return i.obj
+	}
+	return i.conn.BusObject()
+}
+
+func (i *Player) getNameOwner() (string, error) {
+	var owner string
+	err := i.busCall(getNameOwnerMethod, i.name).Store(&owner)
+	if err != nil {
+		return "", err
+	}
+	return owner, nil
+}
+
+// Ping checks that the player is reachable and answering calls.
+func (i *Player) Ping() error {
+	return i.query(pingMethod).Err
+}
+
+// Close closes the signal subscriptions of the player, removing their match rules, and the
+// connection if it's owned by the player. The copies made by WithContext share the
+// subscriptions, so closing one of them closes them all. The player can still be used for
+// calls, unless its connection was closed.
+func (i *Player) Close() error {
+	err := i.closeSubscriptions()
+	if i.ownsConn && i.conn != nil {
+		if closeErr := i.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
 	}
-	return
+	return err
 }
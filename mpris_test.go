@@ -1,12 +1,23 @@
 package mpris
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Pauloo27/go-mpris/mprismem"
+	"github.com/Pauloo27/go-mpris/mpristest"
 	"github.com/godbus/dbus/v5"
 )
 
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
 func checkVolume(t *testing.T, player *Player) {
 	volume, err := player.GetVolume()
 
@@ -60,30 +71,420 @@ func checkLoop(t *testing.T, player *Player) {
 	player.SetLoopStatus(loopStatus)
 }
 
-func TestPlayer(t *testing.T) {
-	conn, err := dbus.SessionBus()
+func checkBase(t *testing.T, player *Player) {
+	hasTrackList, err := player.HasTrackList()
 	if err != nil {
 		t.Error(err)
 		return
 	}
+	t.Logf("Player has track list: %v", hasTrackList)
+
+	desktopEntry, err := player.GetDesktopEntry()
+	if err != nil {
+		t.Logf("Player has no desktop entry: %v", err)
+		return
+	}
+	t.Logf("Player desktop entry is %s", desktopEntry)
+}
 
-	names, err := List(conn)
+func checkSupported(t *testing.T, player *Player) {
+	schemes, err := player.GetSupportedUriSchemes()
 	if err != nil {
 		t.Error(err)
 		return
 	}
+	t.Logf("Player supported uri schemes are %v", schemes)
 
-	if len(names) == 0 {
-		t.Error("No players found")
+	mimeTypes, err := player.GetSupportedMimeTypes()
+	if err != nil {
+		t.Error(err)
 		return
 	}
+	t.Logf("Player supported mime types are %v", mimeTypes)
+}
+
+var testPlayerCount uint32
+
+// newTestPlayer exports a fake player on its own connection to the session bus and returns a
+// client for it, using the shared session bus connection.
+func newTestPlayer(t *testing.T) (*Player, *mpristest.Player) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		t.Skip(err)
+	}
 
-	name := names[0]
-	t.Logf("Found player %s", name)
+	id := atomic.AddUint32(&testPlayerCount, 1)
+	fake, err := mpristest.New(mpristest.PrivateConn(t), fmt.Sprintf("mpristest.instance%d_%d", os.Getpid(), id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.Close() })
 
-	player := New(conn, name)
+	return New(conn, fake.Name()), fake
+}
+
+func TestPlayer(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	names, err := List(player.conn)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	found := false
+	for _, name := range names {
+		found = found || name == fake.Name()
+	}
+	if !found {
+		t.Errorf("Player %s not listed in %v", fake.Name(), names)
+		return
+	}
+
+	t.Logf("Found player %s", player.GetName())
 
 	t.Run("Playback", func(t *testing.T) { checkPlayback(t, player) })
 	t.Run("Loop", func(t *testing.T) { checkLoop(t, player) })
 	t.Run("Volume", func(t *testing.T) { checkVolume(t, player) })
+	t.Run("Base", func(t *testing.T) { checkBase(t, player) })
+	t.Run("Supported", func(t *testing.T) { checkSupported(t, player) })
+}
+
+func TestDurationConversion(t *testing.T) {
+	duration := 3*time.Minute + 25*time.Second + 120*time.Microsecond
+
+	microseconds := durationToMicroseconds(duration)
+	if microseconds != 205000120 {
+		t.Errorf("Expected 205000120 microseconds, got %d", microseconds)
+	}
+
+	if converted := microsecondsToDuration(microseconds); converted != duration {
+		t.Errorf("Expected %s, got %s", duration, converted)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	player := New(conn, BaseInterface+".gompris").WithContext(ctx)
+	if player.Context() != ctx {
+		t.Error("Player context was not replaced")
+	}
+
+	err = player.Play()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	// the interface clients of the copy are bound to the context too
+	err = player.Player.Next()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	checked, err := NewChecked(player.conn, fake.Name(), WithStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checked.Ping(); err != nil {
+		t.Error(err)
+	}
+	if stats := checked.Stats()[nameHasOwnerMethod]; stats.Calls != 1 {
+		t.Errorf("Expected the check to be counted like the other calls, got %d calls", stats.Calls)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := checked.WithContext(ctx).Exists(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the check to use the context of the player, got %v", err)
+	}
+
+	_, err = NewChecked(player.conn, BaseInterface+".gompris.missing")
+	if !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+
+	missing := New(player.conn, BaseInterface+".gompris.missing")
+	if err := missing.Ping(); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+	if err := missing.NoReply().Ping(); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected the ping of a NoReply copy to wait for the answer, got %v", err)
+	}
+}
+
+func TestSetPosition(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	trackID := TrackID("/org/mpris/MediaPlayer2/Track/1")
+	err := fake.SetMetadata(map[string]dbus.Variant{"mpris:trackid": dbus.MakeVariant(trackID.ObjectPath())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.SetPosition(2); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 2000000 {
+		t.Errorf("Expected the position to be 2s, got %dµs", fake.Position())
+	}
+
+	if err := player.SetPositionWithTrackID(trackID, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SetPositionWithTrackID("/org/mpris/MediaPlayer2/Track/2", 4*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 3000000 {
+		t.Errorf("Expected the stale track id to be ignored, got %dµs", fake.Position())
+	}
+	if err := player.SetPositionWithTrackID(NoTrack, 0); !errors.Is(err, ErrInvalidTrackID) {
+		t.Errorf("Expected ErrInvalidTrackID, got %v", err)
+	}
+
+	fake.HandleFunc(PlayerInterface, "SetPosition", func(args ...interface{}) *dbus.Error {
+		return dbus.MakeFailedError(errors.New("nope"))
+	})
+	if err := player.SetPosition(5); err == nil {
+		t.Error("Expected the call error to be returned")
+	}
+
+	if err := fake.SetMetadata(map[string]dbus.Variant{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SetPosition(1); !errors.Is(err, ErrNilVariant) {
+		t.Errorf("Expected ErrNilVariant without a track id, got %v", err)
+	}
+}
+
+func TestCycleLoopStatus(t *testing.T) {
+	player, _ := newTestPlayer(t)
+
+	for _, expected := range []LoopStatus{LoopPlaylist, LoopTrack, LoopNone} {
+		status, err := player.CycleLoopStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != expected {
+			t.Errorf("Expected %s, got %s", expected, status)
+		}
+		if current, _ := player.GetLoopStatus(); current != expected {
+			t.Errorf("Expected the player loop status to be %s, got %s", expected, current)
+		}
+	}
+}
+
+func TestToggleShuffle(t *testing.T) {
+	player, _ := newTestPlayer(t)
+
+	for _, expected := range []bool{true, false} {
+		shuffle, err := player.ToggleShuffle()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current, _ := player.GetShuffle(); shuffle != expected || current != expected {
+			t.Errorf("Expected the shuffle to be %t, got %t and %t", expected, shuffle, current)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	_, fake := newTestPlayer(t)
+	conn := mpristest.PrivateConn(t)
+	player := New(conn, fake.Name(), WithOwnedConn())
+
+	events, err := player.WithContext(context.Background()).Subscribe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no more events")
+		}
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for the channel to be closed")
+	}
+
+	if _, err := player.OnSignal(make(chan *dbus.Signal)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if err := player.Ping(); !errors.Is(err, dbus.ErrClosed) {
+		t.Errorf("Expected the owned connection to be closed, got %v", err)
+	}
+}
+
+func TestListPlayers(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	players, err := ListPlayers(player.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range players {
+		if info.Name != fake.Name() {
+			continue
+		}
+		if info.Identity != "mpristest" || info.DesktopEntry != "mpristest" {
+			t.Errorf("Unexpected identity %q and desktop entry %q", info.Identity, info.DesktopEntry)
+		}
+		if info.Player.GetName() != fake.Name() {
+			t.Errorf("Unexpected player name %s", info.Player.GetName())
+		}
+		return
+	}
+	t.Errorf("Player %s not listed", fake.Name())
+}
+
+func TestCompareUniqueNames(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{":1.42", ":1.42", 0},
+		{":1.100", ":1.42", 1},
+		{":1.9", ":1.42", -1},
+		{":2.1", ":1.42", 1},
+	}
+
+	for _, c := range cases {
+		result := compareUniqueNames(c.a, c.b)
+		if (result > 0) != (c.expected > 0) || (result < 0) != (c.expected < 0) {
+			t.Errorf("Expected compare(%s, %s) to be %d, got %d", c.a, c.b, c.expected, result)
+		}
+	}
+}
+
+func TestFindActive(t *testing.T) {
+	player, _ := newTestPlayer(t)
+	_, playing := newTestPlayer(t)
+
+	if err := playing.SetPlaybackStatus(string(PlaybackPlaying)); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := FindActive(player.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := active.GetPlaybackStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != PlaybackPlaying {
+		t.Errorf("Expected a playing player, got %s with status %s", active.GetName(), status)
+	}
+}
+
+func TestNewWithObject(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.mem")
+	obj.Set(PlayerInterface, "Metadata", map[string]dbus.Variant{
+		"mpris:length": dbus.MakeVariant(uint64(90000000)),
+	})
+	obj.Set(PlayerInterface, "Volume", int32(1))
+	player := NewWithObject(obj, WithCallTimeout(50*time.Millisecond))
+
+	if player.GetName() != "org.mpris.MediaPlayer2.mem" {
+		t.Errorf("Expected the name of the object, got %s", player.GetName())
+	}
+	metadata, err := player.GetMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if length := metadata.Length(); length != 90*time.Second {
+		t.Errorf("Expected a uint64 length of 1m30s, got %s", length)
+	}
+	if volume, err := player.GetVolume(); err != nil || volume != 1 {
+		t.Errorf("Expected an int32 volume of 1, got %f (%v)", volume, err)
+	}
+	if _, err := player.GetRate(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for a missing property, got %v", err)
+	}
+
+	if err := player.SetVolume(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if volume, _ := player.GetVolume(); volume != 0.5 {
+		t.Errorf("Expected the volume to be set, got %f", volume)
+	}
+	if owner, err := player.Owner(); err != nil || owner != mprismem.UniqueName {
+		t.Errorf("Expected the owner %s, got %s (%v)", mprismem.UniqueName, owner, err)
+	}
+	if _, err := player.OnSignal(make(chan *dbus.Signal)); !errors.Is(err, ErrNoConnection) {
+		t.Errorf("Expected ErrNoConnection when subscribing, got %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	obj.Handle(PlayerInterface+".Next", func(args ...interface{}) ([]interface{}, *dbus.Error) {
+		<-release
+		return nil, nil
+	})
+	if err := player.Next(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the call timeout, got %v", err)
+	}
+}
+
+func TestFullscreen(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.fullscreen")
+	player := NewWithObject(obj)
+
+	if _, err := player.Base.CanSetFullscreen(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported without the optional property, got %v", err)
+	}
+	obj.Set(BaseInterface, "CanSetFullscreen", true)
+	obj.Set(BaseInterface, "Fullscreen", false)
+	if can, err := player.Base.CanSetFullscreen(); err != nil || !can {
+		t.Errorf("Expected the fullscreen to be settable, got %v (%v)", can, err)
+	}
+	if err := player.Base.SetFullscreen(true); err != nil {
+		t.Fatal(err)
+	}
+	if fullscreen, err := player.Base.GetFullscreen(); err != nil || !fullscreen {
+		t.Errorf("Expected the player to be fullscreen, got %v (%v)", fullscreen, err)
+	}
+}
+
+func TestRawAccess(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.mem")
+	obj.Set(BaseInterface, "Identity", "Mem")
+	obj.Handle("org.example.Custom.Echo", func(args ...interface{}) ([]interface{}, *dbus.Error) {
+		return args, nil
+	})
+	player := NewWithObject(obj, WithStats())
+
+	if player.Conn() != nil {
+		t.Errorf("Expected no connection for a player created with an object")
+	}
+	if player.Object() != obj {
+		t.Errorf("Expected the object of the player")
+	}
+
+	var echoed string
+	if err := player.Call("org.example.Custom.Echo", "hello").Store(&echoed); err != nil || echoed != "hello" {
+		t.Errorf("Expected the custom method to echo hello, got %s %v", echoed, err)
+	}
+	if err := player.NoReply().Call("org.example.Custom.Echo", "quiet").Store(&echoed); err != nil || echoed != "quiet" {
+		t.Errorf("Expected the call of a NoReply copy to be answered, got %s %v", echoed, err)
+	}
+	var identity dbus.Variant
+	if err := player.Call(getPropertyMethod, BaseInterface, "Identity").Store(&identity); err != nil || identity.Value() != "Mem" {
+		t.Errorf("Expected the identity Mem, got %v %v", identity, err)
+	}
+	if stats := player.Stats()["org.example.Custom.Echo"]; stats.Calls != 2 {
+		t.Errorf("Expected the raw call to be counted, got %d calls", stats.Calls)
+	}
 }
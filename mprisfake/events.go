@@ -0,0 +1,236 @@
+package mprisfake
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// subscriber queues the events of a subscription, so emitting never blocks on a slow reader.
+type subscriber struct {
+	mu     sync.Mutex
+	queue  []mpris.Event
+	notify chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *subscriber) push(event mpris.Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, event)
+	s.mu.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) pop() []mpris.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := s.queue
+	s.queue = nil
+	return queue
+}
+
+func (s *subscriber) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Subscribe returns a channel receiving the changes made to the player, until ctx is done or
+// the player is closed. The events are shaped by opts like the ones of mpris.Player.Subscribe,
+// the initial state of mpris.WithInitialState being the properties of the player interface.
+func (p *Player) Subscribe(ctx context.Context, opts ...mpris.SubscribeOption) (<-chan mpris.Event, error) {
+	sub := &subscriber{notify: make(chan struct{}, 1), done: make(chan struct{})}
+	p.mu.Lock()
+	if p.gone {
+		p.mu.Unlock()
+		return nil, mpris.ErrPlayerNotFound
+	}
+	p.subscribers[sub] = struct{}{}
+	p.mu.Unlock()
+	unsubscribe := func() {
+		p.mu.Lock()
+		delete(p.subscribers, sub)
+		p.mu.Unlock()
+	}
+
+	emitted := make(chan mpris.Event)
+	events, err := mpris.ShapeEvents(ctx, emitted, p.playerProperties, opts...)
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+	go func() {
+		defer close(emitted)
+		defer unsubscribe()
+
+		send := func() bool {
+			for _, event := range sub.pop() {
+				select {
+				case emitted <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.done:
+				// the events emitted before closing are still sent
+				send()
+				return
+			case <-sub.notify:
+				if !send() {
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// playerProperties returns the properties of the player interface, sent first by the
+// subscriptions with mpris.WithInitialState.
+func (p *Player) playerProperties() (map[string]dbus.Variant, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gone {
+		return nil, mpris.ErrPlayerNotFound
+	}
+	values := make(map[string]dbus.Variant, len(p.props[mpris.PlayerInterface]))
+	for name, value := range p.props[mpris.PlayerInterface] {
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Events returns the player events as an iterator, like mpris.Player.Events.
+func (p *Player) Events(ctx context.Context, opts ...mpris.SubscribeOption) iter.Seq[mpris.Event] {
+	return func(yield func(mpris.Event) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := p.Subscribe(ctx, opts...)
+		if err != nil {
+			return
+		}
+		for event := range events {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// OnTrackChange returns a channel receiving an event every time the metadata is set to
+// another track, until ctx is done.
+func (p *Player) OnTrackChange(ctx context.Context) (<-chan mpris.TrackChangeEvent, error) {
+	current := p.currentTrackID()
+	events, err := p.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan mpris.TrackChangeEvent)
+	go func() {
+		defer close(changes)
+		for event := range events {
+			changed, ok := event.(mpris.PropertiesChangedEvent)
+			if !ok || changed.Interface != mpris.PlayerInterface {
+				continue
+			}
+			value, ok := changed.Changed["Metadata"].Value().(map[string]dbus.Variant)
+			if !ok {
+				continue
+			}
+			metadata := mpris.Metadata(value)
+			if metadata.TrackID() == current {
+				continue
+			}
+			current = metadata.TrackID()
+			select {
+			case changes <- mpris.TrackChangeEvent{Metadata: metadata}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// TrackChanges returns the track changes as an iterator.
+func (p *Player) TrackChanges(ctx context.Context) iter.Seq[mpris.TrackChangeEvent] {
+	return func(yield func(mpris.TrackChangeEvent) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		changes, err := p.OnTrackChange(ctx)
+		if err != nil {
+			return
+		}
+		for change := range changes {
+			if !yield(change) {
+				return
+			}
+		}
+	}
+}
+
+// WaitForStatus blocks until the playback status is the status.
+func (p *Player) WaitForStatus(ctx context.Context, status mpris.PlaybackStatus) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := p.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := p.GetPlaybackStatus()
+	if err != nil {
+		return err
+	}
+	if current == status {
+		return nil
+	}
+
+	for event := range events {
+		changed, ok := event.(mpris.PropertiesChangedEvent)
+		if !ok || changed.Interface != mpris.PlayerInterface {
+			continue
+		}
+		if value, ok := changed.Changed["PlaybackStatus"].Value().(string); ok && mpris.PlaybackStatus(value) == status {
+			return nil
+		}
+	}
+	return closedError(ctx)
+}
+
+// WaitForTrackChange blocks until the metadata is set to another track and returns it.
+func (p *Player) WaitForTrackChange(ctx context.Context) (mpris.Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes, err := p.OnTrackChange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	change, ok := <-changes
+	if !ok {
+		return nil, closedError(ctx)
+	}
+	return change.Metadata, nil
+}
+
+// closedError returns why an event stream was closed, like for the real players.
+func closedError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbus.ErrClosed
+}
@@ -0,0 +1,733 @@
+// Package mprisfake provides an in-memory implementation of mpris.PlayerAPI, so code
+// controlling players can be unit tested without a D-Bus session.
+package mprisfake
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeOwner is the unique name reported by Owner.
+const fakeOwner = ":fake"
+
+// Call is a method call received by the fake player.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Player is a fake player that keeps its properties in memory. The commands change the
+// properties like a real player would, and every change is sent to the subscribers.
+type Player struct {
+	name string
+
+	mu          sync.Mutex
+	props       map[string]map[string]dbus.Variant
+	calls       []Call
+	errors      map[string]error
+	subscribers map[*subscriber]struct{}
+	muteVolume  float64
+	gone        bool
+}
+
+var _ mpris.PlayerAPI = (*Player)(nil)
+
+// New returns a stopped fake player named org.mpris.MediaPlayer2.<name>, with no current
+// track.
+func New(name string) *Player {
+	return &Player{
+		name: mpris.BaseInterface + "." + name,
+		props: map[string]map[string]dbus.Variant{
+			mpris.BaseInterface: {
+				"CanQuit":             dbus.MakeVariant(true),
+				"CanRaise":            dbus.MakeVariant(true),
+				"HasTrackList":        dbus.MakeVariant(false),
+				"Identity":            dbus.MakeVariant(name),
+				"DesktopEntry":        dbus.MakeVariant(name),
+				"SupportedUriSchemes": dbus.MakeVariant([]string{"file"}),
+				"SupportedMimeTypes":  dbus.MakeVariant([]string{"audio/mpeg"}),
+			},
+			mpris.PlayerInterface: {
+				"PlaybackStatus": dbus.MakeVariant(string(mpris.PlaybackStopped)),
+				"LoopStatus":     dbus.MakeVariant(string(mpris.LoopNone)),
+				"Rate":           dbus.MakeVariant(1.0),
+				"Shuffle":        dbus.MakeVariant(false),
+				"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+					"mpris:trackid": dbus.MakeVariant(mpris.NoTrack.ObjectPath()),
+				}),
+				"Volume":        dbus.MakeVariant(1.0),
+				"Position":      dbus.MakeVariant(int64(0)),
+				"MinimumRate":   dbus.MakeVariant(1.0),
+				"MaximumRate":   dbus.MakeVariant(1.0),
+				"CanGoNext":     dbus.MakeVariant(true),
+				"CanGoPrevious": dbus.MakeVariant(true),
+				"CanPlay":       dbus.MakeVariant(true),
+				"CanPause":      dbus.MakeVariant(true),
+				"CanSeek":       dbus.MakeVariant(true),
+				"CanControl":    dbus.MakeVariant(true),
+			},
+		},
+		errors:      make(map[string]error),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Calls returns the method calls received so far, in order. Property reads are not recorded.
+func (p *Player) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// SetError makes the method, like "Next", return err. A nil err restores the default
+// behavior.
+func (p *Player) SetError(method string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.errors, method)
+	} else {
+		p.errors[method] = err
+	}
+}
+
+// Set changes a property and sends the change to the subscribers, except for Position that
+// players don't announce.
+func (p *Player) Set(iface, name string, value interface{}) {
+	variant, ok := value.(dbus.Variant)
+	if !ok {
+		variant = dbus.MakeVariant(value)
+	}
+
+	p.mu.Lock()
+	if p.props[iface] == nil {
+		p.props[iface] = make(map[string]dbus.Variant)
+	}
+	p.props[iface][name] = variant
+	p.mu.Unlock()
+
+	if iface == mpris.PlayerInterface && name == "Position" {
+		return
+	}
+	p.Emit(mpris.PropertiesChangedEvent{
+		Interface: iface,
+		Changed:   map[string]dbus.Variant{name: variant},
+	})
+}
+
+// SetPlaybackStatus sets the playback status.
+func (p *Player) SetPlaybackStatus(status mpris.PlaybackStatus) {
+	p.Set(mpris.PlayerInterface, "PlaybackStatus", string(status))
+}
+
+// SetMetadata replaces the current track metadata.
+func (p *Player) SetMetadata(metadata mpris.Metadata) {
+	p.Set(mpris.PlayerInterface, "Metadata", map[string]dbus.Variant(metadata))
+}
+
+// SetPositionDuration sets the current track position without sending any event.
+func (p *Player) SetPositionDuration(position time.Duration) {
+	p.Set(mpris.PlayerInterface, "Position", position.Microseconds())
+}
+
+// Emit sends the event to the subscribers.
+func (p *Player) Emit(event mpris.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for sub := range p.subscribers {
+		sub.push(event)
+	}
+}
+
+// Vanish makes the player leave, as if it quit. The subscribers get an OwnerChangedEvent
+// before their subscriptions are closed, and the following calls return
+// mpris.ErrPlayerNotFound.
+func (p *Player) Vanish() {
+	p.Emit(mpris.OwnerChangedEvent{OldOwner: fakeOwner})
+	p.mu.Lock()
+	p.gone = true
+	subs := p.subscribers
+	p.subscribers = make(map[*subscriber]struct{})
+	p.mu.Unlock()
+	for sub := range subs {
+		sub.close()
+	}
+}
+
+// call records the method call and returns the error it must fail with, if any.
+func (p *Player) call(method string, args ...interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, Call{method, args})
+	if p.gone {
+		return mpris.ErrPlayerNotFound
+	}
+	return p.errors[method]
+}
+
+func (p *Player) get(iface, name string) (dbus.Variant, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gone {
+		return dbus.Variant{}, mpris.ErrPlayerNotFound
+	}
+	value, ok := p.props[iface][name]
+	if !ok {
+		return dbus.Variant{}, mpris.ErrNotSupported
+	}
+	return value, nil
+}
+
+func (p *Player) getString(iface, name string) (string, error) {
+	variant, err := p.get(iface, name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := variant.Value().(string)
+	if !ok {
+		return "", &mpris.TypeError{Name: name, Signature: variant.Signature().String(), Expected: "string"}
+	}
+	return value, nil
+}
+
+func (p *Player) getBool(iface, name string) (bool, error) {
+	variant, err := p.get(iface, name)
+	if err != nil {
+		return false, err
+	}
+	value, ok := variant.Value().(bool)
+	if !ok {
+		return false, &mpris.TypeError{Name: name, Signature: variant.Signature().String(), Expected: "bool"}
+	}
+	return value, nil
+}
+
+func (p *Player) getStrings(iface, name string) ([]string, error) {
+	variant, err := p.get(iface, name)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := variant.Value().([]string)
+	if !ok {
+		return nil, &mpris.TypeError{Name: name, Signature: variant.Signature().String(), Expected: "[]string"}
+	}
+	return value, nil
+}
+
+func (p *Player) getFloat(name string) (float64, error) {
+	variant, err := p.get(mpris.PlayerInterface, name)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := variant.Value().(float64)
+	if !ok {
+		return 0, &mpris.TypeError{Name: name, Signature: variant.Signature().String(), Expected: "float64"}
+	}
+	return value, nil
+}
+
+// GetName returns the full bus name of the player.
+func (p *Player) GetName() string {
+	return p.name
+}
+
+// Owner returns ":fake" as the unique name of the player, or mpris.ErrPlayerNotFound once it
+// vanished.
+func (p *Player) Owner() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gone {
+		return "", mpris.ErrPlayerNotFound
+	}
+	return fakeOwner, nil
+}
+
+// PID returns the id of the current process, as the fake player runs in it.
+func (p *Player) PID() (uint32, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.gone {
+		return 0, mpris.ErrPlayerNotFound
+	}
+	return uint32(os.Getpid()), nil
+}
+
+// Context returns context.Background, the fake player doesn't use contexts for its calls.
+func (p *Player) Context() context.Context {
+	return context.Background()
+}
+
+// Exists returns false once the player vanished.
+func (p *Player) Exists() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.gone, nil
+}
+
+// Ping returns mpris.ErrPlayerNotFound once the player vanished.
+func (p *Player) Ping() error {
+	return p.call("Ping")
+}
+
+// Close closes the subscriptions.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	subs := p.subscribers
+	p.subscribers = make(map[*subscriber]struct{})
+	p.mu.Unlock()
+	for sub := range subs {
+		sub.close()
+	}
+	return nil
+}
+
+// Raise records the call.
+func (p *Player) Raise() error {
+	return p.call("Raise")
+}
+
+// Quit makes the player vanish.
+func (p *Player) Quit() error {
+	if err := p.call("Quit"); err != nil {
+		return err
+	}
+	p.Vanish()
+	return nil
+}
+
+// GetIdentity returns the Identity property.
+func (p *Player) GetIdentity() (string, error) {
+	return p.getString(mpris.BaseInterface, "Identity")
+}
+
+// GetDesktopEntry returns the DesktopEntry property.
+func (p *Player) GetDesktopEntry() (string, error) {
+	return p.getString(mpris.BaseInterface, "DesktopEntry")
+}
+
+// HasTrackList returns the HasTrackList property.
+func (p *Player) HasTrackList() (bool, error) {
+	return p.getBool(mpris.BaseInterface, "HasTrackList")
+}
+
+// GetSupportedUriSchemes returns the SupportedUriSchemes property.
+func (p *Player) GetSupportedUriSchemes() ([]string, error) {
+	return p.getStrings(mpris.BaseInterface, "SupportedUriSchemes")
+}
+
+// GetSupportedMimeTypes returns the SupportedMimeTypes property.
+func (p *Player) GetSupportedMimeTypes() ([]string, error) {
+	return p.getStrings(mpris.BaseInterface, "SupportedMimeTypes")
+}
+
+// Next records the call, the track is left unchanged.
+func (p *Player) Next() error {
+	return p.call("Next")
+}
+
+// Previous records the call, the track is left unchanged.
+func (p *Player) Previous() error {
+	return p.call("Previous")
+}
+
+func (p *Player) setStatus(method string, status mpris.PlaybackStatus) error {
+	if err := p.call(method); err != nil {
+		return err
+	}
+	p.SetPlaybackStatus(status)
+	return nil
+}
+
+// Pause sets the playback status to Paused.
+func (p *Player) Pause() error {
+	return p.setStatus("Pause", mpris.PlaybackPaused)
+}
+
+// Play sets the playback status to Playing.
+func (p *Player) Play() error {
+	return p.setStatus("Play", mpris.PlaybackPlaying)
+}
+
+// PlayPause toggles the playback status between Playing and Paused.
+func (p *Player) PlayPause() error {
+	status, err := p.GetPlaybackStatus()
+	if err != nil {
+		return err
+	}
+	if status == mpris.PlaybackPlaying {
+		return p.setStatus("PlayPause", mpris.PlaybackPaused)
+	}
+	return p.setStatus("PlayPause", mpris.PlaybackPlaying)
+}
+
+// Stop sets the playback status to Stopped and moves back to the start of the track.
+func (p *Player) Stop() error {
+	if err := p.setStatus("Stop", mpris.PlaybackStopped); err != nil {
+		return err
+	}
+	p.SetPositionDuration(0)
+	return nil
+}
+
+// OpenUri records the call and makes the uri the current track.
+func (p *Player) OpenUri(uri string) error {
+	if err := p.call("OpenUri", uri); err != nil {
+		return err
+	}
+	p.SetMetadata(mpris.Metadata{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(fmt.Sprintf("/org/mprisfake/track/%d", len(p.Calls())))),
+		"xesam:url":     dbus.MakeVariant(uri),
+	})
+	return nil
+}
+
+// Seek moves the position by offset seconds.
+func (p *Player) Seek(offset float64) error {
+	return p.SeekBy(time.Duration(offset * float64(time.Second)))
+}
+
+// SeekBy moves the position by offset and emits a Seeked event.
+func (p *Player) SeekBy(offset time.Duration) error {
+	if err := p.call("Seek", offset); err != nil {
+		return err
+	}
+	position, err := p.GetPositionDuration()
+	if err != nil {
+		return err
+	}
+	p.seekTo(position + offset)
+	return nil
+}
+
+func (p *Player) seekTo(position time.Duration) {
+	if position < 0 {
+		position = 0
+	}
+	p.SetPositionDuration(position)
+	p.Emit(mpris.SeekedEvent{Position: position})
+}
+
+func (p *Player) currentTrackID() mpris.TrackID {
+	metadata, _ := p.GetMetadata()
+	return metadata.TrackID()
+}
+
+// SeekTo sets the position of the current track.
+func (p *Player) SeekTo(position time.Duration) error {
+	return p.SetPositionWithTrackID(p.currentTrackID(), position)
+}
+
+// SetTrackPosition sets the position of the track, in seconds.
+func (p *Player) SetTrackPosition(trackId *dbus.ObjectPath, position float64) error {
+	return p.SetPositionWithTrackID(mpris.TrackID(*trackId), time.Duration(position*float64(time.Second)))
+}
+
+// SetPosition sets the position of the current track, in seconds.
+func (p *Player) SetPosition(position float64) error {
+	return p.SeekTo(time.Duration(position * float64(time.Second)))
+}
+
+// SetPositionWithTrackID ignores the calls for another track than the current one, as the spec
+// requires.
+func (p *Player) SetPositionWithTrackID(trackID mpris.TrackID, position time.Duration) error {
+	if trackID.IsNoTrack() || !trackID.IsValid() {
+		return mpris.ErrInvalidTrackID
+	}
+	if err := p.call("SetPosition", trackID, position); err != nil {
+		return err
+	}
+	if trackID == p.currentTrackID() && position >= 0 {
+		p.seekTo(position)
+	}
+	return nil
+}
+
+// seekLength returns the length of the current track, for the percentages.
+func (p *Player) seekLength() (time.Duration, error) {
+	canSeek, err := p.CanSeek()
+	if err != nil {
+		return 0, err
+	}
+	if !canSeek {
+		return 0, mpris.ErrNotSupported
+	}
+	length, err := p.GetLengthDuration()
+	if err != nil {
+		return 0, err
+	}
+	if length <= 0 {
+		return 0, mpris.ErrNilVariant
+	}
+	return length, nil
+}
+
+func clampPosition(position, length time.Duration) time.Duration {
+	if position < 0 {
+		return 0
+	}
+	if position > length {
+		return length
+	}
+	return position
+}
+
+// SetPositionPercent sets the position to the percentage of the track length.
+func (p *Player) SetPositionPercent(percent float64) error {
+	length, err := p.seekLength()
+	if err != nil {
+		return err
+	}
+	return p.SeekTo(clampPosition(time.Duration(float64(length)*percent/100), length))
+}
+
+// SeekPercent moves the position by the percentage of the track length.
+func (p *Player) SeekPercent(percent float64) error {
+	length, err := p.seekLength()
+	if err != nil {
+		return err
+	}
+	position, err := p.GetPositionDuration()
+	if err != nil {
+		return err
+	}
+	return p.SeekTo(clampPosition(position+time.Duration(float64(length)*percent/100), length))
+}
+
+// CanSeek returns the CanSeek property.
+func (p *Player) CanSeek() (bool, error) {
+	return p.getBool(mpris.PlayerInterface, "CanSeek")
+}
+
+// GetPosition returns the position in seconds.
+func (p *Player) GetPosition() (float64, error) {
+	position, err := p.GetPositionDuration()
+	return position.Seconds(), err
+}
+
+// GetPositionDuration returns the Position property.
+func (p *Player) GetPositionDuration() (time.Duration, error) {
+	variant, err := p.get(mpris.PlayerInterface, "Position")
+	if err != nil {
+		return 0, err
+	}
+	position, ok := variant.Value().(int64)
+	if !ok {
+		return 0, &mpris.TypeError{Name: "Position", Signature: variant.Signature().String(), Expected: "int64"}
+	}
+	return time.Duration(position) * time.Microsecond, nil
+}
+
+// GetLength returns the track length in seconds.
+func (p *Player) GetLength() (float64, error) {
+	length, err := p.GetLengthDuration()
+	return length.Seconds(), err
+}
+
+// GetLengthDuration returns the mpris:length of the metadata.
+func (p *Player) GetLengthDuration() (time.Duration, error) {
+	metadata, err := p.GetMetadata()
+	if err != nil {
+		return 0, err
+	}
+	if metadata["mpris:length"].Value() == nil {
+		return 0, mpris.ErrNilVariant
+	}
+	return metadata.Length(), nil
+}
+
+// GetPlaybackStatus returns the PlaybackStatus property.
+func (p *Player) GetPlaybackStatus() (mpris.PlaybackStatus, error) {
+	status, err := p.getString(mpris.PlayerInterface, "PlaybackStatus")
+	return mpris.PlaybackStatus(status), err
+}
+
+// GetLoopStatus returns the LoopStatus property.
+func (p *Player) GetLoopStatus() (mpris.LoopStatus, error) {
+	status, err := p.getString(mpris.PlayerInterface, "LoopStatus")
+	return mpris.LoopStatus(status), err
+}
+
+// SetLoopStatus sets the LoopStatus property.
+func (p *Player) SetLoopStatus(loopStatus mpris.LoopStatus) error {
+	return p.SetPlayerProperty("LoopStatus", string(loopStatus))
+}
+
+// CycleLoopStatus moves to the next loop status, from None to Playlist to Track.
+func (p *Player) CycleLoopStatus() (mpris.LoopStatus, error) {
+	current, err := p.GetLoopStatus()
+	if err != nil {
+		return "", err
+	}
+	next := map[mpris.LoopStatus]mpris.LoopStatus{
+		mpris.LoopNone:     mpris.LoopPlaylist,
+		mpris.LoopPlaylist: mpris.LoopTrack,
+	}[current]
+	if next == "" {
+		next = mpris.LoopNone
+	}
+	return next, p.SetLoopStatus(next)
+}
+
+// GetShuffle returns the Shuffle property.
+func (p *Player) GetShuffle() (bool, error) {
+	return p.getBool(mpris.PlayerInterface, "Shuffle")
+}
+
+// SetShuffle sets the Shuffle property.
+func (p *Player) SetShuffle(value bool) error {
+	return p.SetPlayerProperty("Shuffle", value)
+}
+
+// ToggleShuffle flips the Shuffle property and returns the new value.
+func (p *Player) ToggleShuffle() (bool, error) {
+	shuffle, err := p.GetShuffle()
+	if err != nil {
+		return false, err
+	}
+	return !shuffle, p.SetShuffle(!shuffle)
+}
+
+// GetRate returns the Rate property.
+func (p *Player) GetRate() (float64, error) {
+	return p.getFloat("Rate")
+}
+
+// GetVolume returns the Volume property.
+func (p *Player) GetVolume() (float64, error) {
+	return p.getFloat("Volume")
+}
+
+// SetVolume sets the Volume property.
+func (p *Player) SetVolume(volume float64) error {
+	return p.SetPlayerProperty("Volume", volume)
+}
+
+// IncreaseVolume adds step to the volume, clamped to 0..1, and returns the new volume.
+func (p *Player) IncreaseVolume(step float64) (float64, error) {
+	volume, err := p.GetVolume()
+	if err != nil {
+		return 0, err
+	}
+	volume += step
+	if volume < 0 {
+		volume = 0
+	} else if volume > 1 {
+		volume = 1
+	}
+	return volume, p.SetVolume(volume)
+}
+
+// DecreaseVolume removes step from the volume, like IncreaseVolume.
+func (p *Player) DecreaseVolume(step float64) (float64, error) {
+	return p.IncreaseVolume(-step)
+}
+
+// ToggleMute sets the volume to 0, or back to the volume before muting, and returns true if
+// muted.
+func (p *Player) ToggleMute() (bool, error) {
+	volume, err := p.GetVolume()
+	if err != nil {
+		return false, err
+	}
+	if volume > 0 {
+		p.mu.Lock()
+		p.muteVolume = volume
+		p.mu.Unlock()
+		return true, p.SetVolume(0)
+	}
+	p.mu.Lock()
+	restored := p.muteVolume
+	p.muteVolume = 0
+	p.mu.Unlock()
+	if restored <= 0 {
+		restored = 1
+	}
+	return false, p.SetVolume(restored)
+}
+
+// GetMetadata returns the Metadata property.
+func (p *Player) GetMetadata() (mpris.Metadata, error) {
+	variant, err := p.get(mpris.PlayerInterface, "Metadata")
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := variant.Value().(map[string]dbus.Variant)
+	if !ok {
+		return nil, &mpris.TypeError{Name: "Metadata", Signature: variant.Signature().String(), Expected: "Metadata"}
+	}
+	return mpris.Metadata(metadata), nil
+}
+
+// GetTitle returns the xesam:title of the metadata.
+func (p *Player) GetTitle() (string, error) {
+	metadata, err := p.GetMetadata()
+	return metadata.Title(), err
+}
+
+// GetArtists returns the xesam:artist of the metadata.
+func (p *Player) GetArtists() ([]string, error) {
+	metadata, err := p.GetMetadata()
+	return metadata.Artists(), err
+}
+
+// GetAlbum returns the xesam:album of the metadata.
+func (p *Player) GetAlbum() (string, error) {
+	metadata, err := p.GetMetadata()
+	return metadata.Album(), err
+}
+
+// GetArtURL returns the mpris:artUrl of the metadata.
+func (p *Player) GetArtURL() (string, error) {
+	metadata, err := p.GetMetadata()
+	return metadata.ArtURL(), err
+}
+
+// GetState returns the player properties, only failing if the playback status is missing.
+func (p *Player) GetState() (mpris.PlayerState, error) {
+	var state mpris.PlayerState
+	var err error
+	if state.PlaybackStatus, err = p.GetPlaybackStatus(); err != nil {
+		return mpris.PlayerState{}, err
+	}
+	state.LoopStatus, _ = p.GetLoopStatus()
+	state.Shuffle, _ = p.GetShuffle()
+	state.Volume, _ = p.GetVolume()
+	state.Rate, _ = p.GetRate()
+	state.Position, _ = p.GetPositionDuration()
+	state.PositionAt = time.Now()
+	state.Metadata, _ = p.GetMetadata()
+	return state, nil
+}
+
+// GetProperty returns the property, or mpris.ErrNotSupported if it was never set.
+func (p *Player) GetProperty(targetInterface, propertyName string) (dbus.Variant, error) {
+	return p.get(targetInterface, propertyName)
+}
+
+// GetPlayerProperty returns a property of the player interface.
+func (p *Player) GetPlayerProperty(propertyName string) (dbus.Variant, error) {
+	return p.get(mpris.PlayerInterface, propertyName)
+}
+
+// SetProperty records the call and changes the property, which must exist.
+func (p *Player) SetProperty(targetInterface, propertyName string, value interface{}) error {
+	if err := p.call("Set", targetInterface, propertyName, value); err != nil {
+		return err
+	}
+	if _, err := p.get(targetInterface, propertyName); err != nil {
+		return err
+	}
+	p.Set(targetInterface, propertyName, value)
+	return nil
+}
+
+// SetPlayerProperty sets a property of the player interface.
+func (p *Player) SetPlayerProperty(propertyName string, value interface{}) error {
+	return p.SetProperty(mpris.PlayerInterface, propertyName, value)
+}
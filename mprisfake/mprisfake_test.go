@@ -0,0 +1,152 @@
+package mprisfake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// pauseIfPlaying is the kind of code the fake is meant to test.
+func pauseIfPlaying(player mpris.PlayerAPI) error {
+	status, err := player.GetPlaybackStatus()
+	if err != nil || status != mpris.PlaybackPlaying {
+		return err
+	}
+	return player.Pause()
+}
+
+func TestCommands(t *testing.T) {
+	player := New("fake")
+
+	if err := pauseIfPlaying(player); err != nil {
+		t.Fatal(err)
+	}
+	if calls := player.Calls(); len(calls) != 0 {
+		t.Errorf("Expected no call, got %v", calls)
+	}
+
+	player.SetPlaybackStatus(mpris.PlaybackPlaying)
+	if err := pauseIfPlaying(player); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := player.GetPlaybackStatus(); status != mpris.PlaybackPaused {
+		t.Errorf("Expected the player to be paused, got %s", status)
+	}
+	if calls := player.Calls(); len(calls) != 1 || calls[0].Method != "Pause" {
+		t.Errorf("Expected a Pause call, got %v", calls)
+	}
+
+	failure := errors.New("failure")
+	player.SetError("Next", failure)
+	if err := player.Next(); err != failure {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	player := New("fake")
+
+	if err := player.SeekTo(time.Second); err != mpris.ErrInvalidTrackID {
+		t.Errorf("Expected ErrInvalidTrackID without a track, got %v", err)
+	}
+
+	player.SetMetadata(mpris.Metadata{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(10 * time.Second / time.Microsecond)),
+	})
+	if err := player.SetPositionPercent(50); err != nil {
+		t.Fatal(err)
+	}
+	if position, _ := player.GetPositionDuration(); position != 5*time.Second {
+		t.Errorf("Expected the position to be 5s, got %s", position)
+	}
+	if err := player.SeekBy(-time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if position, _ := player.GetPositionDuration(); position != 0 {
+		t.Errorf("Expected the position to be clamped to 0, got %s", position)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	player := New("fake")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan mpris.Metadata)
+	go func() {
+		metadata, err := player.WaitForTrackChange(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- metadata
+	}()
+
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.SetVolume(0.5)
+	event := <-events
+	if changed, ok := event.(mpris.PropertiesChangedEvent); !ok || changed.Changed["Volume"].Value() != 0.5 {
+		t.Errorf("Expected a volume change, got %#v", event)
+	}
+
+	// wait for the track change subscription, it's made in the goroutine
+	for {
+		player.mu.Lock()
+		subscribers := len(player.subscribers)
+		player.mu.Unlock()
+		if subscribers == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	player.SetMetadata(mpris.Metadata{"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/track/1"))})
+	if metadata := <-done; metadata.TrackID() != "/track/1" {
+		t.Errorf("Expected the new track, got %v", metadata)
+	}
+
+	player.Vanish()
+	for range events {
+	}
+	if err := player.Ping(); err != mpris.ErrPlayerNotFound {
+		t.Errorf("Expected ErrPlayerNotFound after vanishing, got %v", err)
+	}
+}
+
+func TestSubscribeOptions(t *testing.T) {
+	player := New("fake")
+	player.SetPlaybackStatus(mpris.PlaybackPlaying)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := player.Subscribe(ctx, mpris.WithInitialState(), mpris.WithCoalesce())
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial, ok := (<-events).(mpris.PropertiesChangedEvent)
+	if !ok || initial.Interface != mpris.PlayerInterface || initial.Changed["PlaybackStatus"].Value() != "Playing" {
+		t.Fatalf("Expected the initial state first, got %#v", initial)
+	}
+	if _, ok := initial.Changed["Volume"]; !ok {
+		t.Errorf("Expected every property in the initial state, got %v", initial.Changed)
+	}
+
+	// the volume is already 1, so only the new status is sent
+	player.SetVolume(1)
+	player.SetPlaybackStatus(mpris.PlaybackPaused)
+	changed, ok := (<-events).(mpris.PropertiesChangedEvent)
+	if !ok || changed.Changed["PlaybackStatus"].Value() != "Paused" {
+		t.Errorf("Expected the coalesced status change, got %#v", changed)
+	}
+
+	player.Vanish()
+	if _, err := player.Subscribe(ctx, mpris.WithInitialState()); !errors.Is(err, mpris.ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound once vanished, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+// Package mprismem provides an in-memory dbus.BusObject answering the calls of an MPRIS player
+// with scripted values, so go-mpris and the code using it can be unit tested without any bus.
+// The properties are stored as given, so the exact payloads sent by a player, like a uint64
+// length, can be reproduced:
+//
+//	obj := mprismem.New("org.mpris.MediaPlayer2.test")
+//	obj.Set("org.mpris.MediaPlayer2.Player", "Metadata", map[string]dbus.Variant{
+//		"mpris:length": dbus.MakeVariant(uint64(90000000)),
+//	})
+//	player := mpris.NewWithObject(obj)
+//
+// Unlike the mpristest package, no signal is ever sent.
+package mprismem
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	objectPath = "/org/mpris/MediaPlayer2"
+
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+	busInterface        = "org.freedesktop.DBus"
+
+	// UniqueName is the unique name the object answers to the bus daemon calls asking for the
+	// owner of its name.
+	UniqueName = ":mprismem.1"
+)
+
+// Call is a method call received by the object.
+type Call struct {
+	// Method is the full name of the method, like "org.mpris.MediaPlayer2.Player.Next".
+	Method string
+	Args   []interface{}
+}
+
+// Handler answers a method call, with the body of the reply or the error sent back to the
+// caller.
+type Handler func(args ...interface{}) ([]interface{}, *dbus.Error)
+
+// Object is an in-memory MPRIS player object. The properties are read and set through the
+// org.freedesktop.DBus.Properties methods, the other methods answer with an empty reply
+// unless a handler is set with Handle. The calls to the bus daemon, made on the object by the
+// players created with mpris.NewWithObject, answer that the name is owned by UniqueName from
+// the current process. It's safe for concurrent use.
+type Object struct {
+	name string
+	path dbus.ObjectPath
+
+	mu         sync.Mutex
+	properties map[string]map[string]dbus.Variant
+	handlers   map[string]Handler
+	calls      []Call
+}
+
+// New returns an object with no property, for the player with the name.
+func New(name string) *Object {
+	return &Object{
+		name:       name,
+		path:       objectPath,
+		properties: make(map[string]map[string]dbus.Variant),
+		handlers:   make(map[string]Handler),
+	}
+}
+
+// Set sets the property of the interface. The value is stored as is if it's a dbus.Variant,
+// and wrapped in one otherwise.
+func (o *Object) Set(iface, prop string, value interface{}) {
+	variant, ok := value.(dbus.Variant)
+	if !ok {
+		variant = dbus.MakeVariant(value)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.properties[iface] == nil {
+		o.properties[iface] = make(map[string]dbus.Variant)
+	}
+	o.properties[iface][prop] = variant
+}
+
+// Delete removes the property of the interface, which the object then answers is
+// unknown.
+func (o *Object) Delete(iface, prop string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.properties[iface], prop)
+}
+
+// Handle sets the handler of the method, given by its full name like
+// "org.mpris.MediaPlayer2.Player.Next". A nil handler restores the default behavior.
+func (o *Object) Handle(method string, handler Handler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if handler == nil {
+		delete(o.handlers, method)
+		return
+	}
+	o.handlers[method] = handler
+}
+
+// Calls returns the method calls received so far, in order.
+func (o *Object) Calls() []Call {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Call(nil), o.calls...)
+}
+
+// Call calls the method, like CallWithContext with the background context.
+func (o *Object) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	return o.CallWithContext(context.Background(), method, flags, args...)
+}
+
+// CallWithContext calls the method and waits for the answer, or for ctx to be done.
+func (o *Object) CallWithContext(ctx context.Context, method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	return <-o.GoWithContext(ctx, method, flags, make(chan *dbus.Call, 1), args...).Done
+}
+
+// Go calls the method without waiting, like GoWithContext with the background context.
+func (o *Object) Go(method string, flags dbus.Flags, ch chan *dbus.Call, args ...interface{}) *dbus.Call {
+	return o.GoWithContext(context.Background(), method, flags, ch, args...)
+}
+
+// GoWithContext calls the method without waiting for the answer. The call is sent on ch, or
+// on a new channel if it's nil, once answered or once ctx is done. With
+// dbus.FlagNoReplyExpected, the call is done right away and the answer is dropped.
+func (o *Object) GoWithContext(ctx context.Context, method string, flags dbus.Flags, ch chan *dbus.Call, args ...interface{}) *dbus.Call {
+	if ch == nil {
+		ch = make(chan *dbus.Call, 1)
+	}
+	call := &dbus.Call{
+		Destination: o.name,
+		Path:        o.path,
+		Method:      method,
+		Args:        args,
+		Done:        ch,
+	}
+	if err := ctx.Err(); err != nil {
+		call.Err = err
+		ch <- call
+		return call
+	}
+
+	// the call is recorded right away, so the calls are in the order they were made
+	handler := o.record(method, args)
+	answers := make(chan *dbus.Call, 1)
+	go func() {
+		body, err := o.answer(handler, method, args)
+		answer := &dbus.Call{Body: body}
+		if err != nil {
+			answer.Err = *err
+		}
+		answers <- answer
+	}()
+	if flags&dbus.FlagNoReplyExpected != 0 {
+		ch <- call
+		return call
+	}
+	go func() {
+		select {
+		case answer := <-answers:
+			call.Body, call.Err = answer.Body, answer.Err
+		case <-ctx.Done():
+			call.Err = ctx.Err()
+		}
+		ch <- call
+	}()
+	return call
+}
+
+// record records the call and returns the handler of the method.
+func (o *Object) record(method string, args []interface{}) Handler {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, Call{Method: method, Args: args})
+	return o.handlers[method]
+}
+
+// answer answers the call with the handler, or with the default behavior if it's nil.
+func (o *Object) answer(handler Handler, method string, args []interface{}) ([]interface{}, *dbus.Error) {
+	if handler != nil {
+		return handler(args...)
+	}
+
+	switch method {
+	case propertiesInterface + ".Get":
+		iface, prop, ok := stringArgs(args)
+		if !ok {
+			return nil, invalidArgs()
+		}
+		value, err := o.GetProperty(iface + "." + prop)
+		if err != nil {
+			return nil, err.(*dbus.Error)
+		}
+		return []interface{}{value}, nil
+	case propertiesInterface + ".GetAll":
+		if len(args) < 1 {
+			return nil, invalidArgs()
+		}
+		iface, ok := args[0].(string)
+		if !ok {
+			return nil, invalidArgs()
+		}
+		o.mu.Lock()
+		values := make(map[string]dbus.Variant, len(o.properties[iface]))
+		for prop, value := range o.properties[iface] {
+			values[prop] = value
+		}
+		o.mu.Unlock()
+		return []interface{}{values}, nil
+	case propertiesInterface + ".Set":
+		iface, prop, ok := stringArgs(args)
+		if !ok || len(args) < 3 {
+			return nil, invalidArgs()
+		}
+		o.Set(iface, prop, args[2])
+		return nil, nil
+	case busInterface + ".NameHasOwner":
+		return []interface{}{true}, nil
+	case busInterface + ".GetNameOwner":
+		return []interface{}{UniqueName}, nil
+	case busInterface + ".GetConnectionUnixProcessID":
+		return []interface{}{uint32(os.Getpid())}, nil
+	}
+	return nil, nil
+}
+
+// stringArgs returns the first two arguments, which must be strings.
+func stringArgs(args []interface{}) (string, string, bool) {
+	if len(args) < 2 {
+		return "", "", false
+	}
+	first, ok := args[0].(string)
+	if !ok {
+		return "", "", false
+	}
+	second, ok := args[1].(string)
+	return first, second, ok
+}
+
+func invalidArgs() *dbus.Error {
+	return &dbus.Error{Name: "org.freedesktop.DBus.Error.InvalidArgs", Body: []interface{}{"invalid arguments"}}
+}
+
+// AddMatchSignal does nothing, as the object sends no signal.
+func (o *Object) AddMatchSignal(iface, member string, options ...dbus.MatchOption) *dbus.Call {
+	return &dbus.Call{Method: busInterface + ".AddMatch"}
+}
+
+// RemoveMatchSignal does nothing, as the object sends no signal.
+func (o *Object) RemoveMatchSignal(iface, member string, options ...dbus.MatchOption) *dbus.Call {
+	return &dbus.Call{Method: busInterface + ".RemoveMatch"}
+}
+
+// GetProperty returns the property given by its interface and name, like
+// "org.mpris.MediaPlayer2.Player.Volume".
+func (o *Object) GetProperty(p string) (dbus.Variant, error) {
+	iface, prop := splitProperty(p)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	value, ok := o.properties[iface][prop]
+	if !ok {
+		return dbus.Variant{}, &dbus.Error{
+			Name: "org.freedesktop.DBus.Error.UnknownProperty",
+			Body: []interface{}{"unknown property " + p},
+		}
+	}
+	return value, nil
+}
+
+// SetProperty sets the property given by its interface and name, like Set.
+func (o *Object) SetProperty(p string, v interface{}) error {
+	iface, prop := splitProperty(p)
+	o.Set(iface, prop, v)
+	return nil
+}
+
+// splitProperty splits the full name of a property at its last dot.
+func splitProperty(p string) (string, string) {
+	n := strings.LastIndex(p, ".")
+	if n < 0 {
+		return "", p
+	}
+	return p[:n], p[n+1:]
+}
+
+// Destination returns the name of the player.
+func (o *Object) Destination() string {
+	return o.name
+}
+
+// Path returns the object path of the MPRIS players.
+func (o *Object) Path() dbus.ObjectPath {
+	return o.path
+}
+
+var _ dbus.BusObject = (*Object)(nil)
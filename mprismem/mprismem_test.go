@@ -0,0 +1,60 @@
+package mprismem_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mprismem"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestCalls(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.calls")
+	player := mpris.NewWithObject(obj)
+
+	if err := player.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.NoReply().Previous(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-player.PauseAsync(); err != nil {
+		t.Fatal(err)
+	}
+
+	var methods []string
+	for _, call := range obj.Calls() {
+		methods = append(methods, call.Method)
+	}
+	if len(methods) != 3 || methods[0] != mpris.PlayerInterface+".Next" || methods[1] != mpris.PlayerInterface+".Previous" {
+		t.Errorf("Expected Next, Previous and Pause to be called, got %v", methods)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	obj := mprismem.New("org.mpris.MediaPlayer2.handle")
+	player := mpris.NewWithObject(obj)
+
+	obj.Handle(mpris.PlayerInterface+".Play", func(args ...interface{}) ([]interface{}, *dbus.Error) {
+		return nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.NotSupported"}
+	})
+	if err := player.Play(); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected the error of the handler to be mapped, got %v", err)
+	}
+
+	obj.Handle(mpris.PlayerInterface+".Play", nil)
+	if err := player.Play(); err != nil {
+		t.Errorf("Expected the default behavior to be restored, got %v", err)
+	}
+
+	obj.Set(mpris.PlayerInterface, "Position", int64(5000000))
+	if position, err := player.GetPositionDuration(); err != nil || position != 5*time.Second {
+		t.Errorf("Expected the position to be 5s, got %s (%v)", position, err)
+	}
+	obj.Delete(mpris.PlayerInterface, "Position")
+	if _, err := player.GetPositionDuration(); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected a deleted property to be unknown, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package mpristest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// busConfig is the configuration of the private bus, a session bus without any service to
+// activate, listening in dir.
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir={{dir}}</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// busEnv is set by RunWithBus to the address of the private bus, so the test binary started
+// again as a helper process by the tests doesn't start its own.
+const busEnv = "MPRISTEST_BUS_ADDRESS"
+
+// noBusAddress is the session bus address set by RunWithBus without a private bus, whose
+// transport doesn't exist so connecting fails right away.
+const noBusAddress = "mpristest-nobus:"
+
+// busStartTimeout is how long StartBus waits for the daemon to print its address.
+const busStartTimeout = 5 * time.Second
+
+// Bus is a private D-Bus daemon, so the tests neither depend on a session bus nor see the
+// players of the user.
+type Bus struct {
+	cmd     *exec.Cmd
+	dir     string
+	address string
+}
+
+// StartBus starts a private bus with the dbus-daemon program, which must be installed.
+func StartBus() (*Bus, error) {
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "mpristest")
+	if err != nil {
+		return nil, err
+	}
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(strings.ReplaceAll(busConfig, "{{dir}}", dir)), 0o600); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	cmd := exec.Command(path, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	bus := &Bus{cmd: cmd, dir: dir}
+
+	addresses := make(chan string, 1)
+	go func() {
+		address, _ := bufio.NewReader(stdout).ReadString('\n')
+		addresses <- strings.TrimSpace(address)
+	}()
+	select {
+	case bus.address = <-addresses:
+	case <-time.After(busStartTimeout):
+	}
+	if bus.address == "" {
+		bus.Close()
+		return nil, errors.New("dbus-daemon did not print its address")
+	}
+	return bus, nil
+}
+
+// Address returns the D-Bus address of the bus, like "unix:path=/tmp/...".
+func (b *Bus) Address() string {
+	return b.address
+}
+
+// Close stops the daemon.
+func (b *Bus) Close() error {
+	b.cmd.Process.Kill()
+	b.cmd.Wait()
+	return os.RemoveAll(b.dir)
+}
+
+// RunWithBus runs the tests on a private bus, set as the session bus of the tests and of the
+// processes they start, and returns the exit code for os.Exit, as in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(mpristest.RunWithBus(m))
+//	}
+//
+// The tests never run on the session bus of the user, where they would own names and control
+// the players of the desktop. When the private bus can't be started, like when dbus-daemon is
+// not installed, the session bus address is set to one no connection can be made to, so the
+// tests needing a bus are skipped, as they are without a session bus.
+func RunWithBus(m *testing.M) int {
+	if address := os.Getenv(busEnv); address != "" {
+		// started by tests already running on a private bus
+		os.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+		return m.Run()
+	}
+	bus, err := StartBus()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mpristest: skipping the tests needing a bus, no private bus: %v\n", err)
+		os.Setenv("DBUS_SESSION_BUS_ADDRESS", noBusAddress)
+		return m.Run()
+	}
+	defer bus.Close()
+	os.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address())
+	os.Setenv(busEnv, bus.Address())
+	return m.Run()
+}
@@ -0,0 +1,44 @@
+package mpristest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// eventuallyTimeout is how long Eventually waits for its condition.
+const eventuallyTimeout = 2 * time.Second
+
+// PrivateConn opens a private connection to the session bus, closed when the test ends, so
+// the test can own names and receive signals on its own. The test is skipped when there is no
+// session bus.
+func PrivateConn(t testing.TB) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.SessionBusPrivate()
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Hello(); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// Eventually checks the condition every 10ms, until it's true, and fails the test with the
+// message if it's still false after 2 seconds, as when waiting for a signal to be handled.
+func Eventually(t testing.TB, condition func() bool, message string) {
+	t.Helper()
+	deadline := time.Now().Add(eventuallyTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
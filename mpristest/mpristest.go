@@ -0,0 +1,399 @@
+// Package mpristest provides a scriptable fake MPRIS player that can be exported on any D-Bus
+// connection, so code using go-mpris can be tested without a real media player running.
+package mpristest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	objectPath = "/org/mpris/MediaPlayer2"
+
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+	baseInterface       = "org.mpris.MediaPlayer2"
+	playerInterface     = "org.mpris.MediaPlayer2.Player"
+
+	// NoTrack is the track id used when there is no current track.
+	NoTrack = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+)
+
+// methodNames maps the Go methods that can't use the D-Bus name, as Seek would clash with
+// io.Seeker, to their D-Bus names.
+var methodNames = map[string]string{
+	"SeekOffset": "Seek",
+}
+
+// Call is a method call received by the fake player.
+type Call struct {
+	Interface string
+	Method    string
+	Args      []interface{}
+}
+
+// Handler replaces the default behavior of a method. Returning a non nil error sends it back to
+// the caller.
+type Handler func(args ...interface{}) *dbus.Error
+
+// Player is a fake MPRIS player. Every property can be changed with SetProperty and the
+// well known ones have typed setters. Changes are announced with PropertiesChanged signals,
+// just like a real player would do.
+type Player struct {
+	conn *dbus.Conn
+	name string
+
+	mu         sync.Mutex
+	props      map[string]map[string]dbus.Variant
+	calls      []Call
+	handlers   map[string]Handler
+	tracks     []map[string]dbus.Variant
+	trackCount int
+	playlists  []Playlist
+}
+
+// New requests the bus name org.mpris.MediaPlayer2.<name> on the connection and exports a
+// fake player on it.
+func New(conn *dbus.Conn, name string) (*Player, error) {
+	player := &Player{
+		conn:     conn,
+		name:     baseInterface + "." + name,
+		props:    defaultProperties(),
+		handlers: make(map[string]Handler),
+	}
+
+	exports := map[string]interface{}{
+		propertiesInterface: &propertiesExport{player},
+		baseInterface:       &baseExport{player},
+		playerInterface:     &playerExport{player},
+		trackListInterface:  &trackListExport{player},
+		playlistsInterface:  &playlistsExport{player},
+	}
+	for iface, export := range exports {
+		if err := conn.ExportWithMap(export, methodNames, objectPath, iface); err != nil {
+			player.unexport()
+			return nil, err
+		}
+	}
+
+	reply, err := conn.RequestName(player.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		player.unexport()
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		player.unexport()
+		return nil, fmt.Errorf("name %s already taken", player.name)
+	}
+	return player, nil
+}
+
+func defaultProperties() map[string]map[string]dbus.Variant {
+	return map[string]map[string]dbus.Variant{
+		baseInterface: {
+			"CanQuit":             dbus.MakeVariant(true),
+			"CanRaise":            dbus.MakeVariant(true),
+			"HasTrackList":        dbus.MakeVariant(true),
+			"Identity":            dbus.MakeVariant("mpristest"),
+			"DesktopEntry":        dbus.MakeVariant("mpristest"),
+			"SupportedUriSchemes": dbus.MakeVariant([]string{"file"}),
+			"SupportedMimeTypes":  dbus.MakeVariant([]string{"audio/mpeg"}),
+		},
+		playerInterface: {
+			"PlaybackStatus": dbus.MakeVariant("Stopped"),
+			"LoopStatus":     dbus.MakeVariant("None"),
+			"Rate":           dbus.MakeVariant(1.0),
+			"Shuffle":        dbus.MakeVariant(false),
+			"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+				"mpris:trackid": dbus.MakeVariant(NoTrack),
+			}),
+			"Volume":        dbus.MakeVariant(1.0),
+			"Position":      dbus.MakeVariant(int64(0)),
+			"MinimumRate":   dbus.MakeVariant(1.0),
+			"MaximumRate":   dbus.MakeVariant(1.0),
+			"CanGoNext":     dbus.MakeVariant(true),
+			"CanGoPrevious": dbus.MakeVariant(true),
+			"CanPlay":       dbus.MakeVariant(true),
+			"CanPause":      dbus.MakeVariant(true),
+			"CanSeek":       dbus.MakeVariant(true),
+			"CanControl":    dbus.MakeVariant(true),
+		},
+		trackListInterface: {
+			"Tracks":        dbus.MakeVariant([]dbus.ObjectPath{}),
+			"CanEditTracks": dbus.MakeVariant(true),
+		},
+		playlistsInterface: {
+			"PlaylistCount": dbus.MakeVariant(uint32(0)),
+			"Orderings":     dbus.MakeVariant([]string{"UserDefined", "Alphabetical"}),
+			"ActivePlaylist": dbus.MakeVariant(maybePlaylist{
+				Playlist: Playlist{ID: "/"},
+			}),
+		},
+	}
+}
+
+// Name returns the full bus name of the player.
+func (p *Player) Name() string {
+	return p.name
+}
+
+// Close releases the bus name and stops exporting the player.
+func (p *Player) Close() error {
+	p.unexport()
+	_, err := p.conn.ReleaseName(p.name)
+	return err
+}
+
+func (p *Player) unexport() {
+	for _, iface := range []string{propertiesInterface, baseInterface, playerInterface, trackListInterface, playlistsInterface} {
+		p.conn.Export(nil, objectPath, iface)
+	}
+}
+
+// Calls returns the method calls received so far, in order.
+func (p *Player) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// HandleFunc replaces the default behavior of the method of the given interface, like
+// HandleFunc("org.mpris.MediaPlayer2.Player", "Next", ...).
+func (p *Player) HandleFunc(iface, method string, handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[iface+"."+method] = handler
+}
+
+// GetProperty returns the current value of a property.
+func (p *Player) GetProperty(iface, name string) (dbus.Variant, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	value, ok := p.props[iface][name]
+	return value, ok
+}
+
+// SetProperty changes a property and emits PropertiesChanged, except for Position that
+// must not be announced according to the MPRIS spec.
+func (p *Player) SetProperty(iface, name string, value interface{}) error {
+	variant, ok := value.(dbus.Variant)
+	if !ok {
+		variant = dbus.MakeVariant(value)
+	}
+
+	p.mu.Lock()
+	if p.props[iface] == nil {
+		p.props[iface] = make(map[string]dbus.Variant)
+	}
+	p.props[iface][name] = variant
+	p.mu.Unlock()
+
+	if iface == playerInterface && name == "Position" {
+		return nil
+	}
+	return p.EmitPropertiesChanged(iface, map[string]dbus.Variant{name: variant}, nil)
+}
+
+// SetPlaybackStatus sets the playback status to "Playing", "Paused" or "Stopped".
+func (p *Player) SetPlaybackStatus(status string) error {
+	return p.SetProperty(playerInterface, "PlaybackStatus", status)
+}
+
+// SetMetadata replaces the current track metadata.
+func (p *Player) SetMetadata(metadata map[string]dbus.Variant) error {
+	return p.SetProperty(playerInterface, "Metadata", metadata)
+}
+
+// SetPosition sets the current track position, in microseconds, without emitting any signal.
+// Use EmitSeeked to announce a jump.
+func (p *Player) SetPosition(position int64) error {
+	return p.SetProperty(playerInterface, "Position", position)
+}
+
+// Position returns the current track position in microseconds.
+func (p *Player) Position() int64 {
+	value, _ := p.GetProperty(playerInterface, "Position")
+	position, _ := value.Value().(int64)
+	return position
+}
+
+// EmitPropertiesChanged emits a raw PropertiesChanged signal. It doesn't change any property.
+func (p *Player) EmitPropertiesChanged(iface string, changed map[string]dbus.Variant, invalidated []string) error {
+	if changed == nil {
+		changed = map[string]dbus.Variant{}
+	}
+	if invalidated == nil {
+		invalidated = []string{}
+	}
+	return p.conn.Emit(objectPath, propertiesInterface+".PropertiesChanged", iface, changed, invalidated)
+}
+
+// EmitSeeked emits the Seeked signal with the position in microseconds.
+func (p *Player) EmitSeeked(position int64) error {
+	return p.conn.Emit(objectPath, playerInterface+".Seeked", position)
+}
+
+// record stores the call and returns the handler that replaces the default behavior, if any.
+func (p *Player) record(iface, method string, args ...interface{}) Handler {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, Call{Interface: iface, Method: method, Args: args})
+	return p.handlers[iface+"."+method]
+}
+
+func (p *Player) setStatus(status string) *dbus.Error {
+	return toDBusError(p.SetPlaybackStatus(status))
+}
+
+func (p *Player) currentTrackID() dbus.ObjectPath {
+	value, _ := p.GetProperty(playerInterface, "Metadata")
+	metadata, _ := value.Value().(map[string]dbus.Variant)
+	trackID, _ := metadata["mpris:trackid"].Value().(dbus.ObjectPath)
+	return trackID
+}
+
+func toDBusError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	return dbus.MakeFailedError(err)
+}
+
+type propertiesExport struct {
+	p *Player
+}
+
+func (e *propertiesExport) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	value, ok := e.p.GetProperty(iface, name)
+	if !ok {
+		return dbus.Variant{}, dbus.NewError("org.freedesktop.DBus.Error.UnknownProperty", []interface{}{name})
+	}
+	return value, nil
+}
+
+func (e *propertiesExport) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	e.p.mu.Lock()
+	defer e.p.mu.Unlock()
+	props := make(map[string]dbus.Variant, len(e.p.props[iface]))
+	for name, value := range e.p.props[iface] {
+		props[name] = value
+	}
+	return props, nil
+}
+
+func (e *propertiesExport) Set(iface, name string, value dbus.Variant) *dbus.Error {
+	if handler := e.p.record(propertiesInterface, "Set", iface, name, value); handler != nil {
+		return handler(iface, name, value)
+	}
+	if _, ok := e.p.GetProperty(iface, name); !ok {
+		return dbus.NewError("org.freedesktop.DBus.Error.UnknownProperty", []interface{}{name})
+	}
+	return toDBusError(e.p.SetProperty(iface, name, value))
+}
+
+type baseExport struct {
+	p *Player
+}
+
+func (e *baseExport) Raise() *dbus.Error {
+	if handler := e.p.record(baseInterface, "Raise"); handler != nil {
+		return handler()
+	}
+	return nil
+}
+
+func (e *baseExport) Quit() *dbus.Error {
+	if handler := e.p.record(baseInterface, "Quit"); handler != nil {
+		return handler()
+	}
+	return nil
+}
+
+type playerExport struct {
+	p *Player
+}
+
+func (e *playerExport) Next() *dbus.Error {
+	if handler := e.p.record(playerInterface, "Next"); handler != nil {
+		return handler()
+	}
+	return nil
+}
+
+func (e *playerExport) Previous() *dbus.Error {
+	if handler := e.p.record(playerInterface, "Previous"); handler != nil {
+		return handler()
+	}
+	return nil
+}
+
+func (e *playerExport) Pause() *dbus.Error {
+	if handler := e.p.record(playerInterface, "Pause"); handler != nil {
+		return handler()
+	}
+	return e.p.setStatus("Paused")
+}
+
+func (e *playerExport) PlayPause() *dbus.Error {
+	if handler := e.p.record(playerInterface, "PlayPause"); handler != nil {
+		return handler()
+	}
+	value, _ := e.p.GetProperty(playerInterface, "PlaybackStatus")
+	if value.Value() == "Playing" {
+		return e.p.setStatus("Paused")
+	}
+	return e.p.setStatus("Playing")
+}
+
+func (e *playerExport) Stop() *dbus.Error {
+	if handler := e.p.record(playerInterface, "Stop"); handler != nil {
+		return handler()
+	}
+	if err := e.p.SetPosition(0); err != nil {
+		return toDBusError(err)
+	}
+	return e.p.setStatus("Stopped")
+}
+
+func (e *playerExport) Play() *dbus.Error {
+	if handler := e.p.record(playerInterface, "Play"); handler != nil {
+		return handler()
+	}
+	return e.p.setStatus("Playing")
+}
+
+func (e *playerExport) SeekOffset(offset int64) *dbus.Error {
+	if handler := e.p.record(playerInterface, "Seek", offset); handler != nil {
+		return handler(offset)
+	}
+	position := e.p.Position() + offset
+	if position < 0 {
+		position = 0
+	}
+	if err := e.p.SetPosition(position); err != nil {
+		return toDBusError(err)
+	}
+	return toDBusError(e.p.EmitSeeked(position))
+}
+
+func (e *playerExport) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	if handler := e.p.record(playerInterface, "SetPosition", trackID, position); handler != nil {
+		return handler(trackID, position)
+	}
+	// as the spec says, calls with a stale track id are ignored
+	if trackID != e.p.currentTrackID() || position < 0 {
+		return nil
+	}
+	if err := e.p.SetPosition(position); err != nil {
+		return toDBusError(err)
+	}
+	return toDBusError(e.p.EmitSeeked(position))
+}
+
+func (e *playerExport) OpenUri(uri string) *dbus.Error {
+	if handler := e.p.record(playerInterface, "OpenUri", uri); handler != nil {
+		return handler(uri)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package mpristest_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+func newPlayer(t *testing.T, name string) (*mpris.Player, *mpristest.Player) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	fake, err := mpristest.New(conn, fmt.Sprintf("%s.instance%d", name, os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.Close() })
+
+	return mpris.New(conn, fake.Name()), fake
+}
+
+func TestPlayPause(t *testing.T) {
+	player, fake := newPlayer(t, "playpause")
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := player.GetPlaybackStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != mpris.PlaybackPlaying {
+		t.Errorf("Expected %s, got %s", mpris.PlaybackPlaying, status)
+	}
+
+	if err := player.PlayPause(); err != nil {
+		t.Fatal(err)
+	}
+	status, err = player.GetPlaybackStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != mpris.PlaybackPaused {
+		t.Errorf("Expected %s, got %s", mpris.PlaybackPaused, status)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 || calls[0].Method != "Play" || calls[1].Method != "PlayPause" {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	player, fake := newPlayer(t, "position")
+
+	trackID := dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackID),
+		"mpris:length":  dbus.MakeVariant(int64(180000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.SeekTo(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SeekBy(30 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	position, err := player.GetPositionDuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 90*time.Second {
+		t.Errorf("Expected position 1m30s, got %s", position)
+	}
+
+	stale := dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/0")
+	if err := player.SetTrackPosition(&stale, 10); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 90000000 {
+		t.Errorf("Expected stale SetPosition to be ignored, position is %d", fake.Position())
+	}
+}
+
+func TestSetProperty(t *testing.T) {
+	player, fake := newPlayer(t, "property")
+
+	if err := player.SetVolume(0.25); err != nil {
+		t.Fatal(err)
+	}
+	value, _ := fake.GetProperty("org.mpris.MediaPlayer2.Player", "Volume")
+	if value.Value() != 0.25 {
+		t.Errorf("Expected volume 0.25, got %v", value.Value())
+	}
+
+	if err := player.SetProperty(mpris.PlayerInterface, "Unknown", true); err == nil {
+		t.Error("Expected setting an unknown property to fail")
+	}
+}
+
+func TestHandleFunc(t *testing.T) {
+	player, fake := newPlayer(t, "handler")
+
+	fake.HandleFunc("org.mpris.MediaPlayer2.Player", "Next", func(args ...interface{}) *dbus.Error {
+		return dbus.NewError("org.mpris.MediaPlayer2.Error.Test", []interface{}{"no next track"})
+	})
+
+	if err := player.Next(); err == nil {
+		t.Error("Expected Next to fail")
+	}
+}
+
+func TestSignals(t *testing.T) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		t.Skip(err)
+	}
+	_, fake := newPlayer(t, "signals")
+
+	err = conn.AddMatchSignal(dbus.WithMatchSender(fake.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.RemoveMatchSignal(dbus.WithMatchSender(fake.Name()))
+
+	ch := make(chan *dbus.Signal, 10)
+	conn.Signal(ch)
+	defer conn.RemoveSignal(ch)
+
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EmitSeeked(42); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"org.freedesktop.DBus.Properties.PropertiesChanged", "org.mpris.MediaPlayer2.Player.Seeked"} {
+		select {
+		case sig := <-ch:
+			if sig.Name != expected {
+				t.Errorf("Expected signal %s, got %s", expected, sig.Name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s", expected)
+		}
+	}
+}
+
+func TestStartBus(t *testing.T) {
+	bus, err := mpristest.StartBus()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer bus.Close()
+
+	conn, err := dbus.Dial(bus.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Hello(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the players of the session bus aren't on the private bus
+	names, err := mpris.List(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Expected no player on a new bus, got %v", names)
+	}
+}
@@ -0,0 +1,233 @@
+package mpristest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	trackListInterface = "org.mpris.MediaPlayer2.TrackList"
+	playlistsInterface = "org.mpris.MediaPlayer2.Playlists"
+)
+
+// Playlist is a playlist of the fake player.
+type Playlist struct {
+	ID   dbus.ObjectPath
+	Name string
+	Icon string
+}
+
+// maybePlaylist is the D-Bus representation of the ActivePlaylist property.
+type maybePlaylist struct {
+	Valid    bool
+	Playlist Playlist
+}
+
+// SetTracks replaces the track list, emitting TrackListReplaced. Each track is described by
+// its metadata, which must have a "mpris:trackid".
+func (p *Player) SetTracks(tracks []map[string]dbus.Variant) error {
+	ids := make([]dbus.ObjectPath, len(tracks))
+	p.mu.Lock()
+	p.tracks = make([]map[string]dbus.Variant, len(tracks))
+	for i, track := range tracks {
+		p.tracks[i] = track
+		ids[i], _ = track["mpris:trackid"].Value().(dbus.ObjectPath)
+	}
+	p.props[trackListInterface]["Tracks"] = dbus.MakeVariant(ids)
+	p.mu.Unlock()
+
+	return p.conn.Emit(objectPath, trackListInterface+".TrackListReplaced", ids, p.currentTrackID())
+}
+
+// Tracks returns the ids of the tracks in the track list.
+func (p *Player) Tracks() []dbus.ObjectPath {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.trackIDs()
+}
+
+func (p *Player) trackIDs() []dbus.ObjectPath {
+	ids := make([]dbus.ObjectPath, len(p.tracks))
+	for i, track := range p.tracks {
+		ids[i], _ = track["mpris:trackid"].Value().(dbus.ObjectPath)
+	}
+	return ids
+}
+
+func (p *Player) trackIndex(trackID dbus.ObjectPath) int {
+	for i, track := range p.tracks {
+		if track["mpris:trackid"].Value() == trackID {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetPlaylists replaces the playlists, in the user defined order.
+func (p *Player) SetPlaylists(playlists []Playlist) error {
+	p.mu.Lock()
+	p.playlists = append([]Playlist(nil), playlists...)
+	p.mu.Unlock()
+	return p.SetProperty(playlistsInterface, "PlaylistCount", uint32(len(playlists)))
+}
+
+// SetActivePlaylist sets the ActivePlaylist property. A nil playlist means there is no active
+// playlist.
+func (p *Player) SetActivePlaylist(playlist *Playlist) error {
+	active := maybePlaylist{Playlist: Playlist{ID: "/"}}
+	if playlist != nil {
+		active = maybePlaylist{true, *playlist}
+	}
+	return p.SetProperty(playlistsInterface, "ActivePlaylist", active)
+}
+
+// EmitPlaylistChanged emits the PlaylistChanged signal for the playlist.
+func (p *Player) EmitPlaylistChanged(playlist Playlist) error {
+	return p.conn.Emit(objectPath, playlistsInterface+".PlaylistChanged", playlist)
+}
+
+type trackListExport struct {
+	p *Player
+}
+
+func (e *trackListExport) GetTracksMetadata(trackIDs []dbus.ObjectPath) ([]map[string]dbus.Variant, *dbus.Error) {
+	if handler := e.p.record(trackListInterface, "GetTracksMetadata", trackIDs); handler != nil {
+		return nil, handler(trackIDs)
+	}
+	e.p.mu.Lock()
+	defer e.p.mu.Unlock()
+	metadata := make([]map[string]dbus.Variant, 0, len(trackIDs))
+	for _, trackID := range trackIDs {
+		if i := e.p.trackIndex(trackID); i >= 0 {
+			metadata = append(metadata, e.p.tracks[i])
+		}
+	}
+	return metadata, nil
+}
+
+func (e *trackListExport) AddTrack(uri string, after dbus.ObjectPath, setAsCurrent bool) *dbus.Error {
+	if handler := e.p.record(trackListInterface, "AddTrack", uri, after, setAsCurrent); handler != nil {
+		return handler(uri, after, setAsCurrent)
+	}
+
+	e.p.mu.Lock()
+	e.p.trackCount++
+	trackID := dbus.ObjectPath(fmt.Sprintf("/org/mpristest/track/%d", e.p.trackCount))
+	track := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackID),
+		"xesam:url":     dbus.MakeVariant(uri),
+	}
+	index := 0
+	if after != NoTrack {
+		index = e.p.trackIndex(after) + 1
+		if index == 0 {
+			e.p.mu.Unlock()
+			return nil
+		}
+	}
+	e.p.tracks = append(e.p.tracks, nil)
+	copy(e.p.tracks[index+1:], e.p.tracks[index:])
+	e.p.tracks[index] = track
+	e.p.props[trackListInterface]["Tracks"] = dbus.MakeVariant(e.p.trackIDs())
+	e.p.mu.Unlock()
+
+	if err := e.p.conn.Emit(objectPath, trackListInterface+".TrackAdded", track, after); err != nil {
+		return toDBusError(err)
+	}
+	if setAsCurrent {
+		return toDBusError(e.p.SetMetadata(track))
+	}
+	return nil
+}
+
+func (e *trackListExport) RemoveTrack(trackID dbus.ObjectPath) *dbus.Error {
+	if handler := e.p.record(trackListInterface, "RemoveTrack", trackID); handler != nil {
+		return handler(trackID)
+	}
+	e.p.mu.Lock()
+	index := e.p.trackIndex(trackID)
+	if index < 0 {
+		e.p.mu.Unlock()
+		return nil
+	}
+	e.p.tracks = append(e.p.tracks[:index], e.p.tracks[index+1:]...)
+	e.p.props[trackListInterface]["Tracks"] = dbus.MakeVariant(e.p.trackIDs())
+	e.p.mu.Unlock()
+	return toDBusError(e.p.conn.Emit(objectPath, trackListInterface+".TrackRemoved", trackID))
+}
+
+func (e *trackListExport) GoTo(trackID dbus.ObjectPath) *dbus.Error {
+	if handler := e.p.record(trackListInterface, "GoTo", trackID); handler != nil {
+		return handler(trackID)
+	}
+	e.p.mu.Lock()
+	index := e.p.trackIndex(trackID)
+	var track map[string]dbus.Variant
+	if index >= 0 {
+		track = e.p.tracks[index]
+	}
+	e.p.mu.Unlock()
+	if track == nil {
+		return nil
+	}
+	return toDBusError(e.p.SetMetadata(track))
+}
+
+type playlistsExport struct {
+	p *Player
+}
+
+func (e *playlistsExport) ActivatePlaylist(playlistID dbus.ObjectPath) *dbus.Error {
+	if handler := e.p.record(playlistsInterface, "ActivatePlaylist", playlistID); handler != nil {
+		return handler(playlistID)
+	}
+	e.p.mu.Lock()
+	var playlist *Playlist
+	for _, candidate := range e.p.playlists {
+		if candidate.ID == playlistID {
+			candidate := candidate
+			playlist = &candidate
+		}
+	}
+	e.p.mu.Unlock()
+	if playlist == nil {
+		return dbus.MakeFailedError(fmt.Errorf("unknown playlist %s", playlistID))
+	}
+	if err := e.p.SetActivePlaylist(playlist); err != nil {
+		return toDBusError(err)
+	}
+	return e.p.setStatus("Playing")
+}
+
+// GetPlaylists supports the UserDefined and Alphabetical orderings. The others are handled as
+// UserDefined.
+func (e *playlistsExport) GetPlaylists(index, maxCount uint32, order string, reverse bool) ([]Playlist, *dbus.Error) {
+	if handler := e.p.record(playlistsInterface, "GetPlaylists", index, maxCount, order, reverse); handler != nil {
+		return nil, handler(index, maxCount, order, reverse)
+	}
+	e.p.mu.Lock()
+	playlists := append([]Playlist(nil), e.p.playlists...)
+	e.p.mu.Unlock()
+
+	if order == "Alphabetical" {
+		sort.SliceStable(playlists, func(a, b int) bool {
+			return playlists[a].Name < playlists[b].Name
+		})
+	}
+	if reverse {
+		for a, b := 0, len(playlists)-1; a < b; a, b = a+1, b-1 {
+			playlists[a], playlists[b] = playlists[b], playlists[a]
+		}
+	}
+
+	if int(index) >= len(playlists) {
+		return []Playlist{}, nil
+	}
+	playlists = playlists[index:]
+	if int(maxCount) < len(playlists) {
+		playlists = playlists[:maxCount]
+	}
+	return playlists, nil
+}
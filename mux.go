@@ -0,0 +1,197 @@
+package mpris
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy is what a buffer does with a new event or signal when it's full, as set with
+// WithSignalBuffer and EventMux.Subscribe.
+type DropPolicy int
+
+const (
+	// DropNone waits for the receiver to make room, delaying the events of the other
+	// receivers in the meantime.
+	DropNone DropPolicy = iota
+	// DropOldest removes the oldest buffered event to make room for the new one.
+	DropOldest
+	// DropNewest leaves out the new event.
+	DropNewest
+)
+
+// EventMux shares a single subscription to the events of a player between several receivers,
+// each with its own buffer and drop policy, so a slow receiver doesn't hold the others back.
+// It's safe for concurrent use.
+type EventMux struct {
+	cancel  context.CancelFunc
+	stopped chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[*MuxSubscriber]struct{}
+	ended       bool
+}
+
+// MuxSubscriber is a receiver of the events of an EventMux.
+type MuxSubscriber struct {
+	mux     *EventMux
+	events  chan Event
+	policy  DropPolicy
+	dropped atomic.Uint64
+
+	// mu is held while sending, so the channel isn't closed in the meantime.
+	mu        sync.Mutex
+	stop      chan struct{}
+	closed    bool
+	closeOnce sync.Once
+}
+
+// NewEventMux subscribes to the events of the player, shaped by opts, and shares them between
+// the receivers added with Subscribe. The receivers' channels are closed when ctx is done, the
+// mux is closed or the subscription ends, like the connection being closed.
+func NewEventMux(ctx context.Context, player *Player, opts ...SubscribeOption) (*EventMux, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := player.Subscribe(ctx, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	m := &EventMux{
+		cancel:      cancel,
+		stopped:     make(chan struct{}),
+		subscribers: make(map[*MuxSubscriber]struct{}),
+	}
+	go m.run(events)
+	return m, nil
+}
+
+func (m *EventMux) run(events <-chan Event) {
+	defer close(m.stopped)
+	for event := range events {
+		for _, sub := range m.snapshot() {
+			sub.send(event)
+		}
+	}
+
+	m.mu.Lock()
+	m.ended = true
+	subs := m.subscribers
+	m.subscribers = nil
+	m.mu.Unlock()
+	for sub := range subs {
+		sub.close()
+	}
+}
+
+// snapshot returns the current receivers, so they can be sent the events without holding the
+// lock.
+func (m *EventMux) snapshot() []*MuxSubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs := make([]*MuxSubscriber, 0, len(m.subscribers))
+	for sub := range m.subscribers {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// Subscribe adds a receiver getting the events sent from now on, buffering up to buffer
+// events. When the buffer is full, the new events are handled according to policy: with a
+// buffer of 0, the drop policies drop every event the receiver isn't waiting for. The
+// receiver must be closed once done with, unless the mux ended.
+func (m *EventMux) Subscribe(buffer int, policy DropPolicy) *MuxSubscriber {
+	sub := &MuxSubscriber{
+		mux:    m,
+		events: make(chan Event, buffer),
+		policy: policy,
+		stop:   make(chan struct{}),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ended {
+		sub.close()
+		return sub
+	}
+	m.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Close ends the subscription to the player events, closing the channels of the receivers.
+func (m *EventMux) Close() error {
+	m.cancel()
+	<-m.stopped
+	return nil
+}
+
+// send sends the event according to the drop policy, unless the receiver is closed.
+func (s *MuxSubscriber) send(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	switch s.policy {
+	case DropOldest:
+		for {
+			select {
+			case s.events <- event:
+				return
+			default:
+			}
+			// the receiver may take the oldest event in the meantime, which makes room as well
+			select {
+			case <-s.events:
+				s.dropped.Add(1)
+			default:
+				if cap(s.events) == 0 {
+					s.dropped.Add(1)
+					return
+				}
+			}
+		}
+	case DropNewest:
+		select {
+		case s.events <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	default:
+		select {
+		case s.events <- event:
+		case <-s.stop:
+		}
+	}
+}
+
+// Events returns the channel receiving the events. It's closed when the receiver or the mux is
+// closed.
+func (s *MuxSubscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (s *MuxSubscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close removes the receiver from the mux and closes its channel. It can be called more than
+// once.
+func (s *MuxSubscriber) Close() error {
+	s.mux.mu.Lock()
+	delete(s.mux.subscribers, s)
+	s.mux.mu.Unlock()
+	s.close()
+	return nil
+}
+
+func (s *MuxSubscriber) close() {
+	s.closeOnce.Do(func() {
+		// a blocked send is stopped first, so the lock can be taken
+		close(s.stop)
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+	})
+}
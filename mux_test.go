@@ -0,0 +1,65 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestEventMux(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	mux, err := NewEventMux(context.Background(), player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mux.Close()
+
+	reader := mux.Subscribe(0, DropNone)
+	newest := mux.Subscribe(1, DropNewest)
+	oldest := mux.Subscribe(1, DropOldest)
+
+	statuses := []string{"Playing", "Paused", "Stopped"}
+	for _, status := range statuses {
+		if err := fake.SetPlaybackStatus(status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the receivers that aren't read don't hold back the one that is
+	for _, status := range statuses {
+		event := receiveEvent(t, reader.Events())
+		changed, ok := event.(PropertiesChangedEvent)
+		if !ok || changed.Changed["PlaybackStatus"].Value() != status {
+			t.Fatalf("Expected the status %s, got %#v", status, event)
+		}
+	}
+
+	for name, sub := range map[string]*MuxSubscriber{"DropNewest": newest, "DropOldest": oldest} {
+		mpristest.Eventually(t, func() bool { return sub.Dropped() == 2 }, name+" should drop 2 events")
+	}
+	for sub, status := range map[*MuxSubscriber]string{newest: "Playing", oldest: "Stopped"} {
+		event := receiveEvent(t, sub.Events())
+		if changed, ok := event.(PropertiesChangedEvent); !ok || changed.Changed["PlaybackStatus"].Value() != status {
+			t.Errorf("Expected the buffered status to be %s, got %#v", status, event)
+		}
+	}
+
+	if err := newest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-newest.Events(); ok {
+		t.Error("Expected the channel of a closed receiver to be closed")
+	}
+
+	mux.Close()
+	for _, sub := range []*MuxSubscriber{reader, oldest} {
+		if _, ok := <-sub.Events(); ok {
+			t.Error("Expected the channels to be closed with the mux")
+		}
+	}
+	if _, ok := <-mux.Subscribe(1, DropNone).Events(); ok {
+		t.Error("Expected the receivers added after Close to be closed")
+	}
+}
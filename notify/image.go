@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// imageData is the image-data hint of the notifications, a raw RGBA image.
+type imageData struct {
+	Width         int32
+	Height        int32
+	RowStride     int32
+	HasAlpha      bool
+	BitsPerSample int32
+	Channels      int32
+	Data          []byte
+}
+
+// decodeImage decodes a PNG, JPEG or GIF image and scales it down to fit in a square of size
+// pixels, keeping its aspect ratio.
+func decodeImage(data []byte, size int) (imageData, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return imageData{}, err
+	}
+
+	bounds := src.Bounds()
+	width, height := fitSize(bounds.Dx(), bounds.Dy(), size)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst.Set(x, y, averageColor(src, image.Rect(
+				bounds.Min.X+x*bounds.Dx()/width,
+				bounds.Min.Y+y*bounds.Dy()/height,
+				bounds.Min.X+(x+1)*bounds.Dx()/width,
+				bounds.Min.Y+(y+1)*bounds.Dy()/height,
+			)))
+		}
+	}
+
+	return imageData{
+		Width:         int32(width),
+		Height:        int32(height),
+		RowStride:     int32(dst.Stride),
+		HasAlpha:      true,
+		BitsPerSample: 8,
+		Channels:      4,
+		Data:          dst.Pix,
+	}, nil
+}
+
+// fitSize returns the size of the image scaled down to fit in the square, which is never
+// larger than the original.
+func fitSize(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
+
+// averageColor returns the average color of the pixels in the area, so a scaled down image
+// doesn't look noisy. The area is never empty when scaling down.
+func averageColor(src image.Image, area image.Rectangle) color.Color {
+	if area.Empty() {
+		return src.At(area.Min.X, area.Min.Y)
+	}
+	var r, g, b, a, count uint64
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			pr, pg, pb, pa := src.At(x, y).RGBA()
+			r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+			count++
+		}
+	}
+	return color.RGBA64{uint16(r / count), uint16(g / count), uint16(b / count), uint16(a / count)}
+}
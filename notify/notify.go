@@ -0,0 +1,144 @@
+// Package notify shows a desktop notification, through org.freedesktop.Notifications, when
+// the track played by a MPRIS player changes.
+package notify
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/art"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName    = "org.freedesktop.Notifications"
+	notificationsPath    = "/org/freedesktop/Notifications"
+	notifyMethod         = notificationsName + ".Notify"
+	defaultAppName       = "go-mpris"
+	defaultArtSize       = 128
+	defaultExpireTimeout = -1
+)
+
+// Notifier shows the track change notifications. Every player has a single notification, that's
+// replaced when its track changes. The zero value is not usable, use New.
+type Notifier struct {
+	conn *dbus.Conn
+
+	// AppName is the application name sent with the notifications. Defaults to "go-mpris".
+	AppName string
+	// Fetcher fetches the album art shown in the notifications. The art is left out when it's
+	// nil.
+	Fetcher *art.Fetcher
+	// ArtSize is the maximum width and height of the album art, in pixels. Defaults to 128.
+	ArtSize int
+
+	mu       sync.Mutex
+	disabled map[string]bool
+	ids      map[string]uint32
+}
+
+// New returns a notifier sending the notifications on the session bus connection conn, with
+// album art fetched by an art.Fetcher without cache.
+func New(conn *dbus.Conn) *Notifier {
+	return &Notifier{
+		conn:     conn,
+		Fetcher:  &art.Fetcher{},
+		disabled: make(map[string]bool),
+		ids:      make(map[string]uint32),
+	}
+}
+
+// SetEnabled enables or disables the notifications of the player with the full name. The
+// notifications are enabled by default.
+func (n *Notifier) SetEnabled(name string, enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if enabled {
+		delete(n.disabled, name)
+	} else {
+		n.disabled[name] = true
+	}
+}
+
+// Enabled returns true if the notifications of the player are enabled.
+func (n *Notifier) Enabled(name string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return !n.disabled[name]
+}
+
+// Watch shows a notification every time the track of the player changes, until ctx is done.
+// The notification errors are ignored, as a missing notification daemon shouldn't stop the
+// watching.
+func (n *Notifier) Watch(ctx context.Context, player *mpris.Player) error {
+	changes, err := player.OnTrackChange(ctx)
+	if err != nil {
+		return err
+	}
+	for change := range changes {
+		if !n.Enabled(player.GetName()) {
+			continue
+		}
+		n.Notify(ctx, player.GetName(), change.Metadata)
+	}
+	return ctx.Err()
+}
+
+// Notify shows the notification of the track described by the metadata, replacing the previous
+// notification of the player.
+func (n *Notifier) Notify(ctx context.Context, name string, metadata mpris.Metadata) error {
+	summary := metadata.Title()
+	if summary == "" {
+		summary = metadata.URL()
+	}
+	var body []string
+	if artists := metadata.Artists(); len(artists) > 0 {
+		body = append(body, strings.Join(artists, ", "))
+	}
+	if album := metadata.Album(); album != "" {
+		body = append(body, album)
+	}
+
+	hints := map[string]dbus.Variant{}
+	if n.Fetcher != nil && metadata.ArtURL() != "" {
+		// the notification is still worth showing without the art
+		if data, err := n.Fetcher.FetchMetadata(ctx, metadata); err == nil {
+			if image, err := decodeImage(data, n.artSize()); err == nil {
+				hints["image-data"] = dbus.MakeVariant(image)
+			}
+		}
+	}
+
+	n.mu.Lock()
+	replacesID := n.ids[name]
+	n.mu.Unlock()
+
+	var id uint32
+	obj := n.conn.Object(notificationsName, notificationsPath)
+	err := obj.CallWithContext(ctx, notifyMethod, 0, n.appName(), replacesID, "", summary,
+		strings.Join(body, "\n"), []string{}, hints, int32(defaultExpireTimeout)).Store(&id)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.ids[name] = id
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *Notifier) appName() string {
+	if n.AppName == "" {
+		return defaultAppName
+	}
+	return n.AppName
+}
+
+func (n *Notifier) artSize() int {
+	if n.ArtSize <= 0 {
+		return defaultArtSize
+	}
+	return n.ArtSize
+}
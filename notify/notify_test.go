@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+// notification is a Notify call received by the fake notification daemon.
+type notification struct {
+	replacesID uint32
+	summary    string
+	body       string
+	hints      map[string]dbus.Variant
+}
+
+type daemon struct {
+	mu            sync.Mutex
+	notifications []notification
+}
+
+func (d *daemon) Notify(appName string, replacesID uint32, appIcon, summary, body string, actions []string,
+	hints map[string]dbus.Variant, expireTimeout int32) (uint32, *dbus.Error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notifications = append(d.notifications, notification{replacesID, summary, body, hints})
+	if replacesID != 0 {
+		return replacesID, nil
+	}
+	return uint32(len(d.notifications)), nil
+}
+
+// newDaemon exports a fake notification daemon, skipping the test if the bus already has one.
+func newDaemon(t *testing.T) *daemon {
+	conn := mpristest.PrivateConn(t)
+	d := &daemon{}
+	if err := conn.Export(d, notificationsPath, notificationsName); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(notificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Skip("the bus already has a notification daemon")
+	}
+	return d
+}
+
+func pngDataURL(t *testing.T, width, height int) string {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestNotify(t *testing.T) {
+	d := newDaemon(t)
+	notifier := New(mpristest.PrivateConn(t))
+	notifier.ArtSize = 32
+
+	metadata := mpris.Metadata{
+		"xesam:title":  dbus.MakeVariant("Title"),
+		"xesam:artist": dbus.MakeVariant([]string{"First", "Second"}),
+		"xesam:album":  dbus.MakeVariant("Album"),
+		"mpris:artUrl": dbus.MakeVariant(pngDataURL(t, 64, 48)),
+	}
+	ctx := context.Background()
+	if err := notifier.Notify(ctx, "player", metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(ctx, "player", metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.notifications) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(d.notifications))
+	}
+	first := d.notifications[0]
+	if first.summary != "Title" || first.body != "First, Second\nAlbum" {
+		t.Errorf("Unexpected notification %q %q", first.summary, first.body)
+	}
+	if d.notifications[1].replacesID != 1 {
+		t.Errorf("Expected the notification to be replaced, got %d", d.notifications[1].replacesID)
+	}
+
+	image, ok := first.hints["image-data"].Value().([]interface{})
+	if !ok || len(image) != 7 {
+		t.Fatalf("Unexpected image data %v", first.hints["image-data"])
+	}
+	if image[0] != int32(32) || image[1] != int32(24) || len(image[6].([]byte)) != 32*24*4 {
+		t.Errorf("Expected a 32x24 image, got %vx%v", image[0], image[1])
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	notifier := New(nil)
+	if !notifier.Enabled("player") {
+		t.Error("Expected the notifications to be enabled by default")
+	}
+	notifier.SetEnabled("player", false)
+	if notifier.Enabled("player") || !notifier.Enabled("other") {
+		t.Error("Expected only the player notifications to be disabled")
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct{ width, height, size, expectedWidth, expectedHeight int }{
+		{64, 48, 32, 32, 24},
+		{48, 64, 32, 24, 32},
+		{16, 16, 32, 16, 16},
+		{1000, 1, 10, 10, 1},
+	}
+	for _, test := range tests {
+		width, height := fitSize(test.width, test.height, test.size)
+		if width != test.expectedWidth || height != test.expectedHeight {
+			t.Errorf("Expected %dx%d to fit as %dx%d, got %dx%d", test.width, test.height,
+				test.expectedWidth, test.expectedHeight, width, height)
+		}
+	}
+}
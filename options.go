@@ -0,0 +1,66 @@
+package mpris
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Option configures a Player created by New.
+type Option func(*Player)
+
+// WithCallTimeout limits how long each D-Bus call waits for the player to answer.
+// The timeout applies on top of the context set with WithContext.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(p *Player) {
+		p.timeout = timeout
+	}
+}
+
+// WithObjectPath sets the object path of the player, for players that don't use the
+// standard /org/mpris/MediaPlayer2 path.
+func WithObjectPath(path dbus.ObjectPath) Option {
+	return func(p *Player) {
+		p.path = path
+	}
+}
+
+// WithNoAutoStart prevents the bus from starting the player through D-Bus activation when
+// it's not running.
+func WithNoAutoStart() Option {
+	return func(p *Player) {
+		p.flags |= dbus.FlagNoAutoStart
+	}
+}
+
+// WithDefaultFlags sets the flags of all the D-Bus calls of the player, like
+// dbus.FlagNoReplyExpected to make every command fire-and-forget, as WithFlags does for a copy
+// of the player.
+func WithDefaultFlags(flags dbus.Flags) Option {
+	return func(p *Player) {
+		p.flags |= flags
+	}
+}
+
+// WithSignalBuffer sets how many signals the subscriptions of the player buffer, 16 by default,
+// and what they do when the buffer is full because the signals aren't received fast enough.
+// With DropNone, the default, the signals wait for the receiver, until the connection has to
+// queue them itself, out of order. The drop policies instead keep the receiver from falling
+// behind, and the dropped signals are counted by Subscription.Dropped. The size is at least 1.
+//
+// It applies to the subscriptions made with OnSignal, Subscribe and Events, but not to the ones
+// keeping the property cache and the owner up to date, which never drop signals.
+func WithSignalBuffer(size int, policy DropPolicy) Option {
+	return func(p *Player) {
+		p.signalBuffer = max(size, 1)
+		p.dropPolicy = policy
+	}
+}
+
+// WithOwnedConn makes the player own the connection, which is closed along with the player by
+// Close. It's meant for private connections created for the player only.
+func WithOwnedConn() Option {
+	return func(p *Player) {
+		p.ownsConn = true
+	}
+}
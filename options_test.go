@@ -0,0 +1,99 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestWithCallTimeout(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	fake.HandleFunc(PlayerInterface, "Next", func(args ...interface{}) *dbus.Error {
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	})
+
+	player = New(player.conn, fake.Name(), WithCallTimeout(50*time.Millisecond))
+	if err := player.Next(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if err := player.Play(); err != nil {
+		t.Errorf("Expected fast calls to succeed, got %v", err)
+	}
+}
+
+func TestWithObjectPath(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	player = New(player.conn, fake.Name(), WithObjectPath("/org/mpris/Custom"), WithNoAutoStart())
+	if player.obj.Path() != "/org/mpris/Custom" {
+		t.Errorf("Expected custom object path, got %s", player.obj.Path())
+	}
+	if player.flags&dbus.FlagNoAutoStart == 0 {
+		t.Error("Expected the no auto start flag to be set")
+	}
+	if err := player.Play(); err == nil {
+		t.Error("Expected calls on a path without a player to fail")
+	}
+}
+
+func TestWithFlags(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	fake.HandleFunc(PlayerInterface, "Next", func(args ...interface{}) *dbus.Error {
+		time.Sleep(200 * time.Millisecond)
+		return dbus.MakeFailedError(errors.New("next failed"))
+	})
+	if err := player.Next(); err == nil {
+		t.Error("Expected the error of Next")
+	}
+
+	// the fire-and-forget commands don't wait for the player, and its errors are lost
+	start := time.Now()
+	if err := player.NoReply().Next(); err != nil {
+		t.Errorf("Expected no error without a reply, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected Next to return right away, took %v", elapsed)
+	}
+	mpristest.Eventually(t, func() bool {
+		count := 0
+		for _, call := range fake.Calls() {
+			if call.Method == "Next" {
+				count++
+			}
+		}
+		return count == 2
+	}, "Expected the player to get the fire-and-forget Next")
+
+	// the getters still wait for the answer
+	if err := fake.SetProperty(PlayerInterface, "Volume", 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.NoReply().GetVolume(); err != nil || volume != 0.25 {
+		t.Errorf("Expected the volume 0.25, got %v (%v)", volume, err)
+	}
+	if err := player.NoReply().SetVolume(0.5); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		volume, err := player.GetVolume()
+		return err == nil && volume == 0.5
+	}, "Expected the fire-and-forget Set to reach the player")
+
+	defaults := New(player.conn, fake.Name(), WithDefaultFlags(dbus.FlagNoReplyExpected))
+	if defaults.flags != dbus.FlagNoReplyExpected {
+		t.Errorf("Expected the default flags to be set, got %v", defaults.flags)
+	}
+	if copied := defaults.WithFlags(dbus.FlagNoAutoStart); copied.flags != dbus.FlagNoReplyExpected|dbus.FlagNoAutoStart || defaults.flags != dbus.FlagNoReplyExpected {
+		t.Errorf("Expected WithFlags to add the flags to a copy, got %v and %v", copied.flags, defaults.flags)
+	}
+	if status, err := defaults.GetPlaybackStatus(); err != nil || status != PlaybackStopped {
+		t.Errorf("Expected the getters to work with the default flags, got %v (%v)", status, err)
+	}
+}
@@ -0,0 +1,76 @@
+package mpris
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ownerState follows the unique name owning the player name, shared by the copies made by
+// WithContext.
+type ownerState struct {
+	mu    sync.Mutex
+	owner string
+	sub   *Subscription
+}
+
+// Owner returns the unique name of the owner of the player name, like ":1.42", or
+// ErrPlayerNotFound if the player is not running. Once resolved, the owner is kept up to date
+// by the NameOwnerChanged signals, so a player restarting under the same name is followed
+// without asking the bus again.
+func (i *Player) Owner() (string, error) {
+	if i.conn == nil {
+		// without signals, the owner can't be kept up to date
+		return i.getNameOwner()
+	}
+	i.owner.mu.Lock()
+	defer i.owner.mu.Unlock()
+	if i.owner.sub != nil {
+		select {
+		case <-i.owner.sub.Done():
+			i.owner.sub = nil
+		default:
+			if i.owner.owner == "" {
+				return "", ErrPlayerNotFound
+			}
+			return i.owner.owner, nil
+		}
+	}
+
+	// the subscription goes first, so a change in the meantime isn't missed
+	signals := make(chan *dbus.Signal, i.signalBuffer)
+	sub, err := i.onSignal(signals, DropNone)
+	if err != nil {
+		return "", err
+	}
+	owner, err := i.getNameOwner()
+	if err != nil {
+		sub.Close()
+		return "", err
+	}
+	i.owner.sub = sub
+	i.owner.owner = owner
+	go i.owner.follow(sub, signals)
+	return owner, nil
+}
+
+func (o *ownerState) follow(sub *Subscription, signals chan *dbus.Signal) {
+	for {
+		select {
+		case <-sub.Done():
+			return
+		case sig := <-signals:
+			event, ok := sub.player.decodeSignal(sig)
+			if !ok {
+				continue
+			}
+			if changed, ok := event.(OwnerChangedEvent); ok {
+				o.mu.Lock()
+				if o.sub == sub {
+					o.owner = changed.NewOwner
+				}
+				o.mu.Unlock()
+			}
+		}
+	}
+}
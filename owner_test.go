@@ -0,0 +1,81 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestOwner(t *testing.T) {
+	shortName := fmt.Sprintf("mpristest.owner%d", os.Getpid())
+	first := mpristest.PrivateConn(t)
+	fake, err := mpristest.New(first, shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player := New(mpristest.PrivateConn(t), fake.Name())
+	if owner, err := player.Owner(); err != nil || owner != first.Names()[0] {
+		t.Fatalf("Expected the owner to be %s, got %q %v", first.Names()[0], owner, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the player restarts under the same name
+	fake.Close()
+	second := mpristest.PrivateConn(t)
+	restarted, err := mpristest.New(second, shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+
+	var changes []OwnerChangedEvent
+	for len(changes) < 2 {
+		event, ok := <-events
+		if !ok {
+			t.Fatal("Timed out waiting for the owner changes")
+		}
+		if changed, ok := event.(OwnerChangedEvent); ok {
+			changes = append(changes, changed)
+		}
+	}
+	if changes[0].NewOwner != "" || changes[1].NewOwner != second.Names()[0] {
+		t.Errorf("Unexpected owner changes %v", changes)
+	}
+	mpristest.Eventually(t, func() bool {
+		owner, _ := player.Owner()
+		return owner == second.Names()[0]
+	}, "The owner wasn't updated")
+
+	// the subscription follows the new owner
+	if err := restarted.EmitSeeked(1000000); err != nil {
+		t.Fatal(err)
+	}
+	for event := range events {
+		if seeked, ok := event.(SeekedEvent); ok {
+			if seeked.Position != time.Second {
+				t.Errorf("Expected a seek to 1s, got %s", seeked.Position)
+			}
+			return
+		}
+	}
+	t.Error("Timed out waiting for the signal of the restarted player")
+}
+
+func TestOwnerNotFound(t *testing.T) {
+	player := New(mpristest.PrivateConn(t), BaseInterface+".mpristest.missing")
+	if _, err := player.Owner(); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
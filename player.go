@@ -0,0 +1,21 @@
+package mpris
+
+import (
+	"time"
+)
+
+// PlayerClient calls the org.mpris.MediaPlayer2.Player interface of a player. Most of its
+// methods are generated from the spec, in bindings_gen.go.
+type PlayerClient struct {
+	core *Player
+}
+
+// SetPosition sets the position of the track, which must be the current one. As the spec
+// requires, the player ignores the call if the track isn't the current one anymore. It
+// returns ErrInvalidTrackID for NoTrack and invalid track ids.
+func (c PlayerClient) SetPosition(trackID TrackID, position time.Duration) error {
+	if err := checkTrackID(trackID); err != nil {
+		return err
+	}
+	return c.core.call(PlayerInterface+".SetPosition", trackID.ObjectPath(), durationToMicroseconds(position)).Err
+}
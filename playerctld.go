@@ -0,0 +1,69 @@
+package mpris
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// PlayerctldName is the bus name of the playerctl daemon.
+	PlayerctldName = BaseInterface + ".playerctld"
+	// PlayerctldInterface is the interface used to control the playerctl daemon.
+	PlayerctldInterface = "com.github.altdesktop.playerctld"
+)
+
+// Playerctld is the playerctl daemon. It's a player itself that forwards every call to the
+// player it considers active, which is the most recently active one unless shifted.
+type Playerctld struct {
+	*Player
+}
+
+// NewPlayerctld connects to the playerctl daemon in the connection conn, configured by opts.
+func NewPlayerctld(conn *dbus.Conn, opts ...Option) *Playerctld {
+	return &Playerctld{New(conn, PlayerctldName, opts...)}
+}
+
+// DetectPlayerctld connects to the playerctl daemon if it's running, returning
+// ErrPlayerNotFound otherwise.
+func DetectPlayerctld(conn *dbus.Conn, opts ...Option) (*Playerctld, error) {
+	player, err := NewChecked(conn, PlayerctldName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Playerctld{player}, nil
+}
+
+// Shift makes the next player the active one and returns its name.
+func (p *Playerctld) Shift() (string, error) {
+	var name string
+	err := p.query(PlayerctldInterface + ".Shift").Store(&name)
+	return name, err
+}
+
+// Unshift makes the previous player the active one and returns its name.
+func (p *Playerctld) Unshift() (string, error) {
+	var name string
+	err := p.query(PlayerctldInterface + ".Unshift").Store(&name)
+	return name, err
+}
+
+// GetPlayerNames returns the names of the players known by the daemon, the active one first.
+func (p *Playerctld) GetPlayerNames() ([]string, error) {
+	variant, err := p.getProperty(PlayerctldInterface, "PlayerNames")
+	if err != nil {
+		return nil, err
+	}
+	return p.stringsValue(variant, "PlayerNames")
+}
+
+// GetActivePlayer returns the player the daemon considers active, connected with the same
+// options as the daemon. ErrPlayerNotFound is returned when the daemon knows no players.
+func (p *Playerctld) GetActivePlayer() (*Player, error) {
+	names, err := p.GetPlayerNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, ErrPlayerNotFound
+	}
+	return p.withName(names[0]), nil
+}
@@ -0,0 +1,62 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+type fakePlayerctld struct {
+	fake *mpristest.Player
+}
+
+func (f *fakePlayerctld) Shift() (string, *dbus.Error) {
+	value, _ := f.fake.GetProperty(PlayerctldInterface, "PlayerNames")
+	names := value.Value().([]string)
+	names = append(names[1:], names[0])
+	f.fake.SetProperty(PlayerctldInterface, "PlayerNames", names)
+	return names[0], nil
+}
+
+func TestPlayerctld(t *testing.T) {
+	player, _ := newTestPlayer(t)
+	conn := player.conn
+
+	if _, err := DetectPlayerctld(conn); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("Expected ErrPlayerNotFound, got %v", err)
+	}
+
+	fake, err := mpristest.New(conn, "playerctld")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	fake.SetProperty(PlayerctldInterface, "PlayerNames", []string{player.GetName(), BaseInterface + ".other"})
+	if err := conn.Export(&fakePlayerctld{fake}, dbusObjectPath, PlayerctldInterface); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Export(nil, dbusObjectPath, PlayerctldInterface)
+
+	playerctld, err := DetectPlayerctld(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := playerctld.GetActivePlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.GetName() != player.GetName() {
+		t.Errorf("Expected active player %s, got %s", player.GetName(), active.GetName())
+	}
+
+	name, err := playerctld.Shift()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != BaseInterface+".other" {
+		t.Errorf("Expected shifted player %s.other, got %s", BaseInterface, name)
+	}
+}
@@ -0,0 +1,120 @@
+package mpris
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// playlistsPageSize is how many playlists AllPlaylists fetches per call.
+const playlistsPageSize = 100
+
+// PlaylistOrdering is an order the playlists can be listed in.
+type PlaylistOrdering string
+
+const (
+	PlaylistAlphabetical PlaylistOrdering = "Alphabetical"
+	PlaylistCreationDate PlaylistOrdering = "CreationDate"
+	PlaylistModifiedDate PlaylistOrdering = "ModifiedDate"
+	PlaylistLastPlayDate PlaylistOrdering = "LastPlayDate"
+	PlaylistUserDefined  PlaylistOrdering = "UserDefined"
+)
+
+// Playlist is a playlist of the player. Icon is the URI of an image, which may be empty.
+type Playlist struct {
+	ID   dbus.ObjectPath
+	Name string
+	Icon string
+}
+
+// MaybePlaylist is the active playlist of the player. Valid is false when there's no active
+// playlist, or when the player doesn't know which one is active.
+type MaybePlaylist struct {
+	Valid    bool
+	Playlist Playlist
+}
+
+// PlaylistsClient calls the org.mpris.MediaPlayer2.Playlists interface of a player, which is
+// optional. Some of its methods are generated from the spec, in bindings_gen.go.
+type PlaylistsClient struct {
+	core *Player
+}
+
+// GetPlaylists returns up to maxCount playlists, starting at index, sorted by the order.
+func (c PlaylistsClient) GetPlaylists(index, maxCount uint32, order PlaylistOrdering, reverse bool) ([]Playlist, error) {
+	var playlists []Playlist
+	err := c.core.query(PlaylistsInterface+".GetPlaylists", index, maxCount, string(order), reverse).Store(&playlists)
+	if err != nil {
+		return nil, err
+	}
+	return playlists, nil
+}
+
+// GetOrderings returns the orderings supported by the player.
+func (c PlaylistsClient) GetOrderings() ([]PlaylistOrdering, error) {
+	variant, err := c.core.getProperty(PlaylistsInterface, "Orderings")
+	if err != nil {
+		return nil, err
+	}
+	names, err := c.core.stringsValue(variant, "Orderings")
+	if err != nil {
+		return nil, err
+	}
+	orderings := make([]PlaylistOrdering, len(names))
+	for i, name := range names {
+		orderings[i] = PlaylistOrdering(name)
+	}
+	return orderings, nil
+}
+
+// GetActivePlaylist returns the playlist currently playing.
+func (c PlaylistsClient) GetActivePlaylist() (MaybePlaylist, error) {
+	variant, err := c.core.getProperty(PlaylistsInterface, "ActivePlaylist")
+	if err != nil {
+		return MaybePlaylist{}, err
+	}
+	if variant.Value() == nil {
+		return MaybePlaylist{}, ErrNilVariant
+	}
+	var active MaybePlaylist
+	if err := dbus.Store([]interface{}{variant.Value()}, &active); err != nil {
+		return MaybePlaylist{}, &TypeError{Name: "ActivePlaylist", Signature: variant.Signature().String(), Expected: "(b(oss))"}
+	}
+	return active, nil
+}
+
+// AllPlaylists returns every playlist sorted by the order as an iterator, fetching them by pages
+// while the iteration goes on. The iteration ends after yielding an error, which is
+// ErrNotSupported if the player doesn't support the order, as listed by GetOrderings.
+func (c PlaylistsClient) AllPlaylists(ctx context.Context, order PlaylistOrdering, reverse bool) iter.Seq2[Playlist, error] {
+	return func(yield func(Playlist, error) bool) {
+		client := c.core.WithContext(ctx).Playlists
+		orderings, err := client.GetOrderings()
+		if err != nil {
+			yield(Playlist{}, err)
+			return
+		}
+		if !slices.Contains(orderings, order) {
+			yield(Playlist{}, ErrNotSupported)
+			return
+		}
+
+		for index := uint32(0); ; index += playlistsPageSize {
+			playlists, err := client.GetPlaylists(index, playlistsPageSize, order, reverse)
+			if err != nil {
+				yield(Playlist{}, err)
+				return
+			}
+			for _, playlist := range playlists {
+				if !yield(playlist, nil) {
+					return
+				}
+			}
+			if len(playlists) < playlistsPageSize {
+				return
+			}
+		}
+	}
+}
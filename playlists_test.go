@@ -0,0 +1,142 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPlaylists(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	err := fake.SetPlaylists([]mpristest.Playlist{
+		{ID: "/org/mpristest/playlist/rock", Name: "Rock"},
+		{ID: "/org/mpristest/playlist/jazz", Name: "Jazz"},
+		{ID: "/org/mpristest/playlist/blues", Name: "Blues"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := player.Playlists.GetPlaylistCount(); err != nil || count != 3 {
+		t.Errorf("Expected 3 playlists, got %d %v", count, err)
+	}
+	orderings, err := player.Playlists.GetOrderings()
+	if err != nil || len(orderings) != 2 || orderings[1] != PlaylistAlphabetical {
+		t.Errorf("Unexpected orderings %v %v", orderings, err)
+	}
+
+	playlists, err := player.Playlists.GetPlaylists(0, 2, PlaylistAlphabetical, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 2 || playlists[0].Name != "Blues" || playlists[1].Name != "Jazz" {
+		t.Errorf("Unexpected playlists %v", playlists)
+	}
+
+	if err := player.Playlists.ActivatePlaylist("/org/mpristest/playlist/jazz"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := player.GetPlaybackStatus(); status != PlaybackPlaying {
+		t.Errorf("Expected the playlist to be playing, got %s", status)
+	}
+}
+
+func TestActivePlaylist(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	if active, err := player.Playlists.GetActivePlaylist(); err != nil || active.Valid {
+		t.Errorf("Expected no active playlist, got %v %v", active, err)
+	}
+
+	jazz := mpristest.Playlist{ID: "/org/mpristest/playlist/jazz", Name: "Jazz", Icon: "file:///tmp/jazz.png"}
+	if err := fake.SetActivePlaylist(&jazz); err != nil {
+		t.Fatal(err)
+	}
+	active, err := player.Playlists.GetActivePlaylist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MaybePlaylist{true, Playlist{"/org/mpristest/playlist/jazz", "Jazz", "file:///tmp/jazz.png"}}
+	if active != expected {
+		t.Errorf("Expected the jazz playlist to be active, got %v", active)
+	}
+}
+
+func TestPlaylistChanged(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed := mpristest.Playlist{ID: "/org/mpristest/playlist/jazz", Name: "Smooth jazz"}
+	if err := fake.EmitPlaylistChanged(renamed); err != nil {
+		t.Fatal(err)
+	}
+	for event := range events {
+		if changed, ok := event.(PlaylistChangedEvent); ok {
+			if changed.Playlist.Name != "Smooth jazz" || changed.Playlist.ID != renamed.ID {
+				t.Errorf("Unexpected playlist %v", changed.Playlist)
+			}
+			return
+		}
+	}
+	t.Error("Timed out waiting for the PlaylistChanged event")
+}
+
+func TestAllPlaylists(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	// more than a page, so the playlists are fetched in several calls
+	playlists := make([]mpristest.Playlist, playlistsPageSize+5)
+	for i := range playlists {
+		playlists[i] = mpristest.Playlist{
+			ID:   dbus.ObjectPath(fmt.Sprintf("/org/mpristest/playlist/p%d", i)),
+			Name: fmt.Sprintf("Playlist %03d", len(playlists)-i),
+		}
+	}
+	if err := fake.SetPlaylists(playlists); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for playlist, err := range player.Playlists.AllPlaylists(context.Background(), PlaylistAlphabetical, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, playlist.Name)
+	}
+	if len(names) != len(playlists) {
+		t.Fatalf("Expected %d playlists, got %d", len(playlists), len(names))
+	}
+	if names[0] != "Playlist 001" || names[len(names)-1] != "Playlist 105" {
+		t.Errorf("Unexpected playlists, from %q to %q", names[0], names[len(names)-1])
+	}
+
+	calls := 0
+	for _, call := range fake.Calls() {
+		if call.Method == "GetPlaylists" {
+			calls++
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 pages, got %d", calls)
+	}
+
+	var lastErr error
+	for _, err := range player.Playlists.AllPlaylists(context.Background(), PlaylistCreationDate, false) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, ErrNotSupported) {
+		t.Errorf("Expected an unsupported ordering, got %v", lastErr)
+	}
+}
@@ -0,0 +1,194 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// positionRebaseInterval is how often PositionEvents reads the position from the player, to
+// correct the drift of the extrapolation.
+const positionRebaseInterval = 5 * time.Second
+
+// positionClock extrapolates the position of the player from where it was at since while
+// playing, as the players don't announce its changes.
+type positionClock struct {
+	playing  bool
+	rate     float64
+	position time.Duration
+	since    time.Time
+}
+
+// current extrapolates the position at now.
+func (c *positionClock) current(now time.Time) time.Duration {
+	if !c.playing {
+		return c.position
+	}
+	return c.position + time.Duration(float64(now.Sub(c.since))*c.rate)
+}
+
+func (c *positionClock) update(position time.Duration, now time.Time) {
+	c.position = position
+	c.since = now
+}
+
+// applyChanges updates the playback status and rate with the changed player properties,
+// keeping the position so far, as they change how it goes on.
+func (c *positionClock) applyChanges(changed map[string]dbus.Variant, now time.Time) {
+	c.update(c.current(now), now)
+	if status, ok := changed["PlaybackStatus"].Value().(string); ok {
+		c.playing = PlaybackStatus(status) == PlaybackPlaying
+	}
+	if rate, ok := toFloat64(changed["Rate"].Value()); ok && rate != 0 {
+		c.rate = rate
+	}
+}
+
+// positionTracker is the clock of PositionEvents, whose rate is corrected by comparing the
+// positions read from the player, as some players play faster or slower than the rate they
+// announce, especially when it's not 1.0.
+type positionTracker struct {
+	positionClock
+	// announced is the rate announced by the player.
+	announced float64
+	// rebasedAt is when the position was last known for sure, rebasedTo. It's zero when the
+	// playback changed since, so the next position can't measure the rate.
+	rebasedAt time.Time
+	rebasedTo time.Duration
+}
+
+func newPositionTracker(state PlayerState, now time.Time) *positionTracker {
+	rate := state.Rate
+	if rate == 0 {
+		rate = 1
+	}
+	t := &positionTracker{
+		positionClock: positionClock{playing: state.PlaybackStatus == PlaybackPlaying, rate: rate},
+		announced:     rate,
+	}
+	t.rebase(state.Position, now)
+	return t
+}
+
+// rebase sets the position read from the player. When playing since the last one, the
+// measured rate replaces the one used for the extrapolation, unless it's too far from the
+// announced rate, which means the position jumped without a Seeked signal.
+func (t *positionTracker) rebase(position time.Duration, now time.Time) {
+	if t.playing && !t.rebasedAt.IsZero() && now.After(t.rebasedAt) {
+		measured := float64(position-t.rebasedTo) / float64(now.Sub(t.rebasedAt))
+		if measured > t.announced/2 && measured < t.announced*2 {
+			t.rate = measured
+		}
+	}
+	t.update(position, now)
+	t.rebasedAt, t.rebasedTo = now, position
+}
+
+// handle updates the tracker with the event, returning true if the position must be read
+// from the player, like after a track change.
+func (t *positionTracker) handle(event Event, now time.Time) bool {
+	switch event := event.(type) {
+	case SeekedEvent:
+		t.update(event.Position, now)
+		t.rebasedAt, t.rebasedTo = now, event.Position
+	case PropertiesChangedEvent:
+		if event.Interface != PlayerInterface {
+			return false
+		}
+		_, statusChanged := event.Changed["PlaybackStatus"]
+		rate, rateChanged := toFloat64(event.Changed["Rate"].Value())
+		t.applyChanges(event.Changed, now)
+		if rateChanged && rate != 0 {
+			t.announced = rate
+		}
+		if statusChanged || rateChanged {
+			t.rebasedAt = time.Time{}
+		}
+		if position, ok := toInt64(event.Changed["Position"].Value()); ok {
+			t.rebase(microsecondsToDuration(position), now)
+		}
+		_, trackChanged := event.Changed["Metadata"]
+		return trackChanged
+	}
+	return false
+}
+
+// PositionEvents returns a channel receiving the position of the current track every
+// resolution while playing, and when it jumps, like after a seek or a track change. The
+// channel is closed when ctx is done or the connection is closed.
+//
+// As the players don't announce the position while playing, it's extrapolated from the
+// Seeked signals and the playback status and rate, and read from the player every 5 seconds.
+// The rate is corrected by these reads, so the drift of the players that don't play at the
+// rate they announce is bounded too.
+func (i *Player) PositionEvents(ctx context.Context, resolution time.Duration) (<-chan time.Duration, error) {
+	if resolution <= 0 {
+		return nil, errors.New("invalid resolution, it must be positive")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	player := i.WithContext(ctx)
+
+	// subscribe before reading the state, so a change in the meantime isn't missed
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	state, err := player.GetState()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	tracker := newPositionTracker(state, time.Now())
+
+	positions := make(chan time.Duration)
+	go func() {
+		defer cancel()
+		defer close(positions)
+
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+		rebaseTicker := time.NewTicker(positionRebaseInterval)
+		defer rebaseTicker.Stop()
+
+		rebase := func() {
+			position, err := player.GetPositionDuration()
+			if err != nil {
+				player.debug("reading the position", "error", err)
+				return
+			}
+			tracker.rebase(position, time.Now())
+		}
+
+		last := time.Duration(-1)
+		for {
+			// the positions that didn't change, like while paused, aren't sent again
+			if position := tracker.current(time.Now()); position != last {
+				select {
+				case positions <- position:
+					last = position
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-rebaseTicker.C:
+				rebase()
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if tracker.handle(event, time.Now()) {
+					rebase()
+				}
+			}
+		}
+	}()
+	return positions, nil
+}
@@ -0,0 +1,114 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPositionTrackerDrift(t *testing.T) {
+	start := time.Now()
+	tracker := newPositionTracker(PlayerState{PlaybackStatus: PlaybackPlaying, Rate: 1.5}, start)
+
+	if position := tracker.current(start.Add(2 * time.Second)); position != 3*time.Second {
+		t.Errorf("Expected the position to follow the rate, got %s", position)
+	}
+
+	// the player plays at 1.25 instead of the announced 1.5
+	tracker.rebase(5*time.Second, start.Add(4*time.Second))
+	if position := tracker.current(start.Add(8 * time.Second)); position != 10*time.Second {
+		t.Errorf("Expected the measured rate to be used, got %s", position)
+	}
+
+	// a jump without a Seeked signal isn't a rate
+	tracker.rebase(time.Minute, start.Add(9*time.Second))
+	if tracker.rate != 1.25 {
+		t.Errorf("Expected the rate to be kept after a jump, got %f", tracker.rate)
+	}
+
+	// the measure doesn't span a pause
+	tracker.handle(statusEvent("Paused"), start.Add(10*time.Second))
+	tracker.handle(statusEvent("Playing"), start.Add(20*time.Second))
+	tracker.rebase(time.Minute+5*time.Second, start.Add(22*time.Second))
+	if tracker.rate != 1.25 {
+		t.Errorf("Expected the rate to be kept across a pause, got %f", tracker.rate)
+	}
+
+	// some players send the rate as an integer
+	tracker.handle(PropertiesChangedEvent{
+		Interface: PlayerInterface,
+		Changed:   map[string]dbus.Variant{"Rate": dbus.MakeVariant(int32(2))},
+	}, start.Add(23*time.Second))
+	if tracker.rate != 2 || tracker.announced != 2 {
+		t.Errorf("Expected the integer rate to be used, got %f announced %f", tracker.rate, tracker.announced)
+	}
+}
+
+func TestPositionEvents(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	if err := fake.SetPosition(10000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	positions, err := player.PositionEvents(ctx, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive := func() time.Duration {
+		t.Helper()
+		select {
+		case position := <-positions:
+			return position
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for a position")
+		}
+		return 0
+	}
+
+	first := receive()
+	if first < 10*time.Second || first > 11*time.Second {
+		t.Errorf("Expected the position to start at 10s, got %s", first)
+	}
+	for range 5 {
+		receive()
+	}
+	if position := receive(); position <= first {
+		t.Errorf("Expected the position to move on while playing, got %s after %s", position, first)
+	}
+
+	if err := fake.EmitSeeked(60000000); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		position := receive()
+		return position >= time.Minute && position < time.Minute+time.Second
+	}, "Expected the position to jump to the seek")
+
+	if err := fake.SetPlaybackStatus("Paused"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPosition(0); err != nil {
+		t.Fatal(err)
+	}
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/2")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool { return receive() == 0 }, "Expected the position to be read on a new track")
+
+	select {
+	case position := <-positions:
+		t.Errorf("Expected no position while paused, got %s", position)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,69 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RatingsExtensionInterface is the interface of the ratings extension of Pithos and some
+// other players, which lets the user rate the current track.
+const RatingsExtensionInterface = BaseInterface + ".ExtensionSetRatings"
+
+// UserRating returns the "xesam:userRating" value, the rating given by the user from 0 to 1.
+func (m Metadata) UserRating() float64 {
+	rating, _ := toFloat64(m["xesam:userRating"].Value())
+	return rating
+}
+
+// AutoRating returns the "xesam:autoRating" value, the rating computed by the player from 0
+// to 1, like from the play count.
+func (m Metadata) AutoRating() float64 {
+	rating, _ := toFloat64(m["xesam:autoRating"].Value())
+	return rating
+}
+
+// GetUserRating returns the user rating of the current track, or ErrNilVariant if it's not
+// rated.
+func (i *Player) GetUserRating() (float64, error) {
+	return i.getRating("xesam:userRating")
+}
+
+// GetAutoRating returns the rating computed by the player for the current track, or
+// ErrNilVariant if it has none.
+func (i *Player) GetAutoRating() (float64, error) {
+	return i.getRating("xesam:autoRating")
+}
+
+func (i *Player) getRating(key string) (float64, error) {
+	metadata, err := i.GetMetadata()
+	if err != nil {
+		return 0, err
+	}
+	return i.float64Value(metadata[key], key)
+}
+
+// HasRatingsExtension reports whether the player implements RatingsExtensionInterface, so
+// SetRating can be used.
+func (i *Player) HasRatingsExtension() (bool, error) {
+	variant, err := i.Interface(RatingsExtensionInterface).Get("HasRatingsExtension")
+	if errors.Is(err, ErrNotSupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return i.boolValue(variant, "HasRatingsExtension")
+}
+
+// SetRating rates the track from 0 to 1, with the ratings extension. Players like Pithos
+// only accept the current track. ErrNotSupported is returned by the players without the
+// extension.
+func (i *Player) SetRating(trackID TrackID, rating float64) error {
+	if err := checkTrackID(trackID); err != nil {
+		return err
+	}
+	if rating < 0 || rating > 1 {
+		return fmt.Errorf("invalid rating %f, expected a value from 0 to 1", rating)
+	}
+	return i.Interface(RatingsExtensionInterface).Call("SetRating", trackID.ObjectPath(), rating).Err
+}
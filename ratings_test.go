@@ -0,0 +1,74 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+type testRatings struct {
+	ratings chan float64
+}
+
+func (r *testRatings) SetRating(trackID dbus.ObjectPath, rating float64) *dbus.Error {
+	r.ratings <- rating
+	return nil
+}
+
+func TestRatings(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid":    dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"xesam:userRating": dbus.MakeVariant(0.8),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rating, err := player.GetUserRating(); err != nil || rating != 0.8 {
+		t.Errorf("Expected the user rating to be 0.8, got %f %v", rating, err)
+	}
+	if _, err := player.GetAutoRating(); !errors.Is(err, ErrNilVariant) {
+		t.Errorf("Expected ErrNilVariant without auto rating, got %v", err)
+	}
+
+	if has, err := player.HasRatingsExtension(); err != nil || has {
+		t.Errorf("Expected no ratings extension, got %t %v", has, err)
+	}
+	if err := player.SetRating("/org/mpris/MediaPlayer2/Track/1", 1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported without the extension, got %v", err)
+	}
+}
+
+func TestRatingsExtension(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	fake, err := mpristest.New(conn, fmt.Sprintf("mpristest.ratings%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	ratings := &testRatings{make(chan float64, 1)}
+	if err := conn.Export(ratings, "/org/mpris/MediaPlayer2", RatingsExtensionInterface); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(RatingsExtensionInterface, "HasRatingsExtension", true); err != nil {
+		t.Fatal(err)
+	}
+	player := New(mpristest.PrivateConn(t), fake.Name())
+
+	if has, err := player.HasRatingsExtension(); err != nil || !has {
+		t.Errorf("Expected the ratings extension, got %t %v", has, err)
+	}
+	if err := player.SetRating("/org/mpris/MediaPlayer2/Track/1", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if rating := <-ratings.ratings; rating != 0.5 {
+		t.Errorf("Expected the track to be rated 0.5, got %f", rating)
+	}
+	if err := player.SetRating("/org/mpris/MediaPlayer2/Track/1", 2); err == nil {
+		t.Error("Expected an error for a rating above 1")
+	}
+}
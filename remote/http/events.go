@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// The events sent by GET /players/{name}/events.
+type (
+	// StatusEvent is sent as "status" when the playback status changes.
+	StatusEvent struct {
+		PlaybackStatus mpris.PlaybackStatus `json:"playbackStatus"`
+	}
+	// TrackEvent is sent as "track" when the metadata changes.
+	TrackEvent struct {
+		Metadata mpris.Metadata `json:"metadata"`
+	}
+	// PositionEvent is sent as "position" when the position jumps, like after a seek.
+	PositionEvent struct {
+		PositionMs int64 `json:"positionMs"`
+	}
+	// PropertiesEvent is sent as "properties" for the changes of the other player properties,
+	// like the volume.
+	PropertiesEvent struct {
+		Changed map[string]interface{} `json:"changed"`
+	}
+	// OwnerEvent is sent as "owner" when the player quits, with an empty owner, or restarts.
+	OwnerEvent struct {
+		Owner string `json:"owner"`
+	}
+)
+
+// streamEvents sends the player events as Server-Sent Events until the client goes away. The
+// first event is the current state, sent as "state", so clients don't need another request.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, player *mpris.Player) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	events, err := player.Subscribe(r.Context())
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	state, err := player.GetState()
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if writeEvent(w, "state", state) != nil {
+		return
+	}
+	flusher.Flush()
+
+	for event := range events {
+		for _, sse := range toServerEvents(event) {
+			if writeEvent(w, sse.name, sse.data) != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+type serverEvent struct {
+	name string
+	data interface{}
+}
+
+// toServerEvents converts a player event to the events sent to the clients. A change of
+// several properties is split into a status, a track and a properties event.
+func toServerEvents(event mpris.Event) []serverEvent {
+	switch event := event.(type) {
+	case mpris.SeekedEvent:
+		return []serverEvent{{"position", PositionEvent{event.Position.Milliseconds()}}}
+	case mpris.OwnerChangedEvent:
+		return []serverEvent{{"owner", OwnerEvent{event.NewOwner}}}
+	case mpris.PropertiesChangedEvent:
+		if event.Interface != mpris.PlayerInterface {
+			return nil
+		}
+		var events []serverEvent
+		if value, ok := event.Changed["PlaybackStatus"]; ok {
+			status, _ := value.Value().(string)
+			events = append(events, serverEvent{"status", StatusEvent{mpris.PlaybackStatus(status)}})
+		}
+		if value, ok := event.Changed["Metadata"]; ok {
+			metadata, _ := value.Value().(map[string]dbus.Variant)
+			events = append(events, serverEvent{"track", TrackEvent{mpris.Metadata(metadata)}})
+		}
+		others := make(map[string]interface{})
+		for name, value := range event.Changed {
+			if name != "PlaybackStatus" && name != "Metadata" {
+				others[name] = mpris.JSONValue(value.Value())
+			}
+		}
+		if len(others) > 0 {
+			events = append(events, serverEvent{"properties", PropertiesEvent{others}})
+		}
+		return events
+	}
+	return nil
+}
+
+func writeEvent(w http.ResponseWriter, name string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, encoded)
+	return err
+}
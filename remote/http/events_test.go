@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestStreamEvents(t *testing.T) {
+	server, fake := newTestServer(t)
+	shortName := strings.TrimPrefix(fake.Name(), mpris.BaseInterface+".")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/players/"+shortName+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		t.Helper()
+		var name, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return name, data
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if name, data := readEvent(); name != "state" || !strings.Contains(data, `"playbackStatus":"Stopped"`) {
+		t.Errorf("Expected the initial state, got %s %s", name, data)
+	}
+
+	if err := fake.SetPlaybackStatus(string(mpris.PlaybackPlaying)); err != nil {
+		t.Fatal(err)
+	}
+	if name, data := readEvent(); name != "status" || data != `{"playbackStatus":"Playing"}` {
+		t.Errorf("Expected a status event, got %s %s", name, data)
+	}
+
+	if err := fake.EmitSeeked(1500000); err != nil {
+		t.Fatal(err)
+	}
+	if name, data := readEvent(); name != "position" || data != `{"positionMs":1500}` {
+		t.Errorf("Expected a position event, got %s %s", name, data)
+	}
+}
+
+func TestPropertiesEventVariants(t *testing.T) {
+	events := toServerEvents(mpris.PropertiesChangedEvent{
+		Interface: mpris.PlayerInterface,
+		Changed: map[string]dbus.Variant{
+			"Volume":  dbus.MakeVariant(0.5),
+			"Options": dbus.MakeVariant(map[string]dbus.Variant{"mode": dbus.MakeVariant("party")}),
+		},
+	})
+	if len(events) != 1 || events[0].name != "properties" {
+		t.Fatalf("Expected a properties event, got %+v", events)
+	}
+	encoded, err := json.Marshal(events[0].data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"changed":{"Options":{"mode":"party"},"Volume":0.5}}` {
+		t.Errorf("Expected the nested variants to be unwrapped, got %s", encoded)
+	}
+}
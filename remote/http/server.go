@@ -0,0 +1,207 @@
+// Package http exposes the players tracked by a mpris.Manager as a REST API, for building web
+// remotes:
+//
+//	GET  /players                      the players, most recently active first, with their state
+//	GET  /players/{name}/state         the state of the player
+//	POST /players/{name}/play          play, also pause, play-pause, stop, next and previous
+//	POST /players/{name}/seek          seek, with a body like {"positionMs": 1000} or {"offsetMs": -5000}
+//	POST /players/{name}/volume        set the volume, with a body like {"volume": 0.5}
+//	GET  /players/{name}/events        the player events, streamed as Server-Sent Events
+//
+// The players are named either by their full bus name or by the part after
+// org.mpris.MediaPlayer2, like "spotify". The states are encoded by PlayerState.MarshalJSON and
+// the errors are sent as {"error": "message"}.
+//
+// The event stream starts with the current state, as a "state" event, followed by "status",
+// "track", "position", "properties" and "owner" events, described by the types of the same
+// name, like StatusEvent. Browsers can read it with an EventSource.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/remote/internal/command"
+)
+
+// Server is the http.Handler serving the API.
+type Server struct {
+	manager *mpris.Manager
+	mux     *http.ServeMux
+}
+
+// PlayerInfo is a player listed by GET /players, with its state, or the error reading it when
+// the player doesn't answer.
+type PlayerInfo struct {
+	Name  string             `json:"name"`
+	State *mpris.PlayerState `json:"state,omitempty"`
+	Error string             `json:"error,omitempty"`
+}
+
+// NewServer returns a server controlling the players tracked by the manager. The manager is
+// never closed by the server.
+func NewServer(manager *mpris.Manager) *Server {
+	s := &Server{manager: manager, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /players", s.listPlayers)
+	s.mux.HandleFunc("GET /players/{name}/state", s.withPlayer(s.getState))
+	s.mux.HandleFunc("GET /players/{name}/events", s.withPlayer(s.streamEvents))
+	s.mux.HandleFunc("POST /players/{name}/seek", s.withPlayer(s.seek))
+	s.mux.HandleFunc("POST /players/{name}/volume", s.withPlayer(s.setVolume))
+	// the actions without arguments are sent with POST /players/{name}/{command}
+	for _, name := range command.Names() {
+		run, _ := command.Lookup(name)
+		s.mux.HandleFunc("POST /players/{name}/"+name, s.withPlayer(runCommand(run)))
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// findPlayer returns the tracked player with the full or short name, or nil.
+func (s *Server) findPlayer(name string) *mpris.Player {
+	if !strings.HasPrefix(name, mpris.BaseInterface+".") {
+		name = mpris.BaseInterface + "." + name
+	}
+	return s.manager.Player(name)
+}
+
+// withPlayer resolves the player named in the path before calling the handler, and answers
+// with a 404 if it's not tracked.
+func (s *Server) withPlayer(handler func(http.ResponseWriter, *http.Request, *mpris.Player)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		player := s.findPlayer(r.PathValue("name"))
+		if player == nil {
+			writeError(w, http.StatusNotFound, mpris.ErrPlayerNotFound)
+			return
+		}
+		handler(w, r, player.WithContext(r.Context()))
+	}
+}
+
+func (s *Server) listPlayers(w http.ResponseWriter, r *http.Request) {
+	players := []PlayerInfo{}
+	for _, player := range s.manager.Players() {
+		state, err := player.WithContext(r.Context()).GetState()
+		if errors.Is(err, mpris.ErrPlayerNotFound) {
+			// the player quit in the meantime
+			continue
+		}
+		info := PlayerInfo{Name: player.GetName(), State: &state}
+		if err != nil {
+			// the other players are still listed
+			info.State, info.Error = nil, err.Error()
+		}
+		players = append(players, info)
+	}
+	writeJSON(w, http.StatusOK, players)
+}
+
+func (s *Server) getState(w http.ResponseWriter, r *http.Request, player *mpris.Player) {
+	state, err := player.GetState()
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func runCommand(run func(*mpris.Player) error) func(http.ResponseWriter, *http.Request, *mpris.Player) {
+	return func(w http.ResponseWriter, r *http.Request, player *mpris.Player) {
+		if err := run(player); err != nil {
+			writePlayerError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type seekRequest struct {
+	PositionMs *int64 `json:"positionMs"`
+	OffsetMs   *int64 `json:"offsetMs"`
+}
+
+func (s *Server) seek(w http.ResponseWriter, r *http.Request, player *mpris.Player) {
+	var request seekRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+
+	var err error
+	switch {
+	case request.PositionMs != nil && request.OffsetMs == nil:
+		err = player.SeekTo(time.Duration(*request.PositionMs) * time.Millisecond)
+	case request.OffsetMs != nil && request.PositionMs == nil:
+		err = player.SeekBy(time.Duration(*request.OffsetMs) * time.Millisecond)
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("expected either positionMs or offsetMs"))
+		return
+	}
+	if err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type volumeRequest struct {
+	Volume *float64 `json:"volume"`
+}
+
+func (s *Server) setVolume(w http.ResponseWriter, r *http.Request, player *mpris.Player) {
+	var request volumeRequest
+	if !readJSON(w, r, &request) {
+		return
+	}
+	if request.Volume == nil || *request.Volume < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("expected a non-negative volume"))
+		return
+	}
+	if err := player.SetVolume(*request.Volume); err != nil {
+		writePlayerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readJSON decodes the request body, answering with a 400 if it's invalid.
+func readJSON(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{err.Error()})
+}
+
+// writePlayerError answers with the status matching the error returned by the player.
+func writePlayerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, mpris.ErrPlayerNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, mpris.ErrNotSupported):
+		writeError(w, http.StatusNotImplemented, err)
+	case errors.Is(err, mpris.ErrInvalidTrackID), errors.Is(err, mpris.ErrNilVariant):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusBadGateway, err)
+	}
+}
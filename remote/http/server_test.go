@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+// newTestServer serves the players of a manager tracking a fake player.
+func newTestServer(t *testing.T) (*httptest.Server, *mpristest.Player) {
+	shortName := fmt.Sprintf("mpristest.http%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fake.Close() })
+
+	manager, err := mpris.NewManager(mpristest.PrivateConn(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { manager.Close() })
+
+	server := httptest.NewServer(NewServer(manager))
+	t.Cleanup(server.Close)
+	return server, fake
+}
+
+func request(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(content)
+}
+
+func TestServer(t *testing.T) {
+	server, fake := newTestServer(t)
+	shortName := strings.TrimPrefix(fake.Name(), mpris.BaseInterface+".")
+	playerURL := server.URL + "/players/" + shortName
+
+	status, body := request(t, "GET", server.URL+"/players", "")
+	var players []struct{ Name string }
+	if err := json.Unmarshal([]byte(body), &players); status != http.StatusOK || err != nil {
+		t.Fatalf("Unexpected players %d %s %v", status, body, err)
+	}
+	found := false
+	for _, player := range players {
+		found = found || player.Name == fake.Name()
+	}
+	if !found {
+		t.Errorf("Expected %s to be listed in %s", fake.Name(), body)
+	}
+
+	if status, body := request(t, "POST", playerURL+"/play", ""); status != http.StatusNoContent {
+		t.Errorf("Unexpected play answer %d %s", status, body)
+	}
+	if status, body := request(t, "GET", playerURL+"/state", ""); status != http.StatusOK ||
+		!strings.Contains(body, `"playbackStatus":"Playing"`) {
+		t.Errorf("Expected the player to be playing, got %d %s", status, body)
+	}
+
+	if status, body := request(t, "POST", playerURL+"/volume", `{"volume": 0.25}`); status != http.StatusNoContent {
+		t.Errorf("Unexpected volume answer %d %s", status, body)
+	}
+	if value, _ := fake.GetProperty(mpris.PlayerInterface, "Volume"); value.Value() != 0.25 {
+		t.Errorf("Expected the volume to be 0.25, got %v", value.Value())
+	}
+
+	if status, body := request(t, "POST", playerURL+"/seek", `{"offsetMs": 2000}`); status != http.StatusNoContent {
+		t.Errorf("Unexpected seek answer %d %s", status, body)
+	}
+	calls := fake.Calls()
+	if last := calls[len(calls)-1]; last.Method != "Seek" || last.Args[0] != (2*time.Second).Microseconds() {
+		t.Errorf("Expected a seek of 2s, got %v", last)
+	}
+
+	if status, _ := request(t, "POST", playerURL+"/seek", `{}`); status != http.StatusBadRequest {
+		t.Errorf("Expected a bad request without position, got %d", status)
+	}
+	if status, _ := request(t, "POST", server.URL+"/players/missing/play", ""); status != http.StatusNotFound {
+		t.Errorf("Expected a missing player not to be found, got %d", status)
+	}
+}
+
+func TestListPlayersError(t *testing.T) {
+	server, fake := newTestServer(t)
+
+	// a name without the player object, whose state can't be read
+	broken := fmt.Sprintf("%s.mpristest.httpbroken%d", mpris.BaseInterface, os.Getpid())
+	if _, err := mpristest.PrivateConn(t).RequestName(broken, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var players []struct {
+		Name  string
+		State json.RawMessage
+		Error string
+	}
+	mpristest.Eventually(t, func() bool {
+		status, body := request(t, "GET", server.URL+"/players", "")
+		players = nil
+		return status == http.StatusOK && json.Unmarshal([]byte(body), &players) == nil && len(players) == 2
+	}, "The players weren't both listed")
+	for _, player := range players {
+		switch player.Name {
+		case fake.Name():
+			if player.State == nil || player.Error != "" {
+				t.Errorf("Expected the state of %s, got %+v", player.Name, player)
+			}
+		case broken:
+			if player.State != nil || player.Error == "" {
+				t.Errorf("Expected the error of %s, got %+v", player.Name, player)
+			}
+		default:
+			t.Errorf("Unexpected player %s", player.Name)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package command holds the commands without arguments shared by the remote controls, so the
+// HTTP server and the MQTT bridge accept the same ones.
+package command
+
+import (
+	"sort"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+var commands = map[string]func(*mpris.Player) error{
+	"play":       (*mpris.Player).Play,
+	"pause":      (*mpris.Player).Pause,
+	"play-pause": (*mpris.Player).PlayPause,
+	"stop":       (*mpris.Player).Stop,
+	"next":       (*mpris.Player).Next,
+	"previous":   (*mpris.Player).Previous,
+}
+
+// Lookup returns the command with the name, like "play-pause".
+func Lookup(name string) (func(*mpris.Player) error, bool) {
+	run, ok := commands[name]
+	return run, ok
+}
+
+// Names returns the names of the commands, sorted.
+func Names() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
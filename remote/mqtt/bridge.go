@@ -0,0 +1,298 @@
+// Package mqtt bridges the MPRIS players to MQTT, for home automation like Home Assistant.
+//
+// The state of every player is published as retained messages, under the player name without
+// the org.mpris.MediaPlayer2 prefix:
+//
+//	{prefix}/{player}/state      the state, encoded by PlayerState.MarshalJSON
+//	{prefix}/{player}/status     the playback status, like "Playing"
+//	{prefix}/{player}/metadata   the metadata, encoded by Metadata.MarshalJSON
+//
+// The retained messages are cleared, by publishing empty ones, when the player quits. The
+// players are controlled by publishing to {prefix}/{player}/command/{command}, the commands
+// being play, pause, play-pause, stop, next and previous, which ignore the payload, volume,
+// with a payload like "0.5", and seek, with an offset in milliseconds like "-5000".
+//
+// The package doesn't depend on a MQTT library, the client is given as a Client. With
+// paho.mqtt.golang it's a small adapter, and Resync must be called from the OnConnect handler
+// so the bridge subscribes again and republishes the states after a reconnection.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/remote/internal/command"
+	"github.com/godbus/dbus/v5"
+)
+
+// DefaultPrefix is the topic prefix used when Options.Prefix is empty.
+const DefaultPrefix = "mpris"
+
+// Client is the MQTT client used by the bridge.
+type Client interface {
+	// Publish sends the payload to the topic.
+	Publish(topic string, retained bool, payload []byte) error
+	// Subscribe calls the handler for every message sent to the topic, which can contain
+	// the + wildcard.
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+}
+
+// Bridge publishes the players state and runs the commands it receives.
+type Bridge struct {
+	conn   *dbus.Conn
+	client Client
+	prefix string
+
+	watcher *mpris.Watcher
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu      sync.Mutex
+	players map[string]*bridgedPlayer
+	// retained is the last payload of every retained topic, republished by Resync.
+	retained map[string][]byte
+}
+
+type bridgedPlayer struct {
+	player *mpris.Player
+	cancel context.CancelFunc
+
+	// mu serializes the publications of the player, so its state isn't published once the
+	// retained messages were cleared by removePlayer, which sets closed.
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewBridge starts bridging the players of the bus to the client, under the topic prefix,
+// DefaultPrefix if empty. The bridge subscribes to the command topics and publishes the state
+// of the players already running before returning.
+func NewBridge(conn *dbus.Conn, client Client, prefix string) (*Bridge, error) {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	watcher, err := mpris.NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bridge{
+		conn:     conn,
+		client:   client,
+		prefix:   prefix,
+		watcher:  watcher,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		players:  make(map[string]*bridgedPlayer),
+		retained: make(map[string][]byte),
+	}
+	if err := b.subscribe(); err != nil {
+		watcher.Close()
+		cancel()
+		return nil, err
+	}
+	for _, name := range watcher.Players() {
+		b.addPlayer(name)
+	}
+	go b.run()
+	return b, nil
+}
+
+func (b *Bridge) subscribe() error {
+	return b.client.Subscribe(b.prefix+"/+/command/+", b.handleCommand)
+}
+
+// Resync subscribes to the command topics again and republishes the retained messages. It's
+// meant to be called when the client reconnects, as the broker may have lost both.
+func (b *Bridge) Resync() error {
+	if err := b.subscribe(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	retained := make(map[string][]byte, len(b.retained))
+	for topic, payload := range b.retained {
+		retained[topic] = payload
+	}
+	b.mu.Unlock()
+
+	var firstErr error
+	for topic, payload := range retained {
+		if err := b.client.Publish(topic, true, payload); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (b *Bridge) run() {
+	defer close(b.done)
+	for event := range b.watcher.Events() {
+		if event.NewOwner == "" {
+			b.removePlayer(event.Name)
+		} else if event.OldOwner == "" {
+			b.addPlayer(event.Name)
+		}
+	}
+}
+
+// topic returns the topic of the player under the prefix.
+func (b *Bridge) topic(name, suffix string) string {
+	return b.prefix + "/" + strings.TrimPrefix(name, mpris.BaseInterface+".") + "/" + suffix
+}
+
+func (b *Bridge) addPlayer(name string) {
+	ctx, cancel := context.WithCancel(b.ctx)
+	player := mpris.New(b.conn, name)
+
+	b.mu.Lock()
+	if _, ok := b.players[name]; ok {
+		b.mu.Unlock()
+		cancel()
+		return
+	}
+	bridged := &bridgedPlayer{player: player, cancel: cancel}
+	b.players[name] = bridged
+	b.mu.Unlock()
+
+	// the changes often come in bursts, like a new track with its metadata and status
+	events, err := player.Subscribe(ctx, mpris.WithDebounce(50*time.Millisecond))
+	if err != nil {
+		// the player can't be followed, so it's left out until it appears again
+		b.mu.Lock()
+		if b.players[name] == bridged {
+			delete(b.players, name)
+		}
+		b.mu.Unlock()
+		cancel()
+		player.Close()
+		return
+	}
+	b.publishState(ctx, bridged)
+	go func() {
+		for event := range events {
+			switch event := event.(type) {
+			case mpris.PropertiesChangedEvent:
+				if event.Interface == mpris.PlayerInterface {
+					b.publishState(ctx, bridged)
+				}
+			case mpris.SeekedEvent:
+				b.publishState(ctx, bridged)
+			}
+		}
+	}()
+}
+
+func (b *Bridge) removePlayer(name string) {
+	b.mu.Lock()
+	bridged, ok := b.players[name]
+	delete(b.players, name)
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	bridged.cancel()
+	bridged.player.Close()
+
+	bridged.mu.Lock()
+	defer bridged.mu.Unlock()
+	bridged.closed = true
+	for _, suffix := range []string{"state", "status", "metadata"} {
+		b.publish(b.topic(name, suffix), nil)
+	}
+}
+
+// publishState fetches the state of the player and publishes it, unless the player was
+// removed in the meantime.
+func (b *Bridge) publishState(ctx context.Context, bridged *bridgedPlayer) {
+	player := bridged.player
+	state, err := player.WithContext(ctx).GetState()
+	if err != nil {
+		return
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	metadata, err := json.Marshal(state.Metadata)
+	if err != nil {
+		return
+	}
+
+	bridged.mu.Lock()
+	defer bridged.mu.Unlock()
+	if bridged.closed || ctx.Err() != nil {
+		return
+	}
+	b.publish(b.topic(player.GetName(), "state"), encoded)
+	b.publish(b.topic(player.GetName(), "status"), []byte(state.PlaybackStatus))
+	b.publish(b.topic(player.GetName(), "metadata"), metadata)
+}
+
+// publish publishes a retained message, or clears it when the payload is empty. The payload
+// is kept for Resync even if publishing fails, as the client may be reconnecting.
+func (b *Bridge) publish(topic string, payload []byte) {
+	b.mu.Lock()
+	if len(payload) == 0 {
+		delete(b.retained, topic)
+	} else {
+		b.retained[topic] = payload
+	}
+	b.mu.Unlock()
+	b.client.Publish(topic, true, payload)
+}
+
+// handleCommand runs the command sent to a command topic. Invalid commands are ignored, as
+// there's nobody to report the error to.
+func (b *Bridge) handleCommand(topic string, payload []byte) {
+	parts := strings.Split(strings.TrimPrefix(topic, b.prefix+"/"), "/")
+	if len(parts) != 3 || parts[1] != "command" {
+		return
+	}
+	b.mu.Lock()
+	bridged, ok := b.players[mpris.BaseInterface+"."+parts[0]]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	player := bridged.player.WithContext(b.ctx)
+
+	arg := strings.TrimSpace(string(payload))
+	switch parts[2] {
+	case "volume":
+		if volume, err := strconv.ParseFloat(arg, 64); err == nil && volume >= 0 {
+			player.SetVolume(volume)
+		}
+	case "seek":
+		if offset, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			player.SeekBy(time.Duration(offset) * time.Millisecond)
+		}
+	default:
+		if run, ok := command.Lookup(parts[2]); ok {
+			run(player)
+		}
+	}
+}
+
+// Close stops bridging the players. The retained messages are left as they are, and neither
+// the connection nor the client are closed.
+func (b *Bridge) Close() error {
+	err := b.watcher.Close()
+	<-b.done
+	b.cancel()
+
+	b.mu.Lock()
+	players := b.players
+	b.players = make(map[string]*bridgedPlayer)
+	b.mu.Unlock()
+	for _, bridged := range players {
+		bridged.player.Close()
+	}
+	return err
+}
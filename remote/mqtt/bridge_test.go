@@ -0,0 +1,139 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+// testClient is a broker keeping the retained messages in memory.
+type testClient struct {
+	mu       sync.Mutex
+	retained map[string]string
+	handlers map[string]func(string, []byte)
+}
+
+func newTestClient() *testClient {
+	return &testClient{retained: make(map[string]string), handlers: make(map[string]func(string, []byte))}
+}
+
+func (c *testClient) Publish(topic string, retained bool, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(payload) == 0 {
+		delete(c.retained, topic)
+	} else {
+		c.retained[topic] = string(payload)
+	}
+	return nil
+}
+
+func (c *testClient) Subscribe(topic string, handler func(string, []byte)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[topic] = handler
+	return nil
+}
+
+func (c *testClient) get(topic string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	payload, ok := c.retained[topic]
+	return payload, ok
+}
+
+// send delivers a message to the handler subscribed with the wildcard topic.
+func (c *testClient) send(topic, payload string) {
+	c.mu.Lock()
+	handler := c.handlers["mpris/+/command/+"]
+	c.mu.Unlock()
+	handler(topic, []byte(payload))
+}
+
+func TestBridge(t *testing.T) {
+	client := newTestClient()
+	bridge, err := NewBridge(mpristest.PrivateConn(t), client, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bridge.Close()
+
+	shortName := fmt.Sprintf("mpristest.mqtt%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	statusTopic := "mpris/" + shortName + "/status"
+	mpristest.Eventually(t, func() bool {
+		status, _ := client.get(statusTopic)
+		return status == "Stopped"
+	}, "The status of the new player wasn't published")
+	if state, _ := client.get("mpris/" + shortName + "/state"); !strings.Contains(state, `"volume":1`) {
+		t.Errorf("Unexpected state %s", state)
+	}
+
+	client.send("mpris/"+shortName+"/command/play", "")
+	mpristest.Eventually(t, func() bool {
+		status, _ := client.get(statusTopic)
+		return status == string(mpris.PlaybackPlaying)
+	}, "The play command wasn't run")
+
+	client.send("mpris/"+shortName+"/command/volume", "0.25")
+	mpristest.Eventually(t, func() bool {
+		value, _ := fake.GetProperty(mpris.PlayerInterface, "Volume")
+		return value.Value() == 0.25
+	}, "The volume command wasn't run")
+
+	fake.Close()
+	mpristest.Eventually(t, func() bool {
+		_, ok := client.get(statusTopic)
+		return !ok
+	}, "The retained status wasn't cleared")
+}
+
+func TestBridgeRemovedPlayer(t *testing.T) {
+	client := newTestClient()
+	bridge, err := NewBridge(mpristest.PrivateConn(t), client, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bridge.Close()
+
+	shortName := fmt.Sprintf("mpristest.mqttremoved%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), shortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	statusTopic := "mpris/" + shortName + "/status"
+	mpristest.Eventually(t, func() bool {
+		_, ok := client.get(statusTopic)
+		return ok
+	}, "The status of the new player wasn't published")
+
+	bridge.mu.Lock()
+	bridged := bridge.players[fake.Name()]
+	bridge.mu.Unlock()
+	bridge.removePlayer(fake.Name())
+	if _, ok := client.get(statusTopic); ok {
+		t.Fatal("Expected the retained status to be cleared")
+	}
+
+	// a publication in flight when the player was removed is dropped
+	bridge.publishState(context.Background(), bridged)
+	if status, ok := client.get(statusTopic); ok {
+		t.Errorf("Expected no retained status once the player was removed, got %s", status)
+	}
+}
@@ -0,0 +1,108 @@
+package mpris
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// CanSeek is a shortcut for PlayerClient.CanSeek.
+func (i *Player) CanSeek() (bool, error) {
+	return i.Player.CanSeek()
+}
+
+// seekTarget has what's needed to set the position of the current track.
+type seekTarget struct {
+	trackID  TrackID
+	position time.Duration
+	length   time.Duration
+}
+
+// getSeekTarget reads the current track, its position and its length in a single round trip,
+// returning ErrNotSupported if the player can't seek and ErrNilVariant if the length is
+// unknown, as it's needed for the percentages.
+func (i *Player) getSeekTarget() (seekTarget, error) {
+	props, err := i.getAllProperties(PlayerInterface)
+	if err != nil {
+		return seekTarget{}, err
+	}
+	if canSeek, ok := props["CanSeek"].Value().(bool); ok && !canSeek {
+		return seekTarget{}, ErrNotSupported
+	}
+
+	metadata, _ := props["Metadata"].Value().(map[string]dbus.Variant)
+	target := seekTarget{
+		trackID: Metadata(metadata).TrackID(),
+		length:  Metadata(metadata).Length(),
+	}
+	if target.trackID == "" || target.trackID.IsNoTrack() || target.length <= 0 {
+		return seekTarget{}, ErrNilVariant
+	}
+	position, err := int64Value(props["Position"], "Position")
+	if err != nil {
+		return seekTarget{}, err
+	}
+	target.position = microsecondsToDuration(position)
+	return target, nil
+}
+
+// clamp keeps the position within the track.
+func (t seekTarget) clamp(position time.Duration) time.Duration {
+	if position < 0 {
+		return 0
+	}
+	if position > t.length {
+		return t.length
+	}
+	return position
+}
+
+// percent returns the percentage of the track length.
+func (t seekTarget) percent(percent float64) time.Duration {
+	return time.Duration(float64(t.length) * percent / 100)
+}
+
+// SetPositionPercent sets the position of the current track to a percentage of its length,
+// from 0 to 100. Values out of the range are clamped to the start or the end of the track.
+func (i *Player) SetPositionPercent(percent float64) error {
+	target, err := i.getSeekTarget()
+	if err != nil {
+		return err
+	}
+	return i.SetPositionWithTrackID(target.trackID, target.clamp(target.percent(percent)))
+}
+
+// SeekPercent moves the position of the current track by a percentage of its length, like
+// -10 to go back by a tenth of the track. The new position is clamped to the track, instead of
+// skipping to the next track like Seek does when going past the end.
+func (i *Player) SeekPercent(percent float64) error {
+	target, err := i.getSeekTarget()
+	if err != nil {
+		return err
+	}
+	return i.SetPositionWithTrackID(target.trackID, target.clamp(target.position+target.percent(percent)))
+}
+
+// PreviousOrRestart seeks to the start of the current track if it has played for more than
+// threshold, and skips to the previous track otherwise, like the previous button of most
+// remotes. The players that can't seek or don't report the position always skip.
+func (i *Player) PreviousOrRestart(threshold time.Duration) error {
+	props, err := i.getAllProperties(PlayerInterface)
+	if err != nil {
+		return err
+	}
+	if canSeek, ok := props["CanSeek"].Value().(bool); !ok || !canSeek {
+		return i.Previous()
+	}
+	position, err := int64Value(props["Position"], "Position")
+	if err != nil || microsecondsToDuration(position) <= threshold {
+		return i.Previous()
+	}
+
+	metadata, _ := props["Metadata"].Value().(map[string]dbus.Variant)
+	if trackID := Metadata(metadata).TrackID(); checkTrackID(trackID) == nil {
+		return i.SetPositionWithTrackID(trackID, 0)
+	}
+	// without a track id, seeking back by the position reaches the start too
+	return i.Player.Seek(-microsecondsToDuration(position))
+}
@@ -0,0 +1,102 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestSeekPercent(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(200000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.SetPositionPercent(50); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 100000000 {
+		t.Errorf("Expected the position to be 100s, got %dµs", fake.Position())
+	}
+
+	if err := player.SeekPercent(-10); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 80000000 {
+		t.Errorf("Expected the position to be 80s, got %dµs", fake.Position())
+	}
+
+	if err := player.SeekPercent(200); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 200000000 {
+		t.Errorf("Expected the position to be clamped to 200s, got %dµs", fake.Position())
+	}
+
+	if err := fake.SetProperty(PlayerInterface, "CanSeek", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SetPositionPercent(0); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestPreviousOrRestart(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(200000000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousCalls := func() int {
+		calls := 0
+		for _, call := range fake.Calls() {
+			if call.Method == "Previous" {
+				calls++
+			}
+		}
+		return calls
+	}
+
+	if err := fake.SetPosition(10000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.PreviousOrRestart(3 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Position() != 0 || previousCalls() != 0 {
+		t.Errorf("Expected the track to restart, got the position %dµs and %d Previous calls", fake.Position(), previousCalls())
+	}
+
+	if err := fake.SetPosition(2000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.PreviousOrRestart(3 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if previousCalls() != 1 {
+		t.Errorf("Expected a Previous call near the start of the track, got %d", previousCalls())
+	}
+
+	if err := fake.SetPosition(10000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetProperty(PlayerInterface, "CanSeek", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.PreviousOrRestart(3 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if previousCalls() != 2 || fake.Position() != 10000000 {
+		t.Errorf("Expected a Previous call when the player can't seek, got %d", previousCalls())
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// BaseAdapter implements Adapter with the defaults of a player that can't do anything, so a
+// player only has to embed it and override what it supports:
+//
+//	type player struct {
+//		server.BaseAdapter
+//		...
+//	}
+//
+//	func (p *player) Identity() string { return "My Player" }
+//	func (p *player) CanControl() bool { return true }
+//	func (p *player) CanPlay() bool    { return true }
+//	func (p *player) Play() error      { ... }
+//
+// As the spec says, the server ignores the calls the flags don't allow, like Play when CanPlay
+// is false, so the methods of BaseAdapter are never called unless the matching flag is
+// overridden. The setters fail with mpris.ErrNotSupported.
+type BaseAdapter struct{}
+
+var _ Adapter = BaseAdapter{}
+
+// Raise does nothing.
+func (BaseAdapter) Raise() error { return nil }
+
+// Quit does nothing.
+func (BaseAdapter) Quit() error { return nil }
+
+// CanQuit returns false.
+func (BaseAdapter) CanQuit() bool { return false }
+
+// CanRaise returns false.
+func (BaseAdapter) CanRaise() bool { return false }
+
+// Identity returns the name of the executable.
+func (BaseAdapter) Identity() string { return filepath.Base(os.Args[0]) }
+
+// DesktopEntry returns an empty string, meaning there is no desktop entry.
+func (BaseAdapter) DesktopEntry() string { return "" }
+
+// SupportedURISchemes returns no scheme.
+func (BaseAdapter) SupportedURISchemes() []string { return nil }
+
+// SupportedMimeTypes returns no mime type.
+func (BaseAdapter) SupportedMimeTypes() []string { return nil }
+
+// Next does nothing.
+func (BaseAdapter) Next() error { return nil }
+
+// Previous does nothing.
+func (BaseAdapter) Previous() error { return nil }
+
+// Pause does nothing.
+func (BaseAdapter) Pause() error { return nil }
+
+// PlayPause does nothing.
+func (BaseAdapter) PlayPause() error { return nil }
+
+// Stop does nothing.
+func (BaseAdapter) Stop() error { return nil }
+
+// Play does nothing.
+func (BaseAdapter) Play() error { return nil }
+
+// Seek does nothing.
+func (BaseAdapter) Seek(offset time.Duration) error { return nil }
+
+// SetPosition does nothing.
+func (BaseAdapter) SetPosition(trackID mpris.TrackID, position time.Duration) error { return nil }
+
+// OpenURI fails with mpris.ErrNotSupported.
+func (BaseAdapter) OpenURI(uri string) error { return mpris.ErrNotSupported }
+
+// PlaybackStatus returns mpris.PlaybackStopped.
+func (BaseAdapter) PlaybackStatus() mpris.PlaybackStatus { return mpris.PlaybackStopped }
+
+// LoopStatus returns mpris.LoopNone.
+func (BaseAdapter) LoopStatus() mpris.LoopStatus { return mpris.LoopNone }
+
+// SetLoopStatus fails with mpris.ErrNotSupported.
+func (BaseAdapter) SetLoopStatus(status mpris.LoopStatus) error { return mpris.ErrNotSupported }
+
+// Rate returns 1.
+func (BaseAdapter) Rate() float64 { return 1 }
+
+// SetRate fails with mpris.ErrNotSupported.
+func (BaseAdapter) SetRate(rate float64) error { return mpris.ErrNotSupported }
+
+// Shuffle returns false.
+func (BaseAdapter) Shuffle() bool { return false }
+
+// SetShuffle fails with mpris.ErrNotSupported.
+func (BaseAdapter) SetShuffle(shuffle bool) error { return mpris.ErrNotSupported }
+
+// Metadata returns the metadata of no track, with mpris.NoTrack as track id.
+func (BaseAdapter) Metadata() mpris.Metadata {
+	return mpris.Metadata{"mpris:trackid": dbus.MakeVariant(mpris.NoTrack.ObjectPath())}
+}
+
+// Volume returns 1.
+func (BaseAdapter) Volume() float64 { return 1 }
+
+// SetVolume fails with mpris.ErrNotSupported.
+func (BaseAdapter) SetVolume(volume float64) error { return mpris.ErrNotSupported }
+
+// Position returns 0.
+func (BaseAdapter) Position() time.Duration { return 0 }
+
+// MinimumRate returns 1.
+func (BaseAdapter) MinimumRate() float64 { return 1 }
+
+// MaximumRate returns 1.
+func (BaseAdapter) MaximumRate() float64 { return 1 }
+
+// CanGoNext returns false.
+func (BaseAdapter) CanGoNext() bool { return false }
+
+// CanGoPrevious returns false.
+func (BaseAdapter) CanGoPrevious() bool { return false }
+
+// CanPlay returns false.
+func (BaseAdapter) CanPlay() bool { return false }
+
+// CanPause returns false.
+func (BaseAdapter) CanPause() bool { return false }
+
+// CanSeek returns false.
+func (BaseAdapter) CanSeek() bool { return false }
+
+// CanControl returns false.
+func (BaseAdapter) CanControl() bool { return false }
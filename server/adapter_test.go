@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// minimalPlayer is a player that can only play and pause.
+type minimalPlayer struct {
+	BaseAdapter
+
+	mu      sync.Mutex
+	playing bool
+}
+
+func (p *minimalPlayer) CanControl() bool { return true }
+func (p *minimalPlayer) CanPlay() bool    { return true }
+func (p *minimalPlayer) CanPause() bool   { return true }
+
+func (p *minimalPlayer) Play() error      { return p.set(true) }
+func (p *minimalPlayer) Pause() error     { return p.set(false) }
+func (p *minimalPlayer) PlayPause() error { return p.set(!p.Playing()) }
+
+func (p *minimalPlayer) set(playing bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playing = playing
+	return nil
+}
+
+func (p *minimalPlayer) Playing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.playing
+}
+
+func (p *minimalPlayer) PlaybackStatus() mpris.PlaybackStatus {
+	if p.Playing() {
+		return mpris.PlaybackPlaying
+	}
+	return mpris.PlaybackPaused
+}
+
+func TestBaseAdapter(t *testing.T) {
+	adapter := &minimalPlayer{}
+	_, player := newTestServer(t, adapter)
+
+	if err := player.PlayPause(); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := player.GetPlaybackStatus(); err != nil || status != mpris.PlaybackPlaying {
+		t.Errorf("Expected %s, got %s %v", mpris.PlaybackPlaying, status, err)
+	}
+	if err := player.Next(); err != nil {
+		t.Errorf("Expected Next to be ignored, got %v", err)
+	}
+	if err := player.SetVolume(0.5); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported setting the volume, got %v", err)
+	}
+
+	state, err := player.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Volume != 1 || state.Rate != 1 || state.LoopStatus != mpris.LoopNone || state.Metadata.TrackID() != mpris.NoTrack {
+		t.Errorf("Unexpected default state %+v", state)
+	}
+	if identity, err := player.GetIdentity(); err != nil || identity != filepath.Base(os.Args[0]) {
+		t.Errorf("Unexpected identity %q %v", identity, err)
+	}
+}
+
+func TestNotControllable(t *testing.T) {
+	_, player := newTestServer(t, BaseAdapter{})
+
+	if err := player.Play(); err != nil {
+		t.Errorf("Expected Play to be ignored, got %v", err)
+	}
+	if err := player.Stop(); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported stopping, got %v", err)
+	}
+	if err := player.SetShuffle(true); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported setting shuffle, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ErrNameTaken is returned by New when the bus name is owned by another connection, and the
+// name policy doesn't allow waiting for it or replacing the owner.
+var ErrNameTaken = errors.New("bus name already taken")
+
+// NamePolicy is what New does when the bus name is already owned.
+type NamePolicy int
+
+const (
+	// NameFail makes New fail with ErrNameTaken. It's the default.
+	NameFail NamePolicy = iota
+	// NameReplace takes the name from the owner, which only works if it allowed replacement,
+	// and fails with ErrNameTaken otherwise.
+	NameReplace
+	// NameQueue waits in the bus queue for the name, which the server gets when the owner
+	// releases it. The server is exported meanwhile, but unreachable through the name.
+	NameQueue
+)
+
+// WithInstanceSuffix appends .instance<pid> to the bus name, as the spec recommends for the
+// players that can run several times, like org.mpris.MediaPlayer2.app.instance1234.
+func WithInstanceSuffix() Option {
+	return func(s *Server) {
+		s.name = fmt.Sprintf("%s.instance%d", s.name, os.Getpid())
+	}
+}
+
+// WithNamePolicy sets what to do when the bus name is already owned.
+func WithNamePolicy(policy NamePolicy) Option {
+	return func(s *Server) {
+		s.namePolicy = policy
+	}
+}
+
+// WithAllowReplacement lets another player take the bus name with NameReplace. The server
+// keeps being exported but stops being reachable through the name when that happens.
+func WithAllowReplacement() Option {
+	return func(s *Server) {
+		s.allowReplacement = true
+	}
+}
+
+func (s *Server) nameFlags() dbus.RequestNameFlags {
+	var flags dbus.RequestNameFlags
+	switch s.namePolicy {
+	case NameReplace:
+		flags = dbus.NameFlagReplaceExisting | dbus.NameFlagDoNotQueue
+	case NameQueue:
+		flags = 0
+	default:
+		flags = dbus.NameFlagDoNotQueue
+	}
+	if s.allowReplacement {
+		flags |= dbus.NameFlagAllowReplacement
+	}
+	return flags
+}
+
+// requestName requests the bus name following the name policy.
+func (s *Server) requestName() error {
+	reply, err := s.conn.RequestName(s.name, s.nameFlags())
+	if err != nil {
+		return err
+	}
+	switch reply {
+	case dbus.RequestNameReplyPrimaryOwner, dbus.RequestNameReplyAlreadyOwner:
+		return nil
+	case dbus.RequestNameReplyInQueue:
+		if s.namePolicy == NameQueue {
+			return nil
+		}
+		// the name isn't wanted anymore, so the server doesn't get it later
+		s.conn.ReleaseName(s.name)
+	}
+	return fmt.Errorf("%w: %s", ErrNameTaken, s.name)
+}
+
+// releaseName releases the bus name, or leaves the queue waiting for it.
+func (s *Server) releaseName() error {
+	_, err := s.conn.ReleaseName(s.name)
+	return err
+}
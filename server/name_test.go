@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func nameOwner(conn *dbus.Conn, name string) string {
+	var owner string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, name).Store(&owner); err != nil {
+		return ""
+	}
+	return owner
+}
+
+func TestInstanceSuffix(t *testing.T) {
+	server, err := New(mpristest.PrivateConn(t), "nametest", newTestAdapter(), WithInstanceSuffix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if expected := fmt.Sprintf("org.mpris.MediaPlayer2.nametest.instance%d", os.Getpid()); server.Name() != expected {
+		t.Errorf("Expected %s, got %s", expected, server.Name())
+	}
+}
+
+func TestNamePolicy(t *testing.T) {
+	name := fmt.Sprintf("nametest.policy%d", os.Getpid())
+	first, second := mpristest.PrivateConn(t), mpristest.PrivateConn(t)
+
+	server, err := New(first, name, newTestAdapter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(second, name, newTestAdapter()); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("Expected ErrNameTaken, got %v", err)
+	}
+	if _, err := New(second, name, newTestAdapter(), WithNamePolicy(NameReplace)); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("Expected ErrNameTaken replacing without permission, got %v", err)
+	}
+
+	queued, err := New(second, name, newTestAdapter(), WithNamePolicy(NameQueue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner := nameOwner(first, server.Name()); owner != first.Names()[0] {
+		t.Errorf("Expected the first server to keep the name, got %s", owner)
+	}
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Close(); err != nil {
+		t.Errorf("Expected closing twice to do nothing, got %v", err)
+	}
+	if owner := nameOwner(first, queued.Name()); owner != second.Names()[0] {
+		t.Errorf("Expected the queued server to get the name, got %s", owner)
+	}
+	if err := queued.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if owner := nameOwner(first, queued.Name()); owner != "" {
+		t.Errorf("Expected the name to be released, got %s", owner)
+	}
+
+	server, err = New(first, name, newTestAdapter(), WithAllowReplacement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	replacing, err := New(second, name, newTestAdapter(), WithNamePolicy(NameReplace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replacing.Close()
+	if owner := nameOwner(first, server.Name()); owner != second.Names()[0] {
+		t.Errorf("Expected the name to be replaced, got %s", owner)
+	}
+}
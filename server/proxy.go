@@ -0,0 +1,574 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// ProxyCall is a call received by a Proxy, named after the Adapter method, like "Play",
+// "SetVolume" or "GoTo", with the arguments of the method, like a float64 for SetVolume.
+type ProxyCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// CallInterceptor intercepts the calls received by a Proxy. next forwards the call, which
+// may be changed, to the proxied player, and not calling it drops the call.
+type CallInterceptor func(call ProxyCall, next func(ProxyCall) error) error
+
+// PropertyRewriter rewrites a property value read from the proxied player, before it's sent
+// by the proxy. The rewriter of the Player Metadata property is also used for the metadata of
+// the track list.
+type PropertyRewriter func(iface, name string, value dbus.Variant) dbus.Variant
+
+// ProxyOption configures a Proxy created by NewProxy.
+type ProxyOption func(*proxyAdapter)
+
+// WithCallInterceptor adds an interceptor to the calls, like one clamping the volume. The
+// interceptors are called in the order they're added.
+func WithCallInterceptor(interceptor CallInterceptor) ProxyOption {
+	return func(a *proxyAdapter) {
+		a.interceptors = append(a.interceptors, interceptor)
+	}
+}
+
+// WithPropertyRewriter adds a rewriter to the property values, like one rewriting the
+// metadata. The rewriters are called in the order they're added.
+func WithPropertyRewriter(rewriter PropertyRewriter) ProxyOption {
+	return func(a *proxyAdapter) {
+		a.rewriters = append(a.rewriters, rewriter)
+	}
+}
+
+// WithServerOptions sets the options of the server exporting the proxy, like
+// WithInstanceSuffix.
+func WithServerOptions(opts ...Option) ProxyOption {
+	return func(a *proxyAdapter) {
+		a.serverOpts = append(a.serverOpts, opts...)
+	}
+}
+
+// Proxy exports an existing player under another bus name, forwarding the calls to the player
+// and the player signals to the clients of the proxy.
+type Proxy struct {
+	server  *Server
+	adapter *proxyAdapter
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewProxy exports the player on the connection under the bus name
+// org.mpris.MediaPlayer2.<name>. The TrackList and Playlists interfaces are exported when the
+// player implements them. Every property read is a call to the player, so a player created
+// with mpris.WithPropertyCache is better for proxies with many clients.
+func NewProxy(conn *dbus.Conn, player *mpris.Player, name string, opts ...ProxyOption) (*Proxy, error) {
+	adapter := &proxyAdapter{player: player}
+	for _, opt := range opts {
+		opt(adapter)
+	}
+
+	serverOpts := adapter.serverOpts
+	trackList, playlists := adapter.optional(player)
+	if trackList != nil {
+		serverOpts = append(serverOpts, WithTrackList(trackList))
+	}
+	if playlists != nil {
+		serverOpts = append(serverOpts, WithPlaylists(playlists))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	server, err := New(conn, name, adapter, serverOpts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	p := &Proxy{server: server, adapter: adapter, cancel: cancel, done: make(chan struct{})}
+	go p.forward(events, p.done)
+	return p, nil
+}
+
+// Name returns the full bus name of the proxy.
+func (p *Proxy) Name() string {
+	return p.server.Name()
+}
+
+// SetPlayer makes the proxy forward to another player, announcing that every property may
+// have changed. The TrackList and Playlists interfaces are exported again for the new player,
+// or not anymore if it doesn't implement them.
+func (p *Proxy) SetPlayer(player *mpris.Player) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	p.cancel()
+	<-p.done
+
+	p.adapter.setPlayer(player)
+	p.cancel, p.done = cancel, make(chan struct{})
+	go p.forward(events, p.done)
+	err = p.server.setOptional(p.adapter.optional(player))
+	p.invalidateAll()
+	return err
+}
+
+// Close stops the proxy. The proxied player is not closed.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	p.cancel()
+	<-p.done
+	p.mu.Unlock()
+	return p.server.Close()
+}
+
+// invalidate announces that every property of the interface may have changed. Interfaces that
+// aren't exported are ignored.
+// invalidateAll announces that every property of every interface may have changed.
+func (p *Proxy) invalidateAll() {
+	p.invalidate(mpris.BaseInterface)
+	p.invalidate(mpris.PlayerInterface)
+	p.invalidate(mpris.TrackListInterface)
+	p.invalidate(mpris.PlaylistsInterface)
+}
+
+func (p *Proxy) invalidate(iface string) {
+	changed := make(map[string]interface{})
+	for name := range propertySignatures[iface] {
+		changed[name] = nil
+	}
+	p.server.EmitPropertiesChanged(iface, changed)
+}
+
+// forward announces the player events to the clients of the proxy, until the events channel
+// is closed. The values that don't follow the spec are dropped by the server.
+func (p *Proxy) forward(events <-chan mpris.Event, done chan struct{}) {
+	defer close(done)
+	adapter := p.adapter
+	for event := range events {
+		switch event := event.(type) {
+		case mpris.PropertiesChangedEvent:
+			changed := make(map[string]interface{}, len(event.Changed)+len(event.Invalidated))
+			for name, value := range event.Changed {
+				if _, ok := propertySignatures[event.Interface][name]; ok {
+					changed[name] = adapter.rewrite(event.Interface, name, value)
+				}
+			}
+			for _, name := range event.Invalidated {
+				if _, ok := propertySignatures[event.Interface][name]; ok {
+					changed[name] = nil
+				}
+			}
+			p.server.EmitPropertiesChanged(event.Interface, changed)
+		case mpris.SeekedEvent:
+			p.server.EmitSeeked(event.Position)
+		case mpris.TrackAddedEvent:
+			p.server.EmitTrackAdded(adapter.rewriteMetadata(event.Metadata), event.After)
+		case mpris.TrackRemovedEvent:
+			p.server.EmitTrackRemoved(event.TrackID)
+		case mpris.TrackListReplacedEvent:
+			p.server.EmitTrackListReplaced(event.Tracks, event.Current)
+		case mpris.TrackMetadataChangedEvent:
+			p.server.EmitTrackMetadataChanged(event.TrackID, adapter.rewriteMetadata(event.Metadata))
+		case mpris.PlaylistChangedEvent:
+			p.server.EmitPlaylistChanged(event.Playlist)
+		case mpris.OwnerChangedEvent:
+			if event.NewOwner == "" {
+				continue
+			}
+			// the player restarted, so everything may have changed, even the interfaces it
+			// implements, which can't fail the forwarding
+			_ = p.server.setOptional(adapter.optional(adapter.current()))
+			p.invalidateAll()
+		}
+	}
+}
+
+// proxyAdapter implements the adapters by calling the proxied player. The properties that
+// can't be read fall back to the BaseAdapter defaults.
+type proxyAdapter struct {
+	BaseAdapter
+
+	mu           sync.RWMutex
+	player       *mpris.Player
+	interceptors []CallInterceptor
+	rewriters    []PropertyRewriter
+	serverOpts   []Option
+}
+
+// current returns the proxied player.
+func (a *proxyAdapter) current() *mpris.Player {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.player
+}
+
+// optional returns the adapter as the TrackList and Playlists adapters of the player, or nil
+// for the interfaces it doesn't implement.
+func (a *proxyAdapter) optional(player *mpris.Player) (TrackListAdapter, PlaylistsAdapter) {
+	var trackList TrackListAdapter
+	var playlists PlaylistsAdapter
+	if hasTrackList, err := player.HasTrackList(); err == nil && hasTrackList {
+		trackList = a
+	}
+	if _, err := player.Playlists.GetPlaylistCount(); err == nil {
+		playlists = a
+	}
+	return trackList, playlists
+}
+
+func (a *proxyAdapter) setPlayer(player *mpris.Player) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.player = player
+}
+
+// call runs the interceptors, then forwards the call to the player.
+func (a *proxyAdapter) call(method string, args ...interface{}) error {
+	next := a.forwardCall
+	for i := len(a.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := a.interceptors[i], next
+		next = func(call ProxyCall) error {
+			return interceptor(call, inner)
+		}
+	}
+	return next(ProxyCall{method, args})
+}
+
+func (a *proxyAdapter) forwardCall(call ProxyCall) error {
+	p := a.current()
+	switch call.Method {
+	case "Raise":
+		return p.Raise()
+	case "Quit":
+		return p.Quit()
+	case "Next":
+		return p.Next()
+	case "Previous":
+		return p.Previous()
+	case "Pause":
+		return p.Pause()
+	case "PlayPause":
+		return p.PlayPause()
+	case "Stop":
+		return p.Stop()
+	case "Play":
+		return p.Play()
+	case "Seek":
+		var offset time.Duration
+		if err := callArgs(call, &offset); err != nil {
+			return err
+		}
+		return p.Player.Seek(offset)
+	case "SetPosition":
+		var trackID mpris.TrackID
+		var position time.Duration
+		if err := callArgs(call, &trackID, &position); err != nil {
+			return err
+		}
+		return p.Player.SetPosition(trackID, position)
+	case "OpenURI":
+		var uri string
+		if err := callArgs(call, &uri); err != nil {
+			return err
+		}
+		return p.Player.OpenUri(uri)
+	case "SetLoopStatus":
+		var status mpris.LoopStatus
+		if err := callArgs(call, &status); err != nil {
+			return err
+		}
+		return p.Player.SetLoopStatus(status)
+	case "SetRate", "SetVolume":
+		var value float64
+		if err := callArgs(call, &value); err != nil {
+			return err
+		}
+		if call.Method == "SetRate" {
+			return p.Player.SetRate(value)
+		}
+		return p.Player.SetVolume(value)
+	case "SetShuffle":
+		var shuffle bool
+		if err := callArgs(call, &shuffle); err != nil {
+			return err
+		}
+		return p.Player.SetShuffle(shuffle)
+	case "AddTrack":
+		var uri string
+		var after mpris.TrackID
+		var setAsCurrent bool
+		if err := callArgs(call, &uri, &after, &setAsCurrent); err != nil {
+			return err
+		}
+		return p.TrackList.AddTrack(uri, after, setAsCurrent)
+	case "RemoveTrack", "GoTo":
+		var trackID mpris.TrackID
+		if err := callArgs(call, &trackID); err != nil {
+			return err
+		}
+		if call.Method == "GoTo" {
+			return p.TrackList.GoTo(trackID)
+		}
+		return p.TrackList.RemoveTrack(trackID)
+	case "ActivatePlaylist":
+		var playlistID dbus.ObjectPath
+		if err := callArgs(call, &playlistID); err != nil {
+			return err
+		}
+		return p.Playlists.ActivatePlaylist(playlistID)
+	}
+	return fmt.Errorf("%w: unknown method %s", mpris.ErrNotSupported, call.Method)
+}
+
+// callArgs stores the arguments of the call, which an interceptor may have got wrong.
+func callArgs(call ProxyCall, args ...interface{}) error {
+	if err := dbus.Store(call.Args, args...); err != nil {
+		return fmt.Errorf("%w: bad arguments for %s: %v", ErrInvalidValue, call.Method, err)
+	}
+	return nil
+}
+
+func (a *proxyAdapter) rewrite(iface, name string, value dbus.Variant) dbus.Variant {
+	for _, rewriter := range a.rewriters {
+		value = rewriter(iface, name, value)
+	}
+	return value
+}
+
+func (a *proxyAdapter) rewriteMetadata(metadata mpris.Metadata) mpris.Metadata {
+	rewritten, ok := a.rewrite(mpris.PlayerInterface, "Metadata", dbus.MakeVariant(map[string]dbus.Variant(metadata))).Value().(map[string]dbus.Variant)
+	if !ok {
+		return metadata
+	}
+	return mpris.Metadata(rewritten)
+}
+
+// property reads and rewrites a property of the player into value, which is left unchanged
+// when the property can't be read.
+func (a *proxyAdapter) property(iface, name string, value interface{}) {
+	variant, err := a.current().GetProperty(iface, name)
+	if err != nil {
+		return
+	}
+	dbus.Store([]interface{}{a.rewrite(iface, name, variant).Value()}, value)
+}
+
+func (a *proxyAdapter) boolProperty(iface, name string, fallback bool) bool {
+	a.property(iface, name, &fallback)
+	return fallback
+}
+
+func (a *proxyAdapter) floatProperty(name string, fallback float64) float64 {
+	a.property(mpris.PlayerInterface, name, &fallback)
+	return fallback
+}
+
+func (a *proxyAdapter) Raise() error { return a.call("Raise") }
+func (a *proxyAdapter) Quit() error  { return a.call("Quit") }
+
+func (a *proxyAdapter) CanQuit() bool {
+	return a.boolProperty(mpris.BaseInterface, "CanQuit", a.BaseAdapter.CanQuit())
+}
+
+func (a *proxyAdapter) CanRaise() bool {
+	return a.boolProperty(mpris.BaseInterface, "CanRaise", a.BaseAdapter.CanRaise())
+}
+
+func (a *proxyAdapter) Identity() string {
+	identity := a.BaseAdapter.Identity()
+	a.property(mpris.BaseInterface, "Identity", &identity)
+	return identity
+}
+
+func (a *proxyAdapter) DesktopEntry() string {
+	var entry string
+	a.property(mpris.BaseInterface, "DesktopEntry", &entry)
+	return entry
+}
+
+func (a *proxyAdapter) SupportedURISchemes() []string {
+	var schemes []string
+	a.property(mpris.BaseInterface, "SupportedUriSchemes", &schemes)
+	return schemes
+}
+
+func (a *proxyAdapter) SupportedMimeTypes() []string {
+	var mimeTypes []string
+	a.property(mpris.BaseInterface, "SupportedMimeTypes", &mimeTypes)
+	return mimeTypes
+}
+
+func (a *proxyAdapter) Next() error      { return a.call("Next") }
+func (a *proxyAdapter) Previous() error  { return a.call("Previous") }
+func (a *proxyAdapter) Pause() error     { return a.call("Pause") }
+func (a *proxyAdapter) PlayPause() error { return a.call("PlayPause") }
+func (a *proxyAdapter) Stop() error      { return a.call("Stop") }
+func (a *proxyAdapter) Play() error      { return a.call("Play") }
+
+func (a *proxyAdapter) Seek(offset time.Duration) error {
+	return a.call("Seek", offset)
+}
+
+func (a *proxyAdapter) SetPosition(trackID mpris.TrackID, position time.Duration) error {
+	return a.call("SetPosition", trackID, position)
+}
+
+func (a *proxyAdapter) OpenURI(uri string) error {
+	return a.call("OpenURI", uri)
+}
+
+func (a *proxyAdapter) PlaybackStatus() mpris.PlaybackStatus {
+	status := string(a.BaseAdapter.PlaybackStatus())
+	a.property(mpris.PlayerInterface, "PlaybackStatus", &status)
+	return mpris.PlaybackStatus(status)
+}
+
+func (a *proxyAdapter) LoopStatus() mpris.LoopStatus {
+	status := string(a.BaseAdapter.LoopStatus())
+	a.property(mpris.PlayerInterface, "LoopStatus", &status)
+	return mpris.LoopStatus(status)
+}
+
+func (a *proxyAdapter) SetLoopStatus(status mpris.LoopStatus) error {
+	return a.call("SetLoopStatus", status)
+}
+
+func (a *proxyAdapter) Rate() float64 {
+	return a.floatProperty("Rate", a.BaseAdapter.Rate())
+}
+
+func (a *proxyAdapter) SetRate(rate float64) error {
+	return a.call("SetRate", rate)
+}
+
+func (a *proxyAdapter) Shuffle() bool {
+	return a.boolProperty(mpris.PlayerInterface, "Shuffle", a.BaseAdapter.Shuffle())
+}
+
+func (a *proxyAdapter) SetShuffle(shuffle bool) error {
+	return a.call("SetShuffle", shuffle)
+}
+
+func (a *proxyAdapter) Metadata() mpris.Metadata {
+	var metadata map[string]dbus.Variant
+	a.property(mpris.PlayerInterface, "Metadata", &metadata)
+	return mpris.Metadata(metadata)
+}
+
+func (a *proxyAdapter) Volume() float64 {
+	return a.floatProperty("Volume", a.BaseAdapter.Volume())
+}
+
+func (a *proxyAdapter) SetVolume(volume float64) error {
+	return a.call("SetVolume", volume)
+}
+
+func (a *proxyAdapter) Position() time.Duration {
+	var position int64
+	a.property(mpris.PlayerInterface, "Position", &position)
+	return microsecondsToDuration(position)
+}
+
+func (a *proxyAdapter) MinimumRate() float64 {
+	return a.floatProperty("MinimumRate", a.BaseAdapter.MinimumRate())
+}
+
+func (a *proxyAdapter) MaximumRate() float64 {
+	return a.floatProperty("MaximumRate", a.BaseAdapter.MaximumRate())
+}
+
+func (a *proxyAdapter) CanGoNext() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanGoNext", a.BaseAdapter.CanGoNext())
+}
+
+func (a *proxyAdapter) CanGoPrevious() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanGoPrevious", a.BaseAdapter.CanGoPrevious())
+}
+
+func (a *proxyAdapter) CanPlay() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanPlay", a.BaseAdapter.CanPlay())
+}
+
+func (a *proxyAdapter) CanPause() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanPause", a.BaseAdapter.CanPause())
+}
+
+func (a *proxyAdapter) CanSeek() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanSeek", a.BaseAdapter.CanSeek())
+}
+
+func (a *proxyAdapter) CanControl() bool {
+	return a.boolProperty(mpris.PlayerInterface, "CanControl", a.BaseAdapter.CanControl())
+}
+
+func (a *proxyAdapter) TracksMetadata(trackIDs []mpris.TrackID) ([]mpris.Metadata, error) {
+	metadata, err := a.current().TrackList.GetTracksMetadata(trackIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, track := range metadata {
+		metadata[i] = a.rewriteMetadata(track)
+	}
+	return metadata, nil
+}
+
+func (a *proxyAdapter) AddTrack(uri string, after mpris.TrackID, setAsCurrent bool) error {
+	return a.call("AddTrack", uri, after, setAsCurrent)
+}
+
+func (a *proxyAdapter) RemoveTrack(trackID mpris.TrackID) error {
+	return a.call("RemoveTrack", trackID)
+}
+
+func (a *proxyAdapter) GoTo(trackID mpris.TrackID) error {
+	return a.call("GoTo", trackID)
+}
+
+func (a *proxyAdapter) Tracks() []mpris.TrackID {
+	tracks, _ := a.current().TrackList.GetTracks()
+	return tracks
+}
+
+func (a *proxyAdapter) CanEditTracks() bool {
+	return a.boolProperty(mpris.TrackListInterface, "CanEditTracks", false)
+}
+
+func (a *proxyAdapter) ActivatePlaylist(playlistID dbus.ObjectPath) error {
+	return a.call("ActivatePlaylist", playlistID)
+}
+
+func (a *proxyAdapter) Playlists(index, maxCount uint32, order mpris.PlaylistOrdering, reverse bool) ([]mpris.Playlist, error) {
+	return a.current().Playlists.GetPlaylists(index, maxCount, order, reverse)
+}
+
+func (a *proxyAdapter) PlaylistCount() uint32 {
+	count, _ := a.current().Playlists.GetPlaylistCount()
+	return count
+}
+
+func (a *proxyAdapter) Orderings() []mpris.PlaylistOrdering {
+	orderings, _ := a.current().Playlists.GetOrderings()
+	return orderings
+}
+
+func (a *proxyAdapter) ActivePlaylist() mpris.MaybePlaylist {
+	active, _ := a.current().Playlists.GetActivePlaylist()
+	return active
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestProxy(t *testing.T) {
+	fake, err := mpristest.New(mpristest.PrivateConn(t), fmt.Sprintf("proxied.instance%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	err = fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/a")),
+		"xesam:title":   dbus.MakeVariant("quiet song"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clampVolume := func(call ProxyCall, next func(ProxyCall) error) error {
+		if call.Method == "SetVolume" {
+			call.Args = []interface{}{math.Min(call.Args[0].(float64), 0.5)}
+		}
+		if call.Method == "Quit" {
+			return nil
+		}
+		return next(call)
+	}
+	upperTitle := func(iface, name string, value dbus.Variant) dbus.Variant {
+		metadata, ok := value.Value().(map[string]dbus.Variant)
+		if iface != mpris.PlayerInterface || name != "Metadata" || !ok {
+			return value
+		}
+		rewritten := make(map[string]dbus.Variant, len(metadata))
+		for key, value := range metadata {
+			rewritten[key] = value
+		}
+		if title, ok := metadata["xesam:title"].Value().(string); ok {
+			rewritten["xesam:title"] = dbus.MakeVariant(strings.ToUpper(title))
+		}
+		return dbus.MakeVariant(rewritten)
+	}
+
+	source := mpris.New(mpristest.PrivateConn(t), fake.Name())
+	proxy, err := NewProxy(mpristest.PrivateConn(t), source, "proxytest",
+		WithCallInterceptor(clampVolume),
+		WithPropertyRewriter(upperTitle),
+		WithServerOptions(WithInstanceSuffix()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+	player := mpris.New(mpristest.PrivateConn(t), proxy.Name())
+	if hasTrackList, err := player.HasTrackList(); err != nil || !hasTrackList {
+		t.Fatalf("Expected the track list of the first player, got %t %v", hasTrackList, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := fake.GetProperty(mpris.PlayerInterface, "PlaybackStatus"); err != true || status.Value() != "Playing" {
+		t.Errorf("Expected the proxied player to play, got %v", status)
+	}
+	if err := player.SetVolume(0.8); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := source.GetVolume(); err != nil || volume != 0.5 {
+		t.Errorf("Expected the volume to be clamped to 0.5, got %f %v", volume, err)
+	}
+	if err := player.Quit(); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range fake.Calls() {
+		if call.Method == "Quit" {
+			t.Error("Expected Quit to be dropped")
+		}
+	}
+
+	if title, err := player.GetTitle(); err != nil || title != "QUIET SONG" {
+		t.Errorf("Expected the title to be rewritten, got %q %v", title, err)
+	}
+	if hasTrackList, err := player.HasTrackList(); err != nil || !hasTrackList {
+		t.Errorf("Expected the track list to be proxied, got %t %v", hasTrackList, err)
+	}
+
+	// the changes made by the calls are forwarded too, so the events are searched
+	waitFor := func(match func(mpris.Event) bool, message string) {
+		t.Helper()
+		for {
+			select {
+			case event := <-events:
+				if match(event) {
+					return
+				}
+			case <-ctx.Done():
+				t.Fatal(message)
+			}
+		}
+	}
+	if err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/b")),
+		"xesam:title":   dbus.MakeVariant("loud song"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(func(event mpris.Event) bool {
+		changed, ok := event.(mpris.PropertiesChangedEvent)
+		if !ok {
+			return false
+		}
+		metadata, _ := changed.Changed["Metadata"].Value().(map[string]dbus.Variant)
+		return mpris.Metadata(metadata).Title() == "LOUD SONG"
+	}, "Rewritten metadata change not received")
+
+	if err := fake.EmitSeeked(3000000); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(func(event mpris.Event) bool {
+		seeked, ok := event.(mpris.SeekedEvent)
+		return ok && seeked.Position == 3*time.Second
+	}, "Seeked not received")
+}
+
+func TestProxySetPlayer(t *testing.T) {
+	newFake := func(name, title string) *mpristest.Player {
+		fake, err := mpristest.New(mpristest.PrivateConn(t), fmt.Sprintf("%s.instance%d", name, os.Getpid()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = fake.SetMetadata(map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/" + name)),
+			"xesam:title":   dbus.MakeVariant(title),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fake
+	}
+	first := newFake("first", "first song")
+	defer first.Close()
+	second := newFake("second", "second song")
+	defer second.Close()
+	if err := second.SetProperty(mpris.BaseInterface, "HasTrackList", false); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := mpristest.PrivateConn(t)
+	proxy, err := NewProxy(conn, mpris.New(conn, first.Name()), "proxytest", WithServerOptions(WithInstanceSuffix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+	player := mpris.New(mpristest.PrivateConn(t), proxy.Name())
+	if hasTrackList, err := player.HasTrackList(); err != nil || !hasTrackList {
+		t.Fatalf("Expected the track list of the first player, got %t %v", hasTrackList, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := proxy.SetPlayer(mpris.New(conn, second.Name())); err != nil {
+		t.Fatal(err)
+	}
+	for received := false; !received; {
+		select {
+		case event := <-events:
+			changed, ok := event.(mpris.PropertiesChangedEvent)
+			if ok && changed.Interface == mpris.PlayerInterface {
+				metadata, _ := changed.Changed["Metadata"].Value().(map[string]dbus.Variant)
+				received = mpris.Metadata(metadata).Title() == "second song"
+			}
+		case <-ctx.Done():
+			t.Fatal("Change of player not announced")
+		}
+	}
+
+	if hasTrackList, err := player.HasTrackList(); err != nil || hasTrackList {
+		t.Errorf("Expected no track list for the second player, got %t %v", hasTrackList, err)
+	}
+	if _, err := player.TrackList.GetTracks(); err == nil {
+		t.Error("Expected the TrackList interface not to be exported for the second player")
+	}
+	if _, err := player.Playlists.GetPlaylistCount(); err != nil {
+		t.Errorf("Expected the Playlists interface to stay exported, got %v", err)
+	}
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Calls()) != 0 {
+		t.Errorf("Expected the first player to not be called, got %v", first.Calls())
+	}
+	if status, ok := second.GetProperty(mpris.PlayerInterface, "PlaybackStatus"); !ok || status.Value() != "Playing" {
+		t.Errorf("Expected the second player to play, got %v", status)
+	}
+}
+
+func TestProxyRestart(t *testing.T) {
+	name := fmt.Sprintf("restarted.instance%d", os.Getpid())
+	fake, err := mpristest.New(mpristest.PrivateConn(t), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy, err := NewProxy(mpristest.PrivateConn(t), mpris.New(mpristest.PrivateConn(t), fake.Name()), "restarttest",
+		WithServerOptions(WithInstanceSuffix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := mpris.New(mpristest.PrivateConn(t), proxy.Name()).Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.Close()
+	restarted, err := mpristest.New(mpristest.PrivateConn(t), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+
+	// the identity of the restarted player may have changed too
+	for {
+		select {
+		case event := <-events:
+			if changed, ok := event.(mpris.PropertiesChangedEvent); ok && changed.Interface == mpris.BaseInterface {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("Expected the base properties to be invalidated when the player restarted")
+		}
+	}
+}
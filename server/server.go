@@ -0,0 +1,553 @@
+// Package server exports a media player implemented in Go over MPRIS, so it can be controlled
+// like any other player by desktop environments, playerctl or go-mpris itself.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/spec"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+//go:generate go run ../internal/mprisgen -server -o bindings_gen.go
+
+const (
+	objectPath              = "/org/mpris/MediaPlayer2"
+	propertiesInterface     = "org.freedesktop.DBus.Properties"
+	introspectableInterface = "org.freedesktop.DBus.Introspectable"
+
+	errUnknownInterface = "org.freedesktop.DBus.Error.UnknownInterface"
+	errUnknownProperty  = "org.freedesktop.DBus.Error.UnknownProperty"
+	errPropertyReadOnly = "org.freedesktop.DBus.Error.PropertyReadOnly"
+	errInvalidArgs      = "org.freedesktop.DBus.Error.InvalidArgs"
+	errNotSupported     = "org.freedesktop.DBus.Error.NotSupported"
+)
+
+// ErrNotExported is returned when emitting a signal of an interface the server doesn't export,
+// like TrackAdded without WithTrackList.
+var ErrNotExported = errors.New("interface not exported")
+
+// errNotControllable is returned when changing a player whose CanControl is false.
+var errNotControllable = fmt.Errorf("%w: the player can't be controlled", mpris.ErrNotSupported)
+
+// methodNames maps the Go methods that can't use the D-Bus name, as Seek would clash with
+// io.Seeker, to their D-Bus names.
+var methodNames = map[string]string{
+	"SeekOffset": "Seek",
+}
+
+// RootAdapter implements the org.mpris.MediaPlayer2 interface.
+type RootAdapter interface {
+	Raise() error
+	Quit() error
+
+	CanQuit() bool
+	CanRaise() bool
+	Identity() string
+	DesktopEntry() string
+	SupportedURISchemes() []string
+	SupportedMimeTypes() []string
+}
+
+// PlayerAdapter implements the org.mpris.MediaPlayer2.Player interface. Positions and offsets
+// are converted from and to the microseconds used on the bus.
+type PlayerAdapter interface {
+	Next() error
+	Previous() error
+	Pause() error
+	PlayPause() error
+	Stop() error
+	Play() error
+	Seek(offset time.Duration) error
+	SetPosition(trackID mpris.TrackID, position time.Duration) error
+	OpenURI(uri string) error
+
+	PlaybackStatus() mpris.PlaybackStatus
+	LoopStatus() mpris.LoopStatus
+	SetLoopStatus(status mpris.LoopStatus) error
+	Rate() float64
+	SetRate(rate float64) error
+	Shuffle() bool
+	SetShuffle(shuffle bool) error
+	Metadata() mpris.Metadata
+	Volume() float64
+	SetVolume(volume float64) error
+	Position() time.Duration
+	MinimumRate() float64
+	MaximumRate() float64
+	CanGoNext() bool
+	CanGoPrevious() bool
+	CanPlay() bool
+	CanPause() bool
+	CanSeek() bool
+	CanControl() bool
+}
+
+// Adapter is the player exported by a Server. The methods are called from the D-Bus connection
+// goroutines, so they must be safe for concurrent use. The changes of the properties must be
+// announced with EmitPropertiesChanged, except for the ones set through the bus, which the
+// server announces itself.
+type Adapter interface {
+	RootAdapter
+	PlayerAdapter
+}
+
+// Option configures a Server created by New.
+type Option func(*Server)
+
+// Server exports an Adapter on a D-Bus connection as an MPRIS player.
+type Server struct {
+	conn    *dbus.Conn
+	name    string
+	adapter Adapter
+
+	maxVolume        float64
+	namePolicy       NamePolicy
+	allowReplacement bool
+	closeOnce        sync.Once
+
+	// mu guards the optional adapters and the properties, which a Proxy changes along with
+	// its player.
+	mu        sync.RWMutex
+	trackList TrackListAdapter
+	playlists PlaylistsAdapter
+	props     map[string]map[string]property
+}
+
+// property is a property of an exported interface. Read only properties have no set.
+type property struct {
+	get func() interface{}
+	set func(value dbus.Variant) error
+}
+
+// New exports the adapter on the connection and requests the bus name
+// org.mpris.MediaPlayer2.<name>, with an instance suffix when WithInstanceSuffix is given. When
+// the name is already owned, New fails with ErrNameTaken unless another policy is set with
+// WithNamePolicy. The TrackList and Playlists interfaces are only exported when their adapters
+// are given with WithTrackList and WithPlaylists.
+func New(conn *dbus.Conn, name string, adapter Adapter, opts ...Option) (*Server, error) {
+	s := &Server{
+		conn:    conn,
+		name:    mpris.BaseInterface + "." + name,
+		adapter: adapter,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.props = s.properties()
+
+	if err := s.export(); err != nil {
+		s.unexport()
+		return nil, err
+	}
+
+	if err := s.requestName(); err != nil {
+		s.unexport()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the full bus name of the server.
+func (s *Server) Name() string {
+	return s.name
+}
+
+// Close releases the bus name and stops exporting the player. Calling it more than once does
+// nothing.
+func (s *Server) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		// the name goes first, so no call is received once the interfaces are gone
+		err = s.releaseName()
+		s.unexport()
+	})
+	return err
+}
+
+// exports returns the exported interfaces. Like export, it's called with mu held, or by New
+// before the server is shared.
+func (s *Server) exports() map[string]interface{} {
+	exports := map[string]interface{}{
+		propertiesInterface:   &propertiesExport{s},
+		mpris.BaseInterface:   &rootExport{s.adapter},
+		mpris.PlayerInterface: &playerExport{s.adapter},
+	}
+	if s.trackList != nil {
+		exports[mpris.TrackListInterface] = &trackListExport{s.trackList}
+	}
+	if s.playlists != nil {
+		exports[mpris.PlaylistsInterface] = &playlistsExport{s.playlists}
+	}
+	exports[introspectableInterface] = s.introspection(exports)
+	return exports
+}
+
+// introspection returns the introspection data of the exported interfaces, the MPRIS ones
+// being described by the spec, without the properties the server leaves out.
+func (s *Server) introspection(exports map[string]interface{}) introspect.Introspectable {
+	node := &introspect.Node{Name: objectPath}
+	for name := range exports {
+		if name == propertiesInterface {
+			node.Interfaces = append(node.Interfaces, prop.IntrospectData)
+			continue
+		}
+		iface, ok := spec.Lookup(name)
+		if !ok {
+			continue
+		}
+		data := iface.Introspection()
+		data.Properties = slices.DeleteFunc(data.Properties, func(p introspect.Property) bool {
+			_, ok := s.props[name][p.Name]
+			return !ok
+		})
+		node.Interfaces = append(node.Interfaces, data)
+	}
+	slices.SortFunc(node.Interfaces, func(a, b introspect.Interface) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return introspect.NewIntrospectable(node)
+}
+
+func (s *Server) export() error {
+	for iface, export := range s.exports() {
+		if err := s.conn.ExportWithMap(export, methodNames, objectPath, iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) unexport() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for iface := range s.exports() {
+		s.conn.Export(nil, objectPath, iface)
+	}
+}
+
+// setOptional exports the TrackList and Playlists interfaces of the adapters, and stops
+// exporting the ones whose adapter is nil. The clients are left to be told that HasTrackList
+// and the properties of the interfaces may have changed.
+func (s *Server) setOptional(trackList TrackListAdapter, playlists PlaylistsAdapter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if trackList == nil {
+		s.conn.Export(nil, objectPath, mpris.TrackListInterface)
+	}
+	if playlists == nil {
+		s.conn.Export(nil, objectPath, mpris.PlaylistsInterface)
+	}
+	s.trackList, s.playlists = trackList, playlists
+	s.props = s.properties()
+	return s.export()
+}
+
+// propertiesOf returns the properties of the interface, or false if it isn't exported.
+func (s *Server) propertiesOf(iface string) (map[string]property, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	props, ok := s.props[iface]
+	return props, ok
+}
+
+func (s *Server) hasTrackList() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.trackList != nil
+}
+
+func (s *Server) hasPlaylists() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.playlists != nil
+}
+
+// properties returns the properties of the exported interfaces: the getters generated from the
+// spec, completed with the setters and the properties that need more than a conversion.
+func (s *Server) properties() map[string]map[string]property {
+	props := map[string]map[string]property{
+		mpris.BaseInterface:   rootProperties(s.adapter),
+		mpris.PlayerInterface: playerProperties(s.adapter),
+	}
+	props[mpris.BaseInterface]["HasTrackList"] = property{get: func() interface{} {
+		return s.hasTrackList()
+	}}
+	for name, set := range s.playerSetters() {
+		prop := props[mpris.PlayerInterface][name]
+		prop.set = set
+		props[mpris.PlayerInterface][name] = prop
+	}
+	if s.trackList != nil {
+		props[mpris.TrackListInterface] = trackListProperties(s.trackList)
+	}
+	if s.playlists != nil {
+		props[mpris.PlaylistsInterface] = playlistsProperties(s.playlists)
+		addPlaylistsProperties(props[mpris.PlaylistsInterface], s.playlists)
+	}
+	return props
+}
+
+// playerSetters returns the setters of the writable properties of the Player interface.
+func (s *Server) playerSetters() map[string]func(value dbus.Variant) error {
+	a := s.adapter
+	return map[string]func(value dbus.Variant) error{
+		"LoopStatus": func(value dbus.Variant) error {
+			status, ok := value.Value().(string)
+			if !ok {
+				return invalidArgs("LoopStatus", value)
+			}
+			if err := checkLoopStatus(mpris.LoopStatus(status)); err != nil {
+				return err
+			}
+			return a.SetLoopStatus(mpris.LoopStatus(status))
+		},
+		"Rate": func(value dbus.Variant) error {
+			rate, ok := value.Value().(float64)
+			if !ok {
+				return invalidArgs("Rate", value)
+			}
+			if rate == 0 {
+				// as the spec says, a rate of 0 pauses the player
+				if !a.CanPause() {
+					return nil
+				}
+				return a.Pause()
+			}
+			if err := s.checkRate(rate); err != nil {
+				return err
+			}
+			return a.SetRate(rate)
+		},
+		"Shuffle": func(value dbus.Variant) error {
+			shuffle, ok := value.Value().(bool)
+			if !ok {
+				return invalidArgs("Shuffle", value)
+			}
+			return a.SetShuffle(shuffle)
+		},
+		"Volume": func(value dbus.Variant) error {
+			volume, ok := value.Value().(float64)
+			if !ok {
+				return invalidArgs("Volume", value)
+			}
+			// as the spec says, negative volumes mute the player
+			volume = math.Max(volume, 0)
+			if err := s.checkVolume(volume); err != nil {
+				return err
+			}
+			return a.SetVolume(volume)
+		},
+	}
+}
+
+func durationToMicroseconds(duration time.Duration) int64 {
+	return int64(duration / time.Microsecond)
+}
+
+func microsecondsToDuration(microseconds int64) time.Duration {
+	return time.Duration(microseconds) * time.Microsecond
+}
+
+// metadataValue converts the metadata to the type it's sent as, empty metadata being sent as
+// the metadata of NoTrack.
+func metadataValue(metadata mpris.Metadata) map[string]dbus.Variant {
+	if len(metadata) == 0 {
+		return map[string]dbus.Variant{"mpris:trackid": dbus.MakeVariant(mpris.NoTrack.ObjectPath())}
+	}
+	return map[string]dbus.Variant(metadata)
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func invalidArgs(name string, value dbus.Variant) *dbus.Error {
+	return dbus.NewError(errInvalidArgs, []interface{}{
+		fmt.Sprintf("unexpected type %s for %s", value.Signature(), name),
+	})
+}
+
+// toDBusError converts the errors returned by the adapters to D-Bus errors, mpris.ErrNotSupported
+// becoming org.freedesktop.DBus.Error.NotSupported, and ErrInvalidValue and
+// mpris.ErrInvalidTrackID becoming org.freedesktop.DBus.Error.InvalidArgs.
+func toDBusError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	var dbusErr *dbus.Error
+	if errors.As(err, &dbusErr) {
+		return dbusErr
+	}
+	if errors.Is(err, mpris.ErrNotSupported) {
+		return dbus.NewError(errNotSupported, []interface{}{err.Error()})
+	}
+	if errors.Is(err, ErrInvalidValue) || errors.Is(err, mpris.ErrInvalidTrackID) {
+		return dbus.NewError(errInvalidArgs, []interface{}{err.Error()})
+	}
+	return dbus.MakeFailedError(err)
+}
+
+type propertiesExport struct {
+	s *Server
+}
+
+func (e *propertiesExport) property(iface, name string) (property, *dbus.Error) {
+	props, ok := e.s.propertiesOf(iface)
+	if !ok {
+		return property{}, dbus.NewError(errUnknownInterface, []interface{}{iface})
+	}
+	prop, ok := props[name]
+	if !ok {
+		return property{}, dbus.NewError(errUnknownProperty, []interface{}{name})
+	}
+	return prop, nil
+}
+
+func (e *propertiesExport) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	prop, err := e.property(iface, name)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	value := prop.get()
+	if err := e.s.checkProperty(iface, name, value); err != nil {
+		// the adapter is wrong, not the caller
+		return dbus.Variant{}, dbus.MakeFailedError(err)
+	}
+	return dbus.MakeVariant(value), nil
+}
+
+func (e *propertiesExport) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	props, ok := e.s.propertiesOf(iface)
+	if !ok {
+		return nil, dbus.NewError(errUnknownInterface, []interface{}{iface})
+	}
+	values := make(map[string]dbus.Variant, len(props))
+	for name, prop := range props {
+		value := prop.get()
+		if e.s.checkProperty(iface, name, value) == nil {
+			values[name] = dbus.MakeVariant(value)
+		}
+	}
+	return values, nil
+}
+
+func (e *propertiesExport) Set(iface, name string, value dbus.Variant) *dbus.Error {
+	prop, err := e.property(iface, name)
+	if err != nil {
+		return err
+	}
+	if prop.set == nil {
+		return dbus.NewError(errPropertyReadOnly, []interface{}{name})
+	}
+	if iface == mpris.PlayerInterface && !e.s.adapter.CanControl() {
+		return toDBusError(errNotControllable)
+	}
+	if err := prop.set(value); err != nil {
+		return toDBusError(err)
+	}
+	return toDBusError(e.s.EmitPropertiesChanged(iface, map[string]interface{}{name: prop.get()}))
+}
+
+type rootExport struct {
+	a RootAdapter
+}
+
+// The methods the flags don't allow have no effect, as the spec says, except for Stop that
+// also fails.
+
+func (e *rootExport) Raise() *dbus.Error {
+	if !e.a.CanRaise() {
+		return nil
+	}
+	return toDBusError(e.a.Raise())
+}
+
+func (e *rootExport) Quit() *dbus.Error {
+	if !e.a.CanQuit() {
+		return nil
+	}
+	return toDBusError(e.a.Quit())
+}
+
+type playerExport struct {
+	a PlayerAdapter
+}
+
+// can returns true if the player can be controlled and the flag is true.
+func (e *playerExport) can(flag func() bool) bool {
+	return e.a.CanControl() && flag()
+}
+
+func (e *playerExport) Next() *dbus.Error {
+	if !e.can(e.a.CanGoNext) {
+		return nil
+	}
+	return toDBusError(e.a.Next())
+}
+
+func (e *playerExport) Previous() *dbus.Error {
+	if !e.can(e.a.CanGoPrevious) {
+		return nil
+	}
+	return toDBusError(e.a.Previous())
+}
+
+func (e *playerExport) Pause() *dbus.Error {
+	if !e.can(e.a.CanPause) {
+		return nil
+	}
+	return toDBusError(e.a.Pause())
+}
+
+func (e *playerExport) PlayPause() *dbus.Error {
+	if !e.can(e.a.CanPause) {
+		return nil
+	}
+	return toDBusError(e.a.PlayPause())
+}
+
+func (e *playerExport) Stop() *dbus.Error {
+	if !e.a.CanControl() {
+		return toDBusError(errNotControllable)
+	}
+	return toDBusError(e.a.Stop())
+}
+
+func (e *playerExport) Play() *dbus.Error {
+	if !e.can(e.a.CanPlay) {
+		return nil
+	}
+	return toDBusError(e.a.Play())
+}
+
+func (e *playerExport) SeekOffset(offset int64) *dbus.Error {
+	if !e.can(e.a.CanSeek) {
+		return nil
+	}
+	return toDBusError(e.a.Seek(microsecondsToDuration(offset)))
+}
+
+func (e *playerExport) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	// as the spec says, negative positions are ignored
+	if !e.can(e.a.CanSeek) || position < 0 {
+		return nil
+	}
+	return toDBusError(e.a.SetPosition(mpris.TrackID(trackID), microsecondsToDuration(position)))
+}
+
+func (e *playerExport) OpenUri(uri string) *dbus.Error {
+	return toDBusError(e.a.OpenURI(uri))
+}
@@ -0,0 +1,488 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/Pauloo27/go-mpris/spec"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+// testAdapter is a player recording the calls it receives.
+type testAdapter struct {
+	BaseAdapter
+
+	mu       sync.Mutex
+	calls    []string
+	status   mpris.PlaybackStatus
+	volume   float64
+	position time.Duration
+	metadata mpris.Metadata
+}
+
+func newTestAdapter() *testAdapter {
+	return &testAdapter{status: mpris.PlaybackStopped, volume: 1}
+}
+
+func (a *testAdapter) record(call string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, call)
+}
+
+func (a *testAdapter) Calls() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.calls...)
+}
+
+func (a *testAdapter) Raise() error { a.record("Raise"); return nil }
+
+func (a *testAdapter) CanRaise() bool                { return true }
+func (a *testAdapter) Identity() string              { return "Test Player" }
+func (a *testAdapter) SupportedURISchemes() []string { return []string{"file"} }
+
+func (a *testAdapter) Next() error      { a.record("Next"); return nil }
+func (a *testAdapter) Previous() error  { a.record("Previous"); return nil }
+func (a *testAdapter) Pause() error     { return a.setStatus(mpris.PlaybackPaused) }
+func (a *testAdapter) PlayPause() error { a.record("PlayPause"); return nil }
+func (a *testAdapter) Stop() error      { return a.setStatus(mpris.PlaybackStopped) }
+func (a *testAdapter) Play() error      { return a.setStatus(mpris.PlaybackPlaying) }
+
+func (a *testAdapter) setStatus(status mpris.PlaybackStatus) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.status = status
+	return nil
+}
+
+func (a *testAdapter) Seek(offset time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.position += offset
+	return nil
+}
+
+func (a *testAdapter) SetPosition(trackID mpris.TrackID, position time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if trackID == a.metadata.TrackID() {
+		a.position = position
+	}
+	return nil
+}
+
+func (a *testAdapter) OpenURI(uri string) error { a.record("OpenURI " + uri); return nil }
+
+func (a *testAdapter) PlaybackStatus() mpris.PlaybackStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status
+}
+
+func (a *testAdapter) Metadata() mpris.Metadata {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.metadata
+}
+
+func (a *testAdapter) Volume() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.volume
+}
+
+func (a *testAdapter) SetVolume(volume float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.volume = volume
+	return nil
+}
+
+func (a *testAdapter) Position() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.position
+}
+
+func (a *testAdapter) CanGoNext() bool     { return true }
+func (a *testAdapter) CanGoPrevious() bool { return true }
+func (a *testAdapter) CanPlay() bool       { return true }
+func (a *testAdapter) CanPause() bool      { return true }
+func (a *testAdapter) CanSeek() bool       { return true }
+func (a *testAdapter) CanControl() bool    { return true }
+
+// testTrackList is a queue of tracks named after their uris.
+type testTrackList struct {
+	mu     sync.Mutex
+	tracks []mpris.Metadata
+}
+
+func trackMetadata(trackID mpris.TrackID, title string) mpris.Metadata {
+	return mpris.Metadata{
+		"mpris:trackid": dbus.MakeVariant(trackID.ObjectPath()),
+		"xesam:title":   dbus.MakeVariant(title),
+	}
+}
+
+func (l *testTrackList) TracksMetadata(trackIDs []mpris.TrackID) ([]mpris.Metadata, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var metadata []mpris.Metadata
+	for _, trackID := range trackIDs {
+		for _, track := range l.tracks {
+			if track.TrackID() == trackID {
+				metadata = append(metadata, track)
+			}
+		}
+	}
+	return metadata, nil
+}
+
+func (l *testTrackList) AddTrack(uri string, after mpris.TrackID, setAsCurrent bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tracks = append(l.tracks, trackMetadata(mpris.TrackID("/org/test/track/"+uri), uri))
+	return nil
+}
+
+func (l *testTrackList) RemoveTrack(mpris.TrackID) error { return mpris.ErrNotSupported }
+func (l *testTrackList) GoTo(mpris.TrackID) error        { return nil }
+
+func (l *testTrackList) Tracks() []mpris.TrackID {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tracks := make([]mpris.TrackID, len(l.tracks))
+	for i, track := range l.tracks {
+		tracks[i] = track.TrackID()
+	}
+	return tracks
+}
+
+func (l *testTrackList) CanEditTracks() bool { return true }
+
+type testPlaylists struct {
+	playlists []mpris.Playlist
+}
+
+func (p *testPlaylists) ActivatePlaylist(dbus.ObjectPath) error { return nil }
+
+func (p *testPlaylists) Playlists(index, maxCount uint32, order mpris.PlaylistOrdering, reverse bool) ([]mpris.Playlist, error) {
+	if int(index) >= len(p.playlists) {
+		return nil, nil
+	}
+	playlists := p.playlists[index:]
+	if int(maxCount) < len(playlists) {
+		playlists = playlists[:maxCount]
+	}
+	return playlists, nil
+}
+
+func (p *testPlaylists) PlaylistCount() uint32 { return uint32(len(p.playlists)) }
+
+func (p *testPlaylists) Orderings() []mpris.PlaylistOrdering {
+	return []mpris.PlaylistOrdering{mpris.PlaylistUserDefined}
+}
+
+func (p *testPlaylists) ActivePlaylist() mpris.MaybePlaylist { return mpris.MaybePlaylist{} }
+
+func newTestServer(t *testing.T, adapter Adapter, opts ...Option) (*Server, *mpris.Player) {
+	conn := mpristest.PrivateConn(t)
+	server, err := New(conn, fmt.Sprintf("servertest.instance%d", os.Getpid()), adapter, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server, mpris.New(mpristest.PrivateConn(t), server.Name())
+}
+
+func TestServer(t *testing.T) {
+	adapter := newTestAdapter()
+	adapter.metadata = trackMetadata("/org/test/track/a", "A")
+	_, player := newTestServer(t, adapter)
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := player.GetPlaybackStatus(); err != nil || status != mpris.PlaybackPlaying {
+		t.Errorf("Expected %s, got %s %v", mpris.PlaybackPlaying, status, err)
+	}
+	if err := player.Raise(); err != nil {
+		t.Fatal(err)
+	}
+	// CanQuit is false, so quitting does nothing
+	if err := player.Quit(); err != nil {
+		t.Errorf("Expected quitting to be ignored, got %v", err)
+	}
+
+	if err := player.SetVolume(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.GetVolume(); err != nil || volume != 0.5 {
+		t.Errorf("Expected the volume to be 0.5, got %f %v", volume, err)
+	}
+
+	if err := player.SeekTo(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.SeekBy(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if position, err := player.GetPositionDuration(); err != nil || position != 15*time.Second {
+		t.Errorf("Expected the position to be 15s, got %s %v", position, err)
+	}
+
+	if title, err := player.GetTitle(); err != nil || title != "A" {
+		t.Errorf("Expected the title to be A, got %q %v", title, err)
+	}
+	if identity, err := player.GetIdentity(); err != nil || identity != "Test Player" {
+		t.Errorf("Unexpected identity %q %v", identity, err)
+	}
+	if hasTrackList, err := player.HasTrackList(); err != nil || hasTrackList {
+		t.Errorf("Expected no track list, got %t %v", hasTrackList, err)
+	}
+	if _, err := player.TrackList.GetTracks(); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected the track list not to be exported, got %v", err)
+	}
+
+	calls := adapter.Calls()
+	if len(calls) != 1 || calls[0] != "Raise" {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+}
+
+func TestTrackList(t *testing.T) {
+	trackList := &testTrackList{}
+	server, player := newTestServer(t, newTestAdapter(), WithTrackList(trackList))
+
+	if hasTrackList, err := player.HasTrackList(); err != nil || !hasTrackList {
+		t.Errorf("Expected a track list, got %t %v", hasTrackList, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := player.TrackList.AddTrack("a", mpris.NoTrack, false); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := trackList.TracksMetadata([]mpris.TrackID{"/org/test/track/a"})
+	if err != nil || len(metadata) != 1 {
+		t.Fatalf("Expected the track to be added, got %v %v", metadata, err)
+	}
+	if err := server.EmitTrackAdded(metadata[0], ""); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		added, ok := event.(mpris.TrackAddedEvent)
+		if !ok || added.Metadata.Title() != "a" || added.After != mpris.NoTrack {
+			t.Errorf("Unexpected event %#v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("TrackAdded not received")
+	}
+
+	tracks, err := player.TrackList.GetTracks()
+	if err != nil || len(tracks) != 1 || tracks[0] != "/org/test/track/a" {
+		t.Errorf("Unexpected tracks %v %v", tracks, err)
+	}
+	fetched, err := player.TrackList.GetTracksMetadata(tracks)
+	if err != nil || len(fetched) != 1 || fetched[0].Title() != "a" {
+		t.Errorf("Unexpected metadata %v %v", fetched, err)
+	}
+	if err := player.TrackList.RemoveTrack(tracks[0]); !errors.Is(err, mpris.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported removing, got %v", err)
+	}
+
+	if err := server.EmitTrackAdded(mpris.Metadata{}, mpris.NoTrack); !errors.Is(err, mpris.ErrInvalidTrackID) {
+		t.Errorf("Expected ErrInvalidTrackID without a track id, got %v", err)
+	}
+	if err := server.EmitPlaylistChanged(mpris.Playlist{ID: "/org/test/playlist"}); !errors.Is(err, ErrNotExported) {
+		t.Errorf("Expected ErrNotExported without playlists, got %v", err)
+	}
+}
+
+func TestPlaylists(t *testing.T) {
+	playlists := &testPlaylists{playlists: []mpris.Playlist{
+		{ID: "/org/test/playlist/a", Name: "A"},
+		{ID: "/org/test/playlist/b", Name: "B"},
+	}}
+	server, player := newTestServer(t, newTestAdapter(), WithPlaylists(playlists))
+
+	count, err := player.Playlists.GetPlaylistCount()
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 playlists, got %d %v", count, err)
+	}
+	got, err := player.Playlists.GetPlaylists(1, 10, mpris.PlaylistUserDefined, false)
+	if err != nil || len(got) != 1 || got[0].Name != "B" {
+		t.Errorf("Unexpected playlists %v %v", got, err)
+	}
+	active, err := player.Playlists.GetActivePlaylist()
+	if err != nil || active.Valid {
+		t.Errorf("Expected no active playlist, got %v %v", active, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := mpris.Playlist{ID: "/org/test/playlist/a", Name: "Renamed"}
+	if err := server.EmitPlaylistChanged(renamed); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		if changed, ok := event.(mpris.PlaylistChangedEvent); !ok || changed.Playlist != renamed {
+			t.Errorf("Unexpected event %#v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("PlaylistChanged not received")
+	}
+
+	if err := server.EmitTrackRemoved("/org/test/track/a"); !errors.Is(err, ErrNotExported) {
+		t.Errorf("Expected ErrNotExported without a track list, got %v", err)
+	}
+}
+
+func TestEmitPropertiesChanged(t *testing.T) {
+	adapter := newTestAdapter()
+	server, player := newTestServer(t, adapter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() mpris.Event {
+		select {
+		case event := <-events:
+			return event
+		case <-ctx.Done():
+			t.Fatal("No event received")
+			return nil
+		}
+	}
+
+	err = server.EmitPropertiesChanged(mpris.PlayerInterface, map[string]interface{}{
+		"PlaybackStatus": mpris.PlaybackPlaying,
+		"Volume":         1,
+		"Metadata":       trackMetadata("/org/test/track/a", "A"),
+		"Position":       time.Second,
+		"CanSeek":        nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, ok := next().(mpris.PropertiesChangedEvent)
+	if !ok {
+		t.Fatal("Expected a PropertiesChangedEvent")
+	}
+	if changed.Changed["PlaybackStatus"].Value() != "Playing" || changed.Changed["Volume"].Value() != 1.0 {
+		t.Errorf("Unexpected changes %v", changed.Changed)
+	}
+	if metadata, _ := changed.Changed["Metadata"].Value().(map[string]dbus.Variant); mpris.Metadata(metadata).Title() != "A" {
+		t.Errorf("Unexpected metadata %v", changed.Changed["Metadata"])
+	}
+	if _, ok := changed.Changed["Position"]; ok {
+		t.Error("Expected the position not to be announced")
+	}
+	// the invalidated property is fetched by Subscribe
+	if changed.Changed["CanSeek"].Value() != true {
+		t.Errorf("Expected CanSeek to be resolved, got %v", changed.Changed["CanSeek"])
+	}
+
+	if err := server.EmitSeeked(2 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if seeked, ok := next().(mpris.SeekedEvent); !ok || seeked.Position != 2*time.Second {
+		t.Errorf("Unexpected seeked event %#v", seeked)
+	}
+
+	if err := player.SetVolume(0.25); err != nil {
+		t.Fatal(err)
+	}
+	if changed, ok := next().(mpris.PropertiesChangedEvent); !ok || changed.Changed["Volume"].Value() != 0.25 {
+		t.Errorf("Expected the volume change to be announced, got %#v", changed)
+	}
+
+	if err := server.EmitPropertiesChanged(mpris.PlayerInterface, map[string]interface{}{"Volume": "loud"}); err == nil {
+		t.Error("Expected an error for a string volume")
+	}
+	if err := server.EmitPropertiesChanged(mpris.PlayerInterface, map[string]interface{}{"Loudness": 1}); err == nil {
+		t.Error("Expected an error for an unknown property")
+	}
+	if err := server.EmitPropertiesChanged(mpris.TrackListInterface, map[string]interface{}{"CanEditTracks": true}); !errors.Is(err, ErrNotExported) {
+		t.Errorf("Expected ErrNotExported, got %v", err)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	server, player := newTestServer(t, newTestAdapter(), WithTrackList(&testTrackList{}), WithPlaylists(&testPlaylists{}))
+
+	var data string
+	obj := mpristest.PrivateConn(t).Object(server.Name(), objectPath)
+	if err := obj.Call(introspectableInterface+".Introspect", 0).Store(&data); err != nil {
+		t.Fatal(err)
+	}
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatal(err)
+	}
+	introspected := make(map[string]introspect.Interface)
+	for _, iface := range node.Interfaces {
+		introspected[iface.Name] = iface
+	}
+
+	for _, iface := range spec.Interfaces() {
+		data, ok := introspected[iface.Name]
+		if !ok {
+			t.Errorf("Expected %s to be introspected", iface.Name)
+			continue
+		}
+		if len(data.Methods) != len(iface.Methods) {
+			t.Errorf("Expected the %d methods of %s, got %d", len(iface.Methods), iface.Name, len(data.Methods))
+		}
+		for _, prop := range iface.Properties {
+			exported, ok := server.props[iface.Name][prop.Name]
+			if !ok {
+				// the optional fullscreen properties aren't exported
+				if prop.Name != "Fullscreen" && prop.Name != "CanSetFullscreen" {
+					t.Errorf("Expected %s.%s to be exported", iface.Name, prop.Name)
+				}
+				continue
+			}
+			if (exported.set != nil) != prop.Writable() {
+				t.Errorf("Expected %s.%s to be %s", iface.Name, prop.Name, prop.Access)
+			}
+			variant, err := player.GetProperty(iface.Name, prop.Name)
+			if err != nil {
+				t.Errorf("Can't get %s.%s: %v", iface.Name, prop.Name, err)
+			} else if variant.Signature().String() != prop.Type {
+				t.Errorf("Expected %s.%s to be a %s, got %s", iface.Name, prop.Name, prop.Type, variant.Signature())
+			}
+		}
+		if len(data.Properties) != len(server.props[iface.Name]) {
+			t.Errorf("Expected the %d exported properties of %s to be introspected, got %d", len(server.props[iface.Name]), iface.Name, len(data.Properties))
+		}
+	}
+}
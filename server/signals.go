@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// propertySignatures are the D-Bus signatures of the properties, as defined by the spec.
+var propertySignatures = map[string]map[string]string{
+	mpris.BaseInterface: {
+		"CanQuit":             "b",
+		"CanRaise":            "b",
+		"HasTrackList":        "b",
+		"Identity":            "s",
+		"DesktopEntry":        "s",
+		"SupportedUriSchemes": "as",
+		"SupportedMimeTypes":  "as",
+	},
+	mpris.PlayerInterface: {
+		"PlaybackStatus": "s",
+		"LoopStatus":     "s",
+		"Rate":           "d",
+		"Shuffle":        "b",
+		"Metadata":       "a{sv}",
+		"Volume":         "d",
+		"Position":       "x",
+		"MinimumRate":    "d",
+		"MaximumRate":    "d",
+		"CanGoNext":      "b",
+		"CanGoPrevious":  "b",
+		"CanPlay":        "b",
+		"CanPause":       "b",
+		"CanSeek":        "b",
+		"CanControl":     "b",
+	},
+	mpris.TrackListInterface: {
+		"Tracks":        "ao",
+		"CanEditTracks": "b",
+	},
+	mpris.PlaylistsInterface: {
+		"PlaylistCount":  "u",
+		"Orderings":      "as",
+		"ActivePlaylist": "(b(oss))",
+	},
+}
+
+// invalidatedProperties are the properties whose changes are announced without their values,
+// as the spec says.
+var invalidatedProperties = map[string]bool{
+	mpris.TrackListInterface + ".Tracks": true,
+}
+
+// EmitPropertiesChanged announces that properties of the interface changed. The values are
+// converted to the types of the spec, so they can be given as go-mpris types, like
+// mpris.PlaybackStatus, mpris.Metadata or time.Duration, or as any number for numeric
+// properties, and checked like the values the server answers with, failing with
+// ErrInvalidValue. A nil value announces the property as changed without its value.
+//
+// Position is left out, since its changes must be announced with EmitSeeked, and Tracks is
+// always announced without its value.
+func (s *Server) EmitPropertiesChanged(iface string, changed map[string]interface{}) error {
+	if _, ok := s.propertiesOf(iface); !ok {
+		return ErrNotExported
+	}
+
+	values := make(map[string]dbus.Variant, len(changed))
+	invalidated := []string{}
+	for name, value := range changed {
+		signature, ok := propertySignatures[iface][name]
+		if !ok {
+			return fmt.Errorf("unknown property %s.%s", iface, name)
+		}
+		if iface == mpris.PlayerInterface && name == "Position" {
+			continue
+		}
+		if value == nil || invalidatedProperties[iface+"."+name] {
+			invalidated = append(invalidated, name)
+			continue
+		}
+
+		variant, err := propertyValue(value, signature)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", iface, name, err)
+		}
+		if err := s.checkProperty(iface, name, variant.Value()); err != nil {
+			return err
+		}
+		values[name] = variant
+	}
+	if len(values) == 0 && len(invalidated) == 0 {
+		return nil
+	}
+	sort.Strings(invalidated)
+	return s.conn.Emit(objectPath, propertiesInterface+".PropertiesChanged", iface, values, invalidated)
+}
+
+// EmitSeeked announces that the position jumped, like after a seek or when the track is
+// restarted.
+func (s *Server) EmitSeeked(position time.Duration) error {
+	return s.conn.Emit(objectPath, mpris.PlayerInterface+".Seeked", durationToMicroseconds(position))
+}
+
+// propertyValue converts the value to a variant of the given signature.
+func propertyValue(value interface{}, signature string) (dbus.Variant, error) {
+	switch v := value.(type) {
+	case dbus.Variant:
+		value = v.Value()
+	case mpris.PlaybackStatus:
+		value = string(v)
+	case mpris.LoopStatus:
+		value = string(v)
+	case mpris.Metadata:
+		value = metadataValue(v)
+	case mpris.TrackID:
+		value = v.ObjectPath()
+	case []mpris.TrackID:
+		value = trackPaths(v)
+	case []mpris.PlaylistOrdering:
+		orderings := make([]string, len(v))
+		for i, ordering := range v {
+			orderings[i] = string(ordering)
+		}
+		value = orderings
+	case time.Duration:
+		value = durationToMicroseconds(v)
+	case nil:
+		return dbus.Variant{}, fmt.Errorf("nil value")
+	}
+
+	if converted, ok := convertNumber(value, signature); ok {
+		value = converted
+	}
+	variant := dbus.MakeVariant(value)
+	if variant.Signature().String() != signature {
+		return dbus.Variant{}, fmt.Errorf("got %s instead of %s", variant.Signature(), signature)
+	}
+	return variant, nil
+}
+
+// convertNumber converts numbers of any Go type to the one of the numeric signatures.
+func convertNumber(value interface{}, signature string) (interface{}, bool) {
+	rv := reflect.ValueOf(value)
+	var f float64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = rv.Float()
+	default:
+		return nil, false
+	}
+
+	switch signature {
+	case "d":
+		return f, true
+	case "x":
+		return rv.Convert(reflect.TypeOf(int64(0))).Interface(), true
+	case "u":
+		if f < 0 {
+			return nil, false
+		}
+		return rv.Convert(reflect.TypeOf(uint32(0))).Interface(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// TrackListAdapter implements the optional org.mpris.MediaPlayer2.TrackList interface, giving
+// access to the play queue. The changes of the track list are announced with EmitTrackAdded,
+// EmitTrackRemoved, EmitTrackListReplaced and EmitTrackMetadataChanged.
+type TrackListAdapter interface {
+	// TracksMetadata returns the metadata of the tracks, leaving out the unknown ones.
+	TracksMetadata(trackIDs []mpris.TrackID) ([]mpris.Metadata, error)
+	AddTrack(uri string, after mpris.TrackID, setAsCurrent bool) error
+	RemoveTrack(trackID mpris.TrackID) error
+	GoTo(trackID mpris.TrackID) error
+
+	Tracks() []mpris.TrackID
+	CanEditTracks() bool
+}
+
+// PlaylistsAdapter implements the optional org.mpris.MediaPlayer2.Playlists interface. The
+// changes of a playlist are announced with EmitPlaylistChanged.
+type PlaylistsAdapter interface {
+	ActivatePlaylist(playlistID dbus.ObjectPath) error
+	Playlists(index, maxCount uint32, order mpris.PlaylistOrdering, reverse bool) ([]mpris.Playlist, error)
+
+	PlaylistCount() uint32
+	Orderings() []mpris.PlaylistOrdering
+	ActivePlaylist() mpris.MaybePlaylist
+}
+
+// WithTrackList exports the TrackList interface, and sets HasTrackList.
+func WithTrackList(adapter TrackListAdapter) Option {
+	return func(s *Server) {
+		s.trackList = adapter
+	}
+}
+
+// WithPlaylists exports the Playlists interface.
+func WithPlaylists(adapter PlaylistsAdapter) Option {
+	return func(s *Server) {
+		s.playlists = adapter
+	}
+}
+
+// EmitTrackAdded announces that the track described by the metadata was added after the track
+// after, or at the start of the track list if after is mpris.NoTrack or empty. The metadata
+// must have a valid "mpris:trackid".
+func (s *Server) EmitTrackAdded(metadata mpris.Metadata, after mpris.TrackID) error {
+	if !s.hasTrackList() {
+		return ErrNotExported
+	}
+	if err := checkTrackID(metadata.TrackID()); err != nil {
+		return err
+	}
+	if err := checkMetadata(metadataValue(metadata)); err != nil {
+		return err
+	}
+	if after == "" {
+		after = mpris.NoTrack
+	}
+	return s.conn.Emit(objectPath, mpris.TrackListInterface+".TrackAdded", metadataValue(metadata), after.ObjectPath())
+}
+
+// EmitTrackRemoved announces that the track was removed from the track list.
+func (s *Server) EmitTrackRemoved(trackID mpris.TrackID) error {
+	if !s.hasTrackList() {
+		return ErrNotExported
+	}
+	if err := checkTrackID(trackID); err != nil {
+		return err
+	}
+	return s.conn.Emit(objectPath, mpris.TrackListInterface+".TrackRemoved", trackID.ObjectPath())
+}
+
+// EmitTrackListReplaced announces that the whole track list changed, current being the
+// current track or mpris.NoTrack.
+func (s *Server) EmitTrackListReplaced(tracks []mpris.TrackID, current mpris.TrackID) error {
+	if !s.hasTrackList() {
+		return ErrNotExported
+	}
+	if current == "" {
+		current = mpris.NoTrack
+	}
+	return s.conn.Emit(objectPath, mpris.TrackListInterface+".TrackListReplaced", trackPaths(tracks), current.ObjectPath())
+}
+
+// EmitTrackMetadataChanged announces that the metadata of a track of the track list changed.
+// The track id of the metadata is the one of the track afterwards, which may differ from
+// trackID.
+func (s *Server) EmitTrackMetadataChanged(trackID mpris.TrackID, metadata mpris.Metadata) error {
+	if !s.hasTrackList() {
+		return ErrNotExported
+	}
+	if err := checkTrackID(trackID); err != nil {
+		return err
+	}
+	if err := checkTrackID(metadata.TrackID()); err != nil {
+		return err
+	}
+	if err := checkMetadata(metadataValue(metadata)); err != nil {
+		return err
+	}
+	return s.conn.Emit(objectPath, mpris.TrackListInterface+".TrackMetadataChanged", trackID.ObjectPath(), metadataValue(metadata))
+}
+
+// EmitPlaylistChanged announces that the name or the icon of the playlist changed.
+func (s *Server) EmitPlaylistChanged(playlist mpris.Playlist) error {
+	if !s.hasPlaylists() {
+		return ErrNotExported
+	}
+	if !playlist.ID.IsValid() {
+		return fmt.Errorf("invalid playlist id %q", playlist.ID)
+	}
+	return s.conn.Emit(objectPath, mpris.PlaylistsInterface+".PlaylistChanged", playlist)
+}
+
+// checkTrackID returns mpris.ErrInvalidTrackID if the track id can't refer to a track. Unlike
+// the clients, the server follows the spec strictly and rejects the ids in the /org/mpris
+// namespace, which is reserved.
+func checkTrackID(trackID mpris.TrackID) error {
+	if !trackID.IsValid() || strings.HasPrefix(string(trackID), "/org/mpris/") {
+		return mpris.ErrInvalidTrackID
+	}
+	return nil
+}
+
+func trackPaths(tracks []mpris.TrackID) []dbus.ObjectPath {
+	paths := make([]dbus.ObjectPath, len(tracks))
+	for i, trackID := range tracks {
+		paths[i] = trackID.ObjectPath()
+	}
+	return paths
+}
+
+// addPlaylistsProperties adds the properties of the Playlists interface that need more than a
+// conversion to the generated getters.
+func addPlaylistsProperties(props map[string]property, a PlaylistsAdapter) {
+	props["Orderings"] = property{get: func() interface{} {
+		orderings := make([]string, 0, len(a.Orderings()))
+		for _, ordering := range a.Orderings() {
+			orderings = append(orderings, string(ordering))
+		}
+		return orderings
+	}}
+	props["ActivePlaylist"] = property{get: func() interface{} {
+		active := a.ActivePlaylist()
+		if !active.Valid {
+			// the playlist must still be a valid struct, with "/" as id
+			active.Playlist = mpris.Playlist{ID: "/"}
+		}
+		return active
+	}}
+}
+
+type trackListExport struct {
+	a TrackListAdapter
+}
+
+func (e *trackListExport) GetTracksMetadata(trackIDs []dbus.ObjectPath) ([]map[string]dbus.Variant, *dbus.Error) {
+	tracks := make([]mpris.TrackID, len(trackIDs))
+	for i, path := range trackIDs {
+		tracks[i] = mpris.TrackID(path)
+	}
+	metadata, err := e.a.TracksMetadata(tracks)
+	if err != nil {
+		return nil, toDBusError(err)
+	}
+	result := make([]map[string]dbus.Variant, len(metadata))
+	for i, track := range metadata {
+		result[i] = metadataValue(track)
+	}
+	return result, nil
+}
+
+func (e *trackListExport) AddTrack(uri string, after dbus.ObjectPath, setAsCurrent bool) *dbus.Error {
+	return toDBusError(e.a.AddTrack(uri, mpris.TrackID(after), setAsCurrent))
+}
+
+func (e *trackListExport) RemoveTrack(trackID dbus.ObjectPath) *dbus.Error {
+	if err := checkTrackID(mpris.TrackID(trackID)); err != nil {
+		return toDBusError(err)
+	}
+	return toDBusError(e.a.RemoveTrack(mpris.TrackID(trackID)))
+}
+
+func (e *trackListExport) GoTo(trackID dbus.ObjectPath) *dbus.Error {
+	if err := checkTrackID(mpris.TrackID(trackID)); err != nil {
+		return toDBusError(err)
+	}
+	return toDBusError(e.a.GoTo(mpris.TrackID(trackID)))
+}
+
+type playlistsExport struct {
+	a PlaylistsAdapter
+}
+
+func (e *playlistsExport) ActivatePlaylist(playlistID dbus.ObjectPath) *dbus.Error {
+	return toDBusError(e.a.ActivatePlaylist(playlistID))
+}
+
+func (e *playlistsExport) GetPlaylists(index, maxCount uint32, order string, reverse bool) ([]mpris.Playlist, *dbus.Error) {
+	playlists, err := e.a.Playlists(index, maxCount, mpris.PlaylistOrdering(order), reverse)
+	if err != nil {
+		return nil, toDBusError(err)
+	}
+	if playlists == nil {
+		playlists = []mpris.Playlist{}
+	}
+	return playlists, nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// ErrInvalidValue is returned when a property value doesn't follow the spec, like a volume
+// above the maximum or an unknown loop status. The server never sends such values: Get fails
+// instead, and GetAll leaves the property out.
+var ErrInvalidValue = errors.New("invalid property value")
+
+// DefaultMaxVolume is the maximum volume accepted when WithMaxVolume is not set.
+const DefaultMaxVolume = 1.0
+
+// WithMaxVolume accepts volumes up to max instead of DefaultMaxVolume, for players that can
+// amplify the sound.
+func WithMaxVolume(max float64) Option {
+	return func(s *Server) {
+		s.maxVolume = max
+	}
+}
+
+func invalidValue(name string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s %s", ErrInvalidValue, name, fmt.Sprintf(format, args...))
+}
+
+// checkProperty checks the value of a property, as sent on the bus.
+func (s *Server) checkProperty(iface, name string, value interface{}) error {
+	switch iface + "." + name {
+	case mpris.PlayerInterface + ".PlaybackStatus":
+		return checkPlaybackStatus(mpris.PlaybackStatus(value.(string)))
+	case mpris.PlayerInterface + ".LoopStatus":
+		return checkLoopStatus(mpris.LoopStatus(value.(string)))
+	case mpris.PlayerInterface + ".Volume":
+		return s.checkVolume(value.(float64))
+	case mpris.PlayerInterface + ".Rate":
+		return s.checkRate(value.(float64))
+	case mpris.PlayerInterface + ".MinimumRate":
+		if rate := value.(float64); math.IsNaN(rate) || rate <= 0 || rate > 1 {
+			return invalidValue("MinimumRate", "%f is not in ]0, 1]", rate)
+		}
+	case mpris.PlayerInterface + ".MaximumRate":
+		if rate := value.(float64); math.IsNaN(rate) || rate < 1 {
+			return invalidValue("MaximumRate", "%f is below 1", rate)
+		}
+	case mpris.PlayerInterface + ".Position":
+		if position := value.(int64); position < 0 {
+			return invalidValue("Position", "%d is negative", position)
+		}
+	case mpris.PlayerInterface + ".Metadata":
+		return checkMetadata(value.(map[string]dbus.Variant))
+	case mpris.TrackListInterface + ".Tracks":
+		for _, trackID := range value.([]dbus.ObjectPath) {
+			if err := checkTrackID(mpris.TrackID(trackID)); err != nil {
+				return invalidValue("Tracks", "has the invalid track id %q", trackID)
+			}
+		}
+	case mpris.PlaylistsInterface + ".ActivePlaylist":
+		if active, ok := value.(mpris.MaybePlaylist); ok && !active.Playlist.ID.IsValid() {
+			return invalidValue("ActivePlaylist", "has the invalid id %q", active.Playlist.ID)
+		}
+	}
+	return nil
+}
+
+func checkPlaybackStatus(status mpris.PlaybackStatus) error {
+	switch status {
+	case mpris.PlaybackPlaying, mpris.PlaybackPaused, mpris.PlaybackStopped:
+		return nil
+	}
+	return invalidValue("PlaybackStatus", "%q is unknown", status)
+}
+
+func checkLoopStatus(status mpris.LoopStatus) error {
+	switch status {
+	case mpris.LoopNone, mpris.LoopTrack, mpris.LoopPlaylist:
+		return nil
+	}
+	return invalidValue("LoopStatus", "%q is unknown", status)
+}
+
+func (s *Server) checkVolume(volume float64) error {
+	max := s.maxVolume
+	if max == 0 {
+		max = DefaultMaxVolume
+	}
+	if math.IsNaN(volume) || volume < 0 || volume > max {
+		return invalidValue("Volume", "%f is not in [0, %f]", volume, max)
+	}
+	return nil
+}
+
+// checkRate checks the rate against the MinimumRate and MaximumRate of the adapter.
+func (s *Server) checkRate(rate float64) error {
+	min, max := s.adapter.MinimumRate(), s.adapter.MaximumRate()
+	if math.IsNaN(rate) || rate < min || rate > max {
+		return invalidValue("Rate", "%f is not in [%f, %f]", rate, min, max)
+	}
+	return nil
+}
+
+// metadataSignatures are the D-Bus signatures of the well known metadata fields.
+var metadataSignatures = map[string]string{
+	"mpris:length":         "x",
+	"xesam:trackNumber":    "i",
+	"xesam:discNumber":     "i",
+	"xesam:useCount":       "i",
+	"xesam:autoRating":     "d",
+	"xesam:userRating":     "d",
+	"xesam:audioBPM":       "i",
+	"xesam:artist":         "as",
+	"xesam:albumArtist":    "as",
+	"xesam:genre":          "as",
+	"xesam:composer":       "as",
+	"xesam:lyricist":       "as",
+	"xesam:comment":        "as",
+	"mpris:artUrl":         "s",
+	"xesam:title":          "s",
+	"xesam:album":          "s",
+	"xesam:url":            "s",
+	"xesam:asText":         "s",
+	"xesam:contentCreated": "s",
+	"xesam:firstUsed":      "s",
+	"xesam:lastUsed":       "s",
+}
+
+// checkMetadata checks the track id and the types of the well known fields.
+func checkMetadata(metadata map[string]dbus.Variant) error {
+	variant, ok := metadata["mpris:trackid"]
+	if !ok {
+		return invalidValue("Metadata", "has no mpris:trackid")
+	}
+	trackID, ok := variant.Value().(dbus.ObjectPath)
+	if !ok {
+		return invalidValue("Metadata", "has a mpris:trackid of type %s instead of o", variant.Signature())
+	}
+	if mpris.TrackID(trackID) != mpris.NoTrack && checkTrackID(mpris.TrackID(trackID)) != nil {
+		return invalidValue("Metadata", "has the invalid mpris:trackid %q", trackID)
+	}
+
+	for key, signature := range metadataSignatures {
+		if value, ok := metadata[key]; ok && value.Signature().String() != signature {
+			return invalidValue("Metadata", "has a %s of type %s instead of %s", key, value.Signature(), signature)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/godbus/dbus/v5"
+)
+
+// rateAdapter is a test player whose rate can go up to 2.
+type rateAdapter struct {
+	*testAdapter
+	rate float64
+}
+
+func (a *rateAdapter) MaximumRate() float64 { return 2 }
+
+func (a *rateAdapter) Rate() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate
+}
+
+func (a *rateAdapter) SetRate(rate float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rate = rate
+	return nil
+}
+
+func TestSetValidation(t *testing.T) {
+	adapter := &rateAdapter{testAdapter: newTestAdapter(), rate: 1}
+	_, player := newTestServer(t, adapter)
+
+	if err := player.SetVolume(-1); err != nil {
+		t.Fatal(err)
+	}
+	if volume := adapter.Volume(); volume != 0 {
+		t.Errorf("Expected a negative volume to mute, got %f", volume)
+	}
+	if err := player.SetVolume(1.5); err == nil {
+		t.Error("Expected an error setting the volume above 1")
+	}
+
+	if err := player.Player.SetRate(3); err == nil {
+		t.Error("Expected an error setting the rate above the maximum")
+	}
+	if err := player.Player.SetRate(1.5); err != nil || adapter.Rate() != 1.5 {
+		t.Errorf("Expected the rate to be 1.5, got %f %v", adapter.Rate(), err)
+	}
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.Player.SetRate(0); err != nil {
+		t.Fatal(err)
+	}
+	if status := adapter.PlaybackStatus(); status != mpris.PlaybackPaused || adapter.Rate() != 1.5 {
+		t.Errorf("Expected a rate of 0 to pause, got %s at %f", status, adapter.Rate())
+	}
+
+	err := player.Player.SetLoopStatus("Sometimes")
+	if err == nil {
+		t.Error("Expected an error setting an unknown loop status")
+	}
+}
+
+func TestAnswerValidation(t *testing.T) {
+	adapter := newTestAdapter()
+	adapter.volume = 1.5
+	adapter.metadata = mpris.Metadata{"mpris:trackid": dbus.MakeVariant("/org/test/track/a")}
+	server, player := newTestServer(t, adapter)
+
+	if _, err := player.GetVolume(); err == nil {
+		t.Error("Expected an error getting a volume above 1")
+	}
+	if _, err := player.GetMetadata(); err == nil {
+		t.Error("Expected an error getting a string track id")
+	}
+	state, err := player.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.PlaybackStatus != mpris.PlaybackStopped || state.Volume != 0 || state.Metadata != nil {
+		t.Errorf("Expected the invalid properties to be left out, got %+v", state)
+	}
+
+	server.Close()
+	_, player = newTestServer(t, adapter, WithMaxVolume(2))
+	if volume, err := player.GetVolume(); err != nil || volume != 1.5 {
+		t.Errorf("Expected the volume to be 1.5, got %f %v", volume, err)
+	}
+}
+
+func TestEmitValidation(t *testing.T) {
+	server, player := newTestServer(t, newTestAdapter(), WithTrackList(&testTrackList{}))
+
+	invalid := []map[string]interface{}{
+		{"Volume": 3},
+		{"LoopStatus": "Sometimes"},
+		{"PlaybackStatus": "Buffering"},
+		{"Metadata": mpris.Metadata{"mpris:trackid": dbus.MakeVariant("/org/test/track/a")}},
+		{"Metadata": mpris.Metadata{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/test/track/a")),
+			"mpris:length":  dbus.MakeVariant(uint64(1000)),
+		}},
+	}
+	for _, changed := range invalid {
+		if err := server.EmitPropertiesChanged(mpris.PlayerInterface, changed); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Expected ErrInvalidValue emitting %v, got %v", changed, err)
+		}
+	}
+	if err := server.EmitPropertiesChanged(mpris.PlayerInterface, map[string]interface{}{"Metadata": mpris.Metadata{}}); err != nil {
+		t.Errorf("Expected empty metadata to be sent as NoTrack, got %v", err)
+	}
+
+	if err := server.EmitTrackAdded(trackMetadata("/org/mpris/track", "A"), mpris.NoTrack); !errors.Is(err, mpris.ErrInvalidTrackID) {
+		t.Errorf("Expected ErrInvalidTrackID in the /org/mpris namespace, got %v", err)
+	}
+	if err := player.TrackList.GoTo(mpris.NoTrack); err == nil {
+		t.Error("Expected an error going to NoTrack")
+	}
+}
@@ -0,0 +1,114 @@
+package mpris
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// SetterQueue coalesces the volume and position changes made faster than the player should
+// get them, like the ones of a slider being dragged or of a scroll wheel. The first change is
+// sent right away, then at most one call per interval is made with the latest value, the
+// values in between being dropped. It's safe for concurrent use.
+type SetterQueue struct {
+	player   *Player
+	interval time.Duration
+
+	mu       sync.Mutex
+	volume   *float64
+	position *time.Duration
+	// next is when the next calls can be made.
+	next   time.Time
+	timer  *time.Timer
+	err    error
+	closed bool
+
+	// sending serializes the calls, so the values are sent in order.
+	sending sync.Mutex
+}
+
+// NewSetterQueue returns a queue making at most one call per interval for each of the volume
+// and the position of the player.
+func NewSetterQueue(player *Player, interval time.Duration) *SetterQueue {
+	return &SetterQueue{player: player, interval: interval}
+}
+
+// SetVolume queues the volume, replacing the one not sent yet.
+func (q *SetterQueue) SetVolume(volume float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.volume = &volume
+	q.schedule()
+}
+
+// SetPosition queues the position in the current track, replacing the one not sent yet. It's
+// sent like SeekTo.
+func (q *SetterQueue) SetPosition(position time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.position = &position
+	q.schedule()
+}
+
+// schedule sends the queued values once the interval since the last calls is over. It must be
+// called with the lock held.
+func (q *SetterQueue) schedule() {
+	if q.closed || q.timer != nil {
+		return
+	}
+	q.timer = time.AfterFunc(max(time.Until(q.next), 0), func() {
+		q.send()
+	})
+}
+
+// send makes the calls for the queued values. The errors are kept for Flush and Close.
+func (q *SetterQueue) send() error {
+	q.sending.Lock()
+	defer q.sending.Unlock()
+
+	q.mu.Lock()
+	volume, position := q.volume, q.position
+	q.volume, q.position = nil, nil
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	q.next = time.Now().Add(q.interval)
+	q.mu.Unlock()
+
+	var errs []error
+	if volume != nil {
+		errs = append(errs, q.player.SetVolume(*volume))
+	}
+	if position != nil {
+		errs = append(errs, q.player.SeekTo(*position))
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		q.player.debug("queued setter failed", "error", err)
+		q.mu.Lock()
+		q.err = errors.Join(q.err, err)
+		q.mu.Unlock()
+	}
+	return err
+}
+
+// Flush sends the queued values right away. It returns the errors of the calls made since the
+// last Flush, including the ones made in the background.
+func (q *SetterQueue) Flush() error {
+	q.send()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	err := q.err
+	q.err = nil
+	return err
+}
+
+// Close sends the queued values, like Flush, and stops the queue. The values queued after
+// Close are dropped.
+func (q *SetterQueue) Close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	return q.Flush()
+}
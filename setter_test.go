@@ -0,0 +1,78 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestSetterQueue(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	if err := fake.SetMetadata(map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"mpris:length":  dbus.MakeVariant(int64(300000000)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	countCalls := func(method string) int {
+		count := 0
+		for _, call := range fake.Calls() {
+			if call.Method == method {
+				count++
+			}
+		}
+		return count
+	}
+
+	queue := NewSetterQueue(player, 100*time.Millisecond)
+	// a slider dragged for a moment
+	for n := 1; n <= 100; n++ {
+		queue.SetVolume(float64(n) / 100)
+		queue.SetPosition(time.Duration(n) * time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	mpristest.Eventually(t, func() bool {
+		volume, err := player.GetVolume()
+		return err == nil && volume == 1 && fake.Position() == 100000000
+	}, "Expected the latest values to be sent")
+	if sets := countCalls("Set"); sets > 4 {
+		t.Errorf("Expected the volume changes to be coalesced, got %d calls", sets)
+	}
+	if seeks := countCalls("SetPosition"); seeks > 4 {
+		t.Errorf("Expected the position changes to be coalesced, got %d calls", seeks)
+	}
+	if err := queue.Flush(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Flush sends right away and reports the errors
+	failed := errors.New("volume is locked")
+	fake.HandleFunc(propertiesInterface, "Set", func(args ...interface{}) *dbus.Error {
+		return dbus.MakeFailedError(failed)
+	})
+	queue.SetVolume(0.5)
+	if err := queue.Flush(); err == nil || err.Error() != failed.Error() {
+		t.Errorf("Expected the error of the Set call, got %v", err)
+	}
+	if err := queue.Flush(); err != nil {
+		t.Errorf("Expected the error to be reported once, got %v", err)
+	}
+
+	fake.HandleFunc(propertiesInterface, "Set", nil)
+	queue.SetVolume(0.25)
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.GetVolume(); err != nil || volume != 0.25 {
+		t.Errorf("Expected Close to send the queued volume, got %v (%v)", volume, err)
+	}
+	calls := len(fake.Calls())
+	queue.SetVolume(0.75)
+	time.Sleep(150 * time.Millisecond)
+	if len(fake.Calls()) != calls {
+		t.Error("Expected the values queued after Close to be dropped")
+	}
+}
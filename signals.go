@@ -0,0 +1,294 @@
+package mpris
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// defaultSignalBuffer is how many signals the subscriptions buffer, unless set with
+// WithSignalBuffer.
+const defaultSignalBuffer = 16
+
+// subscriptions is the registry of the channels registered with OnSignal, shared by the copies
+// of a player made by WithContext.
+type subscriptions struct {
+	mu       sync.Mutex
+	channels map[chan<- *dbus.Signal]*Subscription
+	closed   bool
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{channels: make(map[chan<- *dbus.Signal]*Subscription)}
+}
+
+func (s *subscriptions) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// playerSignals are the signals of the player sent to the subscriptions.
+var playerSignals = map[string]bool{
+	propertiesChangedSignal: true,
+	seekedSignal:            true,
+	playlistChangedSignal:   true,
+
+	trackAddedSignal:           true,
+	trackRemovedSignal:         true,
+	trackListReplacedSignal:    true,
+	trackMetadataChangedSignal: true,
+}
+
+// Subscription is a channel registered with OnSignal. Close can be called more than once and
+// from any goroutine.
+type Subscription struct {
+	player  *Player
+	ch      chan<- *dbus.Signal
+	signals chan *dbus.Signal
+	rules   [][]dbus.MatchOption
+	policy  DropPolicy
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// OnSignal registers ch to receive the PropertiesChanged, Seeked, PlaylistChanged and track list
+// signals sent by the player. Only the signals of the player are sent to ch, using match rules on
+// its name and object path. The NameOwnerChanged signals of the player name are sent too, and the
+// subscription follows the new owner when the player restarts under the same name.
+//
+// The subscription must be closed, with Close or RemoveSignal, to remove the match rules.
+// The channel is never closed by the subscription. The signals are buffered as set with
+// WithSignalBuffer.
+func (i *Player) OnSignal(ch chan<- *dbus.Signal) (*Subscription, error) {
+	return i.onSignal(ch, i.dropPolicy)
+}
+
+// onSignal registers ch like OnSignal, with the drop policy.
+func (i *Player) onSignal(ch chan<- *dbus.Signal, policy DropPolicy) (*Subscription, error) {
+	if i.conn == nil {
+		return nil, ErrNoConnection
+	}
+	if i.subscriptions.isClosed() {
+		return nil, ErrClosed
+	}
+	owner, err := i.getNameOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		player:  i,
+		ch:      ch,
+		signals: make(chan *dbus.Signal, i.signalBuffer),
+		rules:   i.signalRules(),
+		policy:  policy,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := i.addMatchRules(sub.rules); err != nil {
+		return nil, err
+	}
+	i.conn.Signal(sub.signals)
+
+	i.subscriptions.mu.Lock()
+	if i.subscriptions.closed {
+		i.subscriptions.mu.Unlock()
+		i.conn.RemoveSignal(sub.signals)
+		i.removeMatchRules(sub.rules)
+		return nil, ErrClosed
+	}
+	if previous, ok := i.subscriptions.channels[ch]; ok {
+		defer previous.Close()
+	}
+	i.subscriptions.channels[ch] = sub
+	i.subscriptions.mu.Unlock()
+
+	i.debug("subscribed to signals", "owner", owner)
+	go sub.forward(owner)
+	return sub, nil
+}
+
+// RemoveSignal closes the subscription of the channel registered with OnSignal.
+func (i *Player) RemoveSignal(ch chan<- *dbus.Signal) error {
+	i.subscriptions.mu.Lock()
+	sub, ok := i.subscriptions.channels[ch]
+	i.subscriptions.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return sub.Close()
+}
+
+// closeSubscriptions closes every subscription of the player, and prevents new ones.
+func (i *Player) closeSubscriptions() error {
+	i.subscriptions.mu.Lock()
+	i.subscriptions.closed = true
+	subs := make([]*Subscription, 0, len(i.subscriptions.channels))
+	for _, sub := range i.subscriptions.channels {
+		subs = append(subs, sub)
+	}
+	i.subscriptions.mu.Unlock()
+
+	var err error
+	for _, sub := range subs {
+		if closeErr := sub.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// forward sends the signals of the player to the subscription channel. The connection gets
+// every signal matched by any rule, so the ones from other senders are dropped. The owner is
+// updated by the NameOwnerChanged signals, as the player signals come from the unique name.
+//
+// The signals waiting for the receiver are queued. With DropNone, no signal is read from the
+// connection while one is waiting, otherwise the queue is bounded by the buffer size.
+func (s *Subscription) forward(owner string) {
+	defer close(s.done)
+	var pending []*dbus.Signal
+	for {
+		var out chan<- *dbus.Signal
+		var next *dbus.Signal
+		in := s.signals
+		if len(pending) > 0 {
+			out, next = s.ch, pending[0]
+			if s.policy == DropNone {
+				in = nil
+			}
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case out <- next:
+			pending = pending[1:]
+		case sig, ok := <-in:
+			if !ok {
+				s.player.debug("subscription ended by the connection closing")
+				return
+			}
+			if change, ok := parseNameOwnerChanged(sig); ok {
+				if change.Name != s.player.name {
+					continue
+				}
+				s.player.debug("player owner changed", "old_owner", change.OldOwner, "new_owner", change.NewOwner)
+				owner = change.NewOwner
+			} else if sig.Sender != owner || sig.Path != s.player.path {
+				continue
+			} else if !playerSignals[sig.Name] {
+				continue
+			}
+			pending = s.queue(pending, sig)
+		}
+	}
+}
+
+// queue adds the signal to the pending ones, dropping one according to the policy when the
+// buffer is full.
+func (s *Subscription) queue(pending []*dbus.Signal, sig *dbus.Signal) []*dbus.Signal {
+	if s.policy == DropNone || len(pending) < cap(s.signals) {
+		return append(pending, sig)
+	}
+	s.dropped.Add(1)
+	if s.policy == DropNewest {
+		s.player.debug("dropping signal", "signal", sig.Name)
+		return pending
+	}
+	s.player.debug("dropping signal", "signal", pending[0].Name)
+	return append(pending[1:], sig)
+}
+
+// Dropped returns the number of signals dropped because the receiver didn't keep up, as set
+// with WithSignalBuffer.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Done returns a channel that's closed when the subscription ends, either because it was
+// closed or because the connection was closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close unregisters the channel and removes the match rules.
+func (s *Subscription) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.player.conn.RemoveSignal(s.signals)
+		s.closeErr = s.player.removeMatchRules(s.rules)
+		s.player.debug("unsubscribed from signals")
+
+		subs := s.player.subscriptions
+		subs.mu.Lock()
+		if subs.channels[s.ch] == s {
+			delete(subs.channels, s.ch)
+		}
+		subs.mu.Unlock()
+	})
+	return s.closeErr
+}
+
+// signalRules returns the match rules for the signals sent by the player.
+func (i *Player) signalRules() [][]dbus.MatchOption {
+	return [][]dbus.MatchOption{
+		{
+			dbus.WithMatchSender(i.name),
+			dbus.WithMatchObjectPath(i.path),
+			dbus.WithMatchInterface(propertiesInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		},
+		{
+			dbus.WithMatchSender(i.name),
+			dbus.WithMatchObjectPath(i.path),
+			dbus.WithMatchInterface(PlayerInterface),
+			dbus.WithMatchMember("Seeked"),
+		},
+		{
+			dbus.WithMatchSender(i.name),
+			dbus.WithMatchObjectPath(i.path),
+			dbus.WithMatchInterface(PlaylistsInterface),
+			dbus.WithMatchMember("PlaylistChanged"),
+		},
+		{
+			dbus.WithMatchSender(i.name),
+			dbus.WithMatchObjectPath(i.path),
+			dbus.WithMatchInterface(TrackListInterface),
+		},
+		{
+			dbus.WithMatchSender(busName),
+			dbus.WithMatchObjectPath("/org/freedesktop/DBus"),
+			dbus.WithMatchInterface(busName),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchOption("arg0", i.name),
+		},
+	}
+}
+
+// addMatchRules adds the match rules, removing the already added ones if one of them fails.
+func (i *Player) addMatchRules(rules [][]dbus.MatchOption) error {
+	for n, rule := range rules {
+		if err := i.conn.AddMatchSignal(rule...); err != nil {
+			i.removeMatchRules(rules[:n])
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Player) removeMatchRules(rules [][]dbus.MatchOption) error {
+	var err error
+	for _, rule := range rules {
+		if removeErr := i.conn.RemoveMatchSignal(rule...); removeErr != nil && err == nil {
+			err = removeErr
+		}
+	}
+	return err
+}
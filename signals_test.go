@@ -0,0 +1,97 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestOnSignal(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	_, other := newTestPlayer(t)
+
+	ch := make(chan *dbus.Signal, 16)
+	sub, err := player.OnSignal(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := other.EmitSeeked(1000000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EmitSeeked(2000000); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case sig := <-ch:
+		if sig.Name != seekedSignal || sig.Body[0] != int64(2000000) {
+			t.Errorf("Unexpected signal %s %v", sig.Name, sig.Body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the signal")
+	}
+
+	if err := player.RemoveSignal(ch); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the subscription to end")
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("Expected closing twice to succeed, got %v", err)
+	}
+
+	if err := fake.EmitSeeked(3000000); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-ch:
+		t.Errorf("Unexpected signal after removing it %s %v", sig.Name, sig.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestOnSignalNotFound(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	player := New(conn, BaseInterface+".mpristest.missing")
+	if _, err := player.OnSignal(make(chan *dbus.Signal)); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
+
+func TestOnSignalDropPolicy(t *testing.T) {
+	for policy, kept := range map[DropPolicy]string{DropOldest: "Stopped", DropNewest: "Playing"} {
+		player, fake := newTestPlayer(t)
+		player = New(player.conn, fake.Name(), WithSignalBuffer(1, policy))
+
+		ch := make(chan *dbus.Signal)
+		sub, err := player.OnSignal(ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+
+		for _, status := range []string{"Playing", "Paused", "Stopped"} {
+			if err := fake.SetPlaybackStatus(status); err != nil {
+				t.Fatal(err)
+			}
+		}
+		mpristest.Eventually(t, func() bool { return sub.Dropped() == 2 }, "Expected 2 dropped signals")
+
+		select {
+		case sig := <-ch:
+			changed := sig.Body[1].(map[string]dbus.Variant)
+			if status := changed["PlaybackStatus"].Value(); status != kept {
+				t.Errorf("Expected the kept status to be %s, got %v", kept, status)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the signal")
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package spec embeds the introspection XML of the MPRIS interfaces, which the bindings of
+// go-mpris and of its server package are generated from, and which the server sends to the
+// clients introspecting it. The files follow the ones of the MPRIS 2.2 spec, with the spec
+// types like "Time_In_Us" given as tp:type, and a shorter documentation written to end up in
+// the Go doc comments of the bindings.
+package spec
+
+import (
+	"embed"
+	"encoding/xml"
+	"strings"
+
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+//go:embed *.xml
+var files embed.FS
+
+// tpNamespace is the namespace of the Telepathy extensions used by the spec, like tp:type and
+// tp:docstring.
+const tpNamespace = "http://telepathy.freedesktop.org/wiki/DbusSpec#extensions-v0"
+
+// names are the names of the MPRIS interfaces, which are also the names of their files.
+var names = []string{
+	"org.mpris.MediaPlayer2",
+	"org.mpris.MediaPlayer2.Player",
+	"org.mpris.MediaPlayer2.TrackList",
+	"org.mpris.MediaPlayer2.Playlists",
+}
+
+// Arg is an argument of a method or a signal.
+type Arg struct {
+	Name string
+	// Type is the D-Bus signature of the argument, like "x".
+	Type string
+	// TypeName is the spec type of the argument, like "Time_In_Us", if any.
+	TypeName string
+	// Direction is "in" or "out" for the arguments of the methods, and empty for the ones of
+	// the signals.
+	Direction string
+	Doc       string
+}
+
+// Method is a method of an interface.
+type Method struct {
+	Name        string
+	Args        []Arg
+	Doc         string
+	Annotations []introspect.Annotation
+}
+
+// Signal is a signal of an interface.
+type Signal struct {
+	Name        string
+	Args        []Arg
+	Doc         string
+	Annotations []introspect.Annotation
+}
+
+// Property is a property of an interface.
+type Property struct {
+	Name string
+	// Type is the D-Bus signature of the property, like "d".
+	Type string
+	// TypeName is the spec type of the property, like "Playback_Rate", if any.
+	TypeName string
+	// Access is "read" or "readwrite".
+	Access      string
+	Doc         string
+	Annotations []introspect.Annotation
+}
+
+// Writable returns true if the property can be set.
+func (p Property) Writable() bool {
+	return p.Access == "readwrite" || p.Access == "write"
+}
+
+// Interface is an MPRIS interface, as described by its introspection XML.
+type Interface struct {
+	Name        string
+	Doc         string
+	Methods     []Method
+	Signals     []Signal
+	Properties  []Property
+	Annotations []introspect.Annotation
+}
+
+// Property returns the property with the name.
+func (i Interface) Property(name string) (Property, bool) {
+	for _, prop := range i.Properties {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return Property{}, false
+}
+
+// Introspection returns the interface as sent in the answer of
+// org.freedesktop.DBus.Introspectable.Introspect, without the documentation.
+func (i Interface) Introspection() introspect.Interface {
+	iface := introspect.Interface{Name: i.Name, Annotations: i.Annotations}
+	for _, method := range i.Methods {
+		iface.Methods = append(iface.Methods, introspect.Method{
+			Name:        method.Name,
+			Args:        introspectArgs(method.Args),
+			Annotations: method.Annotations,
+		})
+	}
+	for _, signal := range i.Signals {
+		iface.Signals = append(iface.Signals, introspect.Signal{
+			Name:        signal.Name,
+			Args:        introspectArgs(signal.Args),
+			Annotations: signal.Annotations,
+		})
+	}
+	for _, prop := range i.Properties {
+		iface.Properties = append(iface.Properties, introspect.Property{
+			Name:        prop.Name,
+			Type:        prop.Type,
+			Access:      prop.Access,
+			Annotations: prop.Annotations,
+		})
+	}
+	return iface
+}
+
+func introspectArgs(args []Arg) []introspect.Arg {
+	var result []introspect.Arg
+	for _, arg := range args {
+		result = append(result, introspect.Arg{Name: arg.Name, Type: arg.Type, Direction: arg.Direction})
+	}
+	return result
+}
+
+// Interfaces returns the MPRIS interfaces: org.mpris.MediaPlayer2, then the Player, TrackList
+// and Playlists interfaces.
+func Interfaces() []Interface {
+	ifaces := make([]Interface, len(names))
+	for n, name := range names {
+		ifaces[n] = mustParse(name)
+	}
+	return ifaces
+}
+
+// Lookup returns the MPRIS interface with the name, like "org.mpris.MediaPlayer2.Player".
+func Lookup(name string) (Interface, bool) {
+	for _, known := range names {
+		if known == name {
+			return mustParse(name), true
+		}
+	}
+	return Interface{}, false
+}
+
+// XML returns the introspection XML of the MPRIS interface with the name, as embedded.
+func XML(name string) ([]byte, bool) {
+	data, err := files.ReadFile(name + ".xml")
+	return data, err == nil
+}
+
+// The XML is decoded through these types rather than the ones of the introspect package, as
+// the tp:type attributes would be mistaken for the type ones.
+
+type xmlNode struct {
+	Interfaces []xmlInterface `xml:"interface"`
+}
+
+type xmlInterface struct {
+	Name        string                  `xml:"name,attr"`
+	Doc         string                  `xml:"http://telepathy.freedesktop.org/wiki/DbusSpec#extensions-v0 docstring"`
+	Methods     []xmlMember             `xml:"method"`
+	Signals     []xmlMember             `xml:"signal"`
+	Properties  []xmlMember             `xml:"property"`
+	Annotations []introspect.Annotation `xml:"annotation"`
+}
+
+// xmlMember is a method, a signal, a property or an argument.
+type xmlMember struct {
+	Attrs       []xml.Attr              `xml:",any,attr"`
+	Args        []xmlMember             `xml:"arg"`
+	Doc         string                  `xml:"http://telepathy.freedesktop.org/wiki/DbusSpec#extensions-v0 docstring"`
+	Annotations []introspect.Annotation `xml:"annotation"`
+}
+
+// attr returns the attribute with the name, without namespace unless space is given.
+func (m xmlMember) attr(space, local string) string {
+	for _, attr := range m.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (m xmlMember) args() []Arg {
+	var args []Arg
+	for _, arg := range m.Args {
+		args = append(args, Arg{
+			Name:      arg.attr("", "name"),
+			Type:      arg.attr("", "type"),
+			TypeName:  arg.attr(tpNamespace, "type"),
+			Direction: arg.attr("", "direction"),
+			Doc:       doc(arg.Doc),
+		})
+	}
+	return args
+}
+
+// doc joins the lines of a docstring.
+func doc(docstring string) string {
+	return strings.Join(strings.Fields(docstring), " ")
+}
+
+// mustParse parses the embedded file of the interface, which is known to be valid.
+func mustParse(name string) Interface {
+	data, err := files.ReadFile(name + ".xml")
+	if err != nil {
+		panic(err)
+	}
+	var node xmlNode
+	if err := xml.Unmarshal(data, &node); err != nil {
+		panic("spec: invalid " + name + ".xml: " + err.Error())
+	}
+	if len(node.Interfaces) != 1 || node.Interfaces[0].Name != name {
+		panic("spec: " + name + ".xml doesn't describe the " + name + " interface")
+	}
+
+	decoded := node.Interfaces[0]
+	iface := Interface{Name: name, Doc: doc(decoded.Doc), Annotations: decoded.Annotations}
+	for _, method := range decoded.Methods {
+		iface.Methods = append(iface.Methods, Method{
+			Name:        method.attr("", "name"),
+			Args:        method.args(),
+			Doc:         doc(method.Doc),
+			Annotations: method.Annotations,
+		})
+	}
+	for _, signal := range decoded.Signals {
+		iface.Signals = append(iface.Signals, Signal{
+			Name:        signal.attr("", "name"),
+			Args:        signal.args(),
+			Doc:         doc(signal.Doc),
+			Annotations: signal.Annotations,
+		})
+	}
+	for _, prop := range decoded.Properties {
+		iface.Properties = append(iface.Properties, Property{
+			Name:        prop.attr("", "name"),
+			Type:        prop.attr("", "type"),
+			TypeName:    prop.attr(tpNamespace, "type"),
+			Access:      prop.attr("", "access"),
+			Doc:         doc(prop.Doc),
+			Annotations: prop.Annotations,
+		})
+	}
+	return iface
+}
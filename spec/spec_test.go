@@ -0,0 +1,87 @@
+package spec
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+func TestInterfaces(t *testing.T) {
+	ifaces := Interfaces()
+	if len(ifaces) != 4 || ifaces[0].Name != "org.mpris.MediaPlayer2" || ifaces[1].Name != "org.mpris.MediaPlayer2.Player" {
+		t.Fatalf("Expected the 4 MPRIS interfaces, got %v", ifaces)
+	}
+	for _, iface := range ifaces {
+		if iface.Doc == "" {
+			t.Errorf("Expected %s to be documented", iface.Name)
+		}
+		for _, method := range iface.Methods {
+			if method.Doc == "" {
+				t.Errorf("Expected %s.%s to be documented", iface.Name, method.Name)
+			}
+		}
+		for _, prop := range iface.Properties {
+			if prop.Doc == "" || prop.Type == "" || prop.Access == "" {
+				t.Errorf("Expected %s.%s to be complete, got %+v", iface.Name, prop.Name, prop)
+			}
+		}
+	}
+
+	player, ok := Lookup("org.mpris.MediaPlayer2.Player")
+	if !ok {
+		t.Fatal("Expected the Player interface to be found")
+	}
+	// the tp:type attributes come before or after the type ones
+	for name, want := range map[string][2]string{
+		"PlaybackStatus": {"s", "Playback_Status"},
+		"LoopStatus":     {"s", "Loop_Status"},
+		"Volume":         {"d", "Volume"},
+		"CanSeek":        {"b", ""},
+	} {
+		prop, ok := player.Property(name)
+		if !ok || prop.Type != want[0] || prop.TypeName != want[1] {
+			t.Errorf("Expected %s to be a %s of type %q, got %+v", name, want[0], want[1], prop)
+		}
+	}
+	if prop, _ := player.Property("Volume"); !prop.Writable() {
+		t.Error("Expected Volume to be writable")
+	}
+	if prop, _ := player.Property("Position"); prop.Writable() {
+		t.Error("Expected Position to be read only")
+	}
+	seek := player.Methods[6]
+	if seek.Name != "Seek" || len(seek.Args) != 1 || seek.Args[0].Type != "x" || seek.Args[0].TypeName != "Time_In_Us" || seek.Args[0].Direction != "in" {
+		t.Errorf("Unexpected Seek method %+v", seek)
+	}
+
+	if _, ok := Lookup("org.mpris.MediaPlayer2.Unknown"); ok {
+		t.Error("Expected an unknown interface not to be found")
+	}
+	if _, ok := XML("org.mpris.MediaPlayer2.TrackList"); !ok {
+		t.Error("Expected the XML of the TrackList interface")
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	iface, _ := Lookup("org.mpris.MediaPlayer2.TrackList")
+	data, err := xml.Marshal(introspect.Node{Interfaces: []introspect.Interface{iface.Introspection()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var node introspect.Node
+	if err := xml.Unmarshal(data, &node); err != nil {
+		t.Fatal(err)
+	}
+	introspected := node.Interfaces[0]
+	if len(introspected.Methods) != 4 || len(introspected.Signals) != 4 || len(introspected.Properties) != 2 {
+		t.Fatalf("Expected the members of the interface, got %+v", introspected)
+	}
+	if tracks := introspected.Properties[0]; tracks.Name != "Tracks" || tracks.Type != "ao" || tracks.Access != "read" {
+		t.Errorf("Unexpected Tracks property %+v", tracks)
+	}
+	if added := introspected.Signals[1]; added.Name != "TrackAdded" || len(added.Args) != 2 || added.Args[1].Type != "o" {
+		t.Errorf("Unexpected TrackAdded signal %+v", added)
+	}
+}
@@ -0,0 +1,116 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const startServiceByNameMethod = "org.freedesktop.DBus.StartServiceByName"
+
+// WithActivation makes WaitForPlayer start the player with StartPlayer when it's not running,
+// instead of waiting for something else to start it. The pattern given to WaitForPlayer must
+// then be a player name, like "vlc".
+func WithActivation() WaitOption {
+	return func(c *waitConfig) {
+		c.activate = true
+	}
+}
+
+// StartPlayer returns the player with the name, like "vlc" or org.mpris.MediaPlayer2.vlc,
+// starting it if it's not running. The player is started through D-Bus activation or, for the
+// players without a D-Bus service file, by running the Exec line of its desktop file, like
+// vlc.desktop, found in the XDG data directories. StartPlayer then waits for the player to be
+// on the bus, which may take some time, so ctx should have a deadline. When the player has
+// several instances, the most active one is returned, as FindActiveInstance does.
+//
+// ErrPlayerNotFound is returned when there's no way to start the player.
+func StartPlayer(ctx context.Context, conn *dbus.Conn, name string, opts ...Option) (*Player, error) {
+	short := strings.TrimPrefix(name, BaseInterface+".")
+	name = BaseInterface + "." + short
+
+	// watch before starting, so the player isn't missed if it's quick to start
+	watcher, err := NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Close()
+
+	running := watcher.Players()
+	for _, player := range running {
+		if player == name {
+			return New(conn, name, opts...), nil
+		}
+	}
+	if player, err := findActive(conn, instancesOf(running, short), opts); err == nil {
+		return player, nil
+	}
+
+	var reply uint32
+	err = conn.BusObject().CallWithContext(ctx, startServiceByNameMethod, 0, name, uint32(0)).Store(&reply)
+	if err == nil {
+		return New(conn, name, opts...), nil
+	}
+	if !errors.Is(mapError(err), ErrPlayerNotFound) {
+		return nil, err
+	}
+
+	if err := launchDesktopEntry(short); err != nil {
+		if errors.Is(err, ErrNoDesktopFile) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-watcher.Events():
+			if !ok {
+				return nil, ErrPlayerNotFound
+			}
+			if player, _ := SplitInstance(event.Name); event.Appeared() && player == short {
+				return New(conn, event.Name, opts...), nil
+			}
+		}
+	}
+}
+
+// instancesOf returns the names of the instances of the player, a name returned by
+// SplitInstance.
+func instancesOf(names []string, player string) []string {
+	var instances []string
+	for _, name := range names {
+		if instance, _ := SplitInstance(name); instance == player {
+			instances = append(instances, name)
+		}
+	}
+	return instances
+}
+
+// launchDesktopEntry runs the Exec line of the desktop file of the entry, without waiting for
+// the program to exit.
+func launchDesktopEntry(entry string) error {
+	path, err := FindDesktopFile(entry)
+	if err != nil {
+		return err
+	}
+	keys, err := readDesktopEntry(path)
+	if err != nil {
+		return err
+	}
+	args, err := parseExec(keys["Exec"])
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// the player keeps running after us, the process is only reaped if it exits before
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,89 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+)
+
+// TestHelperPlayer isn't a real test: it's the player launched from a desktop file by
+// TestStartPlayer, which runs until it's asked to quit.
+func TestHelperPlayer(t *testing.T) {
+	name := os.Getenv("MPRIS_HELPER_PLAYER")
+	if name == "" {
+		return
+	}
+	fake, err := mpristest.New(mpristest.PrivateConn(t), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		for _, call := range fake.Calls() {
+			if call.Method == "Quit" {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartPlayer(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", home)
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "none"))
+
+	// the running players are returned
+	started, err := StartPlayer(ctx, player.conn, fake.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.name != fake.Name() {
+		t.Errorf("Expected the running player %s, got %s", fake.Name(), started.name)
+	}
+
+	missing := fmt.Sprintf("missing%d", os.Getpid())
+	if _, err := StartPlayer(ctx, player.conn, missing); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+
+	// without a D-Bus service, the player is launched from its desktop file
+	name := fmt.Sprintf("starttest%d", os.Getpid())
+	writeDesktopFile(t, home, name+".desktop", fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Start test
+Exec=env MPRIS_HELPER_PLAYER=%s "%s" -test.run=^TestHelperPlayer$ %%U
+`, name, os.Args[0]))
+
+	started, err = StartPlayer(ctx, player.conn, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer started.Quit()
+	if started.name != BaseInterface+"."+name {
+		t.Errorf("Expected the launched player, got %s", started.name)
+	}
+	if err := started.WithContext(ctx).Ping(); err != nil {
+		t.Errorf("Expected the launched player to answer, got %v", err)
+	}
+
+	// WaitForPlayer only starts the player with WithActivation
+	short, cancelShort := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelShort()
+	if _, err := WaitForPlayer(short, player.conn, missing); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected WaitForPlayer to wait, got %v", err)
+	}
+	if _, err := WaitForPlayer(ctx, player.conn, missing, WithActivation()); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package mpris
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// PlayerState is a snapshot of the player interface properties.
+// Properties that the player doesn't expose are left with their zero value.
+type PlayerState struct {
+	PlaybackStatus PlaybackStatus
+	LoopStatus     LoopStatus
+	Shuffle        bool
+	Volume         float64
+	Rate           float64
+	Position       time.Duration
+	Metadata       Metadata
+	// PositionAt is when the position was read, to extrapolate it while playing.
+	PositionAt time.Time
+}
+
+// positionJumpTolerance is how far the position can be from where it was expected before Diff
+// reports it jumped, as the players are not precise in their rate and position.
+const positionJumpTolerance = time.Second
+
+// GetState returns the playback status, loop status, shuffle, volume, rate, position and
+// metadata of the player in a single round trip.
+func (i *Player) GetState() (PlayerState, error) {
+	props, err := i.getAllProperties(PlayerInterface)
+	if err != nil {
+		return PlayerState{}, err
+	}
+	return newPlayerState(props), nil
+}
+
+func newPlayerState(props map[string]dbus.Variant) PlayerState {
+	var state PlayerState
+	state.apply(props)
+	return state
+}
+
+// apply updates the state with the properties, leaving the missing ones unchanged.
+func (s *PlayerState) apply(props map[string]dbus.Variant) {
+	if value, ok := props["PlaybackStatus"].Value().(string); ok {
+		s.PlaybackStatus = PlaybackStatus(value)
+	}
+	if value, ok := props["LoopStatus"].Value().(string); ok {
+		s.LoopStatus = LoopStatus(value)
+	}
+	if value, ok := props["Shuffle"].Value().(bool); ok {
+		s.Shuffle = value
+	}
+	if value, ok := toFloat64(props["Volume"].Value()); ok {
+		s.Volume = value
+	}
+	if value, ok := toFloat64(props["Rate"].Value()); ok {
+		s.Rate = value
+	}
+	if value, ok := toInt64(props["Position"].Value()); ok {
+		s.Position = microsecondsToDuration(value)
+		s.PositionAt = time.Now()
+	}
+	if value, ok := props["Metadata"].Value().(map[string]dbus.Variant); ok {
+		s.Metadata = Metadata(value)
+	}
+}
+
+// StateChange is the difference between two states of a player, as returned by Diff.
+type StateChange struct {
+	StatusChanged  bool
+	LoopChanged    bool
+	ShuffleChanged bool
+	RateChanged    bool
+	// VolumeDelta is how much the volume changed, negative when it was lowered.
+	VolumeDelta float64
+	// TrackChanged reports another track, told apart like OnTrackChange does, while
+	// MetadataChanged reports any change of the metadata, like the art of the same track being
+	// loaded.
+	TrackChanged    bool
+	MetadataChanged bool
+	// PositionJump is how far the position is from where the playback would have brought it,
+	// negative when it went back, and Jumped reports whether it's more than a second, like
+	// after a seek. The playback between the two states is the time between their PositionAt,
+	// times the rate if the first one is playing.
+	PositionJump time.Duration
+	Jumped       bool
+}
+
+// IsZero reports whether the states are the same.
+func (c StateChange) IsZero() bool {
+	return c == StateChange{}
+}
+
+// Diff returns the changes from the state to the other one, like the previous and the new
+// states given to the StateStore callbacks, to build state machines on top of the snapshots.
+func (s PlayerState) Diff(other PlayerState) StateChange {
+	jump := other.Position - s.expectedPosition(other.PositionAt)
+	return StateChange{
+		StatusChanged:   s.PlaybackStatus != other.PlaybackStatus,
+		LoopChanged:     s.LoopStatus != other.LoopStatus,
+		ShuffleChanged:  s.Shuffle != other.Shuffle,
+		RateChanged:     s.Rate != other.Rate,
+		VolumeDelta:     other.Volume - s.Volume,
+		TrackChanged:    s.Metadata.TrackKey() != other.Metadata.TrackKey(),
+		MetadataChanged: !reflect.DeepEqual(s.Metadata, other.Metadata),
+		PositionJump:    jump,
+		Jumped:          jump > positionJumpTolerance || jump < -positionJumpTolerance,
+	}
+}
+
+// expectedPosition extrapolates the position at the time, or returns it as it is if the time
+// is unknown or the player isn't playing.
+func (s PlayerState) expectedPosition(at time.Time) time.Duration {
+	if s.PositionAt.IsZero() || at.Before(s.PositionAt) {
+		return s.Position
+	}
+	rate := s.Rate
+	if rate == 0 {
+		rate = 1
+	}
+	clock := positionClock{
+		playing:  s.PlaybackStatus == PlaybackPlaying,
+		rate:     rate,
+		position: s.Position,
+		since:    s.PositionAt,
+	}
+	return clock.current(at)
+}
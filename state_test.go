@@ -0,0 +1,133 @@
+package mpris
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestNewPlayerState(t *testing.T) {
+	props := map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant("Playing"),
+		"LoopStatus":     dbus.MakeVariant("Track"),
+		"Shuffle":        dbus.MakeVariant(true),
+		"Volume":         dbus.MakeVariant(0.5),
+		"Rate":           dbus.MakeVariant(1.0),
+		"Position":       dbus.MakeVariant(int64(90000000)),
+		"Metadata": dbus.MakeVariant(map[string]dbus.Variant{
+			"xesam:title": dbus.MakeVariant("Song"),
+		}),
+	}
+
+	state := newPlayerState(props)
+
+	if state.PlaybackStatus != PlaybackPlaying {
+		t.Errorf("Expected playback status %s, got %s", PlaybackPlaying, state.PlaybackStatus)
+	}
+	if state.LoopStatus != LoopTrack {
+		t.Errorf("Expected loop status %s, got %s", LoopTrack, state.LoopStatus)
+	}
+	if !state.Shuffle {
+		t.Error("Expected shuffle to be enabled")
+	}
+	if state.Volume != 0.5 {
+		t.Errorf("Expected volume 0.5, got %f", state.Volume)
+	}
+	if state.Rate != 1.0 {
+		t.Errorf("Expected rate 1.0, got %f", state.Rate)
+	}
+	if state.Position != 90*time.Second {
+		t.Errorf("Expected position 1m30s, got %s", state.Position)
+	}
+	if state.Metadata["xesam:title"].Value() != "Song" {
+		t.Errorf("Expected title Song, got %v", state.Metadata["xesam:title"].Value())
+	}
+}
+
+func TestNewPlayerStateMissingProperties(t *testing.T) {
+	state := newPlayerState(map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant("Stopped"),
+	})
+
+	if state.PlaybackStatus != PlaybackStopped {
+		t.Errorf("Expected playback status %s, got %s", PlaybackStopped, state.PlaybackStatus)
+	}
+	if state.LoopStatus != "" || state.Shuffle || state.Metadata != nil {
+		t.Errorf("Expected missing properties to be zero, got %+v", state)
+	}
+}
+
+func TestPlayerStateDiff(t *testing.T) {
+	track := func(id string) Metadata {
+		return Metadata{"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(id))}
+	}
+	old := PlayerState{
+		PlaybackStatus: PlaybackPlaying,
+		Volume:         0.5,
+		Position:       time.Minute,
+		Metadata:       track("/track/1"),
+	}
+
+	if change := old.Diff(old); !change.IsZero() {
+		t.Errorf("Expected no change from the same state, got %+v", change)
+	}
+
+	updated := old
+	updated.PlaybackStatus = PlaybackPaused
+	updated.Volume = 0.25
+	updated.Position = 30 * time.Second
+	updated.Metadata = track("/track/1")
+	updated.Metadata["mpris:artUrl"] = dbus.MakeVariant("file:///art.png")
+	change := old.Diff(updated)
+	expected := StateChange{
+		StatusChanged:   true,
+		VolumeDelta:     -0.25,
+		MetadataChanged: true,
+		PositionJump:    -30 * time.Second,
+		Jumped:          true,
+	}
+	if change != expected {
+		t.Errorf("Expected %+v, got %+v", expected, change)
+	}
+
+	updated.Metadata = track("/track/2")
+	if change := old.Diff(updated); !change.TrackChanged || !change.MetadataChanged {
+		t.Errorf("Expected the track to change, got %+v", change)
+	}
+}
+
+func TestPlayerStateDiffPlayback(t *testing.T) {
+	read := time.Now()
+	old := PlayerState{
+		PlaybackStatus: PlaybackPlaying,
+		Rate:           2,
+		Position:       time.Minute,
+		PositionAt:     read,
+	}
+
+	played := old
+	played.Position = time.Minute + 20*time.Second
+	played.PositionAt = read.Add(10 * time.Second)
+	if change := old.Diff(played); change.PositionJump != 0 || change.Jumped {
+		t.Errorf("Expected the playback not to be a jump, got %+v", change)
+	}
+
+	seeked := played
+	seeked.Position = 2 * time.Minute
+	if change := old.Diff(seeked); change.PositionJump != 40*time.Second || !change.Jumped {
+		t.Errorf("Expected a jump of 40s, got %+v", change)
+	}
+
+	old.PlaybackStatus = PlaybackPaused
+	if change := old.Diff(played); change.PositionJump != 20*time.Second || !change.Jumped {
+		t.Errorf("Expected the paused position to jump by 20s, got %+v", change)
+	}
+
+	drifted := played
+	drifted.Position += 500 * time.Millisecond
+	old.PlaybackStatus = PlaybackPlaying
+	if change := old.Diff(drifted); change.Jumped {
+		t.Errorf("Expected a drift under the tolerance not to be a jump, got %+v", change)
+	}
+}
@@ -0,0 +1,124 @@
+package mpris
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsSamples is how many of the latest latencies of each method are kept for the
+// percentiles.
+const statsSamples = 256
+
+// MethodStats are the statistics of the calls of a method, or of the reads or writes of a
+// property. The percentiles are computed from the latest calls only.
+type MethodStats struct {
+	Calls  uint64
+	Errors uint64
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// WithStats makes the player count its D-Bus calls and record their latencies, which are
+// returned by Stats. It helps finding which polling loop is hammering the bus.
+func WithStats() Option {
+	return func(p *Player) {
+		p.stats = newCallStats()
+	}
+}
+
+// callStats collects the statistics of the calls, shared by the copies made by WithContext.
+type callStats struct {
+	mu      sync.Mutex
+	methods map[string]*methodSamples
+}
+
+type methodSamples struct {
+	calls     uint64
+	errors    uint64
+	max       time.Duration
+	latencies []time.Duration
+	// next is where the next latency goes once latencies is full.
+	next int
+}
+
+func newCallStats() *callStats {
+	return &callStats{methods: make(map[string]*methodSamples)}
+}
+
+// statsKey returns the key of the call in the statistics: the method, followed by the
+// property for the property calls, like "org.freedesktop.DBus.Properties.Get
+// org.mpris.MediaPlayer2.Player.Position".
+func statsKey(call CallInfo) string {
+	key := call.Interface + "." + call.Member
+	if call.Property != "" {
+		key += " " + call.Property
+	}
+	return key
+}
+
+func (s *callStats) record(call CallInfo, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := statsKey(call)
+	samples, ok := s.methods[key]
+	if !ok {
+		samples = &methodSamples{}
+		s.methods[key] = samples
+	}
+
+	samples.calls++
+	if err != nil {
+		samples.errors++
+	}
+	if latency > samples.max {
+		samples.max = latency
+	}
+	if len(samples.latencies) < statsSamples {
+		samples.latencies = append(samples.latencies, latency)
+	} else {
+		samples.latencies[samples.next] = latency
+		samples.next = (samples.next + 1) % statsSamples
+	}
+}
+
+// Stats returns the statistics of the D-Bus calls made to the player, by method, as described
+// by MethodStats. The property calls are counted by property, like
+// "org.freedesktop.DBus.Properties.Get org.mpris.MediaPlayer2.Player.Position". It returns nil
+// unless the player was created with WithStats.
+func (i *Player) Stats() map[string]MethodStats {
+	if i.stats == nil {
+		return nil
+	}
+	i.stats.mu.Lock()
+	defer i.stats.mu.Unlock()
+
+	stats := make(map[string]MethodStats, len(i.stats.methods))
+	for key, samples := range i.stats.methods {
+		latencies := append([]time.Duration(nil), samples.latencies...)
+		sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+		stats[key] = MethodStats{
+			Calls:  samples.calls,
+			Errors: samples.errors,
+			P50:    percentile(latencies, 50),
+			P90:    percentile(latencies, 90),
+			P99:    percentile(latencies, 99),
+			Max:    samples.max,
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
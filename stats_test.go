@@ -0,0 +1,69 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestStats(t *testing.T) {
+	testPlayer, fake := newTestPlayer(t)
+	fake.HandleFunc(PlayerInterface, "Next", func(args ...interface{}) *dbus.Error {
+		return dbus.MakeFailedError(errors.New("no next track"))
+	})
+
+	if stats := testPlayer.Stats(); stats != nil {
+		t.Errorf("Expected no stats without WithStats, got %v", stats)
+	}
+
+	player := New(testPlayer.conn, testPlayer.name, WithStats())
+	for n := 0; n < 3; n++ {
+		if _, err := player.GetPosition(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := player.Next(); err == nil {
+		t.Fatal("Expected Next to fail")
+	}
+
+	stats := player.WithContext(context.Background()).Stats()
+	position := stats[propertiesInterface+".Get "+PlayerInterface+".Position"]
+	if position.Calls != 3 || position.Errors != 0 {
+		t.Errorf("Expected 3 successful position reads, got %+v", position)
+	}
+	if position.P50 <= 0 || position.P50 > position.P99 || position.P99 > position.Max {
+		t.Errorf("Expected ordered latencies, got %+v", position)
+	}
+	if next := stats[PlayerInterface+".Next"]; next.Calls != 1 || next.Errors != 1 {
+		t.Errorf("Expected a failed Next, got %+v", next)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for n := range latencies {
+		latencies[n] = time.Duration(n+1) * time.Millisecond
+	}
+	cases := []struct {
+		p        int
+		expected time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := percentile(latencies, c.p); got != c.expected {
+			t.Errorf("Expected the p%d to be %v, got %v", c.p, c.expected, got)
+		}
+	}
+	if got := percentile(latencies[:1], 99); got != time.Millisecond {
+		t.Errorf("Expected the only latency, got %v", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0 without latencies, got %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+// Package statusbar formats the state of a MPRIS player for status bars, as waybar JSON or
+// polybar lines, and follows its changes.
+package statusbar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+)
+
+// Format is the output format of a status bar.
+type Format string
+
+const (
+	// Waybar outputs a JSON object per line, like {"text": ..., "class": "playing", "tooltip": ...},
+	// for waybar custom modules with "return-type": "json".
+	Waybar Format = "waybar"
+	// Polybar outputs the text alone, for polybar custom/script modules with tail = true.
+	Polybar Format = "polybar"
+)
+
+// DefaultScrollInterval is the delay between two steps of scrolling when
+// Options.ScrollInterval is not set.
+const DefaultScrollInterval = 500 * time.Millisecond
+
+// Options configures the output. The zero value outputs the whole text, without scrolling.
+type Options struct {
+	// MaxLength is the maximum length of the text in characters. Longer texts are truncated
+	// with an ellipsis, or scrolled if Scroll is set. There's no limit when it's 0.
+	MaxLength int
+	// Scroll makes the texts longer than MaxLength scroll, like a marquee, instead of being
+	// truncated.
+	Scroll bool
+	// ScrollInterval is the delay between two steps of scrolling. Defaults to
+	// DefaultScrollInterval.
+	ScrollInterval time.Duration
+	// ScrollSeparator is put between the end of the text and its start when scrolling.
+	// Defaults to " | ".
+	ScrollSeparator string
+}
+
+func (o Options) scrollInterval() time.Duration {
+	if o.ScrollInterval <= 0 {
+		return DefaultScrollInterval
+	}
+	return o.ScrollInterval
+}
+
+func (o Options) scrollSeparator() string {
+	if o.ScrollSeparator == "" {
+		return " | "
+	}
+	return o.ScrollSeparator
+}
+
+// Text returns the text describing the track, like "Artist - Title".
+func Text(state mpris.PlayerState) string {
+	title := state.Metadata.Title()
+	if title == "" {
+		title = state.Metadata.URL()
+	}
+	if artists := state.Metadata.Artists(); len(artists) > 0 {
+		return strings.Join(artists, ", ") + " - " + title
+	}
+	return title
+}
+
+// Tooltip returns a longer description of the track, with the album and the player status.
+func Tooltip(name string, state mpris.PlayerState) string {
+	lines := []string{Text(state)}
+	if album := state.Metadata.Album(); album != "" {
+		lines = append(lines, album)
+	}
+	player, _ := mpris.SplitInstance(name)
+	lines = append(lines, fmt.Sprintf("%s (%s)", player, state.PlaybackStatus))
+	return strings.Join(lines, "\n")
+}
+
+// Truncate shortens the text to at most length characters, ending it with an ellipsis when
+// it's cut.
+func Truncate(text string, length int) string {
+	runes := []rune(text)
+	if length <= 0 || len(runes) <= length {
+		return text
+	}
+	if length == 1 {
+		return "…"
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// Scroll returns the window of length characters of the text scrolled by offset characters,
+// wrapping around with the separator. Texts that fit are returned as they are.
+func Scroll(text string, length, offset int, separator string) string {
+	runes := []rune(text)
+	if length <= 0 || len(runes) <= length {
+		return text
+	}
+	loop := append(runes, []rune(separator)...)
+	start := offset % len(loop)
+	if start < 0 {
+		start += len(loop)
+	}
+	window := make([]rune, length)
+	for i := range window {
+		window[i] = loop[(start+i)%len(loop)]
+	}
+	return string(window)
+}
+
+type waybarOutput struct {
+	Text    string `json:"text"`
+	Alt     string `json:"alt"`
+	Class   string `json:"class"`
+	Tooltip string `json:"tooltip"`
+}
+
+// Line returns the output line for the text, which is already truncated or scrolled, without
+// the trailing new line.
+func Line(format Format, name, text string, state mpris.PlayerState) (string, error) {
+	switch format {
+	case Waybar:
+		player, _ := mpris.SplitInstance(name)
+		encoded, err := json.Marshal(waybarOutput{
+			Text:    text,
+			Alt:     player,
+			Class:   strings.ToLower(string(state.PlaybackStatus)),
+			Tooltip: Tooltip(name, state),
+		})
+		return string(encoded), err
+	case Polybar:
+		// polybar formatting tags start with %{, the percents of the text must be escaped
+		return strings.ReplaceAll(text, "%", "%%"), nil
+	}
+	return "", fmt.Errorf("unknown status bar format %q", format)
+}
+
+// Run writes a line to w with the state of the player, and another one every time it changes,
+// until ctx is done, returning its error, or the player quits, returning nil after writing an
+// empty line. When scrolling, a line is also written at every step.
+func Run(ctx context.Context, player *mpris.Player, w io.Writer, format Format, opts Options) error {
+	if _, err := Line(format, "", "", mpris.PlayerState{}); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	player = player.WithContext(ctx)
+	state, err := player.GetState()
+	if err != nil {
+		return err
+	}
+
+	var ticks <-chan time.Time
+	if opts.Scroll && opts.MaxLength > 0 {
+		ticker := time.NewTicker(opts.scrollInterval())
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	offset := 0
+	text := Text(state)
+	last := ""
+	for {
+		shown := Truncate(text, opts.MaxLength)
+		if opts.Scroll {
+			shown = Scroll(text, opts.MaxLength, offset, opts.scrollSeparator())
+		}
+		line, err := Line(format, player.GetName(), shown, state)
+		if err != nil {
+			return err
+		}
+		if line != last {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+			last = line
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticks:
+			offset++
+		case event, ok := <-events:
+			if !ok {
+				return ctx.Err()
+			}
+			if changed, ok := event.(mpris.OwnerChangedEvent); ok && changed.NewOwner == "" {
+				// the player quit, the status bar hides the module on an empty line
+				_, err := fmt.Fprintln(w)
+				return err
+			}
+			if changed, ok := event.(mpris.PropertiesChangedEvent); !ok || changed.Interface != mpris.PlayerInterface {
+				continue
+			}
+			if state, err = player.GetState(); err != nil {
+				return err
+			}
+			if newText := Text(state); newText != text {
+				text = newText
+				offset = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package statusbar
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris"
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(mpristest.RunWithBus(m))
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text     string
+		length   int
+		expected string
+	}{
+		{"Title", 0, "Title"},
+		{"Title", 5, "Title"},
+		{"Title", 4, "Tit…"},
+		{"Title", 1, "…"},
+		{"Éléphant", 4, "Élé…"},
+	}
+	for _, test := range tests {
+		if truncated := Truncate(test.text, test.length); truncated != test.expected {
+			t.Errorf("Expected %q truncated to %d to be %q, got %q", test.text, test.length, test.expected, truncated)
+		}
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "abcd"},
+		{1, "bcde"},
+		{4, "ef a"},
+		{7, "abcd"},
+		{-1, " abc"},
+	}
+	for _, test := range tests {
+		if scrolled := Scroll("abcdef", 4, test.offset, " "); scrolled != test.expected {
+			t.Errorf("Expected offset %d to be %q, got %q", test.offset, test.expected, scrolled)
+		}
+	}
+	if scrolled := Scroll("abc", 4, 2, " "); scrolled != "abc" {
+		t.Errorf("Expected a short text not to scroll, got %q", scrolled)
+	}
+}
+
+func TestLine(t *testing.T) {
+	state := mpris.PlayerState{
+		PlaybackStatus: mpris.PlaybackPlaying,
+		Metadata: mpris.Metadata{
+			"xesam:title":  dbus.MakeVariant("100% Title"),
+			"xesam:artist": dbus.MakeVariant([]string{"Artist"}),
+			"xesam:album":  dbus.MakeVariant("Album"),
+		},
+	}
+	name := mpris.BaseInterface + ".vlc.instance42"
+
+	line, err := Line(Waybar, name, Text(state), state)
+	expected := `{"text":"Artist - 100% Title","alt":"vlc","class":"playing","tooltip":"Artist - 100% Title\nAlbum\nvlc (Playing)"}`
+	if err != nil || line != expected {
+		t.Errorf("Unexpected waybar line %s %v", line, err)
+	}
+	if line, err := Line(Polybar, name, Text(state), state); err != nil || line != "Artist - 100%% Title" {
+		t.Errorf("Unexpected polybar line %s %v", line, err)
+	}
+	if _, err := Line("i3bar", name, "", state); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRun(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	fake, err := mpristest.New(conn, fmt.Sprintf("mpristest.statusbar%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+	fake.SetMetadata(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("First")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reader, writer := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, mpris.New(conn, fake.Name()), writer, Polybar, Options{MaxLength: 10})
+	}()
+
+	lines := bufio.NewScanner(reader)
+	if !lines.Scan() || lines.Text() != "First" {
+		t.Fatalf("Expected the first title, got %q", lines.Text())
+	}
+	fake.SetMetadata(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("A much longer title")})
+	if !lines.Scan() || lines.Text() != "A much lo…" {
+		t.Fatalf("Expected the truncated title, got %q", lines.Text())
+	}
+
+	cancel()
+	go io.Copy(io.Discard, reader)
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+}
+
+func TestRunPlayerQuits(t *testing.T) {
+	conn := mpristest.PrivateConn(t)
+	fake, err := mpristest.New(conn, fmt.Sprintf("mpristest.statusbarquit%d", os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.SetMetadata(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("First")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reader, writer := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, mpris.New(conn, fake.Name()), writer, Polybar, Options{})
+	}()
+
+	lines := bufio.NewScanner(reader)
+	if !lines.Scan() || lines.Text() != "First" {
+		t.Fatalf("Expected the first title, got %q", lines.Text())
+	}
+	fake.Close()
+	if !lines.Scan() || lines.Text() != "" {
+		t.Fatalf("Expected an empty line once the player quit, got %q", lines.Text())
+	}
+	go io.Copy(io.Discard, reader)
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error once the player quit, got %v", err)
+	}
+}
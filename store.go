@@ -0,0 +1,137 @@
+package mpris
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StateStore keeps the latest state of a player, updated by its events, for user interfaces
+// that show the state and redraw when it changes. It's safe for concurrent use.
+//
+// The position is only updated when it jumps, like after a seek, as the players don't announce
+// its changes while playing.
+type StateStore struct {
+	player *Player
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.RWMutex
+	state     PlayerState
+	callbacks []storeCallback
+	nextID    int
+}
+
+type storeCallback struct {
+	id int
+	fn func(old, new PlayerState)
+}
+
+// NewStateStore fetches the state of the player and keeps it up to date until ctx is done or
+// the store is closed.
+func NewStateStore(ctx context.Context, player *Player) (*StateStore, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	player = player.WithContext(ctx)
+	state, err := player.GetState()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &StateStore{
+		player: player,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		state:  state,
+	}
+	go s.update(events)
+	return s, nil
+}
+
+// State returns the latest state.
+func (s *StateStore) State() PlayerState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// OnChange registers the callback, called with the previous and the new state after every
+// change, in the order they were registered, and returns the function unregistering it. The
+// callbacks are called one at a time from the goroutine of the store, so a slow callback
+// delays the updates, and user interface toolkits may require moving the work to their main
+// thread.
+func (s *StateStore) OnChange(callback func(old, new PlayerState)) (remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.callbacks = append(s.callbacks, storeCallback{id, callback})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, registered := range s.callbacks {
+			if registered.id == id {
+				s.callbacks = append(s.callbacks[:i:i], s.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Close stops updating the state. The callbacks are never called once it returns.
+func (s *StateStore) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+func (s *StateStore) update(events <-chan Event) {
+	defer close(s.done)
+	for event := range events {
+		s.mu.RLock()
+		state := s.state
+		s.mu.RUnlock()
+
+		switch event := event.(type) {
+		case PropertiesChangedEvent:
+			if event.Interface != PlayerInterface {
+				continue
+			}
+			state.apply(event.Changed)
+		case SeekedEvent:
+			state.Position = event.Position
+			state.PositionAt = time.Now()
+		case OwnerChangedEvent:
+			if event.NewOwner == "" {
+				continue
+			}
+			// the player restarted, nothing is left of the previous state
+			restarted, err := s.player.GetState()
+			if err != nil {
+				continue
+			}
+			state = restarted
+		default:
+			continue
+		}
+		s.set(state)
+	}
+}
+
+// set replaces the state and calls the callbacks.
+func (s *StateStore) set(state PlayerState) {
+	s.mu.Lock()
+	old := s.state
+	s.state = state
+	callbacks := s.callbacks
+	s.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback.fn(old, state)
+	}
+}
@@ -0,0 +1,70 @@
+package mpris
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestStateStore(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	store, err := NewStateStore(context.Background(), player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if store.State().PlaybackStatus != PlaybackStopped {
+		t.Errorf("Expected the initial state to be stopped, got %s", store.State().PlaybackStatus)
+	}
+
+	var mu sync.Mutex
+	var changes [][2]PlayerState
+	remove := store.OnChange(func(old, new PlayerState) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, [2]PlayerState{old, new})
+	})
+
+	if err := fake.SetPlaybackStatus(string(PlaybackPlaying)); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return store.State().PlaybackStatus == PlaybackPlaying
+	}, "The state wasn't updated")
+	if err := fake.EmitSeeked(2000000); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(changes) == 2
+	}, "The callback wasn't called for every change")
+
+	mu.Lock()
+	if len(changes) != 2 || changes[0][0].PlaybackStatus != PlaybackStopped || changes[0][1].PlaybackStatus != PlaybackPlaying {
+		t.Errorf("Unexpected changes %v", changes)
+	}
+	// the other properties are kept
+	if changes[1][1].Position != 2*time.Second || changes[1][1].PlaybackStatus != PlaybackPlaying {
+		t.Errorf("Expected the seek to keep the other properties, got %v", changes[1][1])
+	}
+	mu.Unlock()
+
+	remove()
+	if err := fake.SetMetadata(map[string]dbus.Variant{"xesam:title": dbus.MakeVariant("Title")}); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		return store.State().Metadata.Title() == "Title"
+	}, "The metadata wasn't updated")
+	mu.Lock()
+	if len(changes) != 2 {
+		t.Errorf("Expected the removed callback not to be called, got %d changes", len(changes))
+	}
+	mu.Unlock()
+}
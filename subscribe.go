@@ -0,0 +1,245 @@
+package mpris
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// SubscribeOption configures the events sent by Subscribe and Events.
+type SubscribeOption func(*subscribeConfig)
+
+type subscribeConfig struct {
+	debounce     time.Duration
+	coalesce     bool
+	initialState bool
+}
+
+func newSubscribeConfig(opts []SubscribeOption) subscribeConfig {
+	var config subscribeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithDebounce waits for the player to stop announcing property changes for the duration
+// before sending them, merged in a single event per interface. Players like Spotify announce
+// a track change with several signals in a row, this turns them into one event.
+func WithDebounce(duration time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.debounce = duration
+	}
+}
+
+// WithCoalesce leaves out the properties announced with the same value they already had, and
+// the events left with no change at all.
+func WithCoalesce() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.coalesce = true
+	}
+}
+
+// WithInitialState sends the current values of the player properties, like the playback status,
+// the metadata and the volume, as the first event: a PropertiesChangedEvent of the
+// org.mpris.MediaPlayer2.Player interface with all its properties. The user interfaces can then
+// be updated from the events only, without reading the state first.
+func WithInitialState() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.initialState = true
+	}
+}
+
+// ShapeEvents sends the events on the returned channel, shaped by opts like the ones of
+// Subscribe, until events is closed or ctx is done. It lets the other implementations of
+// PlayerAPI, like the fake player of the mprisfake package, accept the same options. With
+// WithInitialState, state is called once and returns the values of the properties of the
+// org.mpris.MediaPlayer2.Player interface, sent first, and its error is returned.
+func ShapeEvents(ctx context.Context, events <-chan Event, state func() (map[string]dbus.Variant, error), opts ...SubscribeOption) (<-chan Event, error) {
+	config := newSubscribeConfig(opts)
+	var initial Event
+	if config.initialState {
+		values, err := state()
+		if err != nil {
+			return nil, err
+		}
+		initial = PropertiesChangedEvent{Interface: PlayerInterface, Changed: values}
+	}
+
+	shaped := make(chan Event)
+	shaper := newEventShaper(config)
+	go func() {
+		defer close(shaped)
+		defer shaper.stop()
+
+		send := func(toSend []Event) bool {
+			for _, event := range toSend {
+				select {
+				case shaped <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		// the initial state is recorded for WithCoalesce, so the same values aren't sent again,
+		// but it's not delayed by WithDebounce
+		if initial != nil && !send(shaper.coalesce(initial)) {
+			return
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-shaper.timeout():
+				if !send(shaper.flush()) {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					send(shaper.flush())
+					return
+				}
+				if !send(shaper.add(event)) {
+					return
+				}
+			}
+		}
+	}()
+	return shaped, nil
+}
+
+// eventShaper applies the debounce and coalesce options to the events of a subscription.
+type eventShaper struct {
+	config subscribeConfig
+
+	// pending are the merged property changes waiting for the debounce, in the order their
+	// interfaces were first announced.
+	pending []*PropertiesChangedEvent
+	timer   *time.Timer
+	// values are the last sent property values, by interface, for the coalescing.
+	values map[string]map[string]dbus.Variant
+}
+
+func newEventShaper(config subscribeConfig) *eventShaper {
+	return &eventShaper{config: config, values: make(map[string]map[string]dbus.Variant)}
+}
+
+// timeout returns the channel receiving when the pending changes must be sent, or nil if none
+// are waiting.
+func (s *eventShaper) timeout() <-chan time.Time {
+	if s.timer == nil || len(s.pending) == 0 {
+		return nil
+	}
+	return s.timer.C
+}
+
+// add returns the events to send after receiving the event.
+func (s *eventShaper) add(event Event) []Event {
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || s.config.debounce <= 0 {
+		// events other than property changes flush the pending changes, to keep the order
+		return append(s.flush(), s.coalesce(event)...)
+	}
+
+	s.merge(changed)
+	if s.timer == nil {
+		s.timer = time.NewTimer(s.config.debounce)
+	} else {
+		if !s.timer.Stop() {
+			select {
+			case <-s.timer.C:
+			default:
+			}
+		}
+		s.timer.Reset(s.config.debounce)
+	}
+	return nil
+}
+
+func (s *eventShaper) merge(changed PropertiesChangedEvent) {
+	var pending *PropertiesChangedEvent
+	for _, event := range s.pending {
+		if event.Interface == changed.Interface {
+			pending = event
+		}
+	}
+	if pending == nil {
+		pending = &PropertiesChangedEvent{Interface: changed.Interface, Changed: make(map[string]dbus.Variant)}
+		s.pending = append(s.pending, pending)
+	}
+
+	for name, value := range changed.Changed {
+		pending.Changed[name] = value
+		pending.Invalidated = removeString(pending.Invalidated, name)
+	}
+	for _, name := range changed.Invalidated {
+		delete(pending.Changed, name)
+		pending.Invalidated = append(removeString(pending.Invalidated, name), name)
+	}
+}
+
+func removeString(values []string, value string) []string {
+	for i, candidate := range values {
+		if candidate == value {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// flush returns the pending changes.
+func (s *eventShaper) flush() []Event {
+	var events []Event
+	for _, pending := range s.pending {
+		events = append(events, s.coalesce(*pending)...)
+	}
+	s.pending = nil
+	return events
+}
+
+// coalesce removes the properties that didn't change from the event, returning no event when
+// nothing is left.
+func (s *eventShaper) coalesce(event Event) []Event {
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || !s.config.coalesce {
+		return []Event{event}
+	}
+
+	values := s.values[changed.Interface]
+	if values == nil {
+		values = make(map[string]dbus.Variant)
+		s.values[changed.Interface] = values
+	}
+	filtered := PropertiesChangedEvent{
+		Interface:   changed.Interface,
+		Changed:     make(map[string]dbus.Variant),
+		Invalidated: changed.Invalidated,
+	}
+	for name, value := range changed.Changed {
+		if previous, ok := values[name]; ok && reflect.DeepEqual(previous.Value(), value.Value()) {
+			continue
+		}
+		values[name] = value
+		filtered.Changed[name] = value
+	}
+	for _, name := range changed.Invalidated {
+		delete(values, name)
+	}
+
+	if len(filtered.Changed) == 0 && len(filtered.Invalidated) == 0 {
+		return nil
+	}
+	return []Event{filtered}
+}
+
+// stop releases the timer.
+func (s *eventShaper) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
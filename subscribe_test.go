@@ -0,0 +1,135 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func statusEvent(status string) PropertiesChangedEvent {
+	return PropertiesChangedEvent{
+		Interface: PlayerInterface,
+		Changed:   map[string]dbus.Variant{"PlaybackStatus": dbus.MakeVariant(status)},
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	shaper := newEventShaper(subscribeConfig{coalesce: true})
+
+	if events := shaper.add(statusEvent("Playing")); len(events) != 1 {
+		t.Errorf("Expected the first status to be sent, got %v", events)
+	}
+	if events := shaper.add(statusEvent("Playing")); len(events) != 0 {
+		t.Errorf("Expected the same status to be left out, got %v", events)
+	}
+	if events := shaper.add(statusEvent("Paused")); len(events) != 1 {
+		t.Errorf("Expected the new status to be sent, got %v", events)
+	}
+	if events := shaper.add(SeekedEvent{time.Second}); len(events) != 1 {
+		t.Errorf("Expected the seek to be sent, got %v", events)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	shaper := newEventShaper(subscribeConfig{debounce: time.Hour, coalesce: true})
+	defer shaper.stop()
+
+	for _, event := range []Event{
+		statusEvent("Playing"),
+		PropertiesChangedEvent{
+			Interface: PlayerInterface,
+			Changed:   map[string]dbus.Variant{"Volume": dbus.MakeVariant(0.5)},
+		},
+		statusEvent("Paused"),
+	} {
+		if events := shaper.add(event); len(events) != 0 {
+			t.Fatalf("Expected the changes to wait, got %v", events)
+		}
+	}
+	if shaper.timeout() == nil {
+		t.Fatal("Expected a pending timeout")
+	}
+
+	// a seek flushes the pending changes first
+	events := shaper.add(SeekedEvent{time.Second})
+	if len(events) != 2 {
+		t.Fatalf("Expected the merged changes and the seek, got %v", events)
+	}
+	changed := events[0].(PropertiesChangedEvent)
+	if len(changed.Changed) != 2 || changed.Changed["PlaybackStatus"].Value() != "Paused" {
+		t.Errorf("Unexpected merged changes %v", changed)
+	}
+	if _, ok := events[1].(SeekedEvent); !ok {
+		t.Errorf("Expected the seek last, got %v", events[1])
+	}
+}
+
+func TestSubscribeDebounce(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := player.Subscribe(ctx, WithDebounce(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range []string{"Playing", "Paused", "Playing"} {
+		if err := fake.SetPlaybackStatus(status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	event := receiveEvent(t, events)
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || changed.Changed["PlaybackStatus"].Value() != "Playing" {
+		t.Errorf("Unexpected event %#v", event)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected a single event, got %#v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSubscribeInitialState(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := player.Subscribe(ctx, WithInitialState(), WithCoalesce())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := receiveEvent(t, events)
+	changed, ok := event.(PropertiesChangedEvent)
+	if !ok || changed.Interface != PlayerInterface {
+		t.Fatalf("Unexpected event %#v", event)
+	}
+	if status := changed.Changed["PlaybackStatus"].Value(); status != "Playing" {
+		t.Errorf("Expected the status to be Playing, got %v", status)
+	}
+	for _, name := range []string{"Metadata", "Volume"} {
+		if _, ok := changed.Changed[name]; !ok {
+			t.Errorf("Expected %s in the initial state", name)
+		}
+	}
+
+	// the status is known already, so only the new one is sent
+	for _, status := range []string{"Playing", "Paused"} {
+		if err := fake.SetPlaybackStatus(status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	event = receiveEvent(t, events)
+	changed, ok = event.(PropertiesChangedEvent)
+	if !ok || changed.Changed["PlaybackStatus"].Value() != "Paused" {
+		t.Errorf("Unexpected event %#v", event)
+	}
+}
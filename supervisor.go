@@ -0,0 +1,231 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The delays between the attempts to connect again, doubled after each failure.
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 5 * time.Second
+)
+
+// ReconnectedEvent is sent by the subscriptions of a Supervisor once they're subscribed again
+// after the connection to the bus was lost. The events sent in the meantime are lost, so the
+// state of the player should be read again.
+type ReconnectedEvent struct{}
+
+func (ReconnectedEvent) isEvent() {}
+
+// Supervisor keeps a connection to a bus, connecting again when it's lost, like when the
+// session bus restarts. Without it, the players and subscriptions of a lost connection
+// silently stop working. It's safe for concurrent use.
+type Supervisor struct {
+	opts []Option
+	dial func() (*dbus.Conn, error)
+
+	mu   sync.Mutex
+	conn *dbus.Conn
+	// reconnected is closed when the connection is replaced, and then replaced too.
+	reconnected chan struct{}
+	closed      bool
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSupervisor connects to the bus, which is either SessionBus, SystemBus or a D-Bus address
+// as for ConnectBus, and keeps the connection up. The players are created with opts.
+func NewSupervisor(bus string, opts ...Option) (*Supervisor, error) {
+	return newSupervisor(func() (*dbus.Conn, error) { return ConnectBus(bus) }, opts)
+}
+
+func newSupervisor(dial func() (*dbus.Conn, error), opts []Option) (*Supervisor, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	s := &Supervisor{
+		opts:        opts,
+		dial:        dial,
+		conn:        conn,
+		reconnected: make(chan struct{}),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	go s.run(conn)
+	return s, nil
+}
+
+// run connects again each time the connection is lost, until the supervisor is closed.
+func (s *Supervisor) run(conn *dbus.Conn) {
+	defer close(s.stopped)
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-conn.Context().Done():
+		}
+
+		conn = s.redial()
+		if conn == nil {
+			return
+		}
+		s.mu.Lock()
+		s.conn = conn
+		close(s.reconnected)
+		s.reconnected = make(chan struct{})
+		s.mu.Unlock()
+	}
+}
+
+// redial connects to the bus, waiting more and more between the attempts. It returns nil if
+// the supervisor is closed in the meantime.
+func (s *Supervisor) redial() *dbus.Conn {
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-s.done:
+			return nil
+		case <-time.After(delay):
+		}
+		if conn, err := s.dial(); err == nil {
+			return conn
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// Conn returns the current connection to the bus.
+func (s *Supervisor) Conn() *dbus.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn
+}
+
+// Reconnected returns a channel closed once the supervisor connects again after the current
+// connection is lost, or when it's closed, like:
+//
+//	for {
+//		reconnected := supervisor.Reconnected()
+//		manager, err := mpris.NewManager(supervisor.Conn())
+//		// ...
+//		<-reconnected
+//		manager.Close()
+//	}
+func (s *Supervisor) Reconnected() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reconnected
+}
+
+// Player returns the player with the name on the current connection. The player stops working
+// when the connection is lost, so it should be got again once Reconnected is closed.
+func (s *Supervisor) Player(name string) *Player {
+	return New(s.Conn(), name, s.opts...)
+}
+
+// Subscribe returns a channel receiving the events of the player with the name, like
+// Player.Subscribe, that survives the connection losses: once connected again, it waits for the
+// player to be back on the bus, subscribes again and sends a ReconnectedEvent. The channel is
+// closed when ctx is done or the supervisor is closed.
+func (s *Supervisor) Subscribe(ctx context.Context, name string, opts ...SubscribeOption) (<-chan Event, error) {
+	conn := s.Conn()
+	events, err := New(conn, name, s.opts...).Subscribe(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for {
+			for event := range events {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// the subscription ended with the context, the supervisor or the connection
+			conn, events = s.resubscribe(ctx, conn, name, opts)
+			if events == nil {
+				return
+			}
+			select {
+			case out <- ReconnectedEvent{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// resubscribe subscribes to the player again on the connection replacing the lost one. It
+// returns a nil channel when ctx is done, the supervisor closed or the subscription fails.
+func (s *Supervisor) resubscribe(ctx context.Context, lost *dbus.Conn, name string, opts []SubscribeOption) (*dbus.Conn, <-chan Event) {
+	for ctx.Err() == nil {
+		conn, err := s.nextConn(ctx, lost)
+		if err != nil {
+			return nil, nil
+		}
+		player, err := WaitForPlayer(ctx, conn, name, WithWaitPlayerOptions(s.opts...))
+		if err == nil {
+			var events <-chan Event
+			if events, err = player.Subscribe(ctx, opts...); err == nil {
+				return conn, events
+			}
+		}
+		switch {
+		case conn.Context().Err() != nil:
+			// the connection is lost again
+			lost = conn
+		case !errors.Is(err, ErrPlayerNotFound):
+			return nil, nil
+		}
+		// otherwise the player quit in the meantime, and is waited for again
+	}
+	return nil, nil
+}
+
+// nextConn returns the connection replacing the lost one, waiting for it if needed.
+func (s *Supervisor) nextConn(ctx context.Context, lost *dbus.Conn) (*dbus.Conn, error) {
+	for {
+		s.mu.Lock()
+		conn, reconnected, closed := s.conn, s.reconnected, s.closed
+		s.mu.Unlock()
+		if closed {
+			return nil, ErrClosed
+		}
+		if conn != lost {
+			return conn, nil
+		}
+		select {
+		case <-reconnected:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close stops the supervisor and closes its connection, which ends its subscriptions.
+func (s *Supervisor) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		<-s.stopped
+		s.mu.Lock()
+		s.closed = true
+		close(s.reconnected)
+		conn := s.conn
+		s.mu.Unlock()
+		err = conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,148 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+// receiveSupervisedEvent returns the next event that's not a PropertiesChangedEvent, waiting
+// longer than receiveEvent as the supervisor may have to connect again first.
+func receiveSupervisedEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("Expected an event, the channel is closed")
+			}
+			if _, ok := event.(PropertiesChangedEvent); !ok {
+				return event
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+}
+
+func TestSupervisor(t *testing.T) {
+	_, fake := newTestPlayer(t)
+
+	var dials, failures atomic.Int32
+	supervisor, err := newSupervisor(func() (*dbus.Conn, error) {
+		// the first attempts to connect again fail, as when the bus is restarting
+		if dials.Add(1) > 1 && failures.Add(1) <= 2 {
+			return nil, errors.New("bus is restarting")
+		}
+		return ConnectBus(SessionBus)
+	}, nil)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer supervisor.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := supervisor.Subscribe(ctx, fake.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.EmitSeeked(1000000); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := receiveSupervisedEvent(t, events).(SeekedEvent); !ok || event.Position != time.Second {
+		t.Errorf("Expected a SeekedEvent at 1s, got %#v", event)
+	}
+
+	// the connection is lost
+	lost := supervisor.Conn()
+	reconnected := supervisor.Reconnected()
+	lost.Close()
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for the supervisor to connect again")
+	}
+	if dials.Load() != 4 {
+		t.Errorf("Expected 2 failed attempts before connecting again, got %d dials", dials.Load())
+	}
+	if supervisor.Conn() == lost {
+		t.Fatal("Expected a new connection")
+	}
+	if status, err := supervisor.Player(fake.Name()).GetPlaybackStatus(); err != nil || status != PlaybackStopped {
+		t.Errorf("Expected the player to be reachable again, got %v (%v)", status, err)
+	}
+
+	// the subscription is back, and says so
+	if event := receiveSupervisedEvent(t, events); event != (ReconnectedEvent{}) {
+		t.Errorf("Expected a ReconnectedEvent, got %#v", event)
+	}
+	if err := fake.EmitSeeked(2000000); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := receiveSupervisedEvent(t, events).(SeekedEvent); !ok || event.Position != 2*time.Second {
+		t.Errorf("Expected a SeekedEvent at 2s, got %#v", event)
+	}
+
+	// closing the supervisor ends the subscriptions
+	if err := supervisor.Close(); err != nil {
+		t.Error(err)
+	}
+	for range events {
+	}
+	select {
+	case <-supervisor.Reconnected():
+	default:
+		t.Error("Expected Reconnected to be closed with the supervisor")
+	}
+}
+
+func TestSupervisorWaitsForPlayer(t *testing.T) {
+	supervisor, err := NewSupervisor(SessionBus)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer supervisor.Close()
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := supervisor.Subscribe(ctx, fake.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the player is gone when the connection comes back, and is waited for
+	name := fake.Name()
+	fake.Close()
+	supervisor.Conn().Close()
+	<-supervisor.Reconnected()
+
+	if event, ok := receiveSupervisedEvent(t, events).(OwnerChangedEvent); !ok || event.NewOwner != "" {
+		t.Errorf("Expected the player to quit, got %#v", event)
+	}
+
+	conn := mpristest.PrivateConn(t)
+	if _, err := conn.RequestName(name, dbus.NameFlagDoNotQueue); err != nil {
+		t.Fatal(err)
+	}
+	if event := receiveSupervisedEvent(t, events); event != (ReconnectedEvent{}) {
+		t.Errorf("Expected a ReconnectedEvent once the player is back, got %#v", event)
+	}
+	if err := conn.Emit(player.path, PlayerInterface+".Seeked", int64(3000000)); err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := receiveSupervisedEvent(t, events).(SeekedEvent); !ok || event.Position != 3*time.Second {
+		t.Errorf("Expected a SeekedEvent at 3s, got %#v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
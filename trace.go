@@ -0,0 +1,78 @@
+package mpris
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// CallInfo describes a D-Bus call made to a player. For the property calls, Interface and
+// Member are the ones of the Properties interface, like Get, and Property is the accessed
+// property, like org.mpris.MediaPlayer2.Player.Volume, or the interface for GetAll.
+type CallInfo struct {
+	BusName   string
+	Interface string
+	Member    string
+	Property  string
+}
+
+// CallTracer is called before every D-Bus call made to a player, with the context of the call.
+// The returned context is used for the call, and the returned function is called when the call
+// ends, with its latency and error.
+//
+// It's meant to wrap the calls in tracing spans, like with OpenTelemetry:
+//
+//	tracer := otel.Tracer("go-mpris")
+//	traceCall := func(ctx context.Context, call mpris.CallInfo) (context.Context, func(time.Duration, error)) {
+//		ctx, span := tracer.Start(ctx, call.Interface+"."+call.Member, trace.WithAttributes(
+//			attribute.String("dbus.bus_name", call.BusName),
+//			attribute.String("dbus.interface", call.Interface),
+//			attribute.String("dbus.member", call.Member),
+//			attribute.String("mpris.property", call.Property),
+//		))
+//		return ctx, func(latency time.Duration, err error) {
+//			span.SetAttributes(attribute.Int64("dbus.latency_us", latency.Microseconds()))
+//			if err != nil {
+//				span.RecordError(err)
+//				span.SetStatus(codes.Error, err.Error())
+//			}
+//			span.End()
+//		}
+//	}
+//	player := mpris.New(conn, name, mpris.WithCallTracer(traceCall))
+type CallTracer func(ctx context.Context, call CallInfo) (context.Context, func(latency time.Duration, err error))
+
+// WithCallTracer traces every D-Bus call made to the player, including the property reads and
+// writes, with the tracer.
+func WithCallTracer(tracer CallTracer) Option {
+	return func(p *Player) {
+		p.tracer = tracer
+	}
+}
+
+// newCallInfo describes the call of the method, a full D-Bus method name like
+// org.mpris.MediaPlayer2.Player.Play, with the arguments args.
+func newCallInfo(busName, method string, args []interface{}) CallInfo {
+	info := CallInfo{BusName: busName, Interface: method}
+	if dot := strings.LastIndexByte(method, '.'); dot >= 0 {
+		info.Interface, info.Member = method[:dot], method[dot+1:]
+	}
+	if info.Interface != propertiesInterface || len(args) == 0 {
+		return info
+	}
+	if iface, ok := args[0].(string); ok {
+		info.Property = iface
+		if prop, ok := argString(args, 1); ok {
+			info.Property += "." + prop
+		}
+	}
+	return info
+}
+
+func argString(args []interface{}, n int) (string, bool) {
+	if n >= len(args) {
+		return "", false
+	}
+	value, ok := args[n].(string)
+	return value, ok
+}
@@ -0,0 +1,66 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type tracedCall struct {
+	info CallInfo
+	err  error
+}
+
+func TestWithCallTracer(t *testing.T) {
+	testPlayer, fake := newTestPlayer(t)
+	fake.HandleFunc(PlayerInterface, "Next", func(args ...interface{}) *dbus.Error {
+		return dbus.MakeFailedError(errors.New("no next track"))
+	})
+
+	var mu sync.Mutex
+	var calls []tracedCall
+	tracer := func(ctx context.Context, call CallInfo) (context.Context, func(time.Duration, error)) {
+		return ctx, func(latency time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if latency <= 0 {
+				t.Errorf("Expected a positive latency for %v, got %v", call, latency)
+			}
+			calls = append(calls, tracedCall{call, err})
+		}
+	}
+	player := New(testPlayer.conn, testPlayer.name, WithCallTracer(tracer))
+
+	if err := player.Play(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.GetVolume(); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.Next(); err == nil {
+		t.Fatal("Expected Next to fail")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []CallInfo{
+		{BusName: player.name, Interface: PlayerInterface, Member: "Play"},
+		{BusName: player.name, Interface: propertiesInterface, Member: "Get", Property: PlayerInterface + ".Volume"},
+		{BusName: player.name, Interface: PlayerInterface, Member: "Next"},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %d traced calls, got %v", len(expected), calls)
+	}
+	for n, call := range calls {
+		if call.info != expected[n] {
+			t.Errorf("Expected call %d to be %v, got %v", n, expected[n], call.info)
+		}
+	}
+	if calls[0].err != nil || calls[2].err == nil {
+		t.Errorf("Expected only the last call to fail, got %v", calls)
+	}
+}
@@ -0,0 +1,212 @@
+package mpris
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// trackEndTolerance is how far from the end of a track its extrapolated position can be when
+// the next one starts for the track to be considered finished rather than skipped.
+const trackEndTolerance = 2 * time.Second
+
+// TrackEndingEvent is sent by OnTrackEnd when the remaining time of the track drops below the
+// threshold, to preload the next one.
+type TrackEndingEvent struct {
+	Metadata  Metadata
+	Remaining time.Duration
+}
+
+// TrackFinishedEvent is sent by OnTrackEnd when the track was played to its end, and either
+// another one started or the playback stopped. The tracks skipped before their end aren't
+// reported.
+type TrackFinishedEvent struct {
+	Metadata Metadata
+}
+
+func (TrackEndingEvent) isEvent()   {}
+func (TrackFinishedEvent) isEvent() {}
+
+// trackEnd follows the position of the current track until its end.
+type trackEnd struct {
+	tracker   *positionTracker
+	threshold time.Duration
+	metadata  Metadata
+	key       string
+	// ending and finished are set once the events are sent for the track.
+	ending   bool
+	finished bool
+}
+
+func newTrackEnd(state PlayerState, threshold time.Duration, now time.Time) *trackEnd {
+	return &trackEnd{
+		tracker:   newPositionTracker(state, now),
+		threshold: threshold,
+		metadata:  state.Metadata,
+		key:       state.Metadata.TrackKey(),
+	}
+}
+
+// remaining returns the time left in the track at now, or false if its length is unknown.
+func (e *trackEnd) remaining(now time.Time) (time.Duration, bool) {
+	length := e.metadata.Length()
+	if length <= 0 {
+		return 0, false
+	}
+	return length - e.tracker.current(now), true
+}
+
+// check returns the TrackEndingEvent once the remaining time drops below the threshold.
+func (e *trackEnd) check(now time.Time) []Event {
+	remaining, ok := e.remaining(now)
+	if !ok || e.ending || e.finished || remaining > e.threshold {
+		return nil
+	}
+	e.ending = true
+	return []Event{TrackEndingEvent{Metadata: e.metadata, Remaining: max(remaining, 0)}}
+}
+
+// untilEnding returns how long until the remaining time drops below the threshold, or false if
+// it doesn't go down.
+func (e *trackEnd) untilEnding(now time.Time) (time.Duration, bool) {
+	remaining, ok := e.remaining(now)
+	if !ok || e.ending || !e.tracker.playing || e.tracker.rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(max(remaining-e.threshold, 0)) / e.tracker.rate), true
+}
+
+// finish returns the TrackFinishedEvent if the track reached its end.
+func (e *trackEnd) finish(now time.Time) []Event {
+	remaining, ok := e.remaining(now)
+	if !ok || e.finished || remaining > trackEndTolerance {
+		return nil
+	}
+	e.finished = true
+	return []Event{TrackFinishedEvent{Metadata: e.metadata}}
+}
+
+// handle updates the track with the event and returns the events to send, and true if the
+// position must be read from the player.
+func (e *trackEnd) handle(event Event, now time.Time) ([]Event, bool) {
+	var events []Event
+	if changed, ok := event.(PropertiesChangedEvent); ok && changed.Interface == PlayerInterface {
+		if status, ok := changed.Changed["PlaybackStatus"].Value().(string); ok && PlaybackStatus(status) == PlaybackStopped {
+			events = append(events, e.finish(now)...)
+		}
+		if value, ok := changed.Changed["Metadata"].Value().(map[string]dbus.Variant); ok {
+			metadata := Metadata(value)
+			if key := metadata.TrackKey(); key != e.key {
+				events = append(events, e.finish(now)...)
+				e.key, e.ending, e.finished = key, false, false
+				// the new track starts from the beginning, until the position is read
+				e.tracker.update(0, now)
+			}
+			// the length of the same track may be sent later
+			e.metadata = metadata
+		}
+	}
+
+	rebase := e.tracker.handle(event, now)
+	if _, ok := event.(SeekedEvent); ok {
+		// seeking back before the threshold makes the track end again
+		if remaining, ok := e.remaining(now); ok && remaining > e.threshold {
+			e.ending, e.finished = false, false
+		}
+	}
+	return append(events, e.check(now)...), rebase
+}
+
+// OnTrackEnd returns a channel receiving a TrackEndingEvent when the remaining time of the
+// current track drops below threshold, like to preload the next one for a gapless playback,
+// and a TrackFinishedEvent when it was played to its end, like to log the listening history.
+// The channel is closed when ctx is done or the connection is closed.
+//
+// The position is extrapolated like for PositionEvents, and the tracks without a length in
+// their metadata are never reported.
+func (i *Player) OnTrackEnd(ctx context.Context, threshold time.Duration) (<-chan Event, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	player := i.WithContext(ctx)
+
+	// subscribe before reading the state, so a change in the meantime isn't missed
+	events, err := player.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	state, err := player.GetState()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	track := newTrackEnd(state, threshold, time.Now())
+
+	ends := make(chan Event)
+	go func() {
+		defer cancel()
+		defer close(ends)
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		rebaseTicker := time.NewTicker(positionRebaseInterval)
+		defer rebaseTicker.Stop()
+
+		rebase := func() {
+			position, err := player.GetPositionDuration()
+			if err != nil {
+				player.debug("reading the position", "error", err)
+				return
+			}
+			track.tracker.rebase(position, time.Now())
+		}
+		send := func(toSend []Event) bool {
+			for _, event := range toSend {
+				select {
+				case ends <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for {
+			now := time.Now()
+			if !send(track.check(now)) {
+				return
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			var timeout <-chan time.Time
+			if wait, ok := track.untilEnding(now); ok {
+				timer.Reset(wait)
+				timeout = timer.C
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeout:
+			case <-rebaseTicker.C:
+				rebase()
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				toSend, needsRebase := track.handle(event, time.Now())
+				if needsRebase {
+					rebase()
+				}
+				if !send(toSend) {
+					return
+				}
+			}
+		}
+	}()
+	return ends, nil
+}
@@ -0,0 +1,113 @@
+package mpris
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func trackMetadata(id string, length time.Duration) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath(id)),
+		"mpris:length":  dbus.MakeVariant(durationToMicroseconds(length)),
+	}
+}
+
+func metadataEvent(metadata map[string]dbus.Variant) PropertiesChangedEvent {
+	return PropertiesChangedEvent{
+		Interface: PlayerInterface,
+		Changed:   map[string]dbus.Variant{"Metadata": dbus.MakeVariant(metadata)},
+	}
+}
+
+func TestTrackEnd(t *testing.T) {
+	start := time.Now()
+	track := newTrackEnd(PlayerState{
+		PlaybackStatus: PlaybackPlaying,
+		Rate:           1,
+		Metadata:       trackMetadata("/track/1", time.Minute),
+	}, 10*time.Second, start)
+
+	if wait, ok := track.untilEnding(start); !ok || wait != 50*time.Second {
+		t.Errorf("Expected the track to end in 50s, got %s", wait)
+	}
+	if events := track.check(start.Add(49 * time.Second)); len(events) != 0 {
+		t.Errorf("Expected no event before the threshold, got %v", events)
+	}
+	events := track.check(start.Add(55 * time.Second))
+	if len(events) != 1 || events[0].(TrackEndingEvent).Remaining != 5*time.Second {
+		t.Errorf("Expected a TrackEndingEvent with 5s remaining, got %v", events)
+	}
+	if events := track.check(start.Add(56 * time.Second)); len(events) != 0 {
+		t.Errorf("Expected a single TrackEndingEvent, got %v", events)
+	}
+
+	// a track is finished when the next one starts at its end, not when skipped before
+	events, _ = track.handle(metadataEvent(trackMetadata("/track/2", time.Minute)), start.Add(time.Minute))
+	if len(events) != 1 || events[0].(TrackFinishedEvent).Metadata.TrackID() != "/track/1" {
+		t.Errorf("Expected the first track to be finished, got %v", events)
+	}
+	events, _ = track.handle(metadataEvent(trackMetadata("/track/3", time.Minute)), start.Add(90*time.Second))
+	if len(events) != 0 {
+		t.Errorf("Expected no event for a skipped track, got %v", events)
+	}
+
+	// seeking back ends the track again
+	track.handle(SeekedEvent{55 * time.Second}, start.Add(2*time.Minute))
+	track.handle(SeekedEvent{time.Second}, start.Add(2*time.Minute))
+	events = track.check(start.Add(2*time.Minute + 50*time.Second))
+	if len(events) != 1 {
+		t.Errorf("Expected the track to end again after seeking back, got %v", events)
+	}
+
+	// stopping at the end finishes the track
+	events, _ = track.handle(statusEvent("Stopped"), start.Add(3*time.Minute))
+	if len(events) != 1 || events[0].(TrackFinishedEvent).Metadata.TrackID() != "/track/3" {
+		t.Errorf("Expected stopping at the end to finish the track, got %v", events)
+	}
+}
+
+func TestOnTrackEnd(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	if err := fake.SetMetadata(trackMetadata("/org/mpris/MediaPlayer2/Track/1", 10*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPosition(9700000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetPlaybackStatus("Playing"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := player.OnTrackEnd(ctx, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := receiveEvent(t, events)
+	if ending, ok := event.(TrackEndingEvent); !ok || ending.Remaining > 200*time.Millisecond {
+		t.Fatalf("Expected a TrackEndingEvent, got %#v", event)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if err := fake.SetPosition(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetMetadata(trackMetadata("/org/mpris/MediaPlayer2/Track/2", 10*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	event = receiveEvent(t, events)
+	if finished, ok := event.(TrackFinishedEvent); !ok || finished.Metadata.TrackID() != "/org/mpris/MediaPlayer2/Track/1" {
+		t.Fatalf("Expected the first track to be finished, got %#v", event)
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("Expected no event at the start of the track, got %#v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
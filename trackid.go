@@ -0,0 +1,56 @@
+package mpris
+
+import (
+	"errors"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// TrackID identifies a track of the player, as found in the "mpris:trackid" metadata. It's a
+// D-Bus object path, unique within the player.
+type TrackID dbus.ObjectPath
+
+// NoTrack is the track id meaning that there is no current track, as defined by the spec.
+const NoTrack TrackID = "/org/mpris/MediaPlayer2/TrackList/NoTrack"
+
+// ErrInvalidTrackID is returned when a track id is not a valid object path, or is NoTrack
+// where a track is expected.
+var ErrInvalidTrackID = errors.New("invalid track id")
+
+// IsNoTrack returns true if the track id is NoTrack.
+func (t TrackID) IsNoTrack() bool {
+	return t == NoTrack
+}
+
+// IsValid returns true if the track id is a valid object path. The spec reserves the
+// /org/mpris namespace for NoTrack, but some players use it anyway, so it's accepted.
+func (t TrackID) IsValid() bool {
+	return dbus.ObjectPath(t).IsValid()
+}
+
+// ObjectPath returns the track id as an object path, the type it's sent as.
+func (t TrackID) ObjectPath() dbus.ObjectPath {
+	return dbus.ObjectPath(t)
+}
+
+// checkTrackID returns ErrInvalidTrackID if the track id can't be used to refer to a track.
+func checkTrackID(trackID TrackID) error {
+	if trackID.IsNoTrack() || !trackID.IsValid() {
+		return ErrInvalidTrackID
+	}
+	return nil
+}
+
+// trackIDValue converts the "mpris:trackid" variant, accepting the players that send it as a
+// plain string.
+func trackIDValue(variant dbus.Variant) (TrackID, error) {
+	switch trackID := variant.Value().(type) {
+	case dbus.ObjectPath:
+		return TrackID(trackID), nil
+	case string:
+		return TrackID(trackID), nil
+	case nil:
+		return "", ErrNilVariant
+	}
+	return "", &TypeError{"mpris:trackid", variant.Signature().String(), "TrackID"}
+}
@@ -0,0 +1,33 @@
+package mpris
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestTrackID(t *testing.T) {
+	cases := []struct {
+		trackID TrackID
+		valid   bool
+		noTrack bool
+	}{
+		{"/com/spotify/track/4uLU6hMCjMI75M1A2tKUQC", true, false},
+		{"/org/mpris/MediaPlayer2/TrackList/NoTrack", true, true},
+		{"spotify:track:4uLU6hMCjMI75M1A2tKUQC", false, false},
+		{"", false, false},
+	}
+	for _, c := range cases {
+		if c.trackID.IsValid() != c.valid || c.trackID.IsNoTrack() != c.noTrack {
+			t.Errorf("Expected %q to be valid %t and no track %t", c.trackID, c.valid, c.noTrack)
+		}
+	}
+	if checkTrackID(NoTrack) != ErrInvalidTrackID {
+		t.Error("Expected NoTrack to be rejected")
+	}
+
+	metadata := Metadata{"mpris:trackid": dbus.MakeVariant("/org/videolan/vlc/track/1")}
+	if metadata.TrackID() != "/org/videolan/vlc/track/1" {
+		t.Errorf("Expected the string track id to be accepted, got %q", metadata.TrackID())
+	}
+}
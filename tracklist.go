@@ -0,0 +1,171 @@
+package mpris
+
+import (
+	"context"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// tracksMetadataBatchSize is how many tracks FetchTracksMetadata asks for per call.
+	tracksMetadataBatchSize = 50
+	// tracksMetadataConcurrency is how many calls FetchTracksMetadata makes at once.
+	tracksMetadataConcurrency = 4
+)
+
+// TrackListClient calls the org.mpris.MediaPlayer2.TrackList interface of a player, which is
+// only implemented by the players whose HasTrackList is true. Some of its methods are generated
+// from the spec, in bindings_gen.go.
+type TrackListClient struct {
+	core *Player
+}
+
+// GetTracks returns the ids of the tracks in the track list, in order.
+func (c TrackListClient) GetTracks() ([]TrackID, error) {
+	variant, err := c.core.getProperty(TrackListInterface, "Tracks")
+	if err != nil {
+		return nil, err
+	}
+	paths, ok := variant.Value().([]dbus.ObjectPath)
+	if !ok {
+		if variant.Value() == nil {
+			return nil, ErrNilVariant
+		}
+		return nil, &TypeError{"Tracks", variant.Signature().String(), "[]TrackID"}
+	}
+	tracks := make([]TrackID, len(paths))
+	for i, path := range paths {
+		tracks[i] = TrackID(path)
+	}
+	return tracks, nil
+}
+
+// GetTracksMetadata returns the metadata of the tracks. Tracks that are not in the track list
+// anymore are left out, so the result can be shorter than trackIDs.
+func (c TrackListClient) GetTracksMetadata(trackIDs []TrackID) ([]Metadata, error) {
+	paths := make([]dbus.ObjectPath, len(trackIDs))
+	for i, trackID := range trackIDs {
+		paths[i] = trackID.ObjectPath()
+	}
+	var result []map[string]dbus.Variant
+	err := c.core.query(TrackListInterface+".GetTracksMetadata", paths).Store(&result)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make([]Metadata, len(result))
+	for i, track := range result {
+		metadata[i] = Metadata(track)
+	}
+	return metadata, nil
+}
+
+// FetchTracksMetadata returns the metadata of many tracks, like long queues, keyed by track id.
+// The tracks are fetched in batches, several at once, which is much faster than a single call
+// or a call per track on some players. Tracks that are not in the track list anymore are left
+// out. The first failing call cancels the others and its error is returned.
+func (c TrackListClient) FetchTracksMetadata(ctx context.Context, trackIDs []TrackID) (map[TrackID]Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := c.core.WithContext(ctx).TrackList
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	metadata := make(map[TrackID]Metadata, len(trackIDs))
+	slots := make(chan struct{}, tracksMetadataConcurrency)
+	for start := 0; start < len(trackIDs); start += tracksMetadataBatchSize {
+		batch := trackIDs[start:min(start+tracksMetadataBatchSize, len(trackIDs))]
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			tracks, err := client.GetTracksMetadata(batch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for _, track := range tracks {
+				metadata[track.TrackID()] = track
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// AddTracks adds the uris to the track list after the track, or at the start of the list if
+// after is NoTrack, keeping their order. With setAsCurrent, the first of them becomes the
+// current track. ErrNotSupported is returned when CanEditTracks is false.
+func (c TrackListClient) AddTracks(uris []string, after TrackID, setAsCurrent bool) error {
+	canEdit, err := c.CanEditTracks()
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return ErrNotSupported
+	}
+	// AddTrack doesn't return the id of the new track, so the uris are added in reverse order,
+	// each one before the previous one
+	for i := len(uris) - 1; i >= 0; i-- {
+		if err := c.AddTrack(uris[i], after, setAsCurrent && i == 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddTracksAndWait adds the uris like AddTracks, then waits for the player to announce them
+// with TrackAdded signals, and returns their metadata in the order of the uris. The signals
+// are told apart by their count alone, so tracks added by another client in the meantime can
+// be mixed up with the uris.
+func (c TrackListClient) AddTracksAndWait(ctx context.Context, uris []string, after TrackID, setAsCurrent bool) ([]Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the subscription goes first, so no signal is missed
+	events, err := c.core.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.core.WithContext(ctx).TrackList.AddTracks(uris, after, setAsCurrent); err != nil {
+		return nil, err
+	}
+
+	added := make([]Metadata, len(uris))
+	for remaining := len(uris); remaining > 0; {
+		event, ok := <-events
+		if !ok {
+			return nil, closedError(ctx)
+		}
+		if trackAdded, ok := event.(TrackAddedEvent); ok {
+			// the uris were added in reverse order
+			remaining--
+			added[remaining] = trackAdded.Metadata
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,173 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestTrackList(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	err := fake.SetTracks([]map[string]dbus.Variant{
+		{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/a")),
+			"xesam:title":   dbus.MakeVariant("A"),
+		},
+		{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/b")),
+			"xesam:title":   dbus.MakeVariant("B"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracks, err := player.TrackList.GetTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0] != "/org/mpristest/track/a" || tracks[1] != "/org/mpristest/track/b" {
+		t.Fatalf("Unexpected tracks %v", tracks)
+	}
+
+	metadata, err := player.TrackList.GetTracksMetadata([]TrackID{tracks[1], "/org/mpristest/track/missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != 1 || metadata[0].Title() != "B" {
+		t.Errorf("Unexpected metadata %v", metadata)
+	}
+
+	if err := player.TrackList.GoTo(tracks[1]); err != nil {
+		t.Fatal(err)
+	}
+	if title, _ := player.GetTitle(); title != "B" {
+		t.Errorf("Expected the current track to be B, got %s", title)
+	}
+
+	if err := player.TrackList.AddTrack("file:///c.mp3", tracks[0], false); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.TrackList.RemoveTrack(tracks[1]); err != nil {
+		t.Fatal(err)
+	}
+	tracks, err = player.TrackList.GetTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0] != "/org/mpristest/track/a" || tracks[1] == "/org/mpristest/track/b" {
+		t.Errorf("Unexpected tracks after editing %v", tracks)
+	}
+
+	if canEdit, err := player.TrackList.CanEditTracks(); err != nil || !canEdit {
+		t.Errorf("Expected the tracks to be editable, got %t %v", canEdit, err)
+	}
+}
+
+func TestAddTracks(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	err := fake.SetTracks([]map[string]dbus.Variant{
+		{"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpristest/track/a"))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uris := []string{"file:///b.mp3", "file:///c.mp3", "file:///d.mp3"}
+	added, err := player.TrackList.AddTracksAndWait(context.Background(), uris, "/org/mpristest/track/a", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != len(uris) {
+		t.Fatalf("Expected %d added tracks, got %v", len(uris), added)
+	}
+	for i, uri := range uris {
+		if added[i].URL() != uri {
+			t.Errorf("Expected added track %d to be %s, got %s", i, uri, added[i].URL())
+		}
+	}
+
+	tracks, err := player.TrackList.GetTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := player.TrackList.GetTracksMetadata(tracks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, track := range metadata[1:] {
+		urls = append(urls, track.URL())
+	}
+	if len(tracks) != 4 || tracks[0] != "/org/mpristest/track/a" || !reflect.DeepEqual(urls, uris) {
+		t.Errorf("Unexpected track list %v %v", tracks, urls)
+	}
+	if metadata, _ := player.GetMetadata(); metadata.URL() != "file:///b.mp3" {
+		t.Errorf("Expected the first added track to be the current one, got %s", metadata.URL())
+	}
+
+	if err := fake.SetProperty(TrackListInterface, "CanEditTracks", false); err != nil {
+		t.Fatal(err)
+	}
+	mpristest.Eventually(t, func() bool {
+		canEdit, err := player.TrackList.CanEditTracks()
+		return err == nil && !canEdit
+	}, "CanEditTracks to be false")
+	if err := player.TrackList.AddTracks(uris, NoTrack, false); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestFetchTracksMetadata(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	tracks := make([]map[string]dbus.Variant, 2*tracksMetadataBatchSize+20)
+	trackIDs := make([]TrackID, len(tracks))
+	for i := range tracks {
+		trackIDs[i] = TrackID(fmt.Sprintf("/org/mpristest/track/t%d", i))
+		tracks[i] = map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(trackIDs[i].ObjectPath()),
+			"xesam:title":   dbus.MakeVariant(fmt.Sprintf("Track %d", i)),
+		}
+	}
+	if err := fake.SetTracks(tracks); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := player.TrackList.FetchTracksMetadata(context.Background(), append(trackIDs, "/org/mpristest/track/missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != len(trackIDs) {
+		t.Errorf("Expected %d tracks, got %d", len(trackIDs), len(metadata))
+	}
+	for i, trackID := range trackIDs {
+		if title := metadata[trackID].Title(); title != fmt.Sprintf("Track %d", i) {
+			t.Errorf("Unexpected title for %s: %q", trackID, title)
+		}
+	}
+
+	calls := 0
+	for _, call := range fake.Calls() {
+		if call.Method == "GetTracksMetadata" {
+			calls++
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 batches, got %d", calls)
+	}
+
+	fake.HandleFunc(TrackListInterface, "GetTracksMetadata", func(...interface{}) *dbus.Error {
+		return dbus.MakeFailedError(errors.New("too many tracks"))
+	})
+	if _, err := player.TrackList.FetchTracksMetadata(context.Background(), trackIDs); err == nil {
+		t.Error("Expected the failing batch error")
+	}
+}
@@ -0,0 +1,94 @@
+package mpris
+
+import "time"
+
+// checkCapabilities reads the capabilities of the player interface in a single round trip,
+// returning a NotAllowedError for method if CanControl or one of the capabilities is false.
+// The capabilities the player doesn't report are considered true, as the players that don't
+// implement them usually don't enforce them either.
+func (i *Player) checkCapabilities(method string, capabilities ...string) error {
+	props, err := i.getAllProperties(PlayerInterface)
+	if err != nil {
+		return err
+	}
+	for _, capability := range append([]string{"CanControl"}, capabilities...) {
+		if allowed, ok := props[capability].Value().(bool); ok && !allowed {
+			return &NotAllowedError{Method: method, Capability: capability}
+		}
+	}
+	return nil
+}
+
+// TryPlay starts or resumes playback, or returns a NotAllowedError if CanPlay is false.
+func (i *Player) TryPlay() error {
+	if err := i.checkCapabilities("Play", "CanPlay"); err != nil {
+		return err
+	}
+	return i.Play()
+}
+
+// TryPause pauses playback, or returns a NotAllowedError if CanPause is false.
+func (i *Player) TryPause() error {
+	if err := i.checkCapabilities("Pause", "CanPause"); err != nil {
+		return err
+	}
+	return i.Pause()
+}
+
+// TryPlayPause toggles playback, or returns a NotAllowedError if CanPause is false.
+func (i *Player) TryPlayPause() error {
+	if err := i.checkCapabilities("PlayPause", "CanPause"); err != nil {
+		return err
+	}
+	return i.PlayPause()
+}
+
+// TryStop stops playback, or returns a NotAllowedError if CanControl is false.
+func (i *Player) TryStop() error {
+	if err := i.checkCapabilities("Stop"); err != nil {
+		return err
+	}
+	return i.Stop()
+}
+
+// TryNext skips to the next track, or returns a NotAllowedError if CanGoNext is false.
+func (i *Player) TryNext() error {
+	if err := i.checkCapabilities("Next", "CanGoNext"); err != nil {
+		return err
+	}
+	return i.Next()
+}
+
+// TryPrevious skips to the previous track, or returns a NotAllowedError if CanGoPrevious is
+// false.
+func (i *Player) TryPrevious() error {
+	if err := i.checkCapabilities("Previous", "CanGoPrevious"); err != nil {
+		return err
+	}
+	return i.Previous()
+}
+
+// TrySeek moves the position by the offset, or returns a NotAllowedError if CanSeek is false.
+func (i *Player) TrySeek(offset time.Duration) error {
+	if err := i.checkCapabilities("Seek", "CanSeek"); err != nil {
+		return err
+	}
+	return i.Player.Seek(offset)
+}
+
+// TrySetPosition sets the position of the track, or returns a NotAllowedError if CanSeek is
+// false.
+func (i *Player) TrySetPosition(trackID TrackID, position time.Duration) error {
+	if err := i.checkCapabilities("SetPosition", "CanSeek"); err != nil {
+		return err
+	}
+	return i.SetPositionWithTrackID(trackID, position)
+}
+
+// TrySetVolume sets the volume, or returns a NotAllowedError if CanControl is false.
+func (i *Player) TrySetVolume(volume float64) error {
+	if err := i.checkCapabilities("Volume"); err != nil {
+		return err
+	}
+	return i.SetVolume(volume)
+}
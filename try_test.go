@@ -0,0 +1,42 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTryCommands(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	if err := player.TryPlay(); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := player.GetPlaybackStatus(); status != PlaybackPlaying {
+		t.Errorf("Expected the player to play, got %s", status)
+	}
+
+	if err := fake.SetProperty(PlayerInterface, "CanGoNext", false); err != nil {
+		t.Fatal(err)
+	}
+	calls := len(fake.Calls())
+	err := player.TryNext()
+	var notAllowed *NotAllowedError
+	if !errors.Is(err, ErrNotAllowed) || !errors.As(err, &notAllowed) || notAllowed.Capability != "CanGoNext" {
+		t.Errorf("Expected CanGoNext to not allow Next, got %v", err)
+	}
+	if len(fake.Calls()) != calls {
+		t.Errorf("Expected Next to not be called, got %v", fake.Calls()[calls:])
+	}
+
+	// nothing is allowed without CanControl
+	if err := fake.SetProperty(PlayerInterface, "CanControl", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := player.TrySeek(time.Second); !errors.As(err, &notAllowed) || notAllowed.Method != "Seek" || notAllowed.Capability != "CanControl" {
+		t.Errorf("Expected CanControl to not allow Seek, got %v", err)
+	}
+	if err := player.TryStop(); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Expected CanControl to not allow Stop, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package mpris
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	variantType  = reflect.TypeOf(dbus.Variant{})
+)
+
+// Unmarshal stores the metadata in the struct pointed by v, whose fields are mapped to the
+// metadata keys with the mpris tag, like:
+//
+//	type Track struct {
+//		ID      mpris.TrackID `mpris:"mpris:trackid"`
+//		Title   string        `mpris:"xesam:title"`
+//		Artists []string      `mpris:"xesam:artist"`
+//		Length  time.Duration `mpris:"mpris:length"`
+//		Rating  *float64      `mpris:"xesam:userRating"`
+//	}
+//
+// The numbers are converted to the type of the field, like an int32 to an int64 or a float64,
+// as long as they fit. A time.Duration is read from microseconds, like "mpris:length", and a
+// time.Time from a date parsed by ParseDate, like "xesam:contentCreated". Strings and object
+// paths are interchangeable, lists of strings accept a single string, dbus.Variant and
+// interface{} fields get the raw value, and pointer fields are left nil when the key is
+// missing. The fields of embedded structs are mapped too, and the fields without a tag are
+// ignored.
+//
+// A TypeError is returned for a value that can't be stored in its field, the fields before it
+// being already set.
+func (m Metadata) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("unmarshaling the metadata needs a non-nil pointer to a struct")
+	}
+	return m.unmarshalStruct(rv.Elem())
+}
+
+func (m Metadata) unmarshalStruct(rv reflect.Value) error {
+	rt := rv.Type()
+	for n := 0; n < rt.NumField(); n++ {
+		field := rt.Field(n)
+		key, tagged := field.Tag.Lookup("mpris")
+		if !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := m.unmarshalStruct(rv.Field(n)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged || key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		variant, ok := m[key]
+		if !ok || variant.Value() == nil {
+			continue
+		}
+		if err := storeValue(rv.Field(n), variant.Value()); err != nil {
+			return &TypeError{key, variant.Signature().String(), field.Type.String()}
+		}
+	}
+	return nil
+}
+
+// errCantStore is returned by storeValue, and replaced by a TypeError with the key.
+var errCantStore = errors.New("can't store the value")
+
+// storeValue converts the D-Bus value to the type of the field and sets it.
+func storeValue(field reflect.Value, value interface{}) error {
+	if variant, ok := value.(dbus.Variant); ok && field.Type() != variantType {
+		value = variant.Value()
+	}
+
+	switch {
+	case field.Type() == variantType:
+		if variant, ok := value.(dbus.Variant); ok {
+			field.Set(reflect.ValueOf(variant))
+		} else {
+			field.Set(reflect.ValueOf(dbus.MakeVariant(value)))
+		}
+		return nil
+	case field.Type() == durationType:
+		microseconds, ok := toInt64(value)
+		if !ok {
+			return errCantStore
+		}
+		field.SetInt(int64(microsecondsToDuration(microseconds)))
+		return nil
+	case field.Type() == timeType:
+		str, _ := value.(string)
+		date, ok := ParseDate(str)
+		if !ok {
+			return errCantStore
+		}
+		field.Set(reflect.ValueOf(date))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := storeValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Interface:
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || !rv.Type().AssignableTo(field.Type()) {
+			return errCantStore
+		}
+		field.Set(rv)
+	case reflect.String:
+		switch value := value.(type) {
+		case string:
+			field.SetString(value)
+		case dbus.ObjectPath:
+			field.SetString(string(value))
+		default:
+			return errCantStore
+		}
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return errCantStore
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt64(value)
+		if !ok || field.OverflowInt(n) {
+			return errCantStore
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := value.(uint64); ok {
+			if field.OverflowUint(n) {
+				return errCantStore
+			}
+			field.SetUint(n)
+			return nil
+		}
+		n, ok := toInt64(value)
+		if !ok || n < 0 || field.OverflowUint(uint64(n)) {
+			return errCantStore
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(value)
+		if !ok || (field.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32) {
+			return errCantStore
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		return storeSlice(field, value)
+	default:
+		return errCantStore
+	}
+	return nil
+}
+
+// storeSlice stores a list, or a single value as a list of one item, like the artist of the
+// players sending a string instead of a list.
+func storeSlice(field reflect.Value, value interface{}) error {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || (rv.Type().Elem().Kind() == reflect.Uint8 && field.Type().Elem().Kind() != reflect.Uint8) {
+		rv = reflect.ValueOf([]interface{}{value})
+	}
+	slice := reflect.MakeSlice(field.Type(), rv.Len(), rv.Len())
+	for n := 0; n < rv.Len(); n++ {
+		if err := storeValue(slice.Index(n), rv.Index(n).Interface()); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
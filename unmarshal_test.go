@@ -0,0 +1,75 @@
+package mpris
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type testTrackInfo struct {
+	Album string `mpris:"xesam:album"`
+}
+
+type testTrack struct {
+	testTrackInfo
+	ID          TrackID       `mpris:"mpris:trackid"`
+	Title       string        `mpris:"xesam:title"`
+	Artists     []string      `mpris:"xesam:artist"`
+	Genres      []string      `mpris:"xesam:genre"`
+	Length      time.Duration `mpris:"mpris:length"`
+	TrackNumber int           `mpris:"xesam:trackNumber"`
+	DiscNumber  uint8         `mpris:"xesam:discNumber"`
+	Rating      *float64      `mpris:"xesam:userRating"`
+	AutoRating  *float64      `mpris:"xesam:autoRating"`
+	Raw         dbus.Variant  `mpris:"xesam:comment"`
+	Extra       interface{}   `mpris:"xesam:useCount"`
+	Ignored     string
+}
+
+func TestMetadataUnmarshal(t *testing.T) {
+	metadata := Metadata{
+		"mpris:trackid":     dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+		"xesam:title":       dbus.MakeVariant("Title"),
+		"xesam:album":       dbus.MakeVariant("Album"),
+		"xesam:artist":      dbus.MakeVariant("Artist"),
+		"xesam:genre":       dbus.MakeVariant([]interface{}{dbus.MakeVariant("Rock"), "Pop"}),
+		"mpris:length":      dbus.MakeVariant(uint64(180000000)),
+		"xesam:trackNumber": dbus.MakeVariant(int32(3)),
+		"xesam:discNumber":  dbus.MakeVariant(int64(2)),
+		"xesam:userRating":  dbus.MakeVariant(0.8),
+		"xesam:comment":     dbus.MakeVariant([]string{"a comment"}),
+		"xesam:useCount":    dbus.MakeVariant(int32(7)),
+	}
+
+	var track testTrack
+	track.Ignored = "kept"
+	if err := metadata.Unmarshal(&track); err != nil {
+		t.Fatal(err)
+	}
+	if track.ID != "/org/mpris/MediaPlayer2/Track/1" || track.Title != "Title" || track.Album != "Album" {
+		t.Errorf("Unexpected strings in %+v", track)
+	}
+	if len(track.Artists) != 1 || track.Artists[0] != "Artist" || len(track.Genres) != 2 || track.Genres[0] != "Rock" {
+		t.Errorf("Unexpected lists in %+v", track)
+	}
+	if track.Length != 3*time.Minute || track.TrackNumber != 3 || track.DiscNumber != 2 {
+		t.Errorf("Unexpected numbers in %+v", track)
+	}
+	if track.Rating == nil || *track.Rating != 0.8 || track.AutoRating != nil {
+		t.Errorf("Unexpected ratings %v %v", track.Rating, track.AutoRating)
+	}
+	if track.Raw.Signature().String() != "as" || track.Extra != int32(7) || track.Ignored != "kept" {
+		t.Errorf("Unexpected raw values in %+v", track)
+	}
+
+	metadata["xesam:discNumber"] = dbus.MakeVariant(int64(300))
+	var typeErr *TypeError
+	if err := metadata.Unmarshal(&track); !errors.As(err, &typeErr) || typeErr.Name != "xesam:discNumber" || typeErr.Expected != "uint8" {
+		t.Errorf("Expected a TypeError for the overflowing disc number, got %v", err)
+	}
+	if err := metadata.Unmarshal(track); err == nil {
+		t.Error("Expected an error for a non pointer")
+	}
+}
@@ -0,0 +1,165 @@
+package mpris
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// unmuteVolume is the volume restored by ToggleMute when the player was muted by something
+// else, so the volume before muting is unknown.
+const unmuteVolume = 1.0
+
+const (
+	// defaultFadeStep is how often FadeVolume changes the volume by default.
+	defaultFadeStep = 50 * time.Millisecond
+	// minFadeStep and minFadeDelta limit the Set calls of FadeVolume, which never changes the
+	// volume more often or by less than them.
+	minFadeStep  = 20 * time.Millisecond
+	minFadeDelta = 0.005
+)
+
+// muteState remembers the volume before ToggleMute muted the player. It's shared by the copies
+// made by WithContext.
+type muteState struct {
+	mu     sync.Mutex
+	volume float64
+}
+
+func clampVolume(volume float64) float64 {
+	if volume < 0 {
+		return 0
+	}
+	if volume > 1 {
+		return 1
+	}
+	return volume
+}
+
+// IncreaseVolume increases the volume by step, without going above 1. It returns the new
+// volume.
+func (i *Player) IncreaseVolume(step float64) (float64, error) {
+	volume, err := i.GetVolume()
+	if err != nil {
+		return 0, err
+	}
+	volume = clampVolume(volume + step)
+	return volume, i.SetVolume(volume)
+}
+
+// DecreaseVolume decreases the volume by step, without going below 0. It returns the new
+// volume.
+func (i *Player) DecreaseVolume(step float64) (float64, error) {
+	return i.IncreaseVolume(-step)
+}
+
+// ToggleMute mutes the player by setting its volume to 0, or restores the volume it had before
+// being muted. It returns true if the player is now muted.
+func (i *Player) ToggleMute() (bool, error) {
+	// the volume is read under the lock, so concurrent toggles don't both mute
+	i.mute.mu.Lock()
+	defer i.mute.mu.Unlock()
+	volume, err := i.GetVolume()
+	if err != nil {
+		return false, err
+	}
+	if volume > 0 {
+		if err := i.SetVolume(0); err != nil {
+			return false, err
+		}
+		i.mute.volume = volume
+		return true, nil
+	}
+
+	restored := i.mute.volume
+	if restored <= 0 {
+		restored = unmuteVolume
+	}
+	if err := i.SetVolume(restored); err != nil {
+		return true, err
+	}
+	i.mute.volume = 0
+	return false, nil
+}
+
+// FadeOption configures FadeVolume.
+type FadeOption func(*fadeConfig)
+
+type fadeConfig struct {
+	step time.Duration
+}
+
+func newFadeConfig(opts []FadeOption) fadeConfig {
+	config := fadeConfig{step: defaultFadeStep}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.step < minFadeStep {
+		config.step = minFadeStep
+	}
+	return config
+}
+
+// WithFadeStep sets how often FadeVolume changes the volume, 50ms by default. Intervals below
+// 20ms are raised to it, so the fade doesn't flood the bus.
+func WithFadeStep(interval time.Duration) FadeOption {
+	return func(c *fadeConfig) {
+		c.step = interval
+	}
+}
+
+// FadeVolume ramps the volume linearly to target, clamped between 0 and 1, over the duration.
+// The volume changes at most once per step interval and by steps of at least 0.005, so short
+// fades make only a few Set calls. If ctx is canceled during the fade, the volume it started
+// from is restored and the context error is returned.
+func (i *Player) FadeVolume(ctx context.Context, target float64, over time.Duration, opts ...FadeOption) error {
+	config := newFadeConfig(opts)
+	start, err := i.WithContext(ctx).GetVolume()
+	if err != nil {
+		return err
+	}
+	target = clampVolume(target)
+	delta := target - start
+
+	steps := int(over / config.step)
+	if maxSteps := int(math.Abs(delta) / minFadeDelta); steps > maxSteps {
+		steps = maxSteps
+	}
+	if steps <= 1 {
+		return i.WithContext(ctx).SetVolume(target)
+	}
+
+	ticker := time.NewTicker(over / time.Duration(steps))
+	defer ticker.Stop()
+	began := time.Now()
+	for step := 1; ; step++ {
+		select {
+		case <-ctx.Done():
+			return i.restoreVolume(ctx, start)
+		case <-ticker.C:
+		}
+		// the volume follows the elapsed time, so slow calls don't make the fade longer. The
+		// calls aren't bound to ctx, as a canceled one could still reach the player after the
+		// volume is restored.
+		progress := math.Min(float64(time.Since(began))/float64(over), 1)
+		if step >= steps {
+			progress = 1
+		}
+		if err := i.SetVolume(start + delta*progress); err != nil {
+			return err
+		}
+		if progress == 1 {
+			return nil
+		}
+	}
+}
+
+// restoreVolume sets the volume back to where a fade canceled by ctx started, returning the
+// context error unless the volume can't be restored.
+func (i *Player) restoreVolume(ctx context.Context, volume float64) error {
+	if err := i.SetVolume(volume); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
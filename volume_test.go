@@ -0,0 +1,88 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVolumeHelpers(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	if err := player.SetVolume(0.95); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.IncreaseVolume(0.1); err != nil || volume != 1 {
+		t.Errorf("Expected the volume to be clamped to 1, got %f %v", volume, err)
+	}
+	if err := player.SetVolume(0.05); err != nil {
+		t.Fatal(err)
+	}
+	if volume, err := player.DecreaseVolume(0.1); err != nil || volume != 0 {
+		t.Errorf("Expected the volume to be clamped to 0, got %f %v", volume, err)
+	}
+
+	if err := player.SetVolume(0.5); err != nil {
+		t.Fatal(err)
+	}
+	if muted, err := player.ToggleMute(); err != nil || !muted {
+		t.Fatalf("Expected the player to be muted, got %t %v", muted, err)
+	}
+	if value, _ := fake.GetProperty(PlayerInterface, "Volume"); value.Value() != 0.0 {
+		t.Errorf("Expected the volume to be 0, got %v", value.Value())
+	}
+	// the copies share the volume before muting
+	if muted, err := player.WithContext(player.Context()).ToggleMute(); err != nil || muted {
+		t.Fatalf("Expected the player to be unmuted, got %t %v", muted, err)
+	}
+	if value, _ := fake.GetProperty(PlayerInterface, "Volume"); value.Value() != 0.5 {
+		t.Errorf("Expected the volume to be restored to 0.5, got %v", value.Value())
+	}
+}
+
+func TestFadeVolume(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	setCalls := func() int {
+		calls := 0
+		for _, call := range fake.Calls() {
+			if call.Method == "Set" && call.Args[1] == "Volume" {
+				calls++
+			}
+		}
+		return calls
+	}
+
+	if err := player.SetVolume(0); err != nil {
+		t.Fatal(err)
+	}
+	before := setCalls()
+	if err := player.FadeVolume(context.Background(), 1, 200*time.Millisecond, WithFadeStep(time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := fake.GetProperty(PlayerInterface, "Volume"); value.Value() != 1.0 {
+		t.Errorf("Expected the volume to be 1, got %v", value.Value())
+	}
+	// the step is raised to 20ms, so 10 calls at most
+	if calls := setCalls() - before; calls < 2 || calls > 10 {
+		t.Errorf("Expected between 2 and 10 Set calls, got %d", calls)
+	}
+
+	// tiny changes are made at once
+	before = setCalls()
+	if err := player.FadeVolume(context.Background(), 0.996, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if calls := setCalls() - before; calls != 1 {
+		t.Errorf("Expected a single Set call, got %d", calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := player.FadeVolume(ctx, 0, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the fade to time out, got %v", err)
+	}
+	if value, _ := fake.GetProperty(PlayerInterface, "Volume"); value.Value() != 0.996 {
+		t.Errorf("Expected the volume to be restored to 0.996, got %v", value.Value())
+	}
+}
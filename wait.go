@@ -0,0 +1,205 @@
+package mpris
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// WaitOption configures WaitForPlayer.
+type WaitOption func(*waitConfig)
+
+type waitConfig struct {
+	activate   bool
+	playerOpts []Option
+}
+
+// WithWaitPlayerOptions sets the options the player returned by WaitForPlayer is created with.
+func WithWaitPlayerOptions(opts ...Option) WaitOption {
+	return func(c *waitConfig) {
+		c.playerOpts = append(c.playerOpts, opts...)
+	}
+}
+
+// WaitForPlayer blocks until a player matching the pattern is on the bus and returns it, created
+// with the options set with WithWaitPlayerOptions. The pattern is a glob, as in path.Match, checked
+// against the full bus name, the name without the org.mpris.MediaPlayer2 prefix and the player
+// identity, like "spotify", "vlc.instance*" or "Chromium*". Players already on the bus are returned
+// right away. With WithActivation, a player that's not running is started with StartPlayer instead
+// of waited for.
+func WaitForPlayer(ctx context.Context, conn *dbus.Conn, pattern string, opts ...WaitOption) (*Player, error) {
+	var config waitConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// watch before listing, so players starting in the meantime aren't missed
+	watcher, err := NewWatcher(conn)
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Close()
+
+	for _, name := range watcher.Players() {
+		if player := matchPlayer(ctx, conn, name, pattern, config.playerOpts); player != nil {
+			return player, nil
+		}
+	}
+
+	if config.activate {
+		return StartPlayer(ctx, conn, pattern, config.playerOpts...)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-watcher.Events():
+			if !ok {
+				return nil, ErrPlayerNotFound
+			}
+			if !event.Appeared() {
+				continue
+			}
+			if player := matchPlayer(ctx, conn, event.Name, pattern, config.playerOpts); player != nil {
+				return player, nil
+			}
+		}
+	}
+}
+
+// matchPlayer returns the player with the name if it matches the pattern, or nil.
+func matchPlayer(ctx context.Context, conn *dbus.Conn, name, pattern string, opts []Option) *Player {
+	player := New(conn, name, opts...)
+	if matchGlob(pattern, name) || matchGlob(pattern, strings.TrimPrefix(name, BaseInterface+".")) {
+		return player
+	}
+	variant, err := player.WithContext(ctx).getProperty(BaseInterface, "Identity")
+	if err != nil {
+		return nil
+	}
+	if identity, ok := variant.Value().(string); ok && matchGlob(pattern, identity) {
+		return player
+	}
+	return nil
+}
+
+func matchGlob(pattern, name string) bool {
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// WaitForStatus blocks until the player playback status is the status. It returns right away
+// if the player already has the status.
+func (i *Player) WaitForStatus(ctx context.Context, status PlaybackStatus) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// subscribe before checking, so a change in the meantime isn't missed
+	events, err := i.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	current, err := i.WithContext(ctx).GetPlaybackStatus()
+	if err != nil {
+		return err
+	}
+	if current == status {
+		return nil
+	}
+
+	for event := range events {
+		changed, ok := event.(PropertiesChangedEvent)
+		if !ok || changed.Interface != PlayerInterface {
+			continue
+		}
+		if value, ok := changed.Changed["PlaybackStatus"].Value().(string); ok && PlaybackStatus(value) == status {
+			return nil
+		}
+	}
+	return closedError(ctx)
+}
+
+// WaitForTrackChange blocks until the current track changes and returns the new metadata.
+func (i *Player) WaitForTrackChange(ctx context.Context) (Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes, err := i.OnTrackChange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	change, ok := <-changes
+	if !ok {
+		return nil, closedError(ctx)
+	}
+	return change.Metadata, nil
+}
+
+// OpenUriAndWait opens the URI with OpenUri and blocks until the player loads it, returning
+// the new metadata. The track is loaded once the metadata have the URI as "xesam:url", the
+// escaping aside, or for the players that don't set the URL, once the track id changes. Use a
+// ctx with a deadline to give up on players that never load it.
+func (i *Player) OpenUriAndWait(ctx context.Context, uri string) (Metadata, error) {
+	player := i.WithContext(ctx)
+	previous, err := player.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	open := func() error { return player.OpenUri(uri) }
+	return player.waitForMetadata(ctx, open, func(metadata Metadata) bool {
+		if url := metadata.URL(); url != "" {
+			return sameURL(url, uri)
+		}
+		trackID := metadata.TrackID()
+		return trackID != "" && !trackID.IsNoTrack() && trackID != previous.TrackID()
+	})
+}
+
+// waitForMetadata runs action, then blocks until the metadata of the player match and returns
+// them. The current metadata are checked first, as action may change them before the player
+// is subscribed to.
+func (i *Player) waitForMetadata(ctx context.Context, action func() error, match func(Metadata) bool) (Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// subscribe before acting, so the change isn't missed
+	events, err := i.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := action(); err != nil {
+		return nil, err
+	}
+	if metadata, err := i.WithContext(ctx).GetMetadata(); err != nil {
+		return nil, err
+	} else if match(metadata) {
+		return metadata, nil
+	}
+
+	for event := range events {
+		changed, ok := event.(PropertiesChangedEvent)
+		if !ok || changed.Interface != PlayerInterface {
+			continue
+		}
+		if value, ok := changed.Changed["Metadata"].Value().(map[string]dbus.Variant); ok && match(Metadata(value)) {
+			return Metadata(value), nil
+		}
+	}
+	return nil, closedError(ctx)
+}
+
+// closedError returns why an event stream was closed: either the context is done or the
+// connection was closed.
+func closedError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbus.ErrClosed
+}
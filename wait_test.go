@@ -0,0 +1,166 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pauloo27/go-mpris/mpristest"
+	"github.com/godbus/dbus/v5"
+)
+
+func TestWaitForPlayer(t *testing.T) {
+	player, _ := newTestPlayer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	found, err := WaitForPlayer(ctx, player.conn, player.GetName(), WithWaitPlayerOptions(WithStats()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.GetName() != player.GetName() {
+		t.Errorf("Expected %s, got %s", player.GetName(), found.GetName())
+	}
+	if found.Stats() == nil {
+		t.Error("Expected the player to be created with the options")
+	}
+
+	name := fmt.Sprintf("waiting.instance%d", os.Getpid())
+	conn := mpristest.PrivateConn(t)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		fake, err := mpristest.New(conn, name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		fake.SetProperty(BaseInterface, "Identity", "Waiting Player")
+	}()
+
+	found, err = WaitForPlayer(ctx, player.conn, "waiting.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.GetName() != BaseInterface+"."+name {
+		t.Errorf("Expected %s, got %s", name, found.GetName())
+	}
+
+	found, err = WaitForPlayer(ctx, player.conn, "Waiting P*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.GetName() != BaseInterface+"."+name {
+		t.Errorf("Expected %s by identity, got %s", name, found.GetName())
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if _, err := WaitForPlayer(shortCtx, player.conn, "missing"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWaitForStatus(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := player.WaitForStatus(ctx, PlaybackStopped); err != nil {
+		t.Errorf("Expected the current status to be returned right away, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		fake.SetPlaybackStatus(string(PlaybackPaused))
+		fake.SetPlaybackStatus(string(PlaybackPlaying))
+	}()
+	if err := player.WaitForStatus(ctx, PlaybackPlaying); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWaitForTrackChange(t *testing.T) {
+	player, fake := newTestPlayer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		fake.SetMetadata(map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/1")),
+			"xesam:title":   dbus.MakeVariant("Next"),
+		})
+	}()
+
+	metadata, err := player.WaitForTrackChange(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Title() != "Next" {
+		t.Errorf("Expected track Next, got %s", metadata.Title())
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if _, err := player.WaitForTrackChange(shortCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestOpenUriAndWait(t *testing.T) {
+	player, fake := newTestPlayer(t)
+	track := func(id, url string) map[string]dbus.Variant {
+		metadata := map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(dbus.ObjectPath("/org/mpris/MediaPlayer2/Track/" + id)),
+		}
+		if url != "" {
+			metadata["xesam:url"] = dbus.MakeVariant(url)
+		}
+		return metadata
+	}
+	if err := fake.SetMetadata(track("1", "file:///music/a.mp3")); err != nil {
+		t.Fatal(err)
+	}
+	// the player loads the track in two steps, the URL coming last
+	fake.HandleFunc(PlayerInterface, "OpenUri", func(args ...interface{}) *dbus.Error {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			fake.SetMetadata(track("1", ""))
+			time.Sleep(50 * time.Millisecond)
+			fake.SetMetadata(track("2", "file:///music/b%20c.mp3"))
+		}()
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	metadata, err := player.OpenUriAndWait(ctx, "file:///music/b c.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.TrackID() != "/org/mpris/MediaPlayer2/Track/2" {
+		t.Errorf("Expected the metadata of the new track, got %v", metadata)
+	}
+
+	// without URLs, the track id changing is enough
+	fake.HandleFunc(PlayerInterface, "OpenUri", func(args ...interface{}) *dbus.Error {
+		go fake.SetMetadata(track("3", ""))
+		return nil
+	})
+	if metadata, err := player.OpenUriAndWait(ctx, "file:///music/d.mp3"); err != nil || metadata.TrackID() != "/org/mpris/MediaPlayer2/Track/3" {
+		t.Errorf("Expected the metadata of the track 3, got %v %v", metadata, err)
+	}
+
+	fake.HandleFunc(PlayerInterface, "OpenUri", func(args ...interface{}) *dbus.Error { return nil })
+	short, cancelShort := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelShort()
+	if _, err := player.OpenUriAndWait(short, "file:///music/e.mp3"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to time out, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package mpris
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName                = "org.freedesktop.DBus"
+	listNamesMethod        = busName + ".ListNames"
+	nameOwnerChangedSignal = busName + ".NameOwnerChanged"
+)
+
+// WatchEvent is a change of the owner of a player name. OldOwner is empty when the player
+// appeared and NewOwner is empty when it vanished.
+type WatchEvent struct {
+	Name     string
+	OldOwner string
+	NewOwner string
+}
+
+// Appeared returns true if the player appeared on the bus.
+func (e WatchEvent) Appeared() bool {
+	return e.OldOwner == "" && e.NewOwner != ""
+}
+
+// Vanished returns true if the player left the bus.
+func (e WatchEvent) Vanished() bool {
+	return e.OldOwner != "" && e.NewOwner == ""
+}
+
+// Watcher keeps track of the players on the bus and of their unique owner names. It's safe for
+// concurrent use.
+type Watcher struct {
+	conn    *dbus.Conn
+	rule    []dbus.MatchOption
+	signals chan *dbus.Signal
+	events  chan WatchEvent
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	owners map[string]string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewWatcher starts watching the players on the bus. The changes are sent to the Events
+// channel, which has to be drained, until Close is called.
+func NewWatcher(conn *dbus.Conn) (*Watcher, error) {
+	w := &Watcher{
+		conn: conn,
+		rule: []dbus.MatchOption{
+			dbus.WithMatchSender(busName),
+			dbus.WithMatchObjectPath("/org/freedesktop/DBus"),
+			dbus.WithMatchInterface(busName),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchOption("arg0namespace", BaseInterface),
+		},
+		signals: make(chan *dbus.Signal, 16),
+		events:  make(chan WatchEvent, 16),
+		done:    make(chan struct{}),
+		owners:  make(map[string]string),
+	}
+
+	// the match rule goes first, so no change is lost between listing and watching
+	if err := conn.AddMatchSignal(w.rule...); err != nil {
+		return nil, err
+	}
+	conn.Signal(w.signals)
+
+	if err := w.listOwners(); err != nil {
+		conn.RemoveSignal(w.signals)
+		conn.RemoveMatchSignal(w.rule...)
+		return nil, err
+	}
+
+	go w.watch()
+	return w, nil
+}
+
+func (w *Watcher) listOwners() error {
+	var names []string
+	err := w.conn.BusObject().Call(listNamesMethod, 0).Store(&names)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !isPlayerName(name) {
+			continue
+		}
+		var owner string
+		err := w.conn.BusObject().Call(getNameOwnerMethod, 0, name).Store(&owner)
+		if err != nil {
+			// the player quit in the meantime
+			continue
+		}
+		w.owners[name] = owner
+	}
+	return nil
+}
+
+func isPlayerName(name string) bool {
+	return strings.HasPrefix(name, BaseInterface+".")
+}
+
+func (w *Watcher) watch() {
+	defer close(w.events)
+	for {
+		select {
+		case <-w.done:
+			return
+		case sig, ok := <-w.signals:
+			if !ok {
+				return
+			}
+			event, ok := parseNameOwnerChanged(sig)
+			if !ok || !isPlayerName(event.Name) {
+				continue
+			}
+
+			w.mu.Lock()
+			if event.NewOwner == "" {
+				delete(w.owners, event.Name)
+			} else {
+				w.owners[event.Name] = event.NewOwner
+			}
+			w.mu.Unlock()
+
+			select {
+			case w.events <- event:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+func parseNameOwnerChanged(sig *dbus.Signal) (WatchEvent, bool) {
+	if sig.Name != nameOwnerChangedSignal || sig.Sender != busName {
+		return WatchEvent{}, false
+	}
+	if len(sig.Body) < 3 {
+		return WatchEvent{}, false
+	}
+	name, ok1 := sig.Body[0].(string)
+	oldOwner, ok2 := sig.Body[1].(string)
+	newOwner, ok3 := sig.Body[2].(string)
+	if !ok1 || !ok2 || !ok3 {
+		return WatchEvent{}, false
+	}
+	return WatchEvent{name, oldOwner, newOwner}, true
+}
+
+// Events returns the channel receiving the changes. It's closed when the watcher is closed.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Players returns the names of the players currently on the bus.
+func (w *Watcher) Players() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	names := make([]string, 0, len(w.owners))
+	for name := range w.owners {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Owner returns the unique name of the owner of the player name, like ":1.42", or an empty
+// string if the player is not on the bus.
+func (w *Watcher) Owner(name string) string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.owners[name]
+}
+
+// Close stops watching and removes the match rule.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.conn.RemoveSignal(w.signals)
+		w.closeErr = w.conn.RemoveMatchSignal(w.rule...)
+	})
+	return w.closeErr
+}